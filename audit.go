@@ -0,0 +1,256 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+)
+
+// Methods which are not written to the audit log since top calls them every
+// refresh.
+var auditQuietMethods = map[string]bool{
+	"Server.Stats":  true,
+	"Server.Queues": true,
+}
+
+// auditPeer describes who is on the other end of a control connection.
+type auditPeer struct {
+	// unix socket, tcp or grpc.
+	Transport string
+	// Set for unix socket connections on platforms that report the
+	// credentials of the peer.
+	Uid  *int   `json:",omitempty"`
+	User string `json:",omitempty"`
+	Pid  int    `json:",omitempty"`
+	// Remote address of tcp and grpc connections.
+	Addr string `json:",omitempty"`
+}
+
+// auditEntry is one line of the audit log.
+type auditEntry struct {
+	Time     time.Time
+	Peer     auditPeer
+	Method   string
+	Args     json.RawMessage `json:",omitempty"`
+	Result   string
+	Error    string `json:",omitempty"`
+	Duration time.Duration
+}
+
+// auditLog appends a JSON line for every control command the daemon handles.
+// The file is only ever opened for appending, so entries written by previous
+// daemons are kept.
+type auditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// The audit log of the daemon, or nil if --audit-log is not set.
+var gAudit *auditLog
+
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{file: file}, nil
+}
+
+// record writes entry to the log. Failing to write is logged but does not
+// fail the command.
+func (a *auditLog) record(entry auditEntry) {
+	if a == nil {
+		return
+	}
+	if entry.Error == "" {
+		entry.Result = "ok"
+	} else {
+		entry.Result = "error"
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Unable to serialize audit entry for %s: %s", entry.Method, err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		log.Printf("Unable to write audit log: %s", err.Error())
+	}
+}
+
+func (a *auditLog) close() error {
+	if a == nil {
+		return nil
+	}
+	return a.file.Close()
+}
+
+// auditArgs serializes the arguments of a command for the audit log. Secrets,
+// ie, in init options, are redacted.
+func auditArgs(args interface{}) json.RawMessage {
+	if args == nil {
+		return nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+	return redactJSON(data)
+}
+
+// serveRPC serves the control commands sent over c, recording them in the
+// audit log if there is one. Requests over the rate limits are rejected, and
+// audited as such.
+func serveRPC(c net.Conn) {
+	peer := socketPeer(c)
+	if _, isTCP := c.RemoteAddr().(*net.TCPAddr); isTCP {
+		peer = auditPeer{Transport: "tcp", Addr: c.RemoteAddr().String()}
+	}
+	var codec rpc.ServerCodec = newGobServerCodec(c)
+	if gChaos != nil {
+		codec = newChaosCodec(codec)
+	}
+	if gAudit != nil {
+		codec = &auditCodec{
+			ServerCodec: codec,
+			audit:       gAudit,
+			peer:        peer,
+			pending:     make(map[uint64]*auditEntry),
+		}
+	}
+	rpc.ServeCodec(&rateLimitCodec{
+		ServerCodec: codec,
+		client:      socketClient(c, peer),
+		pending:     make(map[uint64]func()),
+	})
+}
+
+// auditCodec wraps the codec of a control connection and records each request
+// once its response is written. net/rpc reads requests sequentially but runs
+// them concurrently, so pending is guarded by mu.
+type auditCodec struct {
+	rpc.ServerCodec
+	audit *auditLog
+	peer  auditPeer
+
+	// The request whose body is read next.
+	current rpc.Request
+
+	mu      sync.Mutex
+	pending map[uint64]*auditEntry
+}
+
+func (c *auditCodec) ReadRequestHeader(r *rpc.Request) error {
+	err := c.ServerCodec.ReadRequestHeader(r)
+	c.current = *r
+	return err
+}
+
+func (c *auditCodec) ReadRequestBody(body interface{}) error {
+	err := c.ServerCodec.ReadRequestBody(body)
+	if auditQuietMethods[c.current.ServiceMethod] {
+		return err
+	}
+	entry := &auditEntry{
+		Time:   time.Now(),
+		Peer:   c.peer,
+		Method: c.current.ServiceMethod,
+		Args:   auditArgs(body),
+	}
+	c.mu.Lock()
+	c.pending[c.current.Seq] = entry
+	c.mu.Unlock()
+	return err
+}
+
+func (c *auditCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.mu.Lock()
+	entry, has := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mu.Unlock()
+
+	err := c.ServerCodec.WriteResponse(r, body)
+	if has {
+		entry.Error = r.Error
+		entry.Duration = time.Since(entry.Time)
+		c.audit.record(*entry)
+	}
+	return err
+}
+
+// gobServerCodec is the codec rpc.ServeConn uses, which net/rpc does not
+// export.
+type gobServerCodec struct {
+	rwc    io.ReadWriteCloser
+	dec    *gob.Decoder
+	enc    *gob.Encoder
+	encBuf *bufio.Writer
+	closed bool
+}
+
+func newGobServerCodec(conn io.ReadWriteCloser) *gobServerCodec {
+	buf := bufio.NewWriter(conn)
+	return &gobServerCodec{
+		rwc:    conn,
+		dec:    gob.NewDecoder(conn),
+		enc:    gob.NewEncoder(buf),
+		encBuf: buf,
+	}
+}
+
+func (c *gobServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	return c.dec.Decode(r)
+}
+
+func (c *gobServerCodec) ReadRequestBody(body interface{}) error {
+	return c.dec.Decode(body)
+}
+
+func (c *gobServerCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	if err := c.enc.Encode(r); err != nil {
+		if c.encBuf.Flush() == nil {
+			// Gob couldn't encode the header; shut down the connection.
+			c.Close()
+		}
+		return err
+	}
+	if err := c.enc.Encode(body); err != nil {
+		if c.encBuf.Flush() == nil {
+			// Gob couldn't encode the body; shut down the connection.
+			c.Close()
+		}
+		return err
+	}
+	return c.encBuf.Flush()
+}
+
+func (c *gobServerCodec) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.rwc.Close()
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"net"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// socketPeer returns the credentials of the process connected to c.
+func socketPeer(c net.Conn) auditPeer {
+	peer := auditPeer{Transport: "unix"}
+	unix, ok := c.(*net.UnixConn)
+	if !ok {
+		return peer
+	}
+	raw, err := unix.SyscallConn()
+	if err != nil {
+		return peer
+	}
+	var cred *syscall.Ucred
+	raw.Control(func(fd uintptr) {
+		cred, err = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || cred == nil {
+		return peer
+	}
+	uid := int(cred.Uid)
+	peer.Uid = &uid
+	peer.Pid = int(cred.Pid)
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		peer.User = u.Username
+	}
+	return peer
+}
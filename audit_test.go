@@ -0,0 +1,111 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type auditTestService struct{}
+
+type AuditTestArgs struct {
+	Name string
+}
+
+func (auditTestService) Greet(args AuditTestArgs, reply *string) error {
+	if args.Name == "" {
+		return errors.New("no name")
+	}
+	*reply = "hello " + args.Name
+	return nil
+}
+
+func (auditTestService) Stats(_ bool, _ *bool) error {
+	return nil
+}
+
+func readAuditLog(t *testing.T, path string) []auditEntry {
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry auditEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditCodec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	// Entries from a previous daemon are kept.
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"Method":"Server.Earlier"}`+"\n"), 0600))
+	audit, err := openAuditLog(path)
+	assert.NoError(t, err)
+
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("Server", auditTestService{}))
+	serverConn, clientConn := net.Pipe()
+	done := make(chan bool)
+	go func() {
+		server.ServeCodec(&auditCodec{
+			ServerCodec: newGobServerCodec(serverConn),
+			audit:       audit,
+			peer:        auditPeer{Transport: "unix", User: "alice"},
+			pending:     make(map[uint64]*auditEntry),
+		})
+		done <- true
+	}()
+
+	client := rpc.NewClient(clientConn)
+	var reply string
+	assert.NoError(t, client.Call("Server.Greet", AuditTestArgs{Name: "bob"}, &reply))
+	assert.Equal(t, "hello bob", reply)
+	assert.EqualError(t, client.Call("Server.Greet", AuditTestArgs{}, &reply), "no name")
+	assert.NoError(t, client.Call("Server.Stats", false, nil))
+	client.Close()
+	<-done
+	assert.NoError(t, audit.close())
+
+	entries := readAuditLog(t, path)
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, "Server.Earlier", entries[0].Method)
+
+		assert.Equal(t, "Server.Greet", entries[1].Method)
+		assert.Equal(t, "alice", entries[1].Peer.User)
+		assert.JSONEq(t, `{"Name":"bob"}`, string(entries[1].Args))
+		assert.Equal(t, "ok", entries[1].Result)
+
+		assert.Equal(t, "error", entries[2].Result)
+		assert.Equal(t, "no name", entries[2].Error)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AutoStartRule starts a language server for the project enclosing a file
+// when the file is queried and no server runs for it, ie,
+//
+//	[[autostart]]
+//	markers = ["compile_commands.json", "build/compile_commands.json"]
+//	extensions = [".c", ".cc", ".h"]
+//	language = "cpp"
+//
+//	[[autostart]]
+//	markers = ["go.mod"]
+//	extensions = [".go"]
+//	command = "gopls"
+//
+// The project directory is the closest directory containing the file that
+// has one of the markers. The first rule that matches the file and finds a
+// project directory applies.
+type AutoStartRule struct {
+	// Files or directories, relative to the project directory, that mark
+	// it, ie, Cargo.toml or package.json. Without markers the directory of
+	// the file is used.
+	Markers []string `toml:"markers,omitempty"`
+
+	// Extensions of the files the rule applies to, ie, ".rs". Empty matches
+	// every file.
+	Extensions []string `toml:"extensions,omitempty"`
+
+	// Configured language whose command is started.
+	Language string `toml:"language,omitempty"`
+
+	// Runs instead of the command of the language.
+	Command string `toml:"command,omitempty"`
+}
+
+func (r AutoStartRule) validate() map[string]error {
+	problems := make(map[string]error)
+	if len(r.Markers) == 0 && len(r.Extensions) == 0 {
+		problems["markers"] = fmt.Errorf("either markers or extensions must be set")
+	}
+	for _, marker := range r.Markers {
+		if marker == "" || filepath.IsAbs(marker) {
+			problems["markers"] = fmt.Errorf("must be relative to the project directory, got %q", marker)
+		}
+	}
+	for _, extension := range r.Extensions {
+		if strings.TrimPrefix(extension, ".") == "" {
+			problems["extensions"] = fmt.Errorf("must not be empty")
+		}
+	}
+	validateCommand(r.Command, r.Language, problems)
+	return problems
+}
+
+// matches returns if the rule applies to path by its extension. Extensions
+// may be given with or without the leading dot.
+func (r AutoStartRule) matches(path string) bool {
+	if len(r.Extensions) == 0 {
+		return true
+	}
+	extension := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, e := range r.Extensions {
+		if strings.TrimPrefix(e, ".") == extension && extension != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// projectFor returns the closest directory containing path that has one of
+// the markers of the rule.
+func (r AutoStartRule) projectFor(path string) (string, bool) {
+	directory := filepath.Dir(filepath.Clean(path))
+	if len(r.Markers) == 0 {
+		return directory, true
+	}
+	for {
+		for _, marker := range r.Markers {
+			if _, err := os.Stat(filepath.Join(directory, marker)); err == nil {
+				return directory, true
+			}
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", false
+		}
+		directory = parent
+	}
+}
+
+// autoStartArgs returns how to start the server of the first auto-start rule
+// that applies to path, if any.
+func (c *Config) autoStartArgs(path string) (StartArgs, bool, error) {
+	for _, rule := range c.AutoStart {
+		if len(rule.validate()) > 0 || !rule.matches(path) {
+			continue
+		}
+		directory, found := rule.projectFor(path)
+		if !found {
+			continue
+		}
+		args, err := c.rootStartArgs(RootConfig{Language: rule.Language, Command: rule.Command}, directory)
+		return args, err == nil, err
+	}
+	return StartArgs{}, false, nil
+}
+
+// autoStartArgsIn returns how to start the servers of the auto-start rules
+// that apply to the files in directory, each server once. Hidden directories,
+// ie, .git, are skipped.
+func (c *Config) autoStartArgsIn(directory string) ([]StartArgs, error) {
+	var all []StartArgs
+	// Which rule applies to a file only depends on its directory and
+	// extension.
+	checked := make(map[string]bool)
+	found := make(map[string]bool)
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Skip what cannot be read.
+			return nil
+		}
+		if info.IsDir() {
+			if path != directory && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		key := filepath.Dir(path) + "\x00" + filepath.Ext(path)
+		if checked[key] {
+			return nil
+		}
+		checked[key] = true
+		args, applies, err := c.autoStartArgs(path)
+		if err != nil {
+			return err
+		}
+		if server := args.Bin + "\x00" + args.Directory; applies && !found[server] {
+			found[server] = true
+			all = append(all, args)
+		}
+		return nil
+	})
+	return all, err
+}
+
+// StartAuto starts the language servers of the auto-start rules that apply
+// to the files in directory which are not already running, as if every file
+// had been queried. Like Up it does not wait for them to initialize.
+func (s *Server) StartAuto(directory string, servers *[]UpServer) error {
+	log.Printf("CMD start-auto %s", directory)
+	all, err := s.currentConfig().autoStartArgsIn(directory)
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("no [[autostart]] rule applies to the files in %s", directory)
+	}
+	*servers = s.startMissing(all)
+	return nil
+}
+
+// startAutoFor starts the language server of the auto-start rules for path
+// unless a server already runs for it. Returns if a server was started.
+func (s *Server) startAutoFor(path string) (bool, error) {
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+
+	// Another query may have started it while this one waited.
+	if _, found := serverForPath(s.serverInfos(), path); found {
+		return false, nil
+	}
+	args, found, err := s.currentConfig().autoStartArgs(path)
+	if err != nil || !found {
+		return false, err
+	}
+	log.Printf("Starting %s for %s", args.Bin, args.Directory)
+	if _, err := s.start(args); err != nil {
+		return false, err
+	}
+	return true, nil
+}
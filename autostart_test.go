@@ -0,0 +1,124 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoStartRuleValidate(t *testing.T) {
+	assert.Empty(t, AutoStartRule{Markers: []string{"go.mod"}, Extensions: []string{".go"}, Command: "gopls"}.validate())
+	assert.Empty(t, AutoStartRule{Extensions: []string{"py"}, Language: "python"}.validate())
+
+	problems := AutoStartRule{}.validate()
+	assert.Contains(t, problems, "markers")
+	assert.Contains(t, problems, "command")
+	assert.Contains(t, AutoStartRule{Markers: []string{"/src/go.mod"}, Command: "gopls"}.validate(), "markers")
+	assert.Contains(t, AutoStartRule{Extensions: []string{"."}, Command: "gopls"}.validate(), "extensions")
+}
+
+func TestAutoStartRuleMatches(t *testing.T) {
+	rule := AutoStartRule{Extensions: []string{".cc", "h"}}
+	assert.True(t, rule.matches("/src/a.cc"))
+	assert.True(t, rule.matches("/src/a.h"))
+	assert.False(t, rule.matches("/src/a.c"))
+	assert.False(t, rule.matches("/src/Makefile"))
+	assert.True(t, AutoStartRule{}.matches("/src/Makefile"))
+}
+
+func TestAutoStartArgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	native := filepath.Join(dir, "native")
+	assert.NoError(t, os.MkdirAll(filepath.Join(native, "src", "build"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), nil, 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(native, "src", "build", "compile_commands.json"), nil, 0644))
+
+	config := &Config{
+		AutoStart: []AutoStartRule{
+			{Markers: []string{"build/compile_commands.json"}, Extensions: []string{".cc"}, Language: "cpp"},
+			{Markers: []string{"go.mod"}, Extensions: []string{".go"}, Command: "gopls"},
+			{Markers: []string{"package.json"}, Command: "typescript-language-server --stdio"},
+			{Extensions: []string{".py"}, Language: "python"},
+		},
+		Languages: map[string]LanguageConfig{"cpp": {Command: "clangd"}},
+	}
+
+	// The closest directory with a marker is the project.
+	args, found, err := config.autoStartArgs(filepath.Join(native, "src", "a", "a.cc"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, StartArgs{Bin: "clangd", Directory: filepath.Join(native, "src"), Language: "cpp"}, args)
+
+	args, found, err = config.autoStartArgs(filepath.Join(native, "src", "main.go"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, StartArgs{Bin: "gopls", Directory: dir}, args)
+
+	// No marker is found, and rules for other extensions do not apply.
+	_, found, err = config.autoStartArgs(filepath.Join(dir, "index.ts"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// The language of the rule has no command.
+	_, found, err = config.autoStartArgs(filepath.Join(dir, "a.py"))
+	assert.Error(t, err)
+	assert.False(t, found)
+}
+
+func TestAutoStartArgsIn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	native := filepath.Join(dir, "native")
+	for _, path := range []string{
+		"go.mod", "main.go", "cmd/tool/main.go", "README.md",
+		"native/src/build/compile_commands.json", "native/src/a/a.cc", "native/src/b/b.cc",
+		// Would fail, since the language of the rule has no command.
+		".cache/a.py",
+	} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	config := &Config{
+		AutoStart: []AutoStartRule{
+			{Markers: []string{"build/compile_commands.json"}, Extensions: []string{".cc"}, Language: "cpp"},
+			{Markers: []string{"go.mod"}, Extensions: []string{".go"}, Command: "gopls"},
+			{Extensions: []string{".py"}, Language: "python"},
+		},
+		Languages: map[string]LanguageConfig{"cpp": {Command: "clangd"}},
+	}
+	all, err := config.autoStartArgsIn(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []StartArgs{
+		{Bin: "gopls", Directory: dir},
+		{Bin: "clangd", Directory: filepath.Join(native, "src"), Language: "cpp"},
+	}, all)
+
+	all, err = config.autoStartArgsIn(filepath.Join(native, "src", "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []StartArgs{{Bin: "clangd", Directory: filepath.Join(native, "src"), Language: "cpp"}}, all)
+
+	_, err = config.autoStartArgsIn(filepath.Join(dir, ".cache"))
+	assert.Error(t, err)
+}
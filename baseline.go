@@ -0,0 +1,141 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// baseline records the diagnostics of a project that are accepted for now,
+// so that only new diagnostics are reported. Entries have no positions so
+// that they still match after unrelated edits move them.
+type baseline struct {
+	Diagnostics []baselineEntry `json:"diagnostics"`
+}
+
+type baselineEntry struct {
+	// Relative to the directory of the baseline file, with / as separator.
+	File     string `json:"file"`
+	Severity string `json:"severity"`
+	Source   string `json:"source,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	// Number of identical diagnostics in the file.
+	Count int `json:"count"`
+}
+
+// baselineKey returns the entry d matches in the baseline at path, with a
+// count of zero.
+func baselineKey(path string, file string, d Diagnostic) baselineEntry {
+	if rel, err := filepath.Rel(filepath.Dir(path), file); err == nil {
+		file = rel
+	}
+	return baselineEntry{
+		File:     filepath.ToSlash(file),
+		Severity: d.Severity,
+		Source:   d.Source,
+		Code:     d.Code,
+		Message:  d.Message,
+	}
+}
+
+// newBaseline returns a baseline to be saved at path which accepts
+// diagnostics.
+func newBaseline(path string, diagnostics map[string][]Diagnostic) *baseline {
+	counts := make(map[baselineEntry]int)
+	for file, ds := range diagnostics {
+		for _, d := range ds {
+			counts[baselineKey(path, file, d)]++
+		}
+	}
+
+	b := &baseline{Diagnostics: []baselineEntry{}}
+	for entry, count := range counts {
+		entry.Count = count
+		b.Diagnostics = append(b.Diagnostics, entry)
+	}
+	sort.Slice(b.Diagnostics, func(i, j int) bool {
+		x, y := b.Diagnostics[i], b.Diagnostics[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Source != y.Source {
+			return x.Source < y.Source
+		}
+		if x.Code != y.Code {
+			return x.Code < y.Code
+		}
+		return x.Message < y.Message
+	})
+	return b
+}
+
+// total returns the number of diagnostics in the baseline.
+func (b *baseline) total() int {
+	total := 0
+	for _, entry := range b.Diagnostics {
+		total += entry.Count
+	}
+	return total
+}
+
+func loadBaseline(path string) (*baseline, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &baseline{}
+	if err := json.Unmarshal(content, b); err != nil {
+		return nil, fmt.Errorf("cannot read baseline %s: %s", path, err.Error())
+	}
+	return b, nil
+}
+
+func (b *baseline) save(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// newDiagnostics returns the diagnostics that are not in the baseline, which
+// was loaded from path. A diagnostic that occurs more often than the baseline
+// allows is new, although which of the occurrences is reported is arbitrary.
+func (b *baseline) newDiagnostics(path string, diagnostics map[string][]Diagnostic) map[string][]Diagnostic {
+	remaining := make(map[baselineEntry]int)
+	for _, entry := range b.Diagnostics {
+		count := entry.Count
+		entry.Count = 0
+		remaining[entry] += count
+	}
+
+	out := make(map[string][]Diagnostic)
+	for file, ds := range diagnostics {
+		for _, d := range ds {
+			key := baselineKey(path, file, d)
+			if remaining[key] > 0 {
+				remaining[key]--
+				continue
+			}
+			out[file] = append(out[file], d)
+		}
+	}
+	return out
+}
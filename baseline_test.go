@@ -0,0 +1,70 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "baseline.json")
+	a := filepath.Join(dir, "src/a.c")
+	unused := Diagnostic{Location: Location{File: a, Line: 3}, Severity: "warning", Source: "clang", Code: "W1", Message: "unused x"}
+	diagnostics := map[string][]Diagnostic{a: {unused, unused}}
+
+	b := newBaseline(path, diagnostics)
+	assert.Equal(t, []baselineEntry{{File: "src/a.c", Severity: "warning", Source: "clang", Code: "W1", Message: "unused x", Count: 2}}, b.Diagnostics)
+	assert.Equal(t, 2, b.total())
+	assert.NoError(t, b.save(path))
+	b, err = loadBaseline(path)
+	assert.NoError(t, err)
+
+	// Moving the diagnostics does not make them new.
+	moved := unused
+	moved.Line = 10
+	assert.Empty(t, b.newDiagnostics(path, map[string][]Diagnostic{a: {moved, moved}}))
+
+	// A third occurrence and a different message are new.
+	other := unused
+	other.Message = "unused y"
+	assert.Equal(t, map[string][]Diagnostic{a: {unused, other}},
+		b.newDiagnostics(path, map[string][]Diagnostic{a: {unused, unused, unused, other}}))
+
+	// So is the same diagnostic in another file.
+	c := filepath.Join(dir, "src/c.c")
+	assert.Equal(t, map[string][]Diagnostic{c: {unused}}, b.newDiagnostics(path, map[string][]Diagnostic{c: {unused}}))
+}
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{Location: Location{File: "/a.c", Line: 2, Column: 5}, Severity: "error", Message: "expected ;"}
+	assert.Equal(t, "/a.c:2:5: error: expected ;", d.String())
+	d.Source = "clang"
+	assert.Equal(t, "/a.c:2:5: error: expected ; [clang]", d.String())
+	d.Code = "E12"
+	assert.Equal(t, "/a.c:2:5: error: expected ; [clang E12]", d.String())
+	d.Server = "clangd, clang"
+	assert.Equal(t, "/a.c:2:5: error: expected ; [clang E12] (clangd, clang)", d.String())
+	d.Server = "clang"
+	assert.Equal(t, "/a.c:2:5: error: expected ; [clang E12]", d.String())
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+)
+
+// BenchIndexArgs describes a server to cold-start for `lspc bench index`.
+type BenchIndexArgs struct {
+	// If empty, the command configured for Language.
+	Bin       string
+	Directory string
+	Language  string
+	// Indexing is done once no $/progress token has been active for this
+	// long.
+	Settle  time.Duration
+	Timeout time.Duration
+}
+
+// BenchIndexResult is written as JSON so that CI can track it over time.
+type BenchIndexResult struct {
+	Command   string `json:"command"`
+	Directory string `json:"directory"`
+	Version   string `json:"version,omitempty"`
+	// From starting the server until indexing finished. Excludes Settle.
+	WallClockSeconds float64 `json:"wall_clock_seconds"`
+	// Peak resident set size of the server, or 0 if the platform does not
+	// report it.
+	PeakRSSBytes int64 `json:"peak_rss_bytes"`
+	// Titles of the $/progress tokens the server reported, in order.
+	Progress []string `json:"progress"`
+	TimedOut bool     `json:"timed_out,omitempty"`
+}
+
+// BenchIndex starts a new language server, waits until it has finished
+// indexing, stops it and reports how long that took and how much memory it
+// used. The server is separate from any already running for the directory.
+func (s *Server) BenchIndex(args BenchIndexArgs, result *BenchIndexResult) error {
+	log.Printf("CMD bench index %s in %s", args.Bin, args.Directory)
+	if args.Bin == "" {
+		language, has := s.currentConfig().Languages[args.Language]
+		if !has || language.Command == "" {
+			return fmt.Errorf("no command given and language %q has no command configured", args.Language)
+		}
+		args.Bin = language.Command
+	}
+
+	// Subscribe before starting so that no progress is missed.
+	sub := daemonEvents.subscribe()
+	defer daemonEvents.unsubscribe(sub)
+
+	start := time.Now()
+	ls, err := s.start(StartArgs{Bin: args.Bin, Directory: args.Directory, Language: args.Language})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := s.stopServer(ls.id); err == nil {
+			ls.close(closeTimeout)
+		}
+	}()
+
+	active := make(map[string]bool)
+	finished := start
+	deadline := time.After(args.Timeout)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+wait:
+	for {
+		select {
+		case e := <-sub.c:
+			if e.Server.ID != ls.id {
+				continue
+			}
+			switch e.Kind {
+			case EventServerStopped:
+				return fmt.Errorf("%s exited while indexing (%s)", ls.name(), e.ExitStatus)
+			case EventProgress:
+				if e.Done {
+					delete(active, e.Progress.Token)
+				} else if !active[e.Progress.Token] {
+					active[e.Progress.Token] = true
+					result.Progress = append(result.Progress, e.Progress.Title)
+				}
+				finished = time.Now()
+			}
+		case <-ticker.C:
+			ls.mu.Lock()
+			initialized := ls.initialized
+			ls.mu.Unlock()
+			if !initialized {
+				finished = time.Now()
+			} else if len(active) == 0 && time.Since(finished) >= args.Settle {
+				break wait
+			}
+		case <-deadline:
+			result.TimedOut = true
+			finished = time.Now()
+			break wait
+		}
+	}
+
+	info := ls.info()
+	result.Command = args.Bin
+	result.Directory = args.Directory
+	result.Version = info.Version
+	result.WallClockSeconds = finished.Sub(start).Seconds()
+
+	if _, err := s.stopServer(ls.id); err != nil {
+		return err
+	}
+	ls.close(closeTimeout)
+	result.PeakRSSBytes = peakRSS(ls.cmd.ProcessState)
+	return nil
+}
+
+// writeBenchResult writes result as indented JSON to path, or stdout if path
+// is empty.
+func writeBenchResult(result BenchIndexResult, path string) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBenchResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bench.json")
+
+	result := BenchIndexResult{
+		Command:          "clangd",
+		Directory:        "/src",
+		WallClockSeconds: 12.5,
+		PeakRSSBytes:     1 << 30,
+		Progress:         []string{"Indexing"},
+	}
+	assert.NoError(t, writeBenchResult(result, path))
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, 12.5, fields["wall_clock_seconds"])
+	assert.Equal(t, float64(1<<30), fields["peak_rss_bytes"])
+	assert.NotContains(t, fields, "timed_out")
+	assert.NotContains(t, fields, "version")
+}
+
+func TestPeakRSSWithoutState(t *testing.T) {
+	assert.Equal(t, int64(0), peakRSS(nil))
+}
@@ -0,0 +1,343 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	easyjson "github.com/mailru/easyjson"
+)
+
+// Capabilities returns the initialize result of a language server, which
+// contains its ServerCapabilities.
+func (s *Server) Capabilities(id int, result *[]byte) error {
+	log.Printf("CMD capabilities %d", id)
+
+	server, err := s.findServer(id)
+	if err != nil {
+		return err
+	}
+	server.mu.Lock()
+	r := server.initializeResult
+	server.mu.Unlock()
+	if r == nil {
+		return fmt.Errorf("language server %d has not finished initializing", id)
+	}
+	*result = toJSON(r)
+	return nil
+}
+
+// loadCapabilities returns the initialize result for source, which is either
+// the id of a running language server or a snapshot file written by
+// `lspc capabilities show`. Also returns a label describing the source.
+func loadCapabilities(source string) (string, *LsInitializeResult, error) {
+	var data []byte
+	label := source
+	if fileExists(source) {
+		var err error
+		if data, err = ioutil.ReadFile(source); err != nil {
+			return "", nil, err
+		}
+	} else {
+		id, err := strconv.Atoi(source)
+		if err != nil {
+			return "", nil, fmt.Errorf("%q is neither a server id nor a snapshot file", source)
+		}
+		doRPC("Server.Capabilities", id, &data)
+		label = "server " + source
+	}
+
+	result := &LsInitializeResult{}
+	if err := fromJSON(data, result); err != nil {
+		return "", nil, fmt.Errorf("cannot parse %s: %s", source, err.Error())
+	}
+	// Also accept snapshots that contain only the capabilities.
+	if len(result.Capabilities) == 0 {
+		result.Capabilities = data
+	}
+	if result.ServerInfo != nil {
+		label = fmt.Sprintf("%s (%s %s)", label, result.ServerInfo.Name, result.ServerInfo.Version)
+	}
+	return label, result, nil
+}
+
+// showCapabilities prints the initialize result of source in a format that
+// can be saved and later passed to diffCapabilities.
+func showCapabilities(source string) error {
+	_, result, err := loadCapabilities(source)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, toJSON(result), "", "  "); err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// diffCapabilities prints the ServerCapabilities that differ between a and
+// b.
+func diffCapabilities(a, b string) error {
+	labelA, resultA, err := loadCapabilities(a)
+	if err != nil {
+		return err
+	}
+	labelB, resultB, err := loadCapabilities(b)
+	if err != nil {
+		return err
+	}
+
+	var capsA, capsB interface{}
+	if err := json.Unmarshal(resultA.Capabilities, &capsA); err != nil {
+		return fmt.Errorf("cannot parse capabilities of %s: %s", labelA, err.Error())
+	}
+	if err := json.Unmarshal(resultB.Capabilities, &capsB); err != nil {
+		return fmt.Errorf("cannot parse capabilities of %s: %s", labelB, err.Error())
+	}
+
+	var diffs []string
+	diffJSON("", capsA, capsB, &diffs)
+	if gJSON {
+		return printJSON(struct {
+			A, B        string
+			Differences []string
+		}{labelA, labelB, diffs})
+	}
+	fmt.Printf("--- %s\n+++ %s\n", labelA, labelB)
+	if len(diffs) == 0 {
+		fmt.Println("capabilities are identical")
+	}
+	for _, diff := range diffs {
+		fmt.Println(diff)
+	}
+	return nil
+}
+
+// diffJSON appends the differences between the decoded JSON values a and b
+// to diffs. Objects are compared key by key, any other value as a whole.
+// Keys only in a are prefixed with -, keys only in b with + and changed
+// values with ~.
+func diffJSON(path string, a, b interface{}, diffs *[]string) {
+	objectA, aIsObject := a.(map[string]interface{})
+	objectB, bIsObject := b.(map[string]interface{})
+	if !aIsObject || !bIsObject {
+		valueA, valueB := jsonString(a), jsonString(b)
+		if valueA != valueB {
+			*diffs = append(*diffs, fmt.Sprintf("~ %s: %s -> %s", path, valueA, valueB))
+		}
+		return
+	}
+
+	var keys []string
+	for key := range objectA {
+		keys = append(keys, key)
+	}
+	for key := range objectB {
+		if _, has := objectA[key]; !has {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := key
+		if path != "" {
+			child = path + "." + key
+		}
+		valueA, hasA := objectA[key]
+		valueB, hasB := objectB[key]
+		switch {
+		case !hasB:
+			*diffs = append(*diffs, fmt.Sprintf("- %s: %s", child, jsonString(valueA)))
+		case !hasA:
+			*diffs = append(*diffs, fmt.Sprintf("+ %s: %s", child, jsonString(valueB)))
+		default:
+			diffJSON(child, valueA, valueB, diffs)
+		}
+	}
+}
+
+func jsonString(v interface{}) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	// Trigger characters such as < and > should print as-is.
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+}
+
+// serverFeature is a request lspc sends and the server capability that
+// announces support for it, as a path of keys into ServerCapabilities.
+// Requests below the method, ie, textDocument/semanticTokens/full, need its
+// capability unless they are listed themselves, before the method.
+type serverFeature struct {
+	method     string
+	capability string
+}
+
+var serverFeatures = []serverFeature{
+	{"textDocument/hover", "hoverProvider"},
+	{"textDocument/completion", "completionProvider"},
+	{"completionItem/resolve", "completionProvider.resolveProvider"},
+	{"textDocument/definition", "definitionProvider"},
+	{"textDocument/declaration", "declarationProvider"},
+	{"textDocument/typeDefinition", "typeDefinitionProvider"},
+	{"textDocument/implementation", "implementationProvider"},
+	{"textDocument/references", "referencesProvider"},
+	{"textDocument/documentSymbol", "documentSymbolProvider"},
+	{"workspace/symbol", "workspaceSymbolProvider"},
+	{"textDocument/codeAction", "codeActionProvider"},
+	{"codeAction/resolve", "codeActionProvider.resolveProvider"},
+	{"textDocument/codeLens", "codeLensProvider"},
+	{"textDocument/formatting", "documentFormattingProvider"},
+	{"textDocument/rangeFormatting", "documentRangeFormattingProvider"},
+	{"textDocument/rename", "renameProvider"},
+	{"textDocument/prepareRename", "renameProvider.prepareProvider"},
+	{"textDocument/linkedEditingRange", "linkedEditingRangeProvider"},
+	{"textDocument/moniker", "monikerProvider"},
+	{"textDocument/inlineValue", "inlineValueProvider"},
+	{"textDocument/semanticTokens/full/delta", "semanticTokensProvider.full.delta"},
+	{"textDocument/semanticTokens/full", "semanticTokensProvider.full"},
+	{"textDocument/semanticTokens/range", "semanticTokensProvider.range"},
+	{"textDocument/semanticTokens", "semanticTokensProvider"},
+	{"textDocument/diagnostic", "diagnosticProvider"},
+	{"workspace/executeCommand", "executeCommandProvider"},
+}
+
+// featureFor returns the feature of method, if lspc knows which capability
+// announces it.
+func featureFor(method string) (serverFeature, bool) {
+	for _, feature := range serverFeatures {
+		if method == feature.method || strings.HasPrefix(method, feature.method+"/") {
+			return feature, true
+		}
+	}
+	return serverFeature{}, false
+}
+
+// hasCapability returns if the capability at path, ie,
+// renameProvider.prepareProvider, is set in capabilities to something other
+// than null or false.
+func hasCapability(capabilities easyjson.RawMessage, path string) bool {
+	value := json.RawMessage(capabilities)
+	for _, key := range strings.Split(path, ".") {
+		var object map[string]json.RawMessage
+		if json.Unmarshal(value, &object) != nil {
+			return false
+		}
+		var has bool
+		if value, has = object[key]; !has {
+			return false
+		}
+	}
+	trimmed := string(bytes.TrimSpace(value))
+	return trimmed != "null" && trimmed != "false"
+}
+
+// checkSupported fails requests for method if the server did not announce
+// the capability for it in its initialize result. Requests are let through
+// until the server has initialized.
+func (l *languageServer) checkSupported(method string) error {
+	feature, known := featureFor(method)
+	if !known {
+		return nil
+	}
+	l.mu.Lock()
+	result := l.initializeResult
+	l.mu.Unlock()
+	if result == nil || hasCapability(result.Capabilities, feature.capability) {
+		return nil
+	}
+	return fmt.Errorf("%s does not support %s; it does not announce %s", l.name(), method, feature.capability)
+}
+
+// FeatureSupport is whether a language server supports a request lspc sends.
+type FeatureSupport struct {
+	Method     string
+	Capability string
+	Supported  bool
+}
+
+// ServerFeatures lists the requests a language server supports.
+type ServerFeatures struct {
+	Server   ServerInfo
+	Features []FeatureSupport
+}
+
+// Features returns the supported requests of the server matched by target as
+// for stop, or of every running server if target is empty.
+func (s *Server) Features(target string, result *[]ServerFeatures) error {
+	log.Printf("CMD capabilities %s", target)
+
+	servers := s.serverList()
+	if target != "" {
+		server, err := s.findServerByTarget(target)
+		if err != nil {
+			return err
+		}
+		servers = []*languageServer{server}
+	}
+	for _, server := range servers {
+		server.mu.Lock()
+		r := server.initializeResult
+		server.mu.Unlock()
+		features := ServerFeatures{Server: server.info()}
+		if r != nil {
+			for _, feature := range serverFeatures {
+				features.Features = append(features.Features, FeatureSupport{
+					Method:     feature.method,
+					Capability: feature.capability,
+					Supported:  hasCapability(r.Capabilities, feature.capability),
+				})
+			}
+		}
+		*result = append(*result, features)
+	}
+	return nil
+}
+
+// printFeatures prints which requests each server supports.
+func printFeatures(out io.Writer, servers []ServerFeatures) {
+	for i, server := range servers {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, server.Server)
+		if server.Features == nil {
+			fmt.Fprintln(out, "  not initialized yet")
+			continue
+		}
+		w := newTable(out)
+		for _, feature := range server.Features {
+			supported := "no"
+			if feature.Supported {
+				supported = "yes"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", feature.Method, feature.Capability, supported)
+		}
+		w.Flush()
+	}
+}
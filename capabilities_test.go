@@ -0,0 +1,90 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffJSON(t *testing.T) {
+	var a, b interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"hoverProvider": true,
+		"codeLensProvider": {"resolveProvider": false},
+		"completionProvider": {"resolveProvider": true, "triggerCharacters": [".", ":"]}
+	}`), &a))
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"hoverProvider": true,
+		"callHierarchyProvider": true,
+		"completionProvider": {"resolveProvider": false, "triggerCharacters": [".", ">"]}
+	}`), &b))
+
+	var diffs []string
+	diffJSON("", a, b, &diffs)
+	assert.Equal(t, []string{
+		"+ callHierarchyProvider: true",
+		`- codeLensProvider: {"resolveProvider":false}`,
+		"~ completionProvider.resolveProvider: true -> false",
+		`~ completionProvider.triggerCharacters: [".",":"] -> [".",">"]`,
+	}, diffs)
+
+	diffs = nil
+	diffJSON("", a, a, &diffs)
+	assert.Empty(t, diffs)
+}
+
+func TestHasCapability(t *testing.T) {
+	caps := easyjson.RawMessage(`{
+		"hoverProvider": true,
+		"definitionProvider": false,
+		"referencesProvider": null,
+		"renameProvider": true,
+		"completionProvider": {"resolveProvider": true},
+		"semanticTokensProvider": {"full": {"delta": true}}
+	}`)
+	assert.True(t, hasCapability(caps, "hoverProvider"))
+	assert.False(t, hasCapability(caps, "definitionProvider"))
+	assert.False(t, hasCapability(caps, "referencesProvider"))
+	assert.False(t, hasCapability(caps, "codeActionProvider"))
+	assert.True(t, hasCapability(caps, "completionProvider.resolveProvider"))
+	assert.True(t, hasCapability(caps, "semanticTokensProvider.full.delta"))
+	// A server that renames with a plain true cannot prepare renames.
+	assert.False(t, hasCapability(caps, "renameProvider.prepareProvider"))
+}
+
+func TestCheckSupported(t *testing.T) {
+	l := newTestLanguageServer(0, "/p")
+	// Requests are sent before initialize answers.
+	assert.NoError(t, l.checkSupported("textDocument/hover"))
+
+	l.initializeResult = &LsInitializeResult{Capabilities: easyjson.RawMessage(`{"hoverProvider": true, "semanticTokensProvider": {"full": true}}`)}
+	assert.NoError(t, l.checkSupported("textDocument/hover"))
+	assert.NoError(t, l.checkSupported("textDocument/semanticTokens/full"))
+	assert.EqualError(t, l.checkSupported("textDocument/semanticTokens/full/delta"),
+		"fake does not support textDocument/semanticTokens/full/delta; it does not announce semanticTokensProvider.full.delta")
+	assert.EqualError(t, l.checkSupported("textDocument/semanticTokens/range"),
+		"fake does not support textDocument/semanticTokens/range; it does not announce semanticTokensProvider.range")
+	assert.NoError(t, l.checkSupported("workspace/unknownRequest"))
+	err := l.checkSupported("textDocument/definition")
+	assert.EqualError(t, err, "fake does not support textDocument/definition; it does not announce definitionProvider")
+	_, err = l.call("textDocument/definition", nil, time.Second)
+	assert.Error(t, err)
+	assert.Empty(t, l.stdin.(*stdinBuffer).String())
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// chaosFault is what happens to a message picked by --chaos.
+type chaosFault int
+
+const (
+	chaosNone chaosFault = iota
+	chaosDelay
+	chaosDrop
+	chaosError
+)
+
+func (f chaosFault) String() string {
+	switch f {
+	case chaosDelay:
+		return "delay"
+	case chaosDrop:
+		return "drop"
+	case chaosError:
+		return "error"
+	}
+	return "none"
+}
+
+// chaos injects faults into responses and events sent to HTTP, WebSocket
+// and control socket clients, so that editor integrations can be tested against flaky servers.
+type chaos struct {
+	fraction float64
+	maxDelay time.Duration
+	seed     int64
+
+	// rand.Rand is not safe for concurrent use.
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Set by --chaos; nil if no faults are injected.
+var gChaos *chaos
+
+// setChaos enables fault injection for fraction of the messages. Delays are
+// up to maxDelay. A seed of 0 picks a random one.
+func setChaos(fraction float64, maxDelay time.Duration, seed int64) error {
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("--chaos must be between 0 and 1, got %v", fraction)
+	}
+	if maxDelay < 0 {
+		return fmt.Errorf("--chaos-delay must not be negative")
+	}
+	if fraction == 0 {
+		gChaos = nil
+		return nil
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	gChaos = &chaos{fraction: fraction, maxDelay: maxDelay, seed: seed, rand: rand.New(rand.NewSource(seed))}
+	return nil
+}
+
+// pick decides what happens to the next message. canFail is false for
+// messages which cannot carry an error, ie, events; they are dropped
+// instead. Safe to call on a nil chaos.
+func (c *chaos) pick(canFail bool) (chaosFault, time.Duration) {
+	if c == nil {
+		return chaosNone, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rand.Float64() >= c.fraction {
+		return chaosNone, 0
+	}
+	fault := chaosFault(1 + c.rand.Intn(3))
+	if fault == chaosError && !canFail {
+		fault = chaosDrop
+	}
+	if fault == chaosDelay {
+		if c.maxDelay <= 0 {
+			return chaosNone, 0
+		}
+		return fault, time.Duration(c.rand.Int63n(int64(c.maxDelay))) + 1
+	}
+	return fault, 0
+}
+
+// chaosErr is the error sent in place of a result.
+var chaosErr = fmt.Errorf("lspc --chaos: injected failure")
+
+// chaosCodec wraps the codec of a control connection and injects faults into
+// its responses. net/rpc writes responses while holding a lock shared by all
+// connections, so delays are injected before the request is run instead.
+type chaosCodec struct {
+	rpc.ServerCodec
+
+	// The request whose body is read next.
+	current rpc.Request
+
+	mu      sync.Mutex
+	pending map[uint64]chaosFault
+}
+
+func newChaosCodec(codec rpc.ServerCodec) *chaosCodec {
+	return &chaosCodec{ServerCodec: codec, pending: make(map[uint64]chaosFault)}
+}
+
+func (c *chaosCodec) ReadRequestHeader(r *rpc.Request) error {
+	err := c.ServerCodec.ReadRequestHeader(r)
+	c.current = *r
+	return err
+}
+
+func (c *chaosCodec) ReadRequestBody(body interface{}) error {
+	if err := c.ServerCodec.ReadRequestBody(body); err != nil {
+		return err
+	}
+	switch fault, delay := gChaos.pick(true); fault {
+	case chaosDelay:
+		time.Sleep(delay)
+	case chaosDrop, chaosError:
+		c.mu.Lock()
+		c.pending[c.current.Seq] = fault
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *chaosCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.mu.Lock()
+	fault := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mu.Unlock()
+
+	switch fault {
+	case chaosDrop:
+		log.Printf("chaos: dropping response to %s", r.ServiceMethod)
+		// Closes the connection without a response, like for HTTP.
+		return c.ServerCodec.Close()
+	case chaosError:
+		// Codecs further out, ie, the audit log, see the real result.
+		response := *r
+		response.Error = chaosErr.Error()
+		return c.ServerCodec.WriteResponse(&response, body)
+	}
+	return c.ServerCodec.WriteResponse(r, body)
+}
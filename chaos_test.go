@@ -0,0 +1,121 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetChaos(t *testing.T) {
+	defer setChaos(0, 0, 0)
+
+	assert.Error(t, setChaos(1.5, time.Second, 1))
+	assert.Error(t, setChaos(0.5, -time.Second, 1))
+	assert.NoError(t, setChaos(0, time.Second, 1))
+	assert.Nil(t, gChaos)
+
+	fault, _ := gChaos.pick(true)
+	assert.Equal(t, chaosNone, fault)
+}
+
+func TestChaosPick(t *testing.T) {
+	defer setChaos(0, 0, 0)
+
+	assert.NoError(t, setChaos(1, time.Second, 42))
+	seen := make(map[chaosFault]int)
+	for i := 0; i < 300; i++ {
+		fault, delay := gChaos.pick(true)
+		seen[fault]++
+		if fault == chaosDelay {
+			assert.True(t, delay > 0 && delay <= time.Second)
+		} else {
+			assert.Equal(t, time.Duration(0), delay)
+		}
+	}
+	assert.Zero(t, seen[chaosNone])
+	assert.NotZero(t, seen[chaosDelay])
+	assert.NotZero(t, seen[chaosDrop])
+	assert.NotZero(t, seen[chaosError])
+
+	// Events cannot carry an error.
+	for i := 0; i < 100; i++ {
+		fault, _ := gChaos.pick(false)
+		assert.NotEqual(t, chaosError, fault)
+	}
+
+	// The same seed injects the same faults.
+	assert.NoError(t, setChaos(0.5, time.Second, 7))
+	var first []chaosFault
+	for i := 0; i < 20; i++ {
+		fault, _ := gChaos.pick(true)
+		first = append(first, fault)
+	}
+	assert.NoError(t, setChaos(0.5, time.Second, 7))
+	for i := 0; i < 20; i++ {
+		fault, _ := gChaos.pick(true)
+		assert.Equal(t, first[i], fault)
+	}
+}
+
+type chaosEcho struct{}
+
+func (chaosEcho) Echo(args string, reply *string) error {
+	*reply = args
+	return nil
+}
+
+func TestChaosCodec(t *testing.T) {
+	defer setChaos(0, 0, 0)
+
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("Echo", chaosEcho{}))
+	call := func() error {
+		serverConn, clientConn := net.Pipe()
+		go server.ServeCodec(newChaosCodec(newGobServerCodec(serverConn)))
+		client := rpc.NewClient(clientConn)
+		defer client.Close()
+		var reply string
+		err := client.Call("Echo.Echo", "hello", &reply)
+		if err == nil {
+			assert.Equal(t, "hello", reply)
+		}
+		return err
+	}
+
+	assert.NoError(t, setChaos(1, 0, 42))
+	seen := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		switch err := call(); {
+		case err == nil:
+			// Delays are turned off by a zero --chaos-delay.
+			seen["none"]++
+		case err.Error() == chaosErr.Error():
+			seen["error"]++
+		default:
+			// Dropped responses close the connection.
+			assert.Equal(t, io.ErrUnexpectedEOF, err)
+			seen["drop"]++
+		}
+	}
+	assert.NotZero(t, seen["none"])
+	assert.NotZero(t, seen["error"])
+	assert.NotZero(t, seen["drop"])
+}
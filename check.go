@@ -0,0 +1,245 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CheckArgs lists the files to check. They must be absolute.
+type CheckArgs struct {
+	Files []string
+	Unit  ColumnUnit
+	// How long to wait for the servers to publish diagnostics.
+	Wait time.Duration
+	// Only report the diagnostics of this server group, see GroupConfig.
+	Group string
+}
+
+// CheckResult holds the diagnostics of every checked file.
+type CheckResult struct {
+	Diagnostics map[string][]Diagnostic
+	// Files the server did not publish diagnostics for in time.
+	TimedOut []string
+}
+
+// openForDiagnostics opens path and reports whether the diagnostics stored
+// for it are current, ie, the server already had the same text and has
+// published diagnostics for it.
+func (l *languageServer) openForDiagnostics(path string) (bool, error) {
+	uri := pathToURI(path)
+	l.docMu.Lock()
+	previous, had := l.documents[uri]
+	l.docMu.Unlock()
+
+	text, err := l.openDocument(path)
+	if err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	_, published := l.diagnostics[uri]
+	l.mu.Unlock()
+	return had && previous == text && published, nil
+}
+
+// Check opens files and waits until their diagnostics have been published.
+func (s *Server) Check(args CheckArgs, result *CheckResult) error {
+	log.Printf("CMD check %d file(s)", len(args.Files))
+	// Subscribe before opening so that no diagnostics are missed.
+	sub := daemonEvents.subscribe()
+	defer daemonEvents.unsubscribe(sub)
+
+	// Every server covering a file checks it.
+	type published struct {
+		server int
+		uri    LsDocumentURI
+	}
+	servers := make(map[string][]*languageServer)
+	waiting := make(map[published]string)
+	for _, file := range args.Files {
+		if !filepath.IsAbs(file) {
+			return fmt.Errorf("file must be an absolute path, got %q", file)
+		}
+		covering, err := s.serversForRequest(file, diagnosticsMethod, args.Group)
+		if err != nil {
+			return err
+		}
+		servers[file] = covering
+		for _, server := range covering {
+			current, err := server.openForDiagnostics(file)
+			if err != nil {
+				return err
+			}
+			if !current {
+				waiting[published{server.id, pathToURI(file)}] = file
+			}
+		}
+	}
+
+	deadline := time.After(args.Wait)
+	timedOut := make(map[string]bool)
+	for len(waiting) > 0 {
+		select {
+		case e := <-sub.c:
+			if e.Kind == EventDiagnostics {
+				delete(waiting, published{e.Server.ID, e.Diagnostics.URI})
+			}
+		case <-deadline:
+			for _, file := range waiting {
+				timedOut[file] = true
+			}
+			waiting = nil
+		}
+	}
+	for file := range timedOut {
+		result.TimedOut = append(result.TimedOut, file)
+	}
+	sort.Strings(result.TimedOut)
+
+	result.Diagnostics = make(map[string][]Diagnostic)
+	for file, covering := range servers {
+		var lists [][]Diagnostic
+		for _, server := range covering {
+			diagnostics, err := server.fileDiagnostics(file, args.Unit)
+			if err != nil {
+				return err
+			}
+			lists = append(lists, diagnostics)
+		}
+		if diagnostics := mergeDiagnostics(lists...); len(diagnostics) > 0 {
+			result.Diagnostics[file] = diagnostics
+		}
+	}
+	return nil
+}
+
+// checkFiles expands the directories in paths to the source files in them
+// and makes every path absolute.
+func checkFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		sources, err := docSourceFiles(path, "")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, sources...)
+	}
+	return files, nil
+}
+
+// severityAtLeast returns true if d is at least as severe as threshold.
+// Diagnostics without a severity count as errors.
+func severityAtLeast(d Diagnostic, threshold LsDiagnosticSeverity) bool {
+	severity, err := parseSeverity(d.Severity)
+	if err != nil {
+		return true
+	}
+	return severity <= threshold
+}
+
+// Formats of printed diagnostics.
+const (
+	// Readable lines with related locations indented below.
+	diagnosticsText = "text"
+	// Lines for the errorformat of vim and emacs' compilation mode; related
+	// locations are notes of their own.
+	diagnosticsQuickfix = "quickfix"
+	// One JSON object per line.
+	diagnosticsJSON = "json"
+	// Tab separated location, severity and message for fzf; related
+	// locations are lines of their own.
+	diagnosticsFzf = "fzf"
+)
+
+func checkDiagnosticsFormat(format string) error {
+	switch format {
+	case diagnosticsText, diagnosticsQuickfix, diagnosticsJSON, diagnosticsFzf:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q; expected text, quickfix, json or fzf", format)
+}
+
+// writeDiagnostic writes d, followed by the locations it refers to, in
+// format.
+func writeDiagnostic(out io.Writer, d Diagnostic, format string) {
+	switch format {
+	case diagnosticsJSON:
+		line, _ := json.Marshal(d)
+		fmt.Fprintf(out, "%s\n", line)
+	case diagnosticsQuickfix:
+		// Every line must start with a location.
+		d.Message = oneLine(d.Message)
+		fmt.Fprintln(out, d)
+		for _, related := range d.Related {
+			fmt.Fprintf(out, "%s: note: %s\n", related.Location, oneLine(related.Message))
+		}
+	case diagnosticsFzf:
+		fmt.Fprintf(out, "%s\t%s\t%s\n", d.Location, d.Severity, oneLine(d.Message))
+		for _, related := range d.Related {
+			fmt.Fprintf(out, "%s\tnote\t%s (%s)\n", related.Location, oneLine(related.Message), d.Location)
+		}
+	default:
+		fmt.Fprintln(out, d)
+		for _, related := range d.Related {
+			fmt.Fprintf(out, "    %s: %s\n", related.Location, related.Message)
+		}
+	}
+}
+
+// oneLine joins the lines of s so that it fits in one column.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// printDiagnostics prints diagnostics sorted by file in format and returns
+// how many of them are at least as severe as failOn.
+func printDiagnostics(diagnostics map[string][]Diagnostic, failOn LsDiagnosticSeverity, format string) int {
+	var files []string
+	for file := range diagnostics {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	failed := 0
+	for _, file := range files {
+		for _, d := range diagnostics[file] {
+			writeDiagnostic(os.Stdout, d, format)
+			if severityAtLeast(d, failOn) {
+				failed++
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDiagnostic(t *testing.T) {
+	d := Diagnostic{
+		Location: Location{File: "/p/a.c", Line: 3, Column: 5, EndLine: 3, EndColumn: 6},
+		Severity: "error",
+		Message:  "redefinition of 'x'\nwith a different type",
+		Source:   "clang",
+		Related: []RelatedInformation{
+			{Location: Location{File: "/p/a.h", Line: 1, Column: 5, EndLine: 1, EndColumn: 6}, Message: "previous definition is here"},
+		},
+	}
+	expected := map[string]string{
+		diagnosticsText: "/p/a.c:3:5: error: redefinition of 'x'\nwith a different type [clang]\n" +
+			"    /p/a.h:1:5: previous definition is here\n",
+		diagnosticsQuickfix: "/p/a.c:3:5: error: redefinition of 'x' with a different type [clang]\n" +
+			"/p/a.h:1:5: note: previous definition is here\n",
+		diagnosticsFzf: "/p/a.c:3:5\terror\tredefinition of 'x' with a different type\n" +
+			"/p/a.h:1:5\tnote\tprevious definition is here (/p/a.c:3:5)\n",
+		diagnosticsJSON: `{"file":"/p/a.c","line":3,"column":5,"end_line":3,"end_column":6,"severity":"error",` +
+			`"message":"redefinition of 'x'\nwith a different type","source":"clang",` +
+			`"related":[{"file":"/p/a.h","line":1,"column":5,"end_line":1,"end_column":6,"message":"previous definition is here"}]}` + "\n",
+	}
+	for format, text := range expected {
+		assert.NoError(t, checkDiagnosticsFormat(format))
+		var out bytes.Buffer
+		writeDiagnostic(&out, d, format)
+		assert.Equal(t, text, out.String(), format)
+	}
+	assert.Error(t, checkDiagnosticsFormat("xml"))
+}
@@ -0,0 +1,454 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long the daemon waits for the user to confirm an edit before
+// rejecting it. A variable so that tests can shorten it.
+var editConfirmTimeout = 10 * time.Minute
+
+// CodeActionArgs selects the code actions at a position, optionally only
+// those of the given kinds, ie, "quickfix".
+type CodeActionArgs struct {
+	PositionArgs
+	Kinds []string
+}
+
+// ApplyCodeActionArgs selects the 1-based Index-th code action of
+// CodeActionArgs.
+type ApplyCodeActionArgs struct {
+	CodeActionArgs
+	Index int
+}
+
+// CodeAction is a code action as shown to users.
+type CodeAction struct {
+	Title     string
+	Kind      string `json:",omitempty"`
+	Preferred bool   `json:",omitempty"`
+	// Why the action cannot be applied, if it cannot.
+	Disabled string `json:",omitempty"`
+	// Whether applying the action edits files and which command it runs.
+	Edit    bool   `json:",omitempty"`
+	Command string `json:",omitempty"`
+	// The server that offered the action, if several were asked.
+	Server string `json:",omitempty"`
+}
+
+func (a CodeAction) String() string {
+	s := a.Title
+	if a.Kind != "" {
+		s = "[" + a.Kind + "] " + s
+	}
+	if a.Preferred {
+		s += " (preferred)"
+	}
+	if a.Disabled != "" {
+		s += " (disabled: " + a.Disabled + ")"
+	}
+	if a.Server != "" {
+		s += " (" + a.Server + ")"
+	}
+	return s
+}
+
+// rangeContains returns true if pos is inside of r, including its ends.
+func rangeContains(r LsRange, pos LsPosition) bool {
+	before := func(a, b LsPosition) bool {
+		return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+	}
+	return !before(pos, r.Start) && !before(r.End, pos)
+}
+
+// command returns the command a runs after its edit, or nil.
+func (a LsCodeAction) command() (*LsCommand, error) {
+	if len(a.Command) == 0 || string(a.Command) == "null" {
+		return nil, nil
+	}
+	// A bare Command rather than a CodeAction.
+	var name string
+	if json.Unmarshal(a.Command, &name) == nil {
+		return &LsCommand{Title: a.Title, Command: name, Arguments: a.Arguments}, nil
+	}
+	command := &LsCommand{}
+	if err := fromJSON(a.Command, command); err != nil {
+		return nil, fmt.Errorf("cannot parse command of code action %q: %s", a.Title, err.Error())
+	}
+	return command, nil
+}
+
+// codeActions asks the server for the code actions at the 1-based line and
+// column of path, passing the diagnostics published there.
+func (l *languageServer) codeActions(path string, line, column int, unit ColumnUnit, kinds []string) ([]LsCodeAction, error) {
+	position, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return nil, err
+	}
+	params := LsCodeActionParams{
+		TextDocument: position.TextDocument,
+		Range:        LsRange{Start: position.Position, End: position.Position},
+		Context:      LsCodeActionContext{Diagnostics: []LsDiagnostic{}, Only: kinds},
+	}
+	l.mu.Lock()
+	for _, d := range l.diagnostics[position.TextDocument.URI] {
+		if rangeContains(d.Range, position.Position) {
+			params.Context.Diagnostics = append(params.Context.Diagnostics, d)
+		}
+	}
+	l.mu.Unlock()
+
+	result, err := l.call("textDocument/codeAction", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	var actions []LsCodeAction
+	if string(result) != "null" {
+		if err := fromJSON(result, &actions); err != nil {
+			return nil, fmt.Errorf("cannot parse codeAction result: %s", err.Error())
+		}
+	}
+	return actions, nil
+}
+
+// offeredCodeAction is a code action and the server that offered it.
+type offeredCodeAction struct {
+	server *languageServer
+	action LsCodeAction
+	// Set if several servers were asked.
+	merged bool
+}
+
+// codeActionsAt returns the code actions of every server codeAction is routed
+// to for args.
+func (s *Server) codeActionsAt(args CodeActionArgs) ([]offeredCodeAction, error) {
+	servers, err := s.serversForPosition(args.PositionArgs, "textDocument/codeAction")
+	if err != nil {
+		return nil, err
+	}
+	lists := make([][]LsCodeAction, len(servers))
+	err = callServers(servers, "textDocument/codeAction", func(i int, l *languageServer) (err error) {
+		lists[i], err = l.codeActions(args.File, args.Line, args.Column, args.Unit, args.Kinds)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var offered []offeredCodeAction
+	for i, list := range lists {
+		for _, action := range list {
+			offered = append(offered, offeredCodeAction{servers[i], action, len(servers) > 1})
+		}
+	}
+	return offered, nil
+}
+
+// CodeActions returns the code actions available at a position.
+func (s *Server) CodeActions(args CodeActionArgs, result *[]CodeAction) error {
+	log.Printf("CMD code-action %s:%d:%d", args.File, args.Line, args.Column)
+	offered, err := s.codeActionsAt(args)
+	if err != nil {
+		return err
+	}
+	for _, o := range offered {
+		a := o.action
+		action := CodeAction{Title: a.Title, Kind: a.Kind, Preferred: a.IsPreferred, Edit: a.Edit != nil}
+		if o.merged {
+			action.Server = o.server.name()
+		}
+		if a.Disabled != nil {
+			action.Disabled = a.Disabled.Reason
+		}
+		command, err := a.command()
+		if err != nil {
+			return err
+		}
+		if command != nil {
+			action.Command = command.Command
+		}
+		*result = append(*result, action)
+	}
+	return nil
+}
+
+// EditStep is returned while applying a code action. Edit is an edit
+// waiting for ConfirmEdit, or nil once the code action is done.
+type EditStep struct {
+	Session int
+	Edit    *EditPreview
+}
+
+// ConfirmEditArgs answers the edit waiting in Session.
+type ConfirmEditArgs struct {
+	Session int
+	Apply   bool
+}
+
+// pendingEdit is an edit waiting for the user to confirm it.
+type pendingEdit struct {
+	plan   *editPlan
+	answer chan bool
+}
+
+// editSession applies a code action of a server. Its edit and the edits the
+// server requests with workspace/applyEdit while running its command are
+// handed to the lspc process that applies the action one after another, and
+// only applied once confirmed.
+type editSession struct {
+	id     int
+	server *languageServer
+	edits  chan *pendingEdit
+	// Closed once the code action is done, after err is set.
+	done chan struct{}
+	err  error
+	// workspace/applyEdit requests being answered. The action is only done
+	// once they are, even if the server did not wait for them.
+	requests sync.WaitGroup
+	// mu guards current, the edit last returned by next, so that only one
+	// of concurrent ConfirmEdit calls answers it.
+	mu      sync.Mutex
+	current *pendingEdit
+}
+
+// editSessions tracks the code actions being applied, so that
+// workspace/applyEdit requests can be routed to them.
+var editSessions = struct {
+	sync.Mutex
+	nextID   int
+	sessions map[int]*editSession
+}{sessions: make(map[int]*editSession)}
+
+func newEditSession(server *languageServer) (*editSession, error) {
+	editSessions.Lock()
+	defer editSessions.Unlock()
+	// Checked under the lock so that concurrent clients cannot both start
+	// a session for the same server.
+	for _, s := range editSessions.sessions {
+		if s.server == server {
+			return nil, fmt.Errorf("another code action of %s is being applied", server.name())
+		}
+	}
+	editSessions.nextID++
+	s := &editSession{
+		id:     editSessions.nextID,
+		server: server,
+		edits:  make(chan *pendingEdit),
+		done:   make(chan struct{}),
+	}
+	editSessions.sessions[s.id] = s
+	return s, nil
+}
+
+// findEditSession returns the session with id, or nil.
+func findEditSession(id int) *editSession {
+	editSessions.Lock()
+	defer editSessions.Unlock()
+	return editSessions.sessions[id]
+}
+
+// editSessionFor returns the session applying a code action of server, or
+// nil if there is none.
+func editSessionFor(server *languageServer) *editSession {
+	editSessions.Lock()
+	defer editSessions.Unlock()
+	for _, s := range editSessions.sessions {
+		if s.server == server {
+			return s
+		}
+	}
+	return nil
+}
+
+func (s *editSession) close() {
+	editSessions.Lock()
+	defer editSessions.Unlock()
+	delete(editSessions.sessions, s.id)
+}
+
+// confirm waits for the user to confirm edit and applies it if they do.
+// Returns whether it was applied.
+func (s *editSession) confirm(label string, edit LsWorkspaceEdit) (bool, error) {
+	plan, err := s.server.planEdit(label, edit)
+	if err != nil {
+		return false, err
+	}
+	pending := &pendingEdit{plan: plan, answer: make(chan bool, 1)}
+	timeout := time.After(editConfirmTimeout)
+	select {
+	case s.edits <- pending:
+	case <-timeout:
+		return false, errors.New("timed out waiting for the edit to be confirmed")
+	}
+	select {
+	case apply := <-pending.answer:
+		if !apply {
+			return false, nil
+		}
+		return true, s.server.applyPlan("code-action --apply", plan)
+	case <-timeout:
+		return false, errors.New("timed out waiting for the edit to be confirmed")
+	}
+}
+
+// run applies the edit of action and then runs its command. The session is
+// closed once it returns, even if the client abandoned it, since unconfirmed
+// edits time out.
+func (s *editSession) run(action LsCodeAction) {
+	defer close(s.done)
+	// Runs after requests.Wait below and before done is closed, so that a
+	// new session can be started as soon as next returns.
+	defer s.close()
+	command, err := action.command()
+	if err != nil {
+		s.err = err
+		return
+	}
+	if action.Edit != nil {
+		applied, err := s.confirm(action.Title, *action.Edit)
+		if err != nil || !applied {
+			s.err = err
+			return
+		}
+	}
+	if command != nil {
+		if err := s.server.checkMethod("workspace/executeCommand"); err != nil {
+			s.err = err
+			return
+		}
+		params := LsExecuteCommandParams{Command: command.Command, Arguments: command.Arguments}
+		_, s.err = s.server.call("workspace/executeCommand", toJSON(params), queryTimeout())
+		s.requests.Wait()
+	}
+}
+
+// next waits for the next edit to confirm or for the code action to finish.
+func (s *editSession) next() (EditStep, error) {
+	select {
+	case pending := <-s.edits:
+		s.mu.Lock()
+		s.current = pending
+		s.mu.Unlock()
+		preview := pending.plan.preview()
+		return EditStep{Session: s.id, Edit: &preview}, nil
+	case <-s.done:
+		return EditStep{Session: s.id}, s.err
+	}
+}
+
+// resolveCodeAction fills in the edit of action with codeAction/resolve if
+// the server left it out.
+func (l *languageServer) resolveCodeAction(action LsCodeAction) (LsCodeAction, error) {
+	if action.Edit != nil || len(action.Command) != 0 || len(action.Data) == 0 {
+		return action, nil
+	}
+	result, err := l.call("codeAction/resolve", toJSON(action), queryTimeout())
+	if err != nil {
+		return action, err
+	}
+	resolved := LsCodeAction{}
+	if err := fromJSON(result, &resolved); err != nil {
+		return action, fmt.Errorf("cannot parse codeAction/resolve result: %s", err.Error())
+	}
+	return resolved, nil
+}
+
+// ApplyCodeAction starts applying a code action. The returned step holds the
+// first edit to confirm with ConfirmEdit.
+func (s *Server) ApplyCodeAction(args ApplyCodeActionArgs, step *EditStep) error {
+	log.Printf("CMD code-action --apply %d %s:%d:%d", args.Index, args.File, args.Line, args.Column)
+	offered, err := s.codeActionsAt(args.CodeActionArgs)
+	if err != nil {
+		return err
+	}
+	if args.Index < 1 || args.Index > len(offered) {
+		return fmt.Errorf("no code action %d at %s:%d:%d; there are %d", args.Index, args.File, args.Line, args.Column, len(offered))
+	}
+	server, action := offered[args.Index-1].server, offered[args.Index-1].action
+	if err := server.checkWritable("code-action --apply"); err != nil {
+		return err
+	}
+	if action.Disabled != nil {
+		return fmt.Errorf("code action %q is disabled: %s", action.Title, action.Disabled.Reason)
+	}
+	if action, err = server.resolveCodeAction(action); err != nil {
+		return err
+	}
+	session, err := newEditSession(server)
+	if err != nil {
+		return err
+	}
+	go session.run(action)
+	*step, err = session.next()
+	return err
+}
+
+// ConfirmEdit applies or rejects the edit waiting in a session and returns
+// the next one.
+func (s *Server) ConfirmEdit(args ConfirmEditArgs, step *EditStep) error {
+	log.Printf("CMD confirm-edit %d %t", args.Session, args.Apply)
+	session := findEditSession(args.Session)
+	if session == nil {
+		return fmt.Errorf("no edit is waiting for confirmation in session %d", args.Session)
+	}
+	session.mu.Lock()
+	current := session.current
+	session.current = nil
+	session.mu.Unlock()
+	if current == nil {
+		return fmt.Errorf("no edit is waiting for confirmation in session %d", args.Session)
+	}
+	current.answer <- args.Apply
+	var err error
+	*step, err = session.next()
+	return err
+}
+
+// answerApplyEdit answers the workspace/applyEdit request id once the user
+// confirmed or rejected it. It does not block the caller.
+func (s *editSession) answerApplyEdit(id RequestID, params LsApplyWorkspaceEditParams) {
+	s.requests.Add(1)
+	go func() {
+		defer s.requests.Done()
+		applied, err := s.confirm(params.Label, params.Edit)
+		result := LsApplyWorkspaceEditResult{Applied: applied}
+		switch {
+		case err != nil:
+			result.FailureReason = err.Error()
+		case !applied:
+			result.FailureReason = "rejected by the user"
+		}
+		s.server.publishEvent(DaemonEvent{Kind: EventApplyEdit, ApplyEdit: newApplyEditEvent(params, result)})
+		s.server.writeResponse(id, toJSON(result), nil)
+	}()
+}
+
+// confirmEdit asks whether to apply an edit on out and reads the answer from
+// in. Only y or yes confirm.
+func confirmEdit(in io.Reader, out io.Writer) bool {
+	fmt.Fprint(out, "Apply these changes? [y/N] ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
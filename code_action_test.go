@@ -0,0 +1,102 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeActionCommand(t *testing.T) {
+	command, err := LsCodeAction{Title: "Organize imports", Command: easyjson.RawMessage(`"organize"`),
+		Arguments: []easyjson.RawMessage{easyjson.RawMessage(`1`)}}.command()
+	assert.NoError(t, err)
+	assert.Equal(t, &LsCommand{Title: "Organize imports", Command: "organize", Arguments: []easyjson.RawMessage{easyjson.RawMessage(`1`)}}, command)
+
+	command, err = LsCodeAction{Title: "Fix", Command: easyjson.RawMessage(`{"title":"Fix","command":"fix"}`)}.command()
+	assert.NoError(t, err)
+	assert.Equal(t, "fix", command.Command)
+
+	command, err = LsCodeAction{Title: "Fix"}.command()
+	assert.NoError(t, err)
+	assert.Nil(t, command)
+}
+
+func TestRangeContains(t *testing.T) {
+	r := LsRange{Start: LsPosition{Line: 1, Character: 4}, End: LsPosition{Line: 2, Character: 2}}
+	assert.True(t, rangeContains(r, LsPosition{Line: 1, Character: 4}))
+	assert.True(t, rangeContains(r, LsPosition{Line: 1, Character: 80}))
+	assert.True(t, rangeContains(r, LsPosition{Line: 2, Character: 2}))
+	assert.False(t, rangeContains(r, LsPosition{Line: 1, Character: 3}))
+	assert.False(t, rangeContains(r, LsPosition{Line: 2, Character: 3}))
+}
+
+func TestConfirmEdit(t *testing.T) {
+	var out strings.Builder
+	assert.True(t, confirmEdit(strings.NewReader("y\n"), &out))
+	assert.True(t, confirmEdit(strings.NewReader("Yes\n"), &out))
+	assert.False(t, confirmEdit(strings.NewReader("\n"), &out))
+	assert.False(t, confirmEdit(strings.NewReader(""), &out))
+	assert.Contains(t, out.String(), "[y/N]")
+}
+
+func TestAbandonedEditSession(t *testing.T) {
+	defer func(timeout time.Duration) { editConfirmTimeout = timeout }(editConfirmTimeout)
+	editConfirmTimeout = 50 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.go")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("package a\n"), 0644))
+	server := newTestLanguageServer(0, dir)
+	action := LsCodeAction{Title: "Rename package", Edit: &LsWorkspaceEdit{Changes: map[LsDocumentURI][]LsTextEdit{
+		pathToURI(path): {{Range: LsRange{Start: LsPosition{Line: 0, Character: 8}, End: LsPosition{Line: 0, Character: 9}}, NewText: "b"}},
+	}}}
+
+	// The client goes away without confirming the edit.
+	session, err := newEditSession(server)
+	assert.NoError(t, err)
+	go session.run(action)
+	step, err := session.next()
+	assert.NoError(t, err)
+	assert.NotNil(t, step.Edit)
+	_, err = newEditSession(server)
+	assert.Error(t, err)
+	<-session.done
+	assert.Error(t, session.err)
+	assert.Nil(t, findEditSession(session.id))
+
+	// The next code action of the server can be applied.
+	session, err = newEditSession(server)
+	assert.NoError(t, err)
+	go session.run(action)
+	step, err = session.next()
+	assert.NoError(t, err)
+	s := &Server{config: &Config{}}
+	assert.NoError(t, s.ConfirmEdit(ConfirmEditArgs{Session: step.Session, Apply: true}, &step))
+	assert.Nil(t, step.Edit)
+	assert.Error(t, s.ConfirmEdit(ConfirmEditArgs{Session: step.Session, Apply: true}, &step))
+	text, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "package b\n", string(text))
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// codec encodes and decodes protocol messages. Every codec handles any type
+// encoding/json can, so message types can be added without regenerating
+// msg_types_easyjson.go; the easyjson codec is only faster for types that
+// have generated code.
+type codec interface {
+	marshal(v interface{}) ([]byte, error)
+	unmarshal(data []byte, v interface{}) error
+}
+
+// bufferedCodec is implemented by codecs that can serialize into a writer
+// and buffer owned by the caller, avoiding an allocation per message.
+type bufferedCodec interface {
+	marshalBuffered(v interface{}, jw *jwriter.Writer, reuse []byte) ([]byte, error)
+}
+
+// Available codecs, keyed by the name used with --codec. Codecs with extra
+// dependencies register themselves behind a build tag.
+var codecs = map[string]codec{
+	"easyjson": easyjsonCodec{},
+	"json":     stdCodec{},
+}
+
+// The codec used for all messages.
+var gCodec codec = easyjsonCodec{}
+
+// setCodec selects the codec registered as name.
+func setCodec(name string) error {
+	c, has := codecs[name]
+	if !has {
+		return fmt.Errorf("unknown codec %q; available codecs are %s", name, strings.Join(codecNames(), ", "))
+	}
+	gCodec = c
+	return nil
+}
+
+func codecNames() []string {
+	var names []string
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toJSON serializes v with the current codec. Messages are built by lspc, so
+// failing to serialize one is a bug.
+func toJSON(v interface{}) easyjson.RawMessage {
+	r, e := gCodec.marshal(v)
+	panicIfError(e)
+	return r
+}
+
+// fromJSON deserializes data into v with the current codec.
+func fromJSON(data []byte, v interface{}) error {
+	return gCodec.unmarshal(data, v)
+}
+
+// easyjsonCodec uses the code generated by easyjson, falling back to
+// encoding/json for types without it.
+type easyjsonCodec struct{}
+
+func (c easyjsonCodec) marshal(v interface{}) ([]byte, error) {
+	return c.marshalBuffered(v, &jwriter.Writer{}, nil)
+}
+
+func (easyjsonCodec) marshalBuffered(v interface{}, jw *jwriter.Writer, reuse []byte) ([]byte, error) {
+	m, isEasy := v.(easyjson.Marshaler)
+	if !isEasy {
+		return json.Marshal(v)
+	}
+	jw.Flags = jwriter.NilMapAsEmpty | jwriter.NilSliceAsEmpty
+	m.MarshalEasyJSON(jw)
+	return jw.BuildBytes(reuse)
+}
+
+func (easyjsonCodec) unmarshal(data []byte, v interface{}) error {
+	u, isEasy := v.(easyjson.Unmarshaler)
+	if !isEasy {
+		return json.Unmarshal(data, v)
+	}
+	l := jlexer.Lexer{Data: data}
+	u.UnmarshalEasyJSON(&l)
+	return l.Error()
+}
+
+// stdCodec uses encoding/json.
+type stdCodec struct{}
+
+func (stdCodec) marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build jsoniter
+// +build jsoniter
+
+package main
+
+import (
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Build with `go build -tags jsoniter` to make --codec jsoniter available.
+func init() {
+	codecs["jsoniter"] = jsoniterCodec{}
+}
+
+// jsoniterCodec uses json-iterator, configured to behave like encoding/json.
+type jsoniterCodec struct{}
+
+func (jsoniterCodec) marshal(v interface{}) ([]byte, error) {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(v)
+}
+
+func (jsoniterCodec) unmarshal(data []byte, v interface{}) error {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, v)
+}
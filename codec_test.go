@@ -0,0 +1,57 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecs(t *testing.T) {
+	// A type without generated easyjson code.
+	type experiment struct {
+		Name string `json:"name"`
+	}
+
+	for name, c := range codecs {
+		notification := JSONRPCHeader{JSONRPC: "2.0", ID: -1, Method: "initialized", Params: easyjson.RawMessage("{}")}
+		data, err := c.marshal(notification)
+		assert.NoError(t, err, name)
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "initialized", "params": {}}`, string(data), name)
+
+		header := JSONRPCHeader{ID: -1}
+		assert.NoError(t, c.unmarshal([]byte(`{"jsonrpc": "2.0", "id": 3, "result": [1]}`), &header), name)
+		assert.Equal(t, RequestID(3), header.ID, name)
+		assert.Equal(t, "[1]", string(header.Result), name)
+
+		data, err = c.marshal(experiment{"x"})
+		assert.NoError(t, err, name)
+		assert.JSONEq(t, `{"name": "x"}`, string(data), name)
+		e := experiment{}
+		assert.NoError(t, c.unmarshal(data, &e), name)
+		assert.Equal(t, "x", e.Name, name)
+
+		assert.Error(t, c.unmarshal([]byte(`{bad`), &header), name)
+	}
+}
+
+func TestSetCodec(t *testing.T) {
+	defer setCodec("easyjson")
+	assert.NoError(t, setCodec("json"))
+	assert.Equal(t, stdCodec{}, gCodec)
+	assert.Error(t, setCodec("gob"))
+}
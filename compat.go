@@ -0,0 +1,191 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mailru/easyjson"
+)
+
+// compat describes which protocol features are used with a language server.
+// Older servers, ie, those written against protocol 2.x, misbehave when
+// offered features they do not know about.
+type compat struct {
+	// Advertise hierarchicalDocumentSymbolSupport. Otherwise servers answer
+	// documentSymbol with flat SymbolInformation.
+	hierarchicalDocumentSymbols bool
+	// Advertise MarkupContent for hover and completion documentation.
+	markupContent bool
+	// Use textDocument/semanticTokens when the server supports it.
+	semanticTokens bool
+}
+
+// compatFor returns the protocol features to use with servers for language.
+func (c *Config) compatFor(language string) compat {
+	config := c.Languages[language].Compat
+	option := func(value *bool) bool {
+		if value != nil {
+			return *value
+		}
+		return !config.Legacy
+	}
+	return compat{
+		hierarchicalDocumentSymbols: option(config.HierarchicalDocumentSymbols),
+		markupContent:               option(config.MarkupContent),
+		semanticTokens:              option(config.SemanticTokens),
+	}
+}
+
+// protocol3Capabilities are ServerCapabilities added in protocol 3.x. A
+// server which announces none of them was written against 2.x, so it does
+// not know MarkupContent or hierarchical document symbols either.
+var protocol3Capabilities = []string{
+	"workspace",
+	"executeCommandProvider",
+	"typeDefinitionProvider",
+	"implementationProvider",
+	"colorProvider",
+	"foldingRangeProvider",
+	"declarationProvider",
+	"selectionRangeProvider",
+	"callHierarchyProvider",
+	"semanticTokensProvider",
+	"linkedEditingRangeProvider",
+	"monikerProvider",
+}
+
+// narrow turns off features the server did not announce in its
+// ServerCapabilities, and those a 2.x server does not know.
+func (c compat) narrow(capabilities easyjson.RawMessage) compat {
+	var caps map[string]json.RawMessage
+	if err := json.Unmarshal(capabilities, &caps); err != nil {
+		return c
+	}
+	announced := func(name string) bool {
+		provider, has := caps[name]
+		return has && string(provider) != "null" && string(provider) != "false"
+	}
+	if !announced("semanticTokensProvider") {
+		c.semanticTokens = false
+	}
+	legacy := true
+	for _, name := range protocol3Capabilities {
+		if announced(name) {
+			legacy = false
+			break
+		}
+	}
+	if legacy {
+		c.hierarchicalDocumentSymbols = false
+		c.markupContent = false
+	}
+	return c
+}
+
+// clientCapabilities returns the capabilities to send in initialize.
+func (c compat) clientCapabilities() LsClientCapabilities {
+	var symbolKinds []LsSymbolKind
+	for kind := range symbolKindNames[1:] {
+		symbolKinds = append(symbolKinds, LsSymbolKind(kind+1))
+	}
+	var completionKinds []LsCompletionItemKind
+	for kind := range completionItemKindNames[1:] {
+		completionKinds = append(completionKinds, LsCompletionItemKind(kind+1))
+	}
+	caps := LsClientCapabilities{
+		Workspace: LsWorkspaceClientCapabilities{
+			Symbol: LsWorkspaceSymbolClientCapabilities{
+				SymbolKind: LsSymbolKindClientCapabilities{ValueSet: symbolKinds},
+			},
+		},
+		Window: LsWindowClientCapabilities{WorkDoneProgress: true},
+		TextDocument: LsTextDocumentClientCapabilities{
+			// Completions are printed, not inserted, so snippets would show
+			// their placeholders.
+			Completion: LsCompletionClientCapabilities{
+				CompletionItemKind: LsCompletionItemKindClientCapabilities{ValueSet: completionKinds},
+			},
+			DocumentSymbol: LsDocumentSymbolClientCapabilities{
+				SymbolKind:                        LsSymbolKindClientCapabilities{ValueSet: symbolKinds},
+				HierarchicalDocumentSymbolSupport: c.hierarchicalDocumentSymbols,
+			},
+		},
+	}
+	// Without MarkupContent servers are asked for plain text, which is
+	// sent as a MarkedString string by those that predate MarkupContent.
+	formats := []LsMarkupKind{PlainText}
+	if c.markupContent {
+		formats = []LsMarkupKind{Markdown, PlainText}
+	}
+	caps.TextDocument.Hover.ContentFormat = formats
+	caps.TextDocument.Completion.CompletionItem.DocumentationFormat = formats
+	if c.semanticTokens {
+		caps.TextDocument.SemanticTokens = &LsSemanticTokensClientCapabilities{
+			Requests: LsSemanticTokensRequests{
+				Range: true,
+				Full:  LsSemanticTokensFullRequests{Delta: true},
+			},
+			TokenTypes:     semanticTokenTypes,
+			TokenModifiers: semanticTokenModifiers,
+			Formats:        []string{"relative"},
+		}
+	}
+	return caps
+}
+
+func (c compat) String() string {
+	var features []string
+	add := func(name string, enabled bool) {
+		if !enabled {
+			name = "no " + name
+		}
+		features = append(features, name)
+	}
+	add("hierarchical documentSymbol", c.hierarchicalDocumentSymbols)
+	add("MarkupContent", c.markupContent)
+	add("semantic tokens", c.semanticTokens)
+	return strings.Join(features, ", ")
+}
+
+// hoverMarkup converts the contents of a Hover, which can be a MarkedString, a
+// MarkedString array or MarkupContent, to MarkupContent.
+func hoverMarkup(contents easyjson.RawMessage) (easyjson.RawMessage, error) {
+	var markup LsMarkupContent
+	var marked []json.RawMessage
+	switch {
+	case json.Unmarshal(contents, &marked) == nil:
+	case json.Unmarshal(contents, &markup) == nil && markup.Kind != "":
+		return contents, nil
+	default:
+		marked = []json.RawMessage{json.RawMessage(contents)}
+	}
+
+	var parts []string
+	for _, m := range marked {
+		var value string
+		var object LsMarkedString
+		if json.Unmarshal(m, &value) == nil {
+			parts = append(parts, value)
+		} else if json.Unmarshal(m, &object) == nil {
+			parts = append(parts, fmt.Sprintf("```%s\n%s\n```", object.Language, object.Value))
+		} else {
+			return nil, fmt.Errorf("invalid MarkedString %s", string(m))
+		}
+	}
+	return json.Marshal(LsMarkupContent{Kind: Markdown, Value: strings.Join(parts, "\n\n")})
+}
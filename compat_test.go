@@ -0,0 +1,89 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompatFor(t *testing.T) {
+	yes := true
+	config := &Config{
+		Languages: map[string]LanguageConfig{
+			"old": {Compat: CompatConfig{Legacy: true, MarkupContent: &yes}},
+		},
+	}
+	assert.Equal(t, compat{true, true, true}, config.compatFor(""))
+	assert.Equal(t, compat{false, true, false}, config.compatFor("old"))
+
+	c := compat{true, true, true}
+	assert.Equal(t, compat{true, true, false}, c.narrow(easyjson.RawMessage(`{"hoverProvider": true, "foldingRangeProvider": true}`)))
+	assert.Equal(t, compat{true, true, false}, c.narrow(easyjson.RawMessage(`{"workspace": {}, "semanticTokensProvider": null}`)))
+	assert.Equal(t, c, c.narrow(easyjson.RawMessage(`{"semanticTokensProvider": {"full": true}}`)))
+
+	// A server that announces nothing added in protocol 3.x predates
+	// MarkupContent and hierarchical document symbols.
+	assert.Equal(t, compat{}, c.narrow(easyjson.RawMessage(`{"hoverProvider": true, "documentSymbolProvider": true}`)))
+	assert.Equal(t, compat{}, c.narrow(easyjson.RawMessage(`{"hoverProvider": true, "colorProvider": false}`)))
+	// Narrowing never turns features on.
+	assert.Equal(t, compat{}, compat{}.narrow(easyjson.RawMessage(`{"semanticTokensProvider": {"full": true}}`)))
+	assert.Equal(t, c, c.narrow(easyjson.RawMessage(`not json`)))
+}
+
+func TestClientCapabilities(t *testing.T) {
+	caps := compat{true, true, true}.clientCapabilities()
+	assert.Len(t, caps.TextDocument.DocumentSymbol.SymbolKind.ValueSet, 26)
+	assert.Equal(t, TypeParameter, caps.Workspace.Symbol.SymbolKind.ValueSet[25])
+	assert.Len(t, caps.TextDocument.Completion.CompletionItemKind.ValueSet, 25)
+	assert.False(t, caps.TextDocument.Completion.CompletionItem.SnippetSupport)
+	assert.Equal(t, []LsMarkupKind{Markdown, PlainText}, caps.TextDocument.Completion.CompletionItem.DocumentationFormat)
+
+	// Pull diagnostics are not implemented, so they are never advertised.
+	encoded, err := json.Marshal(caps.TextDocument)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), `"diagnostic"`)
+
+	assert.Equal(t, []LsMarkupKind{Markdown, PlainText}, caps.TextDocument.Hover.ContentFormat)
+	assert.True(t, caps.TextDocument.DocumentSymbol.HierarchicalDocumentSymbolSupport)
+	assert.NotNil(t, caps.TextDocument.SemanticTokens)
+
+	// Servers that predate MarkupContent are asked for plain text and flat
+	// symbols.
+	caps = compat{}.clientCapabilities()
+	assert.Equal(t, []LsMarkupKind{PlainText}, caps.TextDocument.Hover.ContentFormat)
+	assert.Equal(t, []LsMarkupKind{PlainText}, caps.TextDocument.Completion.CompletionItem.DocumentationFormat)
+	assert.False(t, caps.TextDocument.DocumentSymbol.HierarchicalDocumentSymbolSupport)
+	assert.Nil(t, caps.TextDocument.SemanticTokens)
+	encoded, err = json.Marshal(caps.TextDocument)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"contentFormat":["plaintext"]`)
+}
+
+func TestHoverMarkup(t *testing.T) {
+	convert := func(contents string) string {
+		result, err := hoverMarkup(easyjson.RawMessage(contents))
+		assert.NoError(t, err)
+		return string(result)
+	}
+
+	assert.JSONEq(t, `{"kind": "markdown", "value": "int x"}`, convert(`"int x"`))
+	assert.JSONEq(t, `{"kind": "markdown", "value": "`+"```c\\nint x\\n```"+`\n\ndoc"}`,
+		convert(`[{"language": "c", "value": "int x"}, "doc"]`))
+	assert.JSONEq(t, `{"kind": "plaintext", "value": "int x"}`, convert(`{"kind": "plaintext", "value": "int x"}`))
+}
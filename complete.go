@@ -0,0 +1,347 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	easyjson "github.com/mailru/easyjson"
+)
+
+// CompleteResult is the completion list of the server along with the word
+// being completed.
+type CompleteResult struct {
+	Items []LsCompletionItem
+	// The id of the server that offered each item, which resolves it.
+	Servers      []int
+	IsIncomplete bool
+	// The identifier before the position, which the items are matched
+	// against unless another filter is given.
+	Prefix string
+}
+
+// parseCompletions parses the result of textDocument/completion, which is a
+// CompletionList, an array of CompletionItem or null.
+func parseCompletions(result easyjson.RawMessage) (LsCompletionList, error) {
+	var list LsCompletionList
+	trimmed := bytes.TrimSpace(result)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return list, nil
+	}
+	if trimmed[0] == '[' {
+		err := fromJSON(trimmed, &list.Items)
+		return list, err
+	}
+	err := fromJSON(trimmed, &list)
+	return list, err
+}
+
+// completionPrefix returns the identifier which ends at byte column of line.
+func completionPrefix(line string, column int) string {
+	if column > len(line) {
+		column = len(line)
+	}
+	start := column
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		start -= size
+	}
+	return line[start:column]
+}
+
+// completions returns the completion items at the 1-based line and column of
+// path.
+func (l *languageServer) completions(path string, line, column int, unit ColumnUnit) (CompleteResult, error) {
+	params, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return CompleteResult{}, err
+	}
+	result, err := l.call("textDocument/completion", toJSON(params), queryTimeout())
+	if err != nil {
+		return CompleteResult{}, err
+	}
+	list, err := parseCompletions(result)
+	if err != nil {
+		return CompleteResult{}, fmt.Errorf("cannot parse completions: %s", err.Error())
+	}
+
+	text, err := l.documentText(path)
+	if err != nil {
+		return CompleteResult{}, err
+	}
+	position := fromUTF16(text, params.Position, ByteColumns)
+	servers := make([]int, len(list.Items))
+	for i := range servers {
+		servers[i] = l.id
+	}
+	return CompleteResult{
+		Items:        list.Items,
+		Servers:      servers,
+		IsIncomplete: list.IsIncomplete,
+		Prefix:       completionPrefix(lineAt(text, position.Line), position.Character),
+	}, nil
+}
+
+// Complete returns the completion items at a position. They are ranked by
+// the client, see rankCompletions.
+func (s *Server) Complete(args PositionArgs, result *CompleteResult) error {
+	log.Printf("CMD complete %s:%d:%d", args.File, args.Line, args.Column)
+	servers, err := s.serversForPosition(args, "textDocument/completion")
+	if err != nil {
+		return err
+	}
+	results := make([]CompleteResult, len(servers))
+	err = callServers(servers, "textDocument/completion", func(i int, l *languageServer) (err error) {
+		results[i], err = l.completions(args.File, args.Line, args.Column, args.Unit)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	*result = results[0]
+	for _, r := range results[1:] {
+		result.Items = append(result.Items, r.Items...)
+		result.Servers = append(result.Servers, r.Servers...)
+		result.IsIncomplete = result.IsIncomplete || r.IsIncomplete
+	}
+	return nil
+}
+
+// ResolveCompletionsArgs are completion items to resolve along with the id of
+// the server that offered each.
+type ResolveCompletionsArgs struct {
+	Items   []LsCompletionItem
+	Servers []int
+}
+
+// resolveCompletion sends completionItem/resolve for item.
+func (l *languageServer) resolveCompletion(item LsCompletionItem) (LsCompletionItem, error) {
+	result, err := l.call("completionItem/resolve", toJSON(item), queryTimeout())
+	if err != nil {
+		return item, err
+	}
+	resolved := LsCompletionItem{}
+	if err := fromJSON(result, &resolved); err != nil {
+		return item, fmt.Errorf("cannot parse resolved completion: %s", err.Error())
+	}
+	return resolved, nil
+}
+
+// ResolveCompletions fills in the details and documentation of completion
+// items, which servers often leave out of long lists. Items that cannot be
+// resolved, ie, because their server has stopped, are returned as they are.
+func (s *Server) ResolveCompletions(args ResolveCompletionsArgs, items *[]LsCompletionItem) error {
+	log.Printf("CMD resolve-completions %d", len(args.Items))
+	if len(args.Servers) != len(args.Items) {
+		return fmt.Errorf("expected the server of each of %d items, got %d", len(args.Items), len(args.Servers))
+	}
+	*items = make([]LsCompletionItem, len(args.Items))
+	var wg sync.WaitGroup
+	for i, item := range args.Items {
+		(*items)[i] = item
+		server, err := s.findServer(args.Servers[i])
+		if err != nil {
+			log.Printf("Not resolving %s: %s", item.Label, err.Error())
+			continue
+		}
+		wg.Add(1)
+		go func(i int, server *languageServer, item LsCompletionItem) {
+			defer wg.Done()
+			resolved, err := server.resolveCompletion(item)
+			if err != nil {
+				log.Printf("Not resolving %s: %s", item.Label, err.Error())
+			}
+			(*items)[i] = resolved
+		}(i, server, item)
+	}
+	wg.Wait()
+	return nil
+}
+
+// completionDocumentation returns the first line of the documentation of
+// item as plain text.
+func completionDocumentation(item LsCompletionItem) string {
+	if len(item.Documentation) == 0 {
+		return ""
+	}
+	markdown, err := hoverText(item.Documentation)
+	if err != nil {
+		return ""
+	}
+	text := strings.TrimSpace(markdownText(markdown))
+	if newline := strings.IndexByte(text, '\n'); newline >= 0 {
+		text = text[:newline]
+	}
+	return text
+}
+
+// How completion items are ordered.
+const (
+	sortByScore  = "fuzzy"
+	sortByServer = "server"
+)
+
+// completeOptions selects and orders completion items.
+type completeOptions struct {
+	// Items must fuzzy match this. Empty matches everything.
+	Filter string
+	// If not empty, only items of these kinds are kept.
+	Kinds []LsCompletionItemKind
+	// sortByScore or sortByServer.
+	Sort string
+	// Maximum number of items to keep, or 0 for all.
+	Max int
+}
+
+// parseCompletionKinds parses kind names, ie, "function" or "enum-member".
+// Each name may hold several separated by commas.
+func parseCompletionKinds(names []string) ([]LsCompletionItemKind, error) {
+	var kinds []LsCompletionItemKind
+	for _, list := range names {
+		for _, name := range strings.Split(list, ",") {
+			name = normalizeKindName(name)
+			found := false
+			for kind, kindName := range completionItemKindNames {
+				if kind > 0 && kindName == name {
+					kinds = append(kinds, LsCompletionItemKind(kind))
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown completion kind %q", name)
+			}
+		}
+	}
+	return kinds, nil
+}
+
+// fuzzyScore matches the runes of pattern, ignoring case, in order against
+// candidate. Returns false if they do not all match. Matches at the start of
+// words, consecutive matches and matches with the same case score higher.
+func fuzzyScore(pattern, candidate string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	p := []rune(pattern)
+	score, matched := 0, 0
+	previousMatched := false
+	var previous rune
+	for i, r := range []rune(candidate) {
+		if matched < len(p) && unicode.ToLower(r) == unicode.ToLower(p[matched]) {
+			score++
+			if r == p[matched] {
+				score++
+			}
+			if previousMatched {
+				score += 5
+			}
+			if i == 0 || !unicode.IsLetter(previous) && !unicode.IsDigit(previous) || unicode.IsLower(previous) && unicode.IsUpper(r) {
+				score += 8
+			}
+			matched++
+			previousMatched = true
+		} else {
+			if matched == 0 {
+				// Matches later in the candidate are worth less.
+				score--
+			}
+			previousMatched = false
+		}
+		previous = r
+	}
+	return score, matched == len(p)
+}
+
+// sortKey is what servers expect items to be ordered by.
+func (item LsCompletionItem) sortKey() string {
+	if item.SortText != "" {
+		return item.SortText
+	}
+	return item.Label
+}
+
+// rankCompletions returns the items that match options in order.
+func rankCompletions(items []LsCompletionItem, options completeOptions) []LsCompletionItem {
+	indices := rankCompletionIndices(items, options)
+	result := make([]LsCompletionItem, len(indices))
+	for i, index := range indices {
+		result[i] = items[index]
+	}
+	return result
+}
+
+// rankCompletionIndices is rankCompletions but returns the indices of the
+// items.
+func rankCompletionIndices(items []LsCompletionItem, options completeOptions) []int {
+	type ranked struct {
+		item  LsCompletionItem
+		index int
+		score int
+	}
+	var kept []ranked
+	for index, item := range items {
+		if len(options.Kinds) > 0 && !hasCompletionKind(options.Kinds, item.Kind) {
+			continue
+		}
+		text := item.FilterText
+		if text == "" {
+			text = item.Label
+		}
+		score, ok := fuzzyScore(options.Filter, text)
+		if !ok {
+			continue
+		}
+		kept = append(kept, ranked{item, index, score})
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		a, b := kept[i], kept[j]
+		if options.Sort != sortByServer && a.score != b.score {
+			return a.score > b.score
+		}
+		if a.item.sortKey() != b.item.sortKey() {
+			return a.item.sortKey() < b.item.sortKey()
+		}
+		return len(a.item.Label) < len(b.item.Label)
+	})
+	if options.Max > 0 && len(kept) > options.Max {
+		kept = kept[:options.Max]
+	}
+
+	result := make([]int, len(kept))
+	for i, r := range kept {
+		result[i] = r.index
+	}
+	return result
+}
+
+func hasCompletionKind(kinds []LsCompletionItemKind, kind LsCompletionItemKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCompletions(t *testing.T) {
+	list, err := parseCompletions(easyjson.RawMessage(`{"isIncomplete": true, "items": [{"label": "a", "kind": 3}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, LsCompletionList{IsIncomplete: true, Items: []LsCompletionItem{{Label: "a", Kind: 3}}}, list)
+
+	list, err = parseCompletions(easyjson.RawMessage(`[{"label": "b"}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []LsCompletionItem{{Label: "b"}}, list.Items)
+
+	list, err = parseCompletions(easyjson.RawMessage(`null`))
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestCompletionPrefix(t *testing.T) {
+	assert.Equal(t, "get_val", completionPrefix("  x = get_val", 13))
+	assert.Equal(t, "ge", completionPrefix("  x = get_val", 8))
+	assert.Equal(t, "", completionPrefix("foo.", 4))
+	assert.Equal(t, "größe", completionPrefix("a.größe", 9))
+	assert.Equal(t, "bar", completionPrefix("bar", 10))
+}
+
+func TestParseCompletionKinds(t *testing.T) {
+	kinds, err := parseCompletionKinds([]string{"function,method", "Enum-Member"})
+	assert.NoError(t, err)
+	assert.Equal(t, []LsCompletionItemKind{3, 2, 20}, kinds)
+
+	_, err = parseCompletionKinds([]string{"bogus"})
+	assert.Error(t, err)
+}
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := fuzzyScore("gsv", "getServerVersion")
+	assert.True(t, ok)
+	_, ok = fuzzyScore("gsvx", "getServerVersion")
+	assert.False(t, ok)
+
+	// Word starts beat matches in the middle of words.
+	camel, _ := fuzzyScore("gsv", "getServerVersion")
+	middle, _ := fuzzyScore("gsv", "gasvent")
+	assert.True(t, camel > middle)
+
+	// Prefixes beat later matches.
+	prefix, _ := fuzzyScore("ab", "abc")
+	later, _ := fuzzyScore("ab", "xxab")
+	assert.True(t, prefix > later)
+}
+
+func TestRankCompletions(t *testing.T) {
+	items := []LsCompletionItem{
+		{Label: "gasvent", Kind: 6, SortText: "1"},
+		{Label: "getServerVersion", Kind: 2, SortText: "2"},
+		{Label: "other", Kind: 14, SortText: "0"},
+		{Label: "g_s_v", Kind: 3, SortText: "3", FilterText: "zzz"},
+	}
+	labels := func(items []LsCompletionItem) []string {
+		var labels []string
+		for _, item := range items {
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+
+	assert.Equal(t, []string{"getServerVersion", "gasvent"}, labels(rankCompletions(items, completeOptions{Filter: "gsv", Sort: sortByScore})))
+	assert.Equal(t, []string{"gasvent", "getServerVersion"}, labels(rankCompletions(items, completeOptions{Filter: "gsv", Sort: sortByServer})))
+	assert.Equal(t, []string{"other", "gasvent"}, labels(rankCompletions(items, completeOptions{Sort: sortByServer, Max: 2})))
+	assert.Equal(t, []string{"getServerVersion", "g_s_v"}, labels(rankCompletions(items, completeOptions{Kinds: []LsCompletionItemKind{2, 3}})))
+	assert.Equal(t, []int{1, 0}, rankCompletionIndices(items, completeOptions{Filter: "gsv", Sort: sortByScore}))
+}
+
+func TestCompletionDocumentation(t *testing.T) {
+	assert.Equal(t, "", completionDocumentation(LsCompletionItem{}))
+	assert.Equal(t, "Returns the version.", completionDocumentation(LsCompletionItem{Documentation: easyjson.RawMessage(`"Returns the version.\n\nMore."`)}))
+	assert.Equal(t, "Returns the version of a server.", completionDocumentation(LsCompletionItem{
+		Documentation: easyjson.RawMessage(`{"kind":"markdown","value":"Returns the **version** of a server."}`),
+	}))
+}
@@ -0,0 +1,380 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// The completion scripts call lspc with this hidden command, passing every
+// word after `lspc` up to and including the word being completed.
+const completeCommandName = "__complete"
+
+// When complete returns one of these as its only candidate the shell should
+// fall back to completing paths.
+const (
+	completeFiles = ":files"
+	completeDirs  = ":dirs"
+)
+
+// completer returns candidates for a flag value or positional argument.
+// Candidates may be followed by a tab and a description.
+type completer func() []string
+
+// flagCompleters provides candidates for flag values, keyed by flag name.
+var flagCompleters = map[string]completer{
+	"server": completeServers,
+	"kind":   completeKinds,
+	"group":  completeGroups,
+	"socket": func() []string { return []string{completeFiles} },
+	"dir":    func() []string { return []string{completeDirs} },
+}
+
+// argCompleters provides candidates for the positional arguments of a
+// command, keyed by command name.
+var argCompleters = map[string][]completer{
+	"start": {
+		func() []string { return []string{completeFiles} },
+		func() []string { return []string{completeDirs} },
+	},
+	"capabilities": {
+		func() []string { return append([]string{"show", "diff"}, completeServers()...) },
+		completeServers,
+		completeServers,
+	},
+	"check": {
+		func() []string { return []string{completeFiles} },
+	},
+	"diagnostics": {
+		func() []string { return []string{completeFiles} },
+	},
+	"explain": {
+		func() []string { return []string{completeFiles} },
+	},
+	"docgen": {
+		func() []string { return []string{completeDirs} },
+	},
+	"open": {
+		func() []string { return []string{completeFiles} },
+	},
+	"change": {completeOpenFiles},
+	"close":  {completeOpenFiles},
+	"definition": {
+		func() []string { return []string{completeFiles} },
+	},
+	"references": {
+		func() []string { return []string{completeFiles} },
+	},
+	"document-symbols": {
+		func() []string { return []string{completeFiles} },
+	},
+	"hover": {
+		func() []string { return []string{completeFiles} },
+	},
+	"inline-values": {
+		func() []string { return []string{completeFiles} },
+	},
+	"linked-editing-ranges": {
+		func() []string { return []string{completeFiles} },
+	},
+	"moniker": {
+		func() []string { return []string{completeFiles} },
+	},
+	"complete": {
+		func() []string { return []string{completeFiles} },
+	},
+	"raw-io": {
+		completeServers,
+	},
+	"stop": {
+		completeServers,
+	},
+	"restart": {
+		completeServers,
+	},
+	"swap": {
+		completeServers,
+		func() []string { return []string{completeFiles} },
+	},
+	"up": {
+		func() []string { return []string{completeDirs} },
+	},
+	"down": {
+		func() []string { return []string{completeDirs} },
+	},
+	"prepare-rename": {
+		func() []string { return []string{completeFiles} },
+	},
+	"rename": {
+		func() []string { return []string{completeFiles} },
+	},
+	"format": {
+		func() []string { return []string{completeFiles} },
+	},
+	"range-format": {
+		func() []string { return []string{completeFiles} },
+	},
+	"code-action": {
+		func() []string { return []string{completeFiles} },
+	},
+	"config": {
+		func() []string { return []string{"validate", "show"} },
+		func() []string { return []string{completeDirs} },
+	},
+	"shell-completion": {
+		func() []string { return []string{"bash", "zsh", "fish"} },
+	},
+}
+
+// completeServers asks the daemon for running server ids. It never starts the
+// daemon; if none is running there is nothing to complete.
+func completeServers() []string {
+	var servers []ServerInfo
+	if err := tryRPC("Server.Ls", false, &servers); err != nil {
+		return nil
+	}
+	candidates := []string{"all\tevery language server"}
+	for _, server := range servers {
+		candidates = append(candidates, fmt.Sprintf("%d\t%s in %s", server.ID, strings.Join(server.Args, " "), server.Directory))
+	}
+	return candidates
+}
+
+// completeOpenFiles asks the daemon for the documents its servers have open,
+// which are the only ones change and close accept.
+func completeOpenFiles() []string {
+	var documents []OpenFile
+	if err := tryRPC("Server.Documents", false, &documents); err != nil {
+		return nil
+	}
+	var files []string
+	servers := make(map[string][]string)
+	for _, document := range documents {
+		if _, has := servers[document.File]; !has {
+			files = append(files, document.File)
+		}
+		servers[document.File] = append(servers[document.File], document.DocumentState.String())
+	}
+	sort.Strings(files)
+	var candidates []string
+	for _, file := range files {
+		candidates = append(candidates, file+"\topen in "+strings.Join(servers[file], ", "))
+	}
+	return candidates
+}
+
+// completeGroups lists the server groups of the config.
+func completeGroups() []string {
+	config, err := loadConfig(gConfig)
+	if err != nil {
+		return nil
+	}
+	var candidates []string
+	for name, group := range config.Groups {
+		candidates = append(candidates, name+"\t"+strings.Join(group.Servers, ", "))
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// completeKinds lists the completion kinds accepted by --kind.
+func completeKinds() []string {
+	var candidates []string
+	for _, name := range completionItemKindNames[1:] {
+		candidates = append(candidates, strings.Replace(name, " ", "-", -1))
+	}
+	return candidates
+}
+
+func flagNames(flag cli.Flag) []string {
+	var names []string
+	for _, name := range strings.Split(flag.GetName(), ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+func flagTakesValue(flag cli.Flag) bool {
+	switch flag.(type) {
+	case cli.BoolFlag, cli.BoolTFlag:
+		return false
+	}
+	return true
+}
+
+// findFlag returns the flag matching the command line word arg, ie, --socket
+// or -s.
+func findFlag(flags []cli.Flag, arg string) cli.Flag {
+	name := strings.TrimLeft(arg, "-")
+	for _, flag := range flags {
+		for _, n := range flagNames(flag) {
+			if n == name {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+func completeFlags(flags []cli.Flag) []string {
+	var candidates []string
+	for _, flag := range flags {
+		for _, name := range flagNames(flag) {
+			if len(name) == 1 {
+				candidates = append(candidates, "-"+name)
+			} else {
+				candidates = append(candidates, "--"+name)
+			}
+		}
+	}
+	return candidates
+}
+
+// complete returns completion candidates. words contains the command line
+// after the program name; the last entry is the (possibly empty) word that is
+// being completed.
+func complete(app *cli.App, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	words = words[:len(words)-1]
+
+	// Find the command, skipping over global flags and their values.
+	var command *cli.Command
+	flags := app.Flags
+	i := 0
+	for ; i < len(words) && command == nil; i++ {
+		if strings.HasPrefix(words[i], "-") {
+			flag := findFlag(app.Flags, words[i])
+			if flag != nil && flagTakesValue(flag) && !strings.Contains(words[i], "=") {
+				i++
+				// Query the daemon the user is actually talking to.
+				if i < len(words) && flagNames(flag)[0] == "socket" {
+					gSocketFlag = words[i]
+					initSocket()
+				}
+				if i < len(words) && flagNames(flag)[0] == "profile" {
+					gProfile = words[i]
+					initSocket()
+				}
+			}
+			continue
+		}
+		command = app.Command(words[i])
+		if command == nil {
+			return nil
+		}
+		flags = command.Flags
+	}
+
+	// Complete a flag value.
+	if len(words) > 0 && strings.HasPrefix(words[len(words)-1], "-") {
+		last := words[len(words)-1]
+		if flag := findFlag(flags, last); flag != nil && flagTakesValue(flag) && !strings.Contains(last, "=") {
+			if c, has := flagCompleters[flagNames(flag)[0]]; has {
+				return c()
+			}
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return completeFlags(flags)
+	}
+
+	if command == nil {
+		var candidates []string
+		for _, c := range app.Commands {
+			if !c.Hidden {
+				candidates = append(candidates, c.Name)
+			}
+		}
+		return candidates
+	}
+
+	// Count positional arguments to find which completer to use.
+	position := 0
+	for ; i < len(words); i++ {
+		if strings.HasPrefix(words[i], "-") {
+			flag := findFlag(flags, words[i])
+			if flag != nil && flagTakesValue(flag) && !strings.Contains(words[i], "=") {
+				i++
+			}
+			continue
+		}
+		position++
+	}
+	if completers := argCompleters[command.Name]; position < len(completers) {
+		return completers[position]()
+	}
+	return nil
+}
+
+var completionScripts = map[string]string{
+	"bash": `_lspc() {
+  local cur="${COMP_WORDS[COMP_CWORD]}"
+  local IFS=$'\n'
+  local out=($(lspc ` + completeCommandName + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+  case "${out[0]}" in
+    ` + completeFiles + `) COMPREPLY=($(compgen -f -- "$cur")) ;;
+    ` + completeDirs + `) COMPREPLY=($(compgen -d -- "$cur")) ;;
+    *) COMPREPLY=($(compgen -W "${out[*]%%$'\t'*}" -- "$cur")) ;;
+  esac
+}
+complete -o filenames -F _lspc lspc
+`,
+	"zsh": `#compdef lspc
+_lspc() {
+  local -a out candidates
+  local line
+  out=("${(@f)$(lspc ` + completeCommandName + ` "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+  case "$out[1]" in
+    ` + completeFiles + `) _files ;;
+    ` + completeDirs + `) _files -/ ;;
+    *)
+      for line in "${out[@]}"; do
+        [[ -z "$line" ]] && continue
+        if [[ "$line" == *$'\t'* ]]; then
+          candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+          candidates+=("${line//:/\\:}")
+        fi
+      done
+      _describe 'lspc' candidates
+      ;;
+  esac
+}
+compdef _lspc lspc
+`,
+	"fish": `function __lspc_complete
+  set -l tokens (commandline -opc) (commandline -ct)
+  set -l out (lspc ` + completeCommandName + ` $tokens[2..-1] 2>/dev/null)
+  switch "$out[1]"
+    case '` + completeFiles + `'
+      __fish_complete_path (commandline -ct)
+    case '` + completeDirs + `'
+      __fish_complete_directories (commandline -ct)
+    case '*'
+      printf '%s\n' $out
+  end
+end
+complete -c lspc -f -a '(__lspc_complete)'
+`,
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func testCompletionApp() *cli.App {
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "socket"},
+		cli.BoolFlag{Name: "verbose"},
+	}
+	app.Commands = []cli.Command{
+		{
+			Name: "start",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "force"},
+			},
+		},
+		{
+			Name: "ping",
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{Name: "server"},
+			},
+		},
+		{Name: completeCommandName, Hidden: true},
+	}
+	return app
+}
+
+func TestCompleteCommands(t *testing.T) {
+	app := testCompletionApp()
+	defer func() { gSocketFlag, gSocket = "", "" }()
+	assert.Equal(t, []string{"start", "ping"}, complete(app, []string{""}))
+	assert.Equal(t, []string{"start", "ping"}, complete(app, []string{"--verbose", "p"}))
+	assert.Equal(t, []string{"start", "ping"}, complete(app, []string{"--socket", "/tmp/s", ""}))
+	assert.Nil(t, complete(app, []string{"unknown", ""}))
+}
+
+func TestCompleteFlags(t *testing.T) {
+	app := testCompletionApp()
+	assert.Equal(t, []string{"--socket", "--verbose"}, complete(app, []string{"-"}))
+	assert.Equal(t, []string{"--force"}, complete(app, []string{"start", "--"}))
+}
+
+func TestCompleteArgs(t *testing.T) {
+	app := testCompletionApp()
+	assert.Equal(t, []string{completeFiles}, complete(app, []string{"start", ""}))
+	assert.Equal(t, []string{completeDirs}, complete(app, []string{"start", "--force", "clangd", ""}))
+	assert.Nil(t, complete(app, []string{"start", "clangd", "/dir", ""}))
+}
+
+func TestCompleteFlagValue(t *testing.T) {
+	app := testCompletionApp()
+	flagCompleters["server"] = func() []string { return []string{"0", "1"} }
+	defer func() { flagCompleters["server"] = completeServers }()
+	assert.Equal(t, []string{"0", "1"}, complete(app, []string{"ping", "--server", ""}))
+	assert.Nil(t, complete(app, []string{"ping", "--server", "0", ""}))
+}
@@ -0,0 +1,322 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jacobdufault/lspc/jsonrpc"
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests are meant to be run with -race.
+
+// stdinBuffer stands in for the stdin of a language server.
+type stdinBuffer struct {
+	bytes.Buffer
+}
+
+func (b *stdinBuffer) Close() error {
+	return nil
+}
+
+func newTestLanguageServer(id int, directory string) *languageServer {
+	return &languageServer{
+		id:          id,
+		args:        []string{"fake"},
+		startArgs:   StartArgs{Bin: "fake", Directory: directory},
+		directory:   directory,
+		initialized: true,
+		stdin:       &stdinBuffer{},
+		onResponse:  make(map[RequestID]responseHandler),
+		stats:       newServerStats(),
+		diagnostics: make(map[LsDocumentURI][]LsDiagnostic),
+		documents:   make(map[LsDocumentURI]string),
+		versions:    make(map[LsDocumentURI]int),
+		encodings:   make(map[LsDocumentURI]fileEncoding),
+		tokens:      make(map[LsDocumentURI]semanticTokensState),
+	}
+}
+
+func TestConcurrentServerList(t *testing.T) {
+	s := &Server{config: &Config{}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.mu.Lock()
+				id := s.nextID
+				s.nextID++
+				s.servers = append(s.servers, newTestLanguageServer(id, "/p"))
+				s.mu.Unlock()
+
+				for _, server := range s.serverList() {
+					s.nextInstance(server)
+				}
+				found, err := s.findServer(id)
+				assert.NoError(t, err)
+				assert.Equal(t, id, found.id)
+				if j%2 == 0 {
+					_, err = s.stopServer(id)
+					assert.NoError(t, err)
+				} else {
+					assert.True(t, s.removeServer(found))
+					assert.False(t, s.removeServer(found))
+				}
+				assert.NotNil(t, s.currentConfig())
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Empty(t, s.serverList())
+	assert.Equal(t, 400, s.nextID)
+}
+
+func TestConcurrentCalls(t *testing.T) {
+	defer func(size int) { gMaxMessageSize = size }(gMaxMessageSize)
+	gMaxMessageSize = 1
+
+	// The server answers every request with its params.
+	l := newTestLanguageServer(0, "/p")
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	l.stdin, l.stdout = stdinWriter, stdoutReader
+	go func() {
+		scanner := bufio.NewScanner(stdinReader)
+		scanner.Split((&jsonrpc.Splitter{MaxContentLength: 1024 * 1024}).Split)
+		for scanner.Scan() {
+			var request JSONRPCHeader
+			assert.NoError(t, fromJSON(scanner.Bytes(), &request))
+			response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, request.ID, request.Params)
+			jsonrpc.WriteFramed(stdoutWriter, []byte(response), jsonrpc.FramingLSP)
+		}
+		stdoutWriter.Close()
+	}()
+	l.readers.Add(1)
+	go l.stdoutReader()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				params := fmt.Sprintf(`{"client":%d,"request":%d}`, i, j)
+				result, err := l.call("echo", easyjson.RawMessage(params), 5*time.Second)
+				assert.NoError(t, err)
+				assert.Equal(t, params, string(result))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, RequestID(400), l.nextRequestID)
+	assert.Empty(t, l.onResponse)
+
+	// Writes fail once the server is gone; the first error is kept.
+	stdinReader.Close()
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.writeNotification("exit", nil)
+			l.setErr(fmt.Errorf("later"))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, io.ErrClosedPipe, l.failure())
+	l.readers.Wait()
+}
+
+func TestConcurrentDocumentVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-concurrency")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "a.c")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int a;\n"), 0644))
+	l := newTestLanguageServer(0, dir)
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+
+	// Every client changes the file and then asks about it, as a save
+	// followed by a query would.
+	var fileMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				fileMu.Lock()
+				err := ioutil.WriteFile(path, []byte(fmt.Sprintf("int a%d_%d;\n", i, j)), 0644)
+				fileMu.Unlock()
+				assert.NoError(t, err)
+				_, err = l.openDocument(path)
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Versions were sent in order without gaps or repeats.
+	written := l.stdin.(*stdinBuffer).String()
+	matches := regexp.MustCompile(`"version":(\d+)`).FindAllStringSubmatch(written, -1)
+	assert.NotEmpty(t, matches)
+	for i, m := range matches {
+		version, err := strconv.Atoi(m[1])
+		assert.NoError(t, err)
+		assert.Equal(t, i, version)
+	}
+	assert.Equal(t, len(matches)-1, l.versions[pathToURI(path)])
+}
+
+func TestConcurrentEdits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-concurrency")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "a.c")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int foo;\n"), 0644))
+	l := newTestLanguageServer(0, dir)
+
+	// Two clients plan different edits of the same text. Only the first one
+	// applied may succeed; the second was planned against stale text.
+	var plans []*editPlan
+	for _, name := range []string{"bar", "baz"} {
+		plan, err := l.planEdit("Rename foo", LsWorkspaceEdit{Changes: map[LsDocumentURI][]LsTextEdit{
+			pathToURI(path): {textEdit(0, 4, 0, 7, name)},
+		}})
+		assert.NoError(t, err)
+		plans = append(plans, plan)
+	}
+	errs := make([]error, len(plans))
+	var wg sync.WaitGroup
+	for i, plan := range plans {
+		wg.Add(1)
+		go func(i int, plan *editPlan) {
+			defer wg.Done()
+			errs[i] = l.applyPlan("test", plan)
+		}(i, plan)
+	}
+	wg.Wait()
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			assert.Contains(t, err.Error(), "changed since the edit was planned")
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"int bar;\n", "int baz;\n"}, string(content))
+
+	// Edits wait for requests that are reading the project.
+	plan, err := l.planEdit("Rename", LsWorkspaceEdit{Changes: map[LsDocumentURI][]LsTextEdit{
+		pathToURI(path): {textEdit(0, 4, 0, 7, "qux")},
+	}})
+	assert.NoError(t, err)
+	l.editMu.RLock()
+	applied := make(chan error)
+	go func() {
+		applied <- l.applyPlan("test", plan)
+	}()
+	select {
+	case <-applied:
+		t.Fatal("edit applied while a request was reading")
+	case <-time.After(50 * time.Millisecond):
+	}
+	l.editMu.RUnlock()
+	assert.NoError(t, <-applied)
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "int qux;\n", string(content))
+}
+
+func TestConcurrentEditSessions(t *testing.T) {
+	l := newTestLanguageServer(0, "/p")
+	sessions := make(chan *editSession, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s, err := newEditSession(l); err == nil {
+				sessions <- s
+			}
+		}()
+	}
+	wg.Wait()
+	close(sessions)
+
+	// Only one client at a time may apply a code action of a server.
+	var started []*editSession
+	for s := range sessions {
+		started = append(started, s)
+	}
+	assert.Len(t, started, 1)
+	assert.Equal(t, started[0], editSessionFor(l))
+	started[0].close()
+	assert.Nil(t, editSessionFor(l))
+}
+
+func TestConcurrentStarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-concurrency")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	gLockDir = dir
+	defer func() { gLockDir = "" }()
+
+	// Clients starting the same server at once all get the one server.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	s := &Server{config: &Config{}}
+	ids := make([][]int, 8)
+	ready := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-ready
+			assert.NoError(t, s.Start(StartArgs{Bin: "sleep 30", Directory: dir}, &ids[i]))
+		}(i)
+	}
+	close(ready)
+	wg.Wait()
+	servers := s.serverList()
+	for _, server := range servers {
+		s.stopServer(server.id)
+		server.close(0)
+	}
+	assert.Equal(t, 1, len(servers))
+	for _, started := range ids {
+		assert.Equal(t, []int{0}, started)
+	}
+}
@@ -0,0 +1,788 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jacobdufault/lspc/jsonrpc"
+	easyjson "github.com/mailru/easyjson"
+	shellwords "github.com/mattn/go-shellwords"
+)
+
+// Name of the per-project config file, which lives in the project directory.
+const projectConfigName = ".lspc.toml"
+
+// Config is the user configuration, usually ~/.config/lspc/config.toml.
+//
+//	init_options = '{"cacheDirectory": "/ssd/cache"}'
+//
+//	[language.cpp]
+//	command = "clangd"
+//	init_options = '{"clangdFileStatus": true}'
+//
+// The daemon re-reads the config on `lspc reload-config` or SIGHUP.
+type Config struct {
+	// JSON object passed as initializationOptions to every language server.
+	InitOptions string `toml:"init_options,omitempty"`
+
+	// Overrides --max-servers if set.
+	MaxServers *int `toml:"max_servers,omitempty"`
+
+	// How long to wait for a language server to answer a request, ie, "45s",
+	// before cancelling it with $/cancelRequest. Defaults to 30s.
+	RequestTimeout string `toml:"request_timeout,omitempty"`
+
+	// File the daemon logs to. Defaults to stderr.
+	LogFile string `toml:"log_file,omitempty"`
+
+	// Never modify files in any project, as with --read-only.
+	ReadOnly bool `toml:"read_only,omitempty"`
+
+	// Sandbox for every language server.
+	Sandbox SandboxConfig `toml:"sandbox,omitempty"`
+
+	// Process settings for every language server.
+	Process ProcessConfig `toml:"process,omitempty"`
+
+	// Limits on the requests of each client of the daemon.
+	RateLimit RateLimitConfig `toml:"rate_limit,omitempty"`
+
+	// Rules that send definition queries on to another language server.
+	Rewrites []RewriteRule `toml:"rewrite,omitempty"`
+
+	// Rules that send some methods to specific servers of a project.
+	Routes []RouteRule `toml:"route,omitempty"`
+
+	// Rules that start a language server for a file that is queried while
+	// none runs for it.
+	AutoStart []AutoStartRule `toml:"autostart,omitempty"`
+
+	// Named groups of servers that commands can target with --group.
+	Groups map[string]GroupConfig `toml:"group,omitempty"`
+
+	// Rules that drop or change diagnostics of every language server.
+	DiagnosticRules []DiagnosticRule `toml:"diagnostic_rule,omitempty"`
+
+	// Documents larger than this, ie, "20MB", are not sent to language
+	// servers. Units are powers of 1024. Empty means no limit.
+	MaxDocumentSize string `toml:"max_document_size,omitempty"`
+
+	// What to do with larger documents: refuse, the default, fails requests
+	// about them, truncate sends their beginning up to the last complete
+	// line.
+	LargeDocuments string `toml:"large_documents,omitempty"`
+
+	// Encodings of files that are not UTF-8.
+	Encodings []EncodingRule `toml:"encoding,omitempty"`
+
+	Languages map[string]LanguageConfig `toml:"language,omitempty"`
+
+	// Regular expressions matched, ignoring case, against JSON keys whose
+	// values are hidden in the audit log and config show, in addition to
+	// keys containing token, secret, password, api key or credential.
+	Redact []string `toml:"redact,omitempty"`
+
+	// How symbol and completion kinds are printed, keyed by kind name.
+	Kinds map[string]KindConfig `toml:"kind,omitempty"`
+}
+
+// LanguageConfig configures the language server used for a language.
+type LanguageConfig struct {
+	// Command used to run the language server. Also used to detect the
+	// language of servers started with `lspc start`.
+	Command string `toml:"command,omitempty"`
+
+	// Merged over the global init options.
+	InitOptions string `toml:"init_options,omitempty"`
+
+	// JSON object sent to the server with workspace/didChangeConfiguration and
+	// used to answer workspace/configuration requests.
+	Settings string `toml:"settings,omitempty"`
+
+	// How the server delimits messages: lsp, the default, or jsonl for
+	// servers that write one JSON message per line.
+	Framing string `toml:"framing,omitempty"`
+
+	// Environment variables of the server, added to those of the daemon, ie,
+	// GOFLAGS = "-tags=integration". Values may use the same variables as
+	// init options.
+	Env map[string]string `toml:"env,omitempty"`
+
+	// Protocol features to avoid with older servers.
+	Compat CompatConfig `toml:"compat,omitempty"`
+
+	// Replaces the global sandbox if it sets a tool, otherwise adds paths to
+	// it.
+	Sandbox SandboxConfig `toml:"sandbox,omitempty"`
+
+	// Settings override the global process settings.
+	Process ProcessConfig `toml:"process,omitempty"`
+
+	// Checked before the global diagnostic rules.
+	DiagnosticRules []DiagnosticRule `toml:"diagnostic_rule,omitempty"`
+}
+
+// CompatConfig overrides which protocol features are used with a language
+// server, ie,
+//
+//	[language.cpp.compat]
+//	legacy = true
+//	markup_content = true
+type CompatConfig struct {
+	// The server predates protocol 3.0. Turns off every feature below that
+	// is not set explicitly.
+	Legacy bool `toml:"legacy,omitempty"`
+
+	HierarchicalDocumentSymbols *bool `toml:"hierarchical_document_symbols,omitempty"`
+	MarkupContent               *bool `toml:"markup_content,omitempty"`
+	SemanticTokens              *bool `toml:"semantic_tokens,omitempty"`
+}
+
+// SandboxConfig runs language servers with a restricted view of the
+// filesystem, since they execute third-party code from the projects they
+// analyze, ie,
+//
+//	[language.rust.sandbox]
+//	tool = "bwrap"
+//	paths = ["~/.cargo", "~/.cache/rust-analyzer"]
+//
+// Servers can read the system directories and write to the project
+// directory and paths; the rest of the filesystem, including the home
+// directory, is hidden.
+type SandboxConfig struct {
+	// bwrap or firejail, or none to not sandbox a language even though a
+	// global sandbox is configured.
+	Tool string `toml:"tool,omitempty"`
+
+	// Directories the server may write to, ie, caches. ~ is the home
+	// directory and relative paths are inside of the project.
+	Paths []string `toml:"paths,omitempty"`
+
+	// Directories the server may read, ie, toolchains.
+	ReadOnlyPaths []string `toml:"read_only_paths,omitempty"`
+}
+
+// ProcessConfig controls the privileges of language server processes, ie,
+// when a daemon shared on a build server runs as root:
+//
+//	[process]
+//	umask = "077"
+//	user = "lsp"
+//	close_fds = true
+type ProcessConfig struct {
+	// Octal umask for files the server creates. Not supported on windows.
+	Umask string `toml:"umask,omitempty"`
+
+	// User and group name or id to run the server as. Changing them requires
+	// a privileged daemon. The group defaults to the user's primary group.
+	User  string `toml:"user,omitempty"`
+	Group string `toml:"group,omitempty"`
+
+	// Do not pass file descriptors the daemon inherited on to the server.
+	// The server is started through lspc, which must be executable by user.
+	CloseFds bool `toml:"close_fds,omitempty"`
+}
+
+// ProjectConfig is read from .lspc.toml in the project directory.
+type ProjectConfig struct {
+	// Merged over the global and language init options.
+	InitOptions string `toml:"init_options"`
+
+	// Never modify files in this project, ie, for checkouts that must stay
+	// pristine.
+	ReadOnly bool `toml:"read_only"`
+
+	// Environment variables of the servers of this project, set over those
+	// of their language.
+	Env map[string]string `toml:"env"`
+
+	// Subdirectories served by their own language server.
+	Roots []RootConfig `toml:"root"`
+
+	// Language servers started by lspc up.
+	Servers []ProjectServerConfig `toml:"server"`
+}
+
+// defaultConfigPath returns the location of the user config file.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "lspc", "config.toml")
+}
+
+// loadConfig reads the config at path. A missing file is an empty config.
+// Languages without a command use the server installed for them by
+// `lspc install`, if any.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{}
+	if path != "" && fileExists(path) {
+		if _, err := toml.DecodeFile(path, config); err != nil {
+			return nil, fmt.Errorf("cannot read config %s: %s", path, err.Error())
+		}
+	}
+	manifest, err := loadInstallManifest(gInstallDir)
+	if err != nil {
+		return nil, err
+	}
+	config.useInstalled(manifest)
+	return config, nil
+}
+
+// loadProjectConfig reads .lspc.toml from directory. A missing file is an
+// empty config.
+func loadProjectConfig(directory string) (*ProjectConfig, error) {
+	config := &ProjectConfig{}
+	path := filepath.Join(directory, projectConfigName)
+	if !fileExists(path) {
+		return config, nil
+	}
+	if _, err := toml.DecodeFile(path, config); err != nil {
+		return nil, fmt.Errorf("cannot read project config %s: %s", path, err.Error())
+	}
+	return config, nil
+}
+
+// validateConfig checks the config at path and, if directory is not empty,
+// the project config in directory. Returns a description of every problem.
+func validateConfig(path, directory string) []string {
+	var problems []string
+	if path != "" && fileExists(path) {
+		config := &Config{}
+		md, err := toml.DecodeFile(path, config)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", path, err.Error()))
+		} else {
+			problems = append(problems, undecodedKeys(path, md)...)
+			problems = append(problems, config.validate(path)...)
+		}
+	}
+
+	project := filepath.Join(directory, projectConfigName)
+	if directory != "" && fileExists(project) {
+		config := &ProjectConfig{}
+		md, err := toml.DecodeFile(project, config)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", project, err.Error()))
+		} else {
+			problems = append(problems, undecodedKeys(project, md)...)
+			if err := checkInitOptions(config.InitOptions); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: init_options: %s", project, err.Error()))
+			}
+			for key, err := range validateEnv(config.Env) {
+				problems = append(problems, fmt.Sprintf("%s: env.%s: %s", project, key, err.Error()))
+			}
+			for i, root := range config.Roots {
+				for key, err := range root.validate() {
+					problems = append(problems, fmt.Sprintf("%s: root[%d].%s: %s", project, i, key, err.Error()))
+				}
+			}
+			for i, server := range config.Servers {
+				for key, err := range server.validate() {
+					problems = append(problems, fmt.Sprintf("%s: server[%d].%s: %s", project, i, key, err.Error()))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// undecodedKeys reports keys in a config file that do not map to a setting,
+// which are usually typos.
+func undecodedKeys(path string, md toml.MetaData) []string {
+	var problems []string
+	for _, key := range md.Undecoded() {
+		problems = append(problems, fmt.Sprintf("%s: unknown key %s", path, key.String()))
+	}
+	return problems
+}
+
+// validate checks the values of a config read from path.
+func (c *Config) validate(path string) []string {
+	var problems []string
+	report := func(key string, err error) {
+		problems = append(problems, fmt.Sprintf("%s: %s: %s", path, key, err.Error()))
+	}
+
+	if err := checkInitOptions(c.InitOptions); err != nil {
+		report("init_options", err)
+	}
+	if c.MaxServers != nil && *c.MaxServers < 0 {
+		report("max_servers", fmt.Errorf("must not be negative"))
+	}
+	if c.RequestTimeout != "" {
+		if timeout, err := time.ParseDuration(c.RequestTimeout); err != nil {
+			report("request_timeout", err)
+		} else if timeout <= 0 {
+			report("request_timeout", fmt.Errorf("must be positive"))
+		}
+	}
+	if err := c.Sandbox.validate(); err != nil {
+		report("sandbox.tool", err)
+	}
+	for key, err := range c.Process.validate() {
+		report("process."+key, err)
+	}
+	for key, err := range c.RateLimit.validate() {
+		report("rate_limit."+key, err)
+	}
+	for i, rule := range c.Rewrites {
+		for key, err := range rule.validate() {
+			report(fmt.Sprintf("rewrite[%d].%s", i, key), err)
+		}
+	}
+	for i, rule := range c.Routes {
+		for key, err := range rule.validate() {
+			report(fmt.Sprintf("route[%d].%s", i, key), err)
+		}
+	}
+	for i, rule := range c.AutoStart {
+		problems := rule.validate()
+		if rule.Command == "" && rule.Language != "" && c.Languages[rule.Language].Command == "" {
+			problems["language"] = fmt.Errorf("language %q has no command", rule.Language)
+		}
+		for key, err := range problems {
+			report(fmt.Sprintf("autostart[%d].%s", i, key), err)
+		}
+	}
+	var groups []string
+	for name := range c.Groups {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	for _, name := range groups {
+		for key, err := range c.Groups[name].validate() {
+			report("group."+name+"."+key, err)
+		}
+	}
+	for i, rule := range c.DiagnosticRules {
+		for key, err := range rule.validate() {
+			report(fmt.Sprintf("diagnostic_rule[%d].%s", i, key), err)
+		}
+	}
+
+	for key, err := range c.validateDocumentLimit() {
+		report(key, err)
+	}
+	for i, rule := range c.Encodings {
+		for key, err := range rule.validate() {
+			report(fmt.Sprintf("encoding[%d].%s", i, key), err)
+		}
+	}
+	for i, pattern := range c.Redact {
+		if _, err := regexp.Compile(pattern); err != nil {
+			report(fmt.Sprintf("redact[%d]", i), err)
+		}
+	}
+	for key, err := range validateKinds(c.Kinds) {
+		report("kind."+key, err)
+	}
+
+	var names []string
+	for name := range c.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		language := c.Languages[name]
+		if language.Command != "" {
+			if words, err := shellwords.Parse(language.Command); err != nil {
+				report("language."+name+".command", err)
+			} else if len(words) == 0 {
+				report("language."+name+".command", fmt.Errorf("no program"))
+			}
+		}
+		if err := checkInitOptions(language.InitOptions); err != nil {
+			report("language."+name+".init_options", err)
+		}
+		if _, err := jsonrpc.ParseFraming(language.Framing); err != nil {
+			report("language."+name+".framing", err)
+		}
+		for key, err := range validateEnv(language.Env) {
+			report("language."+name+".env."+key, err)
+		}
+		if err := checkJSONObject(language.Settings); err != nil {
+			report("language."+name+".settings", err)
+		}
+		if err := language.Sandbox.validate(); err != nil {
+			report("language."+name+".sandbox.tool", err)
+		}
+		for key, err := range language.Process.validate() {
+			report("language."+name+".process."+key, err)
+		}
+		for i, rule := range language.DiagnosticRules {
+			for key, err := range rule.validate() {
+				report(fmt.Sprintf("language.%s.diagnostic_rule[%d].%s", name, i, key), err)
+			}
+		}
+	}
+	return problems
+}
+
+// checkJSONObject returns an error if value is neither empty nor a JSON
+// object.
+func checkJSONObject(value string) error {
+	if value == "" {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return fmt.Errorf("invalid JSON: %s", err.Error())
+	}
+	if _, isObject := v.(map[string]interface{}); !isObject {
+		return fmt.Errorf("%s is not a JSON object", value)
+	}
+	return nil
+}
+
+// checkInitOptions is checkJSONObject that also rejects unknown variables.
+func checkInitOptions(value string) error {
+	if err := checkJSONObject(value); err != nil || value == "" {
+		return err
+	}
+	_, err := expandJSONVariables(easyjson.RawMessage(value), "")
+	return err
+}
+
+// effectiveConfig returns the config that applies to servers started in
+// directory: the init options of each language are fully merged with the
+// global and project init options.
+func (c *Config) effectiveConfig(directory string) (*Config, error) {
+	global, err := c.initOptionsFor(StartArgs{Directory: directory})
+	if err != nil {
+		return nil, err
+	}
+	effective := &Config{
+		InitOptions:     string(global),
+		MaxServers:      c.MaxServers,
+		RequestTimeout:  c.RequestTimeout,
+		LogFile:         c.LogFile,
+		ReadOnly:        c.readOnlyFor(directory),
+		Sandbox:         c.Sandbox,
+		Process:         c.Process,
+		RateLimit:       c.RateLimit,
+		Rewrites:        c.Rewrites,
+		Routes:          c.Routes,
+		AutoStart:       c.AutoStart,
+		Groups:          c.Groups,
+		DiagnosticRules: c.DiagnosticRules,
+		MaxDocumentSize: c.MaxDocumentSize,
+		LargeDocuments:  c.LargeDocuments,
+		Encodings:       c.Encodings,
+		Redact:          c.Redact,
+		Languages:       make(map[string]LanguageConfig),
+		Kinds:           c.Kinds,
+	}
+	for name, language := range c.Languages {
+		initOpts, err := c.initOptionsFor(StartArgs{Directory: directory, Language: name})
+		if err != nil {
+			return nil, err
+		}
+		language.InitOptions = string(initOpts)
+		effective.Languages[name] = language
+	}
+	return effective, nil
+}
+
+// printTOMLAsJSON prints c as JSON for config show --json, with the keys of
+// the config file rather than the names of the fields of Config.
+func printTOMLAsJSON(c *Config) error {
+	var encoded bytes.Buffer
+	if err := toml.NewEncoder(&encoded).Encode(c); err != nil {
+		return err
+	}
+	var decoded map[string]interface{}
+	if _, err := toml.Decode(encoded.String(), &decoded); err != nil {
+		return err
+	}
+	return printJSON(decoded)
+}
+
+// requestTimeout returns the request_timeout of c, or the default if it is
+// not set or invalid, which validate reports.
+func (c *Config) requestTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(c.RequestTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultQueryTimeout
+}
+
+// executableName returns the name of the program run by command, ie,
+// "clangd" for "/usr/bin/clangd --background-index".
+func executableName(command string) string {
+	words, err := shellwords.Parse(command)
+	if err != nil || len(words) == 0 {
+		return ""
+	}
+	return filepath.Base(words[0])
+}
+
+// languageFor returns the name of the configured language for a server
+// started with bin. If language is not empty it is used as-is.
+func (c *Config) languageFor(bin, language string) string {
+	if language != "" {
+		return language
+	}
+	exe := executableName(bin)
+	if exe == "" {
+		return ""
+	}
+	// Check in a stable order in case several languages use the same server.
+	var names []string
+	for name := range c.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if executableName(c.Languages[name].Command) == exe {
+			return name
+		}
+	}
+	return ""
+}
+
+// initOptionsLayer is one source of init options.
+type initOptionsLayer struct {
+	name string
+	json string
+}
+
+// initOptionsFor merges init options from the global config, the language,
+// the project and finally the command line, with later layers winning.
+// Variables in strings are expanded, see expandVariables.
+func (c *Config) initOptionsFor(args StartArgs) (easyjson.RawMessage, error) {
+	project, err := loadProjectConfig(args.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []initOptionsLayer{{"global init_options", c.InitOptions}}
+	if language := c.languageFor(args.Bin, args.Language); language != "" {
+		layers = append(layers, initOptionsLayer{
+			fmt.Sprintf("language.%s.init_options", language),
+			c.Languages[language].InitOptions,
+		})
+	}
+	layers = append(layers,
+		initOptionsLayer{projectConfigName + " init_options", project.InitOptions},
+		initOptionsLayer{"command line init options", string(args.InitOpts)})
+
+	merged := easyjson.RawMessage("{}")
+	for _, layer := range layers {
+		if layer.json == "" {
+			continue
+		}
+		expanded, err := expandJSONVariables(easyjson.RawMessage(layer.json), args.Directory)
+		if err == nil {
+			merged, err = mergeJSON(merged, expanded)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", layer.name, err.Error())
+		}
+	}
+	return merged, nil
+}
+
+// validateEnv returns problems of the environment variables env keyed by
+// their name.
+func validateEnv(env map[string]string) map[string]error {
+	problems := make(map[string]error)
+	for name, value := range env {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			problems[name] = fmt.Errorf("invalid variable name %q", name)
+		} else if _, err := expandVariables(value, ""); err != nil {
+			problems[name] = err
+		}
+	}
+	return problems
+}
+
+// envFor returns the environment variables set for a server of language
+// started in directory as NAME=value, sorted by name: those of the language
+// and, over them, those of the project config of directory.
+func (c *Config) envFor(language, directory string) ([]string, error) {
+	project, err := loadProjectConfig(directory)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]string)
+	for name, value := range c.Languages[language].Env {
+		merged[name] = value
+	}
+	for name, value := range project.Env {
+		merged[name] = value
+	}
+	var env []string
+	for name, value := range merged {
+		expanded, err := expandVariables(value, directory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid env %s: %s", name, err.Error())
+		}
+		env = append(env, name+"="+expanded)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// mergeJSON merges override into base. Objects are merged recursively; any
+// other value in override replaces the value in base.
+func mergeJSON(base, override easyjson.RawMessage) (easyjson.RawMessage, error) {
+	var b, o interface{}
+	if err := json.Unmarshal(base, &b); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(override, &o); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValues(b, o))
+}
+
+func mergeValues(base, override interface{}) interface{} {
+	b, baseIsObject := base.(map[string]interface{})
+	o, overrideIsObject := override.(map[string]interface{})
+	if !baseIsObject || !overrideIsObject {
+		return override
+	}
+	for key, value := range o {
+		if existing, has := b[key]; has {
+			b[key] = mergeValues(existing, value)
+		} else {
+			b[key] = value
+		}
+	}
+	return b
+}
+
+// variablePattern matches a variable such as ${userHome} or ${env:HOME}.
+var variablePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expandVariables replaces variables in s: ${workspaceRoot} with directory,
+// the directory of the language server, ${userHome} with the home directory
+// and ${env:NAME} with the environment variable NAME, or nothing if it is not
+// set. Other variables are an error.
+func expandVariables(s, directory string) (string, error) {
+	var err error
+	expanded := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		switch {
+		case name == "workspaceRoot":
+			return directory
+		case name == "userHome":
+			home, e := os.UserHomeDir()
+			if e != nil && err == nil {
+				err = e
+			}
+			return home
+		case strings.HasPrefix(name, "env:"):
+			return os.Getenv(strings.TrimPrefix(name, "env:"))
+		}
+		if err == nil {
+			err = fmt.Errorf("unknown variable %s; expected ${workspaceRoot}, ${userHome} or ${env:NAME}", match)
+		}
+		return match
+	})
+	return expanded, err
+}
+
+// expandJSONVariables expands variables in every string inside of value.
+// Object keys are left alone.
+func expandJSONVariables(value easyjson.RawMessage, directory string) (easyjson.RawMessage, error) {
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+	var expand func(v interface{}) (interface{}, error)
+	expand = func(v interface{}) (interface{}, error) {
+		var err error
+		switch v := v.(type) {
+		case string:
+			return expandVariables(v, directory)
+		case []interface{}:
+			for i := range v {
+				if v[i], err = expand(v[i]); err != nil {
+					return nil, err
+				}
+			}
+		case map[string]interface{}:
+			for key := range v {
+				if v[key], err = expand(v[key]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return v, nil
+	}
+	v, err := expand(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// settingsFor returns the settings of language, or nil if there are none.
+func (c *Config) settingsFor(language string) easyjson.RawMessage {
+	if settings := c.Languages[language].Settings; settings != "" {
+		return easyjson.RawMessage(settings)
+	}
+	return nil
+}
+
+// settingsSection returns the value at the dotted path section inside of
+// settings, ie, "gopls.staticcheck". An empty section returns all settings.
+// Returns null if the section does not exist.
+func settingsSection(settings easyjson.RawMessage, section string) easyjson.RawMessage {
+	null := easyjson.RawMessage("null")
+	if len(settings) == 0 {
+		return null
+	}
+	if section == "" {
+		return settings
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(settings, &value); err != nil {
+		return null
+	}
+	for _, key := range strings.Split(section, ".") {
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return null
+		}
+		var has bool
+		if value, has = object[key]; !has {
+			return null
+		}
+	}
+	result, err := json.Marshal(value)
+	if err != nil {
+		return null
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeJSON(t *testing.T) {
+	merged, err := mergeJSON(easyjson.RawMessage(`{"a": 1, "b": {"c": 2, "d": 3}}`), easyjson.RawMessage(`{"b": {"c": 4}, "e": [5]}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a": 1, "b": {"c": 4, "d": 3}, "e": [5]}`, string(merged))
+
+	merged, err = mergeJSON(easyjson.RawMessage(`{"a": {"b": 1}}`), easyjson.RawMessage(`{"a": 2}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a": 2}`, string(merged))
+
+	_, err = mergeJSON(easyjson.RawMessage(`{}`), easyjson.RawMessage(`{bad`))
+	assert.Error(t, err)
+}
+
+func TestExpandVariables(t *testing.T) {
+	os.Setenv("LSPC_TEST_CACHE", "/cache")
+	defer os.Unsetenv("LSPC_TEST_CACHE")
+	home, err := os.UserHomeDir()
+	assert.NoError(t, err)
+
+	expanded, err := expandVariables("${env:LSPC_TEST_CACHE}/${workspaceRoot}:${userHome}${env:LSPC_TEST_UNSET}", "/p")
+	assert.NoError(t, err)
+	assert.Equal(t, "/cache//p:"+home, expanded)
+	_, err = expandVariables("${workspaceFolder}", "/p")
+	assert.Error(t, err)
+
+	object, err := expandJSONVariables(easyjson.RawMessage(`{"cache": {"directory": "${workspaceRoot}/.cache"}, "${workspaceRoot}": ["${env:LSPC_TEST_CACHE}", 1]}`), "/p")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cache": {"directory": "/p/.cache"}, "${workspaceRoot}": ["/cache", 1]}`, string(object))
+
+	config := &Config{InitOptions: `{"cache": "${workspaceRoot}/.cache"}`}
+	initOpts, err := config.initOptionsFor(StartArgs{Directory: "/p", InitOpts: easyjson.RawMessage(`{"log": "${env:LSPC_TEST_CACHE}/log"}`)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cache": "/p/.cache", "log": "/cache/log"}`, string(initOpts))
+
+	config.InitOptions = `{"cache": "${home}"}`
+	assert.Len(t, config.validate("config.toml"), 1)
+}
+
+func TestLanguageFor(t *testing.T) {
+	config := &Config{Languages: map[string]LanguageConfig{
+		"cpp": {Command: "clangd --background-index"},
+		"go":  {Command: "gopls"},
+	}}
+	assert.Equal(t, "cpp", config.languageFor("/usr/bin/clangd", ""))
+	assert.Equal(t, "go", config.languageFor("gopls serve", ""))
+	assert.Equal(t, "rust", config.languageFor("gopls", "rust"))
+	assert.Equal(t, "", config.languageFor("cquery", ""))
+}
+
+func TestInitOptionsLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, projectConfigName),
+		[]byte(`init_options = '{"project": true, "shared": "project"}'`), 0644))
+
+	config := &Config{
+		InitOptions: `{"global": true, "shared": "global"}`,
+		Languages: map[string]LanguageConfig{
+			"cpp": {Command: "clangd", InitOptions: `{"language": true, "shared": "language"}`},
+		},
+	}
+
+	opts, err := config.initOptionsFor(StartArgs{Bin: "clangd", Directory: dir, InitOpts: easyjson.RawMessage(`{}`)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"global": true, "language": true, "project": true, "shared": "project"}`, string(opts))
+
+	opts, err = config.initOptionsFor(StartArgs{Bin: "clangd", Directory: dir, InitOpts: easyjson.RawMessage(`{"shared": "cli"}`)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"global": true, "language": true, "project": true, "shared": "cli"}`, string(opts))
+
+	config.InitOptions = `{bad`
+	_, err = config.initOptionsFor(StartArgs{Bin: "clangd", Directory: dir})
+	assert.Error(t, err)
+}
+
+func TestSettingsSection(t *testing.T) {
+	settings := easyjson.RawMessage(`{"gopls": {"staticcheck": true, "env": {"GOOS": "linux"}}}`)
+	assert.JSONEq(t, string(settings), string(settingsSection(settings, "")))
+	assert.JSONEq(t, `true`, string(settingsSection(settings, "gopls.staticcheck")))
+	assert.JSONEq(t, `{"GOOS": "linux"}`, string(settingsSection(settings, "gopls.env")))
+	assert.Equal(t, "null", string(settingsSection(settings, "gopls.missing")))
+	assert.Equal(t, "null", string(settingsSection(settings, "gopls.staticcheck.nested")))
+	assert.Equal(t, "null", string(settingsSection(nil, "gopls")))
+}
+
+func TestValidateConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.toml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`
+init_options = '{"ok": true}'
+max_server = 3
+
+[[autostart]]
+extensions = [".rs"]
+language = "rust"
+
+[language.cpp]
+command = "clangd"
+init_options = '{bad'
+settings = '[1]'
+framing = "ndjson"
+env = { CLANGD_FLAGS = "${bogus}" }
+
+[language.cpp.sandbox]
+tool = "docker"
+`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, projectConfigName),
+		[]byte(`init_option = '{}'`), 0644))
+
+	assert.Equal(t, []string{
+		path + ": unknown key max_server",
+		path + `: autostart[0].language: language "rust" has no command`,
+		path + ": language.cpp.init_options: invalid JSON: invalid character 'b' looking for beginning of object key string",
+		path + `: language.cpp.framing: unknown framing "ndjson"; expected lsp or jsonl`,
+		path + ": language.cpp.env.CLANGD_FLAGS: unknown variable ${bogus}; expected ${workspaceRoot}, ${userHome} or ${env:NAME}",
+		path + ": language.cpp.settings: [1] is not a JSON object",
+		path + `: language.cpp.sandbox.tool: unknown tool "docker"; use bwrap, firejail or none`,
+		filepath.Join(dir, projectConfigName) + ": unknown key init_option",
+	}, validateConfig(path, dir))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`[language.cpp]
+command = "clangd"`), 0644))
+	assert.Empty(t, validateConfig(path, ""))
+}
+
+func TestEnvFor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, projectConfigName), []byte(`
+[env]
+GOFLAGS = "-tags=integration"
+`), 0644))
+
+	config := &Config{Languages: map[string]LanguageConfig{
+		"go": {Env: map[string]string{"GOFLAGS": "-mod=mod", "GOCACHE": "${workspaceRoot}/.cache"}},
+	}}
+	env, err := config.envFor("go", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GOCACHE=" + dir + "/.cache", "GOFLAGS=-tags=integration"}, env)
+	env, err = config.envFor("", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GOFLAGS=-tags=integration"}, env)
+
+	assert.Contains(t, validateEnv(map[string]string{"A=B": "c"}), "A=B")
+	assert.Empty(t, validateEnv(map[string]string{"HOME": "${userHome}"}))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	assert.Equal(t, defaultQueryTimeout, (&Config{}).requestTimeout())
+	assert.Equal(t, 45*time.Second, (&Config{RequestTimeout: "45s"}).requestTimeout())
+	assert.Equal(t, defaultQueryTimeout, (&Config{RequestTimeout: "-1s"}).requestTimeout())
+
+	assert.Empty(t, (&Config{RequestTimeout: "1m30s"}).validate("c"))
+	assert.Equal(t, []string{"c: request_timeout: must be positive"}, (&Config{RequestTimeout: "0s"}).validate("c"))
+	assert.Equal(t, []string{`c: request_timeout: time: invalid duration "soon"`}, (&Config{RequestTimeout: "soon"}).validate("c"))
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, projectConfigName),
+		[]byte(`init_options = '{"project": true}'`), 0644))
+
+	config := &Config{
+		InitOptions: `{"global": true}`,
+		Languages: map[string]LanguageConfig{
+			"cpp": {Command: "clangd", InitOptions: `{"language": true}`, Settings: `{"a": 1}`},
+		},
+	}
+	effective, err := config.effectiveConfig(dir)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"global": true, "project": true}`, effective.InitOptions)
+	assert.JSONEq(t, `{"global": true, "language": true, "project": true}`, effective.Languages["cpp"].InitOptions)
+	assert.Equal(t, "clangd", effective.Languages["cpp"].Command)
+	assert.Equal(t, `{"a": 1}`, effective.Languages["cpp"].Settings)
+}
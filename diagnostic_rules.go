@@ -0,0 +1,175 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// DiagnosticRule drops or changes the severity of diagnostics from noisy
+// servers before they are stored or reported, ie,
+//
+//	[[diagnostic_rule]]
+//	source = '^clang-tidy$'
+//	code = '^readability-'
+//	severity = "hint"
+//
+//	[[diagnostic_rule]]
+//	path = '^third_party/'
+//	drop = true
+//
+// Rules of the language are checked before the global rules and the first
+// matching rule applies. Changes apply to diagnostics published after
+// reload-config.
+type DiagnosticRule struct {
+	// Regular expressions matched against the source, code and message of a
+	// diagnostic and the path of its file relative to the project directory,
+	// with / as separator. Empty expressions match everything.
+	Source  string `toml:"source,omitempty"`
+	Code    string `toml:"code,omitempty"`
+	Message string `toml:"message,omitempty"`
+	Path    string `toml:"path,omitempty"`
+
+	// Drop matching diagnostics.
+	Drop bool `toml:"drop,omitempty"`
+
+	// Otherwise change their severity to error, warning, information or hint.
+	Severity string `toml:"severity,omitempty"`
+}
+
+func parseSeverity(s string) (LsDiagnosticSeverity, error) {
+	for _, severity := range []LsDiagnosticSeverity{Error, Warning, Information, Hint} {
+		if severity.String() == s {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q; expected error, warning, information or hint", s)
+}
+
+func (r DiagnosticRule) validate() map[string]error {
+	problems := make(map[string]error)
+	for key, expression := range map[string]string{"source": r.Source, "code": r.Code, "message": r.Message, "path": r.Path} {
+		if _, err := regexp.Compile(expression); err != nil {
+			problems[key] = err
+		}
+	}
+	switch {
+	case r.Drop && r.Severity != "":
+		problems["severity"] = fmt.Errorf("cannot be set together with drop")
+	case !r.Drop && r.Severity == "":
+		problems["severity"] = fmt.Errorf("either drop or severity must be set")
+	case r.Severity != "":
+		if _, err := parseSeverity(r.Severity); err != nil {
+			problems["severity"] = err
+		}
+	}
+	return problems
+}
+
+// diagnosticRule is a compiled DiagnosticRule.
+type diagnosticRule struct {
+	source, code, message, path *regexp.Regexp
+	drop                        bool
+	severity                    LsDiagnosticSeverity
+}
+
+// diagnosticRulesFor compiles the diagnostic rules for servers of language.
+// Invalid rules, which config validate reports, are skipped.
+func (c *Config) diagnosticRulesFor(language string) []diagnosticRule {
+	var rules []diagnosticRule
+	for _, rule := range append(append([]DiagnosticRule(nil), c.Languages[language].DiagnosticRules...), c.DiagnosticRules...) {
+		if len(rule.validate()) > 0 {
+			continue
+		}
+		severity, _ := parseSeverity(rule.Severity)
+		rules = append(rules, diagnosticRule{
+			source:   regexp.MustCompile(rule.Source),
+			code:     regexp.MustCompile(rule.Code),
+			message:  regexp.MustCompile(rule.Message),
+			path:     regexp.MustCompile(rule.Path),
+			drop:     rule.Drop,
+			severity: severity,
+		})
+	}
+	return rules
+}
+
+// diagnosticCode returns the code of d, which is a number or a string, as
+// text.
+func diagnosticCode(d LsDiagnostic) string {
+	if len(d.Code) == 0 {
+		return ""
+	}
+	if code, err := strconv.Unquote(string(d.Code)); err == nil {
+		return code
+	}
+	return string(d.Code)
+}
+
+func (r diagnosticRule) matches(path string, d LsDiagnostic) bool {
+	return r.source.MatchString(d.Source) &&
+		r.code.MatchString(diagnosticCode(d)) &&
+		r.message.MatchString(d.Message) &&
+		r.path.MatchString(path)
+}
+
+// applyDiagnosticRules returns the diagnostics of the file at path, relative
+// to the project directory, with rules applied.
+func applyDiagnosticRules(rules []diagnosticRule, path string, diagnostics []LsDiagnostic) []LsDiagnostic {
+	if len(rules) == 0 {
+		return diagnostics
+	}
+	out := []LsDiagnostic{}
+	for _, d := range diagnostics {
+		drop := false
+		for _, rule := range rules {
+			if rule.matches(path, d) {
+				drop = rule.drop
+				if !drop {
+					d.Severity = rule.severity
+				}
+				break
+			}
+		}
+		if !drop {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// setDiagnosticRules sets the rules applied to published diagnostics.
+func (l *languageServer) setDiagnosticRules(rules []diagnosticRule) {
+	l.mu.Lock()
+	l.diagnosticRules = rules
+	l.mu.Unlock()
+}
+
+// filterDiagnostics applies the diagnostic rules of l to diagnostics
+// published for uri.
+func (l *languageServer) filterDiagnostics(uri LsDocumentURI, diagnostics []LsDiagnostic) []LsDiagnostic {
+	l.mu.Lock()
+	rules := l.diagnosticRules
+	l.mu.Unlock()
+
+	path := uriToPath(uri)
+	if rel, err := filepath.Rel(l.directory, path); err == nil {
+		path = rel
+	}
+	return applyDiagnosticRules(rules, filepath.ToSlash(path), diagnostics)
+}
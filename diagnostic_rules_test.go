@@ -0,0 +1,69 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticRuleValidate(t *testing.T) {
+	assert.Empty(t, DiagnosticRule{Source: "^clang-tidy$", Severity: "hint"}.validate())
+	assert.Empty(t, DiagnosticRule{Path: "^third_party/", Drop: true}.validate())
+
+	problems := DiagnosticRule{Code: "((", Drop: true, Severity: "hint"}.validate()
+	assert.Contains(t, problems, "code")
+	assert.Contains(t, problems, "severity")
+	assert.Contains(t, DiagnosticRule{Message: "x"}.validate(), "severity")
+	assert.Contains(t, DiagnosticRule{Severity: "fatal"}.validate(), "severity")
+}
+
+func TestApplyDiagnosticRules(t *testing.T) {
+	config := &Config{
+		DiagnosticRules: []DiagnosticRule{
+			{Path: "^third_party/", Drop: true},
+			{Code: "^readability-", Severity: "hint"},
+			{Code: "^1[0-9]$", Drop: true},
+			{Message: "((", Drop: true},
+		},
+		Languages: map[string]LanguageConfig{
+			"cpp": {DiagnosticRules: []DiagnosticRule{{Source: "^clang-tidy$", Code: "^readability-braces", Severity: "error"}}},
+		},
+	}
+	diagnostics := []LsDiagnostic{
+		{Severity: Warning, Source: "clang-tidy", Code: easyjson.RawMessage(`"readability-braces-around-statements"`)},
+		{Severity: Warning, Source: "clang-tidy", Code: easyjson.RawMessage(`"readability-magic-numbers"`)},
+		{Severity: Error, Source: "clang", Code: easyjson.RawMessage(`12`)},
+		{Severity: Error, Source: "clang", Code: easyjson.RawMessage(`2`), Message: "(("},
+	}
+
+	severities := func(diagnostics []LsDiagnostic) []LsDiagnosticSeverity {
+		out := []LsDiagnosticSeverity{}
+		for _, d := range diagnostics {
+			out = append(out, d.Severity)
+		}
+		return out
+	}
+	assert.Equal(t, []LsDiagnosticSeverity{Error, Hint, Error},
+		severities(applyDiagnosticRules(config.diagnosticRulesFor("cpp"), "src/a.cc", diagnostics)))
+	assert.Equal(t, []LsDiagnosticSeverity{Hint, Hint, Error},
+		severities(applyDiagnosticRules(config.diagnosticRulesFor("go"), "src/a.cc", diagnostics)))
+	assert.Empty(t, applyDiagnosticRules(config.diagnosticRulesFor("go"), "third_party/b.cc", diagnostics))
+	// Rules of the language are checked first.
+	assert.Len(t, applyDiagnosticRules(config.diagnosticRulesFor("cpp"), "third_party/b.cc", diagnostics), 1)
+	assert.Equal(t, diagnostics, applyDiagnosticRules(nil, "src/a.cc", diagnostics))
+}
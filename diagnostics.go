@@ -0,0 +1,232 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DiagnosticsArgs selects the diagnostics of Server.Diagnostics and
+// Server.WatchDiagnostics: those of File, or of every document if File is
+// empty.
+type DiagnosticsArgs struct {
+	File string
+	Unit ColumnUnit
+	// Server group whose diagnostics of File are reported, see GroupConfig.
+	Group string
+}
+
+// DiagnosticsUpdate is sent by WatchDiagnostics whenever the diagnostics of
+// a file change. Diagnostics replace those of the previous update for File.
+type DiagnosticsUpdate struct {
+	File        string       `json:"file"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// diagnosticsServers returns the servers whose diagnostics of args.File are
+// reported, or every server if File is empty.
+func (s *Server) diagnosticsServers(args DiagnosticsArgs) ([]*languageServer, error) {
+	if args.File == "" {
+		return s.serverList(), nil
+	}
+	if !filepath.IsAbs(args.File) {
+		return nil, fmt.Errorf("file must be an absolute path, got %q", args.File)
+	}
+	return s.serversForRequest(args.File, diagnosticsMethod, args.Group)
+}
+
+// diagnostics returns the diagnostics the language servers last published
+// for args, keyed by path. The diagnostics of every server for a file are
+// merged. A file asked for is opened, so that the servers start analyzing it,
+// but its diagnostics are not waited for as by Check.
+func (s *Server) diagnostics(args DiagnosticsArgs) (map[string][]Diagnostic, error) {
+	servers, err := s.diagnosticsServers(args)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := make(map[string][]Diagnostic)
+	if args.File != "" {
+		var lists [][]Diagnostic
+		for _, server := range servers {
+			list, err := server.fileDiagnostics(args.File, args.Unit)
+			if err != nil {
+				return nil, err
+			}
+			lists = append(lists, list)
+		}
+		if merged := mergeDiagnostics(lists...); len(merged) > 0 {
+			diagnostics[args.File] = merged
+		}
+		return diagnostics, nil
+	}
+	for _, server := range servers {
+		for file, list := range server.allDiagnostics(args.Unit) {
+			diagnostics[file] = mergeDiagnostics(diagnostics[file], list)
+		}
+	}
+	return diagnostics, nil
+}
+
+// Diagnostics returns the diagnostics last published for a file, or for
+// every document, keyed by path.
+func (s *Server) Diagnostics(args DiagnosticsArgs, diagnostics *map[string][]Diagnostic) error {
+	log.Printf("CMD diagnostics %s", args.File)
+	var err error
+	*diagnostics, err = s.diagnostics(args)
+	return err
+}
+
+// WatchDiagnostics opens a socket which streams DiagnosticsUpdates as JSON
+// lines and returns its path, see `lspc diagnostics --watch`. The current
+// diagnostics are sent first.
+func (s *Server) WatchDiagnostics(args DiagnosticsArgs, socket *string) error {
+	log.Printf("CMD diagnostics --watch %s", args.File)
+	// Fails here rather than once the client connected if the file has no
+	// server.
+	if _, err := s.diagnostics(args); err != nil {
+		return err
+	}
+	path := fmt.Sprintf("%s.diagnostics-%d", gSocket, time.Now().UnixNano())
+	if err := serveOnce(path, "diagnostics client", func(conn net.Conn) { s.streamDiagnostics(conn, args) }); err != nil {
+		return err
+	}
+	*socket = path
+	return nil
+}
+
+// streamDiagnostics writes the current diagnostics of args and then an update
+// for every diagnostics event about them to conn until the client
+// disconnects.
+func (s *Server) streamDiagnostics(conn net.Conn, args DiagnosticsArgs) {
+	defer conn.Close()
+	// Subscribe before reading the current diagnostics so that no update is
+	// missed.
+	sub := daemonEvents.subscribe()
+	defer daemonEvents.unsubscribe(sub)
+	current, err := s.diagnostics(args)
+	if err != nil {
+		log.Printf("Cannot stream diagnostics of %s: %s", args.File, err.Error())
+		return
+	}
+
+	// The client never writes; a read returns once it has gone away.
+	gone := make(chan struct{})
+	go func() {
+		conn.Read(make([]byte, 1))
+		close(gone)
+	}()
+
+	encoder := json.NewEncoder(conn)
+	var files []string
+	for file := range current {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if encoder.Encode(DiagnosticsUpdate{File: file, Diagnostics: current[file]}) != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-gone:
+			return
+		case e := <-sub.c:
+			if e.Kind != EventDiagnostics {
+				continue
+			}
+			file := uriToPath(e.Diagnostics.URI)
+			if args.File != "" && file != args.File {
+				continue
+			}
+			if encoder.Encode(DiagnosticsUpdate{File: file, Diagnostics: s.publishedDiagnostics(file, args)}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// publishedDiagnostics merges the latest diagnostics every server reporting
+// diagnostics for args published for file.
+func (s *Server) publishedDiagnostics(file string, args DiagnosticsArgs) []Diagnostic {
+	var servers []*languageServer
+	if args.File != "" {
+		servers, _ = s.diagnosticsServers(args)
+	} else {
+		for _, server := range s.serverList() {
+			if pathInDirectory(file, server.directory) {
+				servers = append(servers, server)
+			}
+		}
+	}
+	var lists [][]Diagnostic
+	for _, server := range servers {
+		lists = append(lists, server.publishedDiagnostics(file, args.Unit))
+	}
+	merged := mergeDiagnostics(lists...)
+	if merged == nil {
+		merged = []Diagnostic{}
+	}
+	return merged
+}
+
+// writeDiagnosticsUpdate prints u in format. With --format json the update
+// itself is printed, so that cleared diagnostics show.
+func writeDiagnosticsUpdate(out io.Writer, u DiagnosticsUpdate, format string) {
+	if format == diagnosticsJSON {
+		line, _ := json.Marshal(u)
+		fmt.Fprintf(out, "%s\n", line)
+		return
+	}
+	if len(u.Diagnostics) == 0 && format == diagnosticsText {
+		fmt.Fprintf(out, "%s: no diagnostics\n", u.File)
+	}
+	for _, d := range u.Diagnostics {
+		writeDiagnostic(out, d, format)
+	}
+}
+
+// watchDiagnostics prints the diagnostics of args and then every update of
+// them until the daemon exits or the command is interrupted.
+func watchDiagnostics(args DiagnosticsArgs, format string, out io.Writer) error {
+	if err := checkLocalDaemon("diagnostics --watch"); err != nil {
+		return err
+	}
+	var socket string
+	doRPC("Server.WatchDiagnostics", args, &socket)
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	for {
+		var u DiagnosticsUpdate
+		if err := decoder.Decode(&u); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		writeDiagnosticsUpdate(out, u, format)
+	}
+}
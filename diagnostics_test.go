@@ -0,0 +1,56 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnostics(t *testing.T) {
+	clangd := newTestLanguageServer(0, "/p")
+	clangd.args = []string{"clangd"}
+	tidy := newTestLanguageServer(1, "/p")
+	tidy.args = []string{"clang-tidy"}
+	warning := LsDiagnostic{Range: LsRange{End: LsPosition{Character: 1}}, Severity: 2, Message: "unused"}
+	clangd.diagnostics[pathToURI("/p/a.c")] = []LsDiagnostic{warning}
+	clangd.diagnostics[pathToURI("/p/b.c")] = []LsDiagnostic{}
+	tidy.diagnostics[pathToURI("/p/a.c")] = []LsDiagnostic{warning}
+	s := &Server{config: &Config{}, servers: []*languageServer{clangd, tidy}}
+
+	var diagnostics map[string][]Diagnostic
+	assert.NoError(t, s.Diagnostics(DiagnosticsArgs{}, &diagnostics))
+	assert.Len(t, diagnostics, 1)
+	assert.Len(t, diagnostics["/p/a.c"], 1)
+	assert.Equal(t, "clangd, clang-tidy", diagnostics["/p/a.c"][0].Server)
+
+	assert.Equal(t, diagnostics["/p/a.c"], s.publishedDiagnostics("/p/a.c", DiagnosticsArgs{}))
+	assert.Equal(t, []Diagnostic{}, s.publishedDiagnostics("/p/b.c", DiagnosticsArgs{}))
+	assert.Equal(t, []Diagnostic{}, s.publishedDiagnostics("/q/a.c", DiagnosticsArgs{}))
+
+	assert.Error(t, s.Diagnostics(DiagnosticsArgs{File: "a.c"}, &diagnostics))
+}
+
+func TestWriteDiagnosticsUpdate(t *testing.T) {
+	var out bytes.Buffer
+	d := Diagnostic{Location: Location{File: "/a.c", Line: 1, Column: 2}, Severity: "error", Message: "x"}
+	writeDiagnosticsUpdate(&out, DiagnosticsUpdate{File: "/a.c", Diagnostics: []Diagnostic{d}}, diagnosticsText)
+	writeDiagnosticsUpdate(&out, DiagnosticsUpdate{File: "/a.c", Diagnostics: []Diagnostic{}}, diagnosticsText)
+	writeDiagnosticsUpdate(&out, DiagnosticsUpdate{File: "/a.c", Diagnostics: []Diagnostic{}}, diagnosticsQuickfix)
+	writeDiagnosticsUpdate(&out, DiagnosticsUpdate{File: "/a.c", Diagnostics: []Diagnostic{}}, diagnosticsJSON)
+	assert.Equal(t, "/a.c:1:2: error: x\n/a.c: no diagnostics\n"+`{"file":"/a.c","diagnostics":[]}`+"\n", out.String())
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lines of unchanged text shown around each change of a unified diff.
+const diffContext = 3
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// diffOp is one line of an edit script: ' ' if the line is in both texts,
+// '-' if it was removed and '+' if it was added.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits text into lines which keep their terminator, so that
+// joining them gives back text.
+func splitLines(text string) []string {
+	var lines []string
+	for text != "" {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			lines = append(lines, text)
+			break
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script turning a into b, computed with
+// Myers' algorithm after removing the common prefix and suffix.
+func diffLines(a, b []string) []diffOp {
+	var prefix, suffix []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append(suffix, diffOp{' ', a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	n, m := len(a), len(b)
+	// v[offset+k] is the furthest x reached on diagonal k = x - y. trace
+	// keeps v[-d-1..d+1] as it was before each step d, for backtracking.
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		found := false
+		for k := -d; k <= d && !found; k += 2 {
+			x := v[offset+k-1] + 1
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			found = x >= n && y >= m
+		}
+		if found {
+			break
+		}
+	}
+
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		// saved[d+1+k] is v[offset+k] before step d.
+		saved := trace[d]
+		k := x - y
+		previousK := k - 1
+		if k == -d || k != d && saved[d+1+k-1] < saved[d+1+k+1] {
+			previousK = k + 1
+		}
+		previousX := saved[d+1+previousK]
+		previousY := previousX - previousK
+		for x > previousX && y > previousY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if d > 0 {
+			if x == previousX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+			}
+		}
+		x, y = previousX, previousY
+	}
+
+	script := prefix
+	for i := len(ops) - 1; i >= 0; i-- {
+		script = append(script, ops[i])
+	}
+	for i := len(suffix) - 1; i >= 0; i-- {
+		script = append(script, suffix[i])
+	}
+	return script
+}
+
+// unifiedDiff returns the changes from a, which is named from, to b, which
+// is named to, as a unified diff, or an empty string if there are none.
+func unifiedDiff(from, to, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+	// Line numbers, 0-based, of the next op in a and b.
+	aLine, bLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			aLine, bLine = aLine+1, bLine+1
+			i++
+			continue
+		}
+		// A hunk includes the context around its changes and ends once more
+		// unchanged lines than two contexts follow a change.
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		last := i
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				if j-last-1 > 2*diffContext {
+					break
+				}
+				last = j
+			}
+		}
+		end := last + 1 + diffContext
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				aLine++
+			}
+			if op.kind != '-' {
+				bLine++
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the 0-based start and count of lines of a hunk header.
+// An empty range refers to the line before it, as in diff.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// colorizeDiff highlights the headers, hunks, removed and added lines of a
+// unified diff for a terminal.
+func colorizeDiff(diff string) string {
+	var out strings.Builder
+	for _, line := range splitLines(diff) {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			color = ansiBold
+		case strings.HasPrefix(line, "@@"):
+			color = ansiCyan
+		case strings.HasPrefix(line, "-"):
+			color = ansiRed
+		case strings.HasPrefix(line, "+"):
+			color = ansiGreen
+		}
+		if color == "" {
+			out.WriteString(line)
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		out.WriteString(color + text + ansiReset + line[len(text):])
+	}
+	return out.String()
+}
+
+// isTerminal returns true if f is a terminal rather than a file, a pipe or
+// the null device.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	script := func(a, b string) string {
+		s := ""
+		for _, op := range diffLines(strings.Split(a, ""), strings.Split(b, "")) {
+			s += string(op.kind) + op.line + " "
+		}
+		return s
+	}
+	assert.Equal(t, " a  b  c ", script("abc", "abc"))
+	assert.Equal(t, " a -b  c ", script("abc", "ac"))
+	assert.Equal(t, " a +x  b  c ", script("abc", "axbc"))
+	assert.Equal(t, "-a -b -c +x +y ", script("abc", "xy"))
+	assert.Equal(t, "-a  b  c +a ", script("abc", "bca"))
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", unifiedDiff("a", "b", "x\n", "x\n"))
+
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"
+	after := strings.Replace(strings.Replace(before, "2\n", "two\n", 1), "18\n", "", 1)
+	assert.Equal(t, `--- a.c
++++ a.c
+@@ -1,5 +1,5 @@
+ 1
+-2
++two
+ 3
+ 4
+ 5
+@@ -15,6 +15,5 @@
+ 15
+ 16
+ 17
+-18
+ 19
+ 20
+`, unifiedDiff("a.c", "a.c", before, after))
+
+	// Changes closer than two contexts share a hunk.
+	after = strings.Replace(strings.Replace(before, "3\n", "three\n", 1), "9\n", "nine\n", 1)
+	assert.Equal(t, 1, strings.Count(unifiedDiff("a.c", "a.c", before, after), "@@ -"))
+
+	assert.Equal(t, `--- /dev/null
++++ new.c
+@@ -0,0 +1 @@
++x
+\ No newline at end of file
+`, unifiedDiff("/dev/null", "new.c", "", "x"))
+}
+
+func TestColorizeDiff(t *testing.T) {
+	assert.Equal(t, ansiBold+"--- a"+ansiReset+"\n"+ansiCyan+"@@ -1 +1 @@"+ansiReset+"\n"+
+		ansiRed+"-x"+ansiReset+"\n"+ansiGreen+"+y"+ansiReset+"\n context\n",
+		colorizeDiff("--- a\n@@ -1 +1 @@\n-x\n+y\n context\n"))
+}
@@ -0,0 +1,392 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// docSymbol is a symbol documented by docgen.
+type docSymbol struct {
+	Location
+	Name     string
+	Kind     string
+	Contents string
+	// Where the symbol is defined, if not at Location.
+	Definitions []Location
+}
+
+// docSite is the documentation of a project, one page per source file.
+type docSite struct {
+	dir string
+	// markdown or html.
+	format string
+	// Symbols of each source file, in order of position.
+	pages map[string][]docSymbol
+}
+
+// docSourceFiles lists the files in dir whose language is known, skipping
+// hidden directories and skip.
+func docSourceFiles(dir, skip string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && (strings.HasPrefix(info.Name(), ".") || path == skip) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, known := languageIDs[strings.ToLower(filepath.Ext(path))]; known {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// collectDocs asks the daemon for the symbols of every source file in dir,
+// their hover text and where they are defined. Files that cannot be queried
+// are reported and left out. Kinds are named as config says.
+func collectDocs(config *Config, dir, out, format string) (*docSite, error) {
+	sources, err := docSourceFiles(dir, out)
+	if err != nil {
+		return nil, err
+	}
+	site := &docSite{dir: dir, format: format, pages: make(map[string][]docSymbol)}
+
+	// Workspace symbols find files in languages docSourceFiles does not know
+	// about, and symbols that documentSymbol does not report.
+	var workspace []Symbol
+	if err := tryRPC("Server.WorkspaceSymbols", WorkspaceSymbolsArgs{Directory: dir, Unit: ByteColumns}, &workspace); err != nil {
+		log.Printf("Unable to list workspace symbols: %s", err.Error())
+	}
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		seen[source] = true
+	}
+	for _, symbol := range workspace {
+		if pathInDirectory(symbol.File, dir) && !seen[symbol.File] {
+			seen[symbol.File] = true
+			sources = append(sources, symbol.File)
+		}
+	}
+
+	documented := make(map[Location]bool)
+	for _, source := range sources {
+		var hovers []Hover
+		args := HoverArgs{PositionArgs: PositionArgs{File: source, Unit: ByteColumns}, Symbols: true}
+		if err := tryRPC("Server.Hover", args, &hovers); err != nil {
+			log.Printf("Skipping %s: %s", source, err.Error())
+			continue
+		}
+		for _, hover := range hovers {
+			documented[hover.Location] = true
+			site.pages[source] = append(site.pages[source], docSymbol{
+				Location: hover.Location,
+				Name:     hover.Symbol,
+				Kind:     config.kindLabel(hover.Kind, false),
+				Contents: hover.Contents,
+			})
+		}
+	}
+	for _, symbol := range workspace {
+		if pathInDirectory(symbol.File, dir) && !documented[symbol.Location] {
+			site.pages[symbol.File] = append(site.pages[symbol.File], docSymbol{
+				Location: symbol.Location,
+				Name:     symbol.Name,
+				Kind:     config.kindLabel(symbol.Kind, false),
+			})
+		}
+	}
+
+	for _, symbols := range site.pages {
+		for i := range symbols {
+			symbol := &symbols[i]
+			var definitions []Location
+			args := PositionArgs{File: symbol.File, Line: symbol.Line, Column: symbol.Column, Unit: ByteColumns}
+			if err := tryRPC("Server.Definition", args, &definitions); err != nil {
+				continue
+			}
+			for _, definition := range definitions {
+				if definition.File != symbol.File || definition.Line != symbol.Line {
+					symbol.Definitions = append(symbol.Definitions, definition)
+				}
+			}
+		}
+		sort.Slice(symbols, func(i, j int) bool {
+			if symbols[i].Line != symbols[j].Line {
+				return symbols[i].Line < symbols[j].Line
+			}
+			return symbols[i].Column < symbols[j].Column
+		})
+	}
+	return site, nil
+}
+
+// pagePath returns the path of the page for source, relative to the output
+// directory.
+func (s *docSite) pagePath(source string) string {
+	rel, err := filepath.Rel(s.dir, source)
+	if err != nil {
+		rel = filepath.Base(source)
+	}
+	if s.format == "html" {
+		return rel + ".html"
+	}
+	return rel + ".md"
+}
+
+func docAnchor(location Location) string {
+	return fmt.Sprintf("L%dC%d", location.Line, location.Column)
+}
+
+// docLink is a link from one page to another, or just text if the target is
+// not documented.
+type docLink struct {
+	Text string
+	Href string
+}
+
+// link returns a link from the page at from to location. The link points to
+// the symbol on the line of location, if one is documented.
+func (s *docSite) link(from string, location Location) docLink {
+	text := location.String()
+	if rel, err := filepath.Rel(s.dir, location.File); err == nil && !strings.HasPrefix(rel, "..") {
+		text = fmt.Sprintf("%s:%d:%d", filepath.ToSlash(rel), location.Line, location.Column)
+	}
+	symbols, documented := s.pages[location.File]
+	if !documented {
+		return docLink{Text: text}
+	}
+	href, err := filepath.Rel(filepath.Dir(from), s.pagePath(location.File))
+	if err != nil {
+		return docLink{Text: text}
+	}
+	link := docLink{Text: text, Href: filepath.ToSlash(href)}
+	for _, symbol := range symbols {
+		if symbol.Line == location.Line {
+			link.Href += "#" + docAnchor(symbol.Location)
+			break
+		}
+	}
+	return link
+}
+
+type docPageSymbol struct {
+	Anchor      string
+	Name        string
+	Kind        string
+	Line        int
+	Contents    string
+	Definitions []docLink
+}
+
+type docPage struct {
+	Title   string
+	Index   string
+	Symbols []docPageSymbol
+}
+
+type docIndexSymbol struct {
+	docLink
+	Kind string
+	File string
+}
+
+type docIndex struct {
+	Title   string
+	Files   []docLink
+	Symbols []docIndexSymbol
+}
+
+const markdownPageTemplate = `# {{.Title}}
+
+[Index]({{.Index}})
+{{range .Symbols}}
+## <a id="{{.Anchor}}"></a>{{.Name}}
+
+{{.Kind}} at line {{.Line}}
+{{if .Contents}}
+{{.Contents}}
+{{end}}{{range .Definitions}}
+Defined at {{if .Href}}[{{.Text}}]({{.Href}}){{else}}{{.Text}}{{end}}
+{{end}}{{end}}`
+
+const markdownIndexTemplate = `# {{.Title}}
+
+## Files
+{{range .Files}}
+- [{{.Text}}]({{.Href}}){{end}}
+
+## Symbols
+{{range .Symbols}}
+- [{{.Text}}]({{.Href}}) {{.Kind}} in {{.File}}{{end}}
+`
+
+const htmlPageTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<p><a href="{{.Index}}">Index</a></p>
+<h1>{{.Title}}</h1>
+{{range .Symbols}}<h2 id="{{.Anchor}}">{{.Name}}</h2>
+<p>{{.Kind}} at line {{.Line}}</p>
+{{if .Contents}}<pre>{{.Contents}}</pre>
+{{end}}{{range .Definitions}}<p>Defined at {{if .Href}}<a href="{{.Href}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}</p>
+{{end}}{{end}}</body>
+</html>
+`
+
+const htmlIndexTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<h2>Files</h2>
+<ul>
+{{range .Files}}<li><a href="{{.Href}}">{{.Text}}</a></li>
+{{end}}</ul>
+<h2>Symbols</h2>
+<ul>
+{{range .Symbols}}<li><a href="{{.Href}}">{{.Text}}</a> {{.Kind}} in {{.File}}</li>
+{{end}}</ul>
+</body>
+</html>
+`
+
+// docTemplate abstracts over text/template and html/template.
+type docTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+func (s *docSite) templates() (page, index docTemplate) {
+	if s.format == "html" {
+		return htmltemplate.Must(htmltemplate.New("page").Parse(htmlPageTemplate)),
+			htmltemplate.Must(htmltemplate.New("index").Parse(htmlIndexTemplate))
+	}
+	return template.Must(template.New("page").Parse(markdownPageTemplate)),
+		template.Must(template.New("index").Parse(markdownIndexTemplate))
+}
+
+// render returns the contents of every page, keyed by path relative to the
+// output directory.
+func (s *docSite) render() (map[string]string, error) {
+	pageTemplate, indexTemplate := s.templates()
+	indexPath := "index.md"
+	if s.format == "html" {
+		indexPath = "index.html"
+	}
+
+	var sources []string
+	for source := range s.pages {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	files := make(map[string]string)
+	index := docIndex{Title: filepath.Base(s.dir)}
+	for _, source := range sources {
+		path := s.pagePath(source)
+		rel, _ := filepath.Rel(s.dir, source)
+		toIndex, _ := filepath.Rel(filepath.Dir(path), indexPath)
+		page := docPage{Title: filepath.ToSlash(rel), Index: filepath.ToSlash(toIndex)}
+		for _, symbol := range s.pages[source] {
+			pageSymbol := docPageSymbol{
+				Anchor:   docAnchor(symbol.Location),
+				Name:     symbol.Name,
+				Kind:     symbol.Kind,
+				Line:     symbol.Line,
+				Contents: symbol.Contents,
+			}
+			for _, definition := range symbol.Definitions {
+				pageSymbol.Definitions = append(pageSymbol.Definitions, s.link(path, definition))
+			}
+			page.Symbols = append(page.Symbols, pageSymbol)
+
+			link := s.link(indexPath, symbol.Location)
+			link.Text = symbol.Name
+			index.Symbols = append(index.Symbols, docIndexSymbol{docLink: link, Kind: symbol.Kind, File: page.Title})
+		}
+
+		var out bytes.Buffer
+		if err := pageTemplate.Execute(&out, page); err != nil {
+			return nil, err
+		}
+		files[path] = out.String()
+		index.Files = append(index.Files, docLink{Text: page.Title, Href: filepath.ToSlash(path)})
+	}
+
+	sort.SliceStable(index.Symbols, func(i, j int) bool {
+		return index.Symbols[i].Text < index.Symbols[j].Text
+	})
+	var out bytes.Buffer
+	if err := indexTemplate.Execute(&out, index); err != nil {
+		return nil, err
+	}
+	files[indexPath] = out.String()
+	return files, nil
+}
+
+// docgen writes the documentation of the project in dir to out.
+func docgen(dir, out, format string) error {
+	if format != "markdown" && format != "html" {
+		return fmt.Errorf("format must be markdown or html, got %q", format)
+	}
+	config, err := loadConfig(gConfig)
+	if err != nil {
+		return err
+	}
+	site, err := collectDocs(config, dir, out, format)
+	if err != nil {
+		return err
+	}
+	if len(site.pages) == 0 {
+		return fmt.Errorf("the language server reported no symbols in %s", dir)
+	}
+	files, err := site.render()
+	if err != nil {
+		return err
+	}
+	for path, content := range files {
+		path = filepath.Join(out, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	if gJSON {
+		return printJSON(struct {
+			Pages     int
+			Directory string
+		}{len(files), out})
+	}
+	fmt.Printf("Wrote %d pages to %s\n", len(files), out)
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocSourceFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, file := range []string{"a.c", "lib/b.go", "README", ".git/c.c", "site/d.c"} {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	files, err := docSourceFiles(dir, filepath.Join(dir, "site"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.c"), filepath.Join(dir, "lib/b.go")}, files)
+}
+
+func TestDocSiteRender(t *testing.T) {
+	site := &docSite{dir: "/p", format: "markdown", pages: map[string][]docSymbol{
+		"/p/a.h": {{
+			Location: Location{File: "/p/a.h", Line: 3, Column: 5},
+			Name:     "foo",
+			Kind:     "function",
+			Contents: "Does foo.",
+			Definitions: []Location{
+				{File: "/p/lib/a.c", Line: 10, Column: 1},
+				{File: "/usr/include/x.h", Line: 2, Column: 1},
+			},
+		}},
+		"/p/lib/a.c": {{
+			Location: Location{File: "/p/lib/a.c", Line: 10, Column: 5},
+			Name:     "foo",
+			Kind:     "function",
+		}},
+	}}
+
+	files, err := site.render()
+	assert.NoError(t, err)
+	assert.Equal(t, `# a.h
+
+[Index](index.md)
+
+## <a id="L3C5"></a>foo
+
+function at line 3
+
+Does foo.
+
+Defined at [lib/a.c:10:1](lib/a.c.md#L10C5)
+
+Defined at /usr/include/x.h:2:1
+`, files["a.h.md"])
+	assert.Contains(t, files["lib/a.c.md"], "[Index](../index.md)")
+	assert.Contains(t, files["index.md"], "- [a.h](a.h.md)\n- [lib/a.c](lib/a.c.md)")
+	assert.Contains(t, files["index.md"], "- [foo](a.h.md#L3C5) function in a.h\n- [foo](lib/a.c.md#L10C5) function in lib/a.c")
+
+	site.format = "html"
+	files, err = site.render()
+	assert.NoError(t, err)
+	assert.Contains(t, files["a.h.html"], `<p>Defined at <a href="lib/a.c.html#L10C5">lib/a.c:10:1</a></p>`)
+	assert.Contains(t, files["index.html"], `<li><a href="lib/a.c.html#L10C5">foo</a> function in lib/a.c</li>`)
+}
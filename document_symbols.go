@@ -0,0 +1,116 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// DocumentSymbol is a symbol of a file as shown to users, located by its
+// name.
+type DocumentSymbol struct {
+	Location
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	// The name of the symbol containing this one, for servers that return a
+	// flat list.
+	Container string           `json:"container,omitempty"`
+	Children  []DocumentSymbol `json:"children,omitempty"`
+}
+
+// DocumentSymbolsArgs selects the file to outline.
+type DocumentSymbolsArgs struct {
+	File string
+	Unit ColumnUnit
+}
+
+// outlineSymbols converts the DocumentSymbols of path.
+func (l *languageServer) outlineSymbols(path string, symbols []LsDocumentSymbol, unit ColumnUnit) []DocumentSymbol {
+	var out []DocumentSymbol
+	for _, symbol := range symbols {
+		out = append(out, DocumentSymbol{
+			Location: l.userRange(path, symbol.SelectionRange, unit),
+			Name:     symbol.Name,
+			Kind:     symbol.Kind.String(),
+			Detail:   symbol.Detail,
+			Children: l.outlineSymbols(path, symbol.Children, unit),
+		})
+	}
+	return out
+}
+
+// outline returns the symbols of path, as a tree if the server returns
+// hierarchical symbols and as a flat list otherwise.
+func (l *languageServer) outline(path string, unit ColumnUnit) ([]DocumentSymbol, error) {
+	symbols, flat, err := l.documentSymbolsResult(path)
+	if err != nil {
+		return nil, err
+	}
+	out := l.outlineSymbols(path, symbols, unit)
+	for _, information := range flat {
+		out = append(out, DocumentSymbol{
+			Location:  l.userRange(uriToPath(information.Location.URI), information.Location.Range, unit),
+			Name:      information.Name,
+			Kind:      information.Kind.String(),
+			Container: information.ContainerName,
+		})
+	}
+	if out == nil {
+		out = []DocumentSymbol{}
+	}
+	return out, nil
+}
+
+// DocumentSymbols returns the outline of a file.
+func (s *Server) DocumentSymbols(args DocumentSymbolsArgs, symbols *[]DocumentSymbol) error {
+	log.Printf("CMD document-symbols %s", args.File)
+	if !filepath.IsAbs(args.File) {
+		return fmt.Errorf("file must be an absolute path, got %q", args.File)
+	}
+	server, err := s.serverForMethod(args.File, "textDocument/documentSymbol")
+	if err != nil {
+		return err
+	}
+	*symbols, err = server.outline(args.File, args.Unit)
+	return err
+}
+
+// writeOutline prints the line, column, kind and name of each symbol, with
+// children indented by two spaces below their parent. The name is followed
+// by the detail or the container of the symbol, if any.
+func writeOutline(out io.Writer, symbols []DocumentSymbol, config *Config, icons bool) {
+	w := newTable(out)
+	var write func(symbols []DocumentSymbol, depth int)
+	write = func(symbols []DocumentSymbol, depth int) {
+		for _, symbol := range symbols {
+			name := strings.Repeat("  ", depth) + symbol.Name
+			if symbol.Detail != "" {
+				name += " " + symbol.Detail
+			}
+			if symbol.Container != "" {
+				name += " (in " + symbol.Container + ")"
+			}
+			fmt.Fprintf(w, "%d:%d\t%s\t%s\n", symbol.Line, symbol.Column, config.kindLabel(symbol.Kind, icons), name)
+			write(symbol.Children, depth+1)
+		}
+	}
+	write(symbols, 0)
+	w.Flush()
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutlineSymbols(t *testing.T) {
+	l := newTestLanguageServer(0, "/p")
+	symbols := []LsDocumentSymbol{{
+		Name:           "Foo",
+		Kind:           Class,
+		SelectionRange: LsRange{Start: LsPosition{Line: 0, Character: 6}, End: LsPosition{Line: 0, Character: 9}},
+		Children: []LsDocumentSymbol{{
+			Name:           "bar",
+			Detail:         "int ()",
+			Kind:           Method,
+			SelectionRange: LsRange{Start: LsPosition{Line: 1, Character: 6}, End: LsPosition{Line: 1, Character: 9}},
+		}},
+	}}
+	assert.Equal(t, []DocumentSymbol{{
+		Location: Location{File: "/p/missing.cc", Line: 1, Column: 7, EndLine: 1, EndColumn: 10},
+		Name:     "Foo",
+		Kind:     "class",
+		Children: []DocumentSymbol{{
+			Location: Location{File: "/p/missing.cc", Line: 2, Column: 7, EndLine: 2, EndColumn: 10},
+			Name:     "bar",
+			Kind:     "method",
+			Detail:   "int ()",
+		}},
+	}}, l.outlineSymbols("/p/missing.cc", symbols, ByteColumns))
+}
+
+func TestWriteOutline(t *testing.T) {
+	config := &Config{}
+	var out bytes.Buffer
+	writeOutline(&out, []DocumentSymbol{
+		{Location: Location{Line: 1, Column: 7}, Name: "Foo", Kind: "class", Children: []DocumentSymbol{
+			{Location: Location{Line: 2, Column: 7}, Name: "bar", Kind: "method", Detail: "int ()"},
+		}},
+		{Location: Location{Line: 10, Column: 5}, Name: "main", Kind: "function"},
+	}, config, false)
+	assert.Equal(t, "1:7   class     Foo\n2:7   method      bar int ()\n10:5  function  main\n", out.String())
+
+	out.Reset()
+	writeOutline(&out, []DocumentSymbol{
+		{Location: Location{Line: 1, Column: 7}, Name: "Foo", Kind: "class"},
+		{Location: Location{Line: 2, Column: 7}, Name: "bar", Kind: "method", Container: "Foo"},
+	}, config, false)
+	assert.Equal(t, "1:7  class   Foo\n2:7  method  bar (in Foo)\n", out.String())
+}
@@ -0,0 +1,317 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// languageIDs maps file extensions to LSP language identifiers. Extensions
+// that are not listed use the extension itself, and files without an
+// extension are plaintext.
+var languageIDs = map[string]string{
+	".c":    "c",
+	".h":    "c",
+	".cc":   "cpp",
+	".cpp":  "cpp",
+	".cxx":  "cpp",
+	".hh":   "cpp",
+	".hpp":  "cpp",
+	".hxx":  "cpp",
+	".m":    "objective-c",
+	".mm":   "objective-cpp",
+	".go":   "go",
+	".rs":   "rust",
+	".py":   "python",
+	".js":   "javascript",
+	".jsx":  "javascriptreact",
+	".ts":   "typescript",
+	".tsx":  "typescriptreact",
+	".java": "java",
+	".rb":   "ruby",
+	".sh":   "shellscript",
+}
+
+// languageID returns the LSP language identifier for path.
+func languageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if id, has := languageIDs[ext]; has {
+		return id
+	}
+	if ext == "" {
+		return "plaintext"
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// openDocument sends textDocument/didOpen for path unless it was already
+// sent, or textDocument/didChange if the file was modified since. Requests
+// about a document are only meaningful once the server has been told about
+// it. Returns the text of the document. If the notification is dropped, the
+// document is left as the server has it and an error is returned.
+func (l *languageServer) openDocument(path string) (string, error) {
+	// Held while sending didOpen so that requests about the document cannot
+	// overtake it.
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+
+	uri := pathToURI(path)
+	previous, has := l.documents[uri]
+	text, e, err := l.readDocument(path)
+	if err != nil {
+		if has {
+			// The server keeps the last text it was sent.
+			return previous, nil
+		}
+		return "", err
+	}
+	if e.encoding != nil && e.name != l.encodings[uri].name {
+		log.Printf("Reading %s as %s for %d", path, e.name, l.id)
+	}
+	l.encodings[uri] = e
+	if has {
+		if text != previous {
+			version := l.versions[uri] + 1
+			if !l.writeNotification("textDocument/didChange", toJSON(LsDidChangeTextDocumentParams{
+				TextDocument:   LsVersionedTextDocumentIdentifier{URI: uri, Version: &version},
+				ContentChanges: []LsTextDocumentContentChangeEvent{{Text: text}},
+			})) {
+				return "", l.queueFullError()
+			}
+			l.documents[uri] = text
+			l.versions[uri] = version
+		}
+		return text, nil
+	}
+
+	if !l.writeNotification("textDocument/didOpen", toJSON(LsDidOpenTextDocumentParams{
+		TextDocument: LsTextDocumentItem{
+			URI:        uri,
+			LanguageID: languageID(path),
+			Version:    0,
+			Text:       text,
+		},
+	})) {
+		delete(l.encodings, uri)
+		return "", l.queueFullError()
+	}
+	l.documents[uri] = text
+	l.versions[uri] = 0
+	return text, nil
+}
+
+// documentText returns the text of path as the language server sees it: the
+// opened document if there is one, otherwise the file on disk.
+func (l *languageServer) documentText(path string) (string, error) {
+	l.docMu.Lock()
+	text, has := l.documents[pathToURI(path)]
+	l.docMu.Unlock()
+	if has {
+		return text, nil
+	}
+	text, _, err := l.readDocument(path)
+	return text, err
+}
+
+// closeDocument sends textDocument/didClose for path if it is open and
+// forgets its text, so that the next request about it opens it again.
+// Returns whether it was open. If didClose is dropped the document stays
+// open.
+func (l *languageServer) closeDocument(path string) (bool, error) {
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+
+	uri := pathToURI(path)
+	if _, has := l.documents[uri]; !has {
+		return false, nil
+	}
+	if !l.writeNotification("textDocument/didClose", toJSON(LsDidCloseTextDocumentParams{
+		TextDocument: LsTextDocumentIdentifier{URI: uri},
+	})) {
+		return true, l.queueFullError()
+	}
+	delete(l.documents, uri)
+	delete(l.versions, uri)
+	delete(l.encodings, uri)
+	delete(l.tokens, uri)
+	return true, nil
+}
+
+// isOpen returns whether path is open in l.
+func (l *languageServer) isOpen(path string) bool {
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+	_, has := l.documents[pathToURI(path)]
+	return has
+}
+
+// openFiles returns the paths of the documents open in l, sorted.
+func (l *languageServer) openFiles() []string {
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+	var files []string
+	for uri := range l.documents {
+		files = append(files, uriToPath(uri))
+	}
+	sort.Strings(files)
+	return files
+}
+
+// DocumentArgs names a document for OpenDocument, ChangeDocument and
+// CloseDocument.
+type DocumentArgs struct {
+	File string
+}
+
+// DocumentState is a document as one language server has it open.
+type DocumentState struct {
+	Server  int
+	Name    string
+	Version int
+}
+
+func (d DocumentState) String() string {
+	return fmt.Sprintf("%d: %s version %d", d.Server, d.Name, d.Version)
+}
+
+func (l *languageServer) documentState(path string) DocumentState {
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+	return DocumentState{Server: l.id, Name: l.name(), Version: l.versions[pathToURI(path)]}
+}
+
+// OpenFile is a document open in a language server.
+type OpenFile struct {
+	File string
+	DocumentState
+}
+
+// Documents lists the documents open in every language server.
+func (s *Server) Documents(_ bool, documents *[]OpenFile) error {
+	log.Print("CMD documents")
+	for _, server := range s.serverList() {
+		for _, file := range server.openFiles() {
+			*documents = append(*documents, OpenFile{file, server.documentState(file)})
+		}
+	}
+	return nil
+}
+
+// documentServers returns every language server for args.File, which must be
+// absolute.
+func (s *Server) documentServers(args DocumentArgs) ([]*languageServer, error) {
+	if !filepath.IsAbs(args.File) {
+		return nil, fmt.Errorf("file must be an absolute path, got %q", args.File)
+	}
+	return s.serversForFile(args.File)
+}
+
+// OpenDocument opens a file in every language server for it. Requests open
+// the files they are about anyway; opening a file up front lets the servers
+// analyze it before the first request.
+func (s *Server) OpenDocument(args DocumentArgs, states *[]DocumentState) error {
+	log.Printf("CMD open %s", args.File)
+	servers, err := s.documentServers(args)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		if _, err := server.openDocument(args.File); err != nil {
+			return err
+		}
+		*states = append(*states, server.documentState(args.File))
+	}
+	return nil
+}
+
+// ChangeDocument sends the current text of a file to the language servers
+// that have it open, if it was modified since they were last sent it.
+func (s *Server) ChangeDocument(args DocumentArgs, states *[]DocumentState) error {
+	log.Printf("CMD change %s", args.File)
+	servers, err := s.documentServers(args)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		if !server.isOpen(args.File) {
+			continue
+		}
+		if _, err := server.openDocument(args.File); err != nil {
+			return err
+		}
+		*states = append(*states, server.documentState(args.File))
+	}
+	if len(*states) == 0 {
+		return fmt.Errorf("%s is not open", args.File)
+	}
+	return nil
+}
+
+// CloseDocument closes a file in the language servers that have it open.
+func (s *Server) CloseDocument(args DocumentArgs, closed *[]DocumentState) error {
+	log.Printf("CMD close %s", args.File)
+	servers, err := s.documentServers(args)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		state := server.documentState(args.File)
+		wasOpen, err := server.closeDocument(args.File)
+		if err != nil {
+			return err
+		}
+		if wasOpen {
+			*closed = append(*closed, state)
+		}
+	}
+	if len(*closed) == 0 {
+		return fmt.Errorf("%s is not open", args.File)
+	}
+	return nil
+}
+
+// runDocumentCommand calls method, one of the document rpcs, for every <file>
+// argument of c and prints the documents it returns.
+func runDocumentCommand(c *cli.Context, method string) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("expected <file>...")
+	}
+	all := make(map[string][]DocumentState)
+	for _, arg := range c.Args() {
+		file, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		var states []DocumentState
+		doRPC(method, DocumentArgs{File: file}, &states)
+		all[file] = states
+		if gJSON {
+			continue
+		}
+		for _, state := range states {
+			fmt.Printf("%s %s\n", file, state)
+		}
+	}
+	if gJSON {
+		return printJSON(all)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-documents")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "a.c")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int a;\n"), 0644))
+	l := newTestLanguageServer(3, dir)
+	closed, err := l.closeDocument(path)
+	assert.NoError(t, err)
+	assert.False(t, closed)
+
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+	assert.True(t, l.isOpen(path))
+	assert.Equal(t, DocumentState{Server: 3, Name: "fake", Version: 0}, l.documentState(path))
+
+	// Unchanged files are not sent again.
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, l.documentState(path).Version)
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int b;\n"), 0644))
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "3: fake version 1", l.documentState(path).String())
+
+	closed, err = l.closeDocument(path)
+	assert.NoError(t, err)
+	assert.True(t, closed)
+	assert.False(t, l.isOpen(path))
+	written := l.stdin.(*stdinBuffer).String()
+	assert.Contains(t, written, `"method":"textDocument/didOpen"`)
+	assert.Contains(t, written, `"method":"textDocument/didChange"`)
+	assert.Contains(t, written, `"method":"textDocument/didClose"`)
+
+	// Opening it again starts over.
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, l.documentState(path).Version)
+}
+
+func TestDocumentSyncQueueFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-documents")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "a.c")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int a;\n"), 0644))
+	l := newTestLanguageServer(0, dir)
+	l.initialized = false
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+	l.pending = make([]JSONRPCHeader, maxPendingMessages)
+
+	// A dropped didChange leaves the document as the server has it, so that
+	// the change is sent again.
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int b;\n"), 0644))
+	_, err = l.openDocument(path)
+	assert.EqualError(t, err, "fake has not finished initializing and its queue is full")
+	text, err := l.documentText(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "int a;\n", text)
+	assert.Equal(t, 0, l.documentState(path).Version)
+
+	// A dropped didClose leaves it open.
+	_, err = l.closeDocument(path)
+	assert.Error(t, err)
+	assert.True(t, l.isOpen(path))
+
+	// A dropped didOpen leaves it closed.
+	other := filepath.Join(dir, "b.c")
+	assert.NoError(t, ioutil.WriteFile(other, []byte("int c;\n"), 0644))
+	_, err = l.openDocument(other)
+	assert.Error(t, err)
+	assert.False(t, l.isOpen(other))
+
+	l.pending = nil
+	_, err = l.openDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, l.documentState(path).Version)
+	_, err = l.openDocument(other)
+	assert.NoError(t, err)
+	assert.True(t, l.isOpen(other))
+}
+
+func TestDocuments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-documents")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	a, b := filepath.Join(dir, "a.c"), filepath.Join(dir, "b.c")
+	assert.NoError(t, ioutil.WriteFile(a, []byte("int a;\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(b, []byte("int b;\n"), 0644))
+	first, second := newTestLanguageServer(0, dir), newTestLanguageServer(1, dir)
+	for _, open := range []struct {
+		l    *languageServer
+		path string
+	}{{first, b}, {first, a}, {second, a}} {
+		_, err := open.l.openDocument(open.path)
+		assert.NoError(t, err)
+	}
+
+	s := &Server{config: &Config{}, servers: []*languageServer{first, second}}
+	var documents []OpenFile
+	assert.NoError(t, s.Documents(false, &documents))
+	assert.Equal(t, []OpenFile{
+		{a, DocumentState{Server: 0, Name: "fake"}},
+		{b, DocumentState{Server: 0, Name: "fake"}},
+		{a, DocumentState{Server: 1, Name: "fake"}},
+	}, documents)
+}
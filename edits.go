@@ -0,0 +1,425 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// offsetOf returns the byte offset of the LSP position pos in text. As the
+// protocol requires, a column past the end of a line means the end of the
+// line and a line past the end of text the end of text.
+func offsetOf(text string, pos LsPosition) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		offset += i + 1
+	}
+	line := text[offset:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = strings.TrimSuffix(line[:i], "\r")
+	}
+	units := 0
+	for i, r := range line {
+		if units >= pos.Character {
+			return offset + i
+		}
+		units += utf16Len(r)
+	}
+	return offset + len(line)
+}
+
+// applyTextEdits returns text with edits applied. The ranges of edits refer
+// to text and must not overlap; edits inserting at the same position are
+// applied in order.
+func applyTextEdits(text string, edits []LsTextEdit) (string, error) {
+	type span struct {
+		start, end int
+		edit       LsTextEdit
+	}
+	spans := make([]span, len(edits))
+	for i, edit := range edits {
+		spans[i] = span{offsetOf(text, edit.Range.Start), offsetOf(text, edit.Range.End), edit}
+		if spans[i].end < spans[i].start {
+			return "", fmt.Errorf("edit at %s ends before it starts", formatRange(edit.Range))
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var out strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			return "", fmt.Errorf("edit at %s overlaps another edit", formatRange(s.edit.Range))
+		}
+		out.WriteString(text[last:s.start])
+		out.WriteString(s.edit.NewText)
+		last = s.end
+	}
+	out.WriteString(text[last:])
+	return out.String(), nil
+}
+
+// formatRange formats r with 1-based lines and columns for errors.
+func formatRange(r LsRange) string {
+	return fmt.Sprintf("%d:%d-%d:%d", r.Start.Line+1, r.Start.Character+1, r.End.Line+1, r.End.Character+1)
+}
+
+// FileDiff previews the changes of an edit to one file.
+type FileDiff struct {
+	Path string
+	// Set if the file is renamed; Diff then describes changes to its text.
+	NewPath string
+	Created bool
+	Deleted bool
+	// A unified diff, empty if the text is unchanged.
+	Diff string
+}
+
+// EditPreview previews a workspace edit before it is applied.
+type EditPreview struct {
+	// Describes the edit, ie, the title of a code action.
+	Label string
+	Files []FileDiff
+}
+
+// writeEditPreview prints p, with colors for a terminal if color is set.
+func writeEditPreview(out io.Writer, p EditPreview, color bool) {
+	if p.Label != "" {
+		fmt.Fprintf(out, "%s:\n", p.Label)
+	}
+	if len(p.Files) == 0 {
+		fmt.Fprintln(out, "no changes")
+	}
+	for _, f := range p.Files {
+		switch {
+		case f.NewPath != "":
+			fmt.Fprintf(out, "rename %s to %s\n", f.Path, f.NewPath)
+		case f.Created:
+			fmt.Fprintf(out, "create %s\n", f.Path)
+		case f.Deleted:
+			fmt.Fprintf(out, "delete %s\n", f.Path)
+		}
+		if color {
+			fmt.Fprint(out, colorizeDiff(f.Diff))
+		} else {
+			fmt.Fprint(out, f.Diff)
+		}
+	}
+}
+
+// planFile is a file as it is before or after an edit.
+type planFile struct {
+	text     string
+	encoding fileEncoding
+	mode     os.FileMode
+}
+
+// editPlan is the result of a workspace edit, computed before anything is
+// written so that the edit can be previewed and then applied all at once.
+type editPlan struct {
+	label string
+	// Every file the edit touches, in the order it first does.
+	paths []string
+	// The files before and after the edit, nil if they do not exist.
+	before map[string]*planFile
+	after  map[string]*planFile
+	// Maps the new path of renamed files to their old path.
+	renamedFrom map[string]string
+	// Set if the plan reverts the last edit of the journal, and so is not
+	// added to it.
+	undo bool
+}
+
+func newEditPlan(label string) *editPlan {
+	return &editPlan{
+		label:       label,
+		before:      make(map[string]*planFile),
+		after:       make(map[string]*planFile),
+		renamedFrom: make(map[string]string),
+	}
+}
+
+// load returns the file at path as changed by the edit so far, reading it
+// if the edit did not touch it yet. Returns nil if the file does not exist.
+func (p *editPlan) load(l *languageServer, path string) (*planFile, error) {
+	if f, has := p.after[path]; has {
+		return f, nil
+	}
+	p.paths = append(p.paths, path)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		p.before[path], p.after[path] = nil, nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	l.mu.Lock()
+	limit := l.documentLimit
+	l.mu.Unlock()
+	if limit.size > 0 && info.Size() > limit.size {
+		return nil, fmt.Errorf("%s is larger than max_document_size; refusing to edit it", path)
+	}
+	text, e, err := l.readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	// Positions in the edit refer to the text the server was sent.
+	l.docMu.Lock()
+	sent, has := l.documents[pathToURI(path)]
+	l.docMu.Unlock()
+	if has && sent != text {
+		return nil, fmt.Errorf("%s changed since the language server read it", path)
+	}
+
+	f := &planFile{text: text, encoding: e, mode: info.Mode().Perm()}
+	before := *f
+	p.before[path], p.after[path] = &before, f
+	return f, nil
+}
+
+// verify returns an error if a file of the plan was modified since it was
+// planned, ie, by another client while the preview was being confirmed.
+func (p *editPlan) verify() error {
+	for _, path := range p.paths {
+		before := p.before[path]
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) && before == nil {
+			continue
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if before == nil {
+			return fmt.Errorf("%s was created since the edit was planned", path)
+		}
+		text, err := before.encoding.decode(content)
+		if err != nil || text != before.text {
+			return fmt.Errorf("%s changed since the edit was planned", path)
+		}
+	}
+	return nil
+}
+
+// planEdit computes the files changed by edit without writing them.
+func (l *languageServer) planEdit(label string, edit LsWorkspaceEdit) (*editPlan, error) {
+	p := newEditPlan(label)
+	editFile := func(uri LsDocumentURI, edits []LsTextEdit) error {
+		path := uriToPath(uri)
+		f, err := p.load(l, path)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return fmt.Errorf("cannot edit %s: no such file", path)
+		}
+		if f.text, err = applyTextEdits(f.text, edits); err != nil {
+			return fmt.Errorf("cannot edit %s: %s", path, err.Error())
+		}
+		return nil
+	}
+
+	// changes is ignored by servers that send documentChanges, which the
+	// client capabilities say are supported.
+	if len(edit.DocumentChanges) == 0 {
+		var uris []string
+		for uri := range edit.Changes {
+			uris = append(uris, string(uri))
+		}
+		sort.Strings(uris)
+		for _, uri := range uris {
+			if err := editFile(LsDocumentURI(uri), edit.Changes[LsDocumentURI(uri)]); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	}
+
+	for _, change := range edit.DocumentChanges {
+		switch change.Kind {
+		case "":
+			if change.TextDocument == nil {
+				return nil, fmt.Errorf("document change without a document or kind")
+			}
+			uri := change.TextDocument.URI
+			if version := change.TextDocument.Version; version != nil {
+				l.docMu.Lock()
+				current, open := l.versions[uri]
+				l.docMu.Unlock()
+				if open && current != *version {
+					return nil, fmt.Errorf("edit is for version %d of %s but the language server has version %d", *version, uriToPath(uri), current)
+				}
+			}
+			if err := editFile(uri, change.Edits); err != nil {
+				return nil, err
+			}
+		case "create":
+			path := uriToPath(change.URI)
+			f, err := p.load(l, path)
+			if err != nil {
+				return nil, err
+			}
+			if f != nil {
+				return nil, fmt.Errorf("cannot create %s: file exists", path)
+			}
+			p.after[path] = &planFile{mode: 0644}
+		case "rename":
+			oldPath, newPath := uriToPath(change.OldURI), uriToPath(change.NewURI)
+			f, err := p.load(l, oldPath)
+			if err != nil {
+				return nil, err
+			}
+			if f == nil {
+				return nil, fmt.Errorf("cannot rename %s: no such file", oldPath)
+			}
+			existing, err := p.load(l, newPath)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, fmt.Errorf("cannot rename %s to %s: file exists", oldPath, newPath)
+			}
+			p.after[oldPath], p.after[newPath] = nil, f
+			if from, has := p.renamedFrom[oldPath]; has {
+				oldPath = from
+				delete(p.renamedFrom, from)
+			}
+			p.renamedFrom[newPath] = oldPath
+		case "delete":
+			path := uriToPath(change.URI)
+			f, err := p.load(l, path)
+			if err != nil {
+				return nil, err
+			}
+			if f == nil {
+				return nil, fmt.Errorf("cannot delete %s: no such file", path)
+			}
+			p.after[path] = nil
+		default:
+			return nil, fmt.Errorf("unknown document change %q", change.Kind)
+		}
+	}
+	return p, nil
+}
+
+// preview describes the changes of p as unified diffs.
+func (p *editPlan) preview() EditPreview {
+	renamed := make(map[string]bool)
+	for newPath, oldPath := range p.renamedFrom {
+		if p.after[newPath] != nil {
+			renamed[oldPath] = true
+		}
+	}
+
+	preview := EditPreview{Label: p.label}
+	for _, path := range p.paths {
+		before, after := p.before[path], p.after[path]
+		if oldPath, has := p.renamedFrom[path]; has && after != nil {
+			before = p.before[oldPath]
+			preview.Files = append(preview.Files, FileDiff{
+				Path:    oldPath,
+				NewPath: path,
+				Diff:    unifiedDiff(oldPath, path, before.text, after.text),
+			})
+			continue
+		}
+		switch {
+		case before == nil && after == nil, renamed[path]:
+		case before == nil:
+			preview.Files = append(preview.Files, FileDiff{
+				Path: path, Created: true, Diff: unifiedDiff("/dev/null", path, "", after.text),
+			})
+		case after == nil:
+			preview.Files = append(preview.Files, FileDiff{
+				Path: path, Deleted: true, Diff: unifiedDiff(path, "/dev/null", before.text, ""),
+			})
+		case before.text != after.text:
+			preview.Files = append(preview.Files, FileDiff{
+				Path: path, Diff: unifiedDiff(path, path, before.text, after.text),
+			})
+		}
+	}
+	return preview
+}
+
+// applyPlan writes the files changed by p and then removes the files it
+// deletes or renames, and adds p to the journal so that it can be undone.
+// op names the operation for checkWritablePaths.
+func (l *languageServer) applyPlan(op string, p *editPlan) error {
+	if err := l.checkWritablePaths(op, p.paths); err != nil {
+		return err
+	}
+	l.editMu.Lock()
+	defer l.editMu.Unlock()
+	if err := p.verify(); err != nil {
+		return err
+	}
+	for _, path := range p.paths {
+		before, after := p.before[path], p.after[path]
+		if after == nil || before != nil && before.text == after.text {
+			continue
+		}
+		content, err := after.encoding.encode(after.text)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, content, after.mode); err != nil {
+			return err
+		}
+		log.Printf("Applied %q to %s", p.label, path)
+	}
+	for _, path := range p.paths {
+		if p.before[path] != nil && p.after[path] == nil {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			log.Printf("Applied %q: removed %s", p.label, path)
+		}
+	}
+	if !p.undo {
+		if err := recordEdit(gEditJournal, l.directory, p); err != nil {
+			log.Printf("Cannot add %q to the edit journal: %s", p.label, err.Error())
+		}
+	}
+
+	// Tell the server about the new text of documents it has open.
+	for _, path := range p.paths {
+		l.docMu.Lock()
+		_, open := l.documents[pathToURI(path)]
+		l.docMu.Unlock()
+		if open && p.after[path] != nil {
+			l.openDocument(path)
+		}
+	}
+	return nil
+}
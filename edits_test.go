@@ -0,0 +1,112 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func textEdit(startLine, startCharacter, endLine, endCharacter int, newText string) LsTextEdit {
+	return LsTextEdit{
+		Range: LsRange{
+			Start: LsPosition{Line: startLine, Character: startCharacter},
+			End:   LsPosition{Line: endLine, Character: endCharacter},
+		},
+		NewText: newText,
+	}
+}
+
+func TestApplyTextEdits(t *testing.T) {
+	text := "int foo;\r\nchar *s = \"😀x\";\n"
+
+	result, err := applyTextEdits(text, []LsTextEdit{
+		textEdit(1, 13, 1, 14, "y"),
+		textEdit(0, 4, 0, 7, "bar"),
+		textEdit(0, 0, 0, 0, "static "),
+		textEdit(0, 0, 0, 0, "const "),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "static const int bar;\r\nchar *s = \"😀y\";\n", result)
+
+	// Past the end of a line or of the text.
+	result, err = applyTextEdits(text, []LsTextEdit{textEdit(0, 100, 0, 100, " // x"), textEdit(5, 0, 5, 0, "end\n")})
+	assert.NoError(t, err)
+	assert.Equal(t, "int foo; // x\r\nchar *s = \"😀x\";\nend\n", result)
+
+	_, err = applyTextEdits(text, []LsTextEdit{textEdit(0, 0, 0, 5, ""), textEdit(0, 3, 0, 7, "")})
+	assert.Error(t, err)
+	_, err = applyTextEdits(text, []LsTextEdit{textEdit(1, 0, 0, 0, "")})
+	assert.Error(t, err)
+}
+
+func TestPlanAndApplyEdit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-edits")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	a := filepath.Join(dir, "a.c")
+	b := filepath.Join(dir, "b.c")
+	c := filepath.Join(dir, "sub", "c.c")
+	gone := filepath.Join(dir, "gone.c")
+	assert.NoError(t, ioutil.WriteFile(a, []byte("int foo;\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(b, []byte("caf\xe9\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(gone, []byte("x\n"), 0644))
+
+	l := &languageServer{directory: dir, encodings: make(map[LsDocumentURI]fileEncoding)}
+	plan, err := l.planEdit("Rename foo", LsWorkspaceEdit{DocumentChanges: []LsDocumentChange{
+		{TextDocument: &LsVersionedTextDocumentIdentifier{URI: pathToURI(a)}, Edits: []LsTextEdit{textEdit(0, 4, 0, 7, "bar")}},
+		{Kind: "rename", OldURI: pathToURI(b), NewURI: pathToURI(c)},
+		{TextDocument: &LsVersionedTextDocumentIdentifier{URI: pathToURI(c)}, Edits: []LsTextEdit{textEdit(0, 4, 0, 4, "s")}},
+		{Kind: "delete", URI: pathToURI(gone)},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, EditPreview{Label: "Rename foo", Files: []FileDiff{
+		{Path: a, Diff: "--- " + a + "\n+++ " + a + "\n@@ -1 +1 @@\n-int foo;\n+int bar;\n"},
+		{Path: b, NewPath: c, Diff: "--- " + b + "\n+++ " + c + "\n@@ -1 +1 @@\n-café\n+cafés\n"},
+		{Path: gone, Deleted: true, Diff: "--- " + gone + "\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n"},
+	}}, plan.preview())
+
+	// Nothing is written until the plan is applied.
+	assert.True(t, fileExists(gone))
+	assert.False(t, fileExists(c))
+
+	l.readOnly = true
+	assert.Error(t, l.applyPlan("test", plan))
+	l.readOnly = false
+	assert.NoError(t, l.applyPlan("test", plan))
+
+	content, err := ioutil.ReadFile(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "int bar;\n", string(content))
+	content, err = ioutil.ReadFile(c)
+	assert.NoError(t, err)
+	assert.Equal(t, "caf\xe9s\n", string(content))
+	info, err := os.Stat(c)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.False(t, fileExists(b))
+	assert.False(t, fileExists(gone))
+
+	// Edits are checked before anything is written.
+	_, err = l.planEdit("", LsWorkspaceEdit{DocumentChanges: []LsDocumentChange{{Kind: "create", URI: pathToURI(a)}}})
+	assert.Error(t, err)
+	_, err = l.planEdit("", LsWorkspaceEdit{Changes: map[LsDocumentURI][]LsTextEdit{pathToURI(gone): {textEdit(0, 0, 0, 0, "x")}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// EncodingRule sets the encoding of files that are not UTF-8, ie,
+//
+//	[[encoding]]
+//	path = '^legacy/'
+//	encoding = "Shift_JIS"
+//
+// Documents are converted to UTF-8 before they are sent to the server, so
+// that the positions it reports are correct. The first matching rule
+// applies. Files that no rule matches are read as UTF-8, or as UTF-16 if they
+// start with its byte order mark, and as ISO-8859-1 if they are neither.
+// Changes apply to documents opened after reload-config.
+type EncodingRule struct {
+	// Regular expression matched against the path of a file relative to the
+	// project directory, with / as separator. Empty matches everything.
+	Path string `toml:"path,omitempty"`
+
+	// IANA name of the encoding, ie, ISO-8859-1, Shift_JIS or EUC-KR.
+	Encoding string `toml:"encoding,omitempty"`
+}
+
+// lookupEncoding returns the encoding called name and its canonical name. A
+// nil encoding means UTF-8, which needs no conversion.
+func lookupEncoding(name string) (encoding.Encoding, string, error) {
+	e, err := ianaindex.IANA.Encoding(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown encoding %q", name)
+	}
+	if e == nil {
+		return nil, "", fmt.Errorf("unsupported encoding %q", name)
+	}
+	canonical, err := ianaindex.IANA.Name(e)
+	if err != nil {
+		canonical = name
+	}
+	if e == unicode.UTF8 {
+		e = nil
+	}
+	return e, canonical, nil
+}
+
+func (r EncodingRule) validate() map[string]error {
+	problems := make(map[string]error)
+	if _, err := regexp.Compile(r.Path); err != nil {
+		problems["path"] = err
+	}
+	if _, _, err := lookupEncoding(r.Encoding); err != nil {
+		problems["encoding"] = err
+	}
+	return problems
+}
+
+// encodingRule is a compiled EncodingRule.
+type encodingRule struct {
+	path     *regexp.Regexp
+	encoding encoding.Encoding
+	name     string
+}
+
+// encodingRules compiles the encoding rules. Invalid rules, which config
+// validate reports, are skipped.
+func (c *Config) encodingRules() []encodingRule {
+	var rules []encodingRule
+	for _, rule := range c.Encodings {
+		if len(rule.validate()) > 0 {
+			continue
+		}
+		e, name, _ := lookupEncoding(rule.Encoding)
+		rules = append(rules, encodingRule{path: regexp.MustCompile(rule.Path), encoding: e, name: name})
+	}
+	return rules
+}
+
+// fileEncoding is the encoding of a document and its name for the log.
+type fileEncoding struct {
+	encoding encoding.Encoding
+	name     string
+}
+
+var (
+	utf8Encoding    = fileEncoding{name: "UTF-8"}
+	latin1Encoding  = fileEncoding{charmap.ISO8859_1, "ISO-8859-1"}
+	utf16LEEncoding = fileEncoding{unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "UTF-16LE"}
+	utf16BEEncoding = fileEncoding{unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "UTF-16BE"}
+)
+
+// detectEncoding returns the encoding of content, the contents of the file
+// at path relative to the project directory, with / as separator.
+func detectEncoding(rules []encodingRule, path string, content []byte) fileEncoding {
+	for _, rule := range rules {
+		if rule.path.MatchString(path) {
+			return fileEncoding{rule.encoding, rule.name}
+		}
+	}
+	switch {
+	case bytes.HasPrefix(content, []byte{0xff, 0xfe}):
+		return utf16LEEncoding
+	case bytes.HasPrefix(content, []byte{0xfe, 0xff}):
+		return utf16BEEncoding
+	case utf8.Valid(content):
+		return utf8Encoding
+	}
+	// Every byte is a character in ISO-8859-1, so the text survives being
+	// written back even if the guess is wrong.
+	return latin1Encoding
+}
+
+func (e fileEncoding) decode(content []byte) (string, error) {
+	if e.encoding == nil {
+		return string(content), nil
+	}
+	text, err := e.encoding.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", fmt.Errorf("cannot read as %s: %s", e.name, err.Error())
+	}
+	return string(text), nil
+}
+
+func (e fileEncoding) encode(text string) ([]byte, error) {
+	if e.encoding == nil {
+		return []byte(text), nil
+	}
+	content, err := e.encoding.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("cannot write as %s: %s", e.name, err.Error())
+	}
+	return content, nil
+}
+
+// setEncodingRules sets the rules used to read documents.
+func (l *languageServer) setEncodingRules(rules []encodingRule) {
+	l.mu.Lock()
+	l.encodingRules = rules
+	l.mu.Unlock()
+}
+
+// relativePath returns path relative to the directory of l with / as
+// separator, as matched by rules.
+func (l *languageServer) relativePath(path string) string {
+	if rel, err := filepath.Rel(l.directory, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// readDocument reads the file at path and converts it to UTF-8. Also
+// returns the encoding it was read with. Files larger than the document limit
+// are refused or truncated.
+func (l *languageServer) readDocument(path string) (string, fileEncoding, error) {
+	l.mu.Lock()
+	rules := l.encodingRules
+	limit := l.documentLimit
+	l.mu.Unlock()
+
+	content, truncated, err := readFileLimited(path, limit)
+	if err != nil {
+		return "", fileEncoding{}, err
+	}
+	utf16 := bytes.HasPrefix(content, []byte{0xff, 0xfe}) || bytes.HasPrefix(content, []byte{0xfe, 0xff})
+	if truncated && !utf16 {
+		content = truncateContent(content)
+	}
+
+	e := detectEncoding(rules, l.relativePath(path), content)
+	if truncated && utf16 {
+		content = content[:len(content)&^1]
+	}
+	text, err := e.decode(content)
+	if err != nil {
+		return "", e, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	if truncated && utf16 {
+		if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+			text = text[:i+1]
+		}
+	}
+	return text, e, nil
+}
+
+// encodeDocument converts text, new contents for the document at path, to
+// the encoding the document was read with, so that edits can be written
+// back without corrupting the file.
+func (l *languageServer) encodeDocument(path string, text string) ([]byte, error) {
+	l.docMu.Lock()
+	e, has := l.encodings[pathToURI(path)]
+	l.docMu.Unlock()
+	if !has {
+		var err error
+		if _, e, err = l.readDocument(path); err != nil {
+			return nil, err
+		}
+	}
+	content, err := e.encode(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	return content, nil
+}
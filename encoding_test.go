@@ -0,0 +1,79 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodingRuleValidate(t *testing.T) {
+	assert.Empty(t, EncodingRule{Path: "^legacy/", Encoding: "shift_jis"}.validate())
+	assert.Empty(t, EncodingRule{Encoding: "latin1"}.validate())
+	problems := EncodingRule{Path: "(", Encoding: "klingon"}.validate()
+	assert.Contains(t, problems, "path")
+	assert.Contains(t, problems, "encoding")
+}
+
+func TestDetectEncoding(t *testing.T) {
+	rules := (&Config{Encodings: []EncodingRule{
+		{Path: `^sjis/`, Encoding: "Shift_JIS"},
+		{Path: `^bad/`, Encoding: "klingon"},
+	}}).encodingRules()
+	assert.Len(t, rules, 1)
+
+	assert.Equal(t, "Shift_JIS", detectEncoding(rules, "sjis/a.c", []byte("abc")).name)
+	assert.Equal(t, "UTF-8", detectEncoding(rules, "a.c", []byte("caf\xc3\xa9")).name)
+	assert.Equal(t, "ISO-8859-1", detectEncoding(rules, "a.c", []byte("caf\xe9")).name)
+	assert.Equal(t, "UTF-16LE", detectEncoding(rules, "a.c", []byte("\xff\xfea\x00")).name)
+	assert.Equal(t, "UTF-16BE", detectEncoding(rules, "a.c", []byte("\xfe\xff\x00a")).name)
+}
+
+func TestReadAndEncodeDocument(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-encoding")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// "日本" in Shift_JIS and "café" in ISO-8859-1.
+	sjis := filepath.Join(dir, "sjis", "a.c")
+	latin1 := filepath.Join(dir, "b.c")
+	assert.NoError(t, os.Mkdir(filepath.Dir(sjis), 0755))
+	assert.NoError(t, ioutil.WriteFile(sjis, []byte("\x93\xfa\x96\x7b"), 0644))
+	assert.NoError(t, ioutil.WriteFile(latin1, []byte("caf\xe9"), 0644))
+
+	l := &languageServer{directory: dir, encodings: make(map[LsDocumentURI]fileEncoding)}
+	l.setEncodingRules((&Config{Encodings: []EncodingRule{{Path: `^sjis/`, Encoding: "Shift_JIS"}}}).encodingRules())
+
+	text, e, err := l.readDocument(sjis)
+	assert.NoError(t, err)
+	assert.Equal(t, "日本", text)
+	assert.Equal(t, "Shift_JIS", e.name)
+	content, err := l.encodeDocument(sjis, "日本語")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("\x93\xfa\x96\x7b\x8c\xea"), content)
+
+	text, _, err = l.readDocument(latin1)
+	assert.NoError(t, err)
+	assert.Equal(t, "café", text)
+	content, err = l.encodeDocument(latin1, "cafés")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("caf\xe9s"), content)
+	_, err = l.encodeDocument(latin1, "日本")
+	assert.Error(t, err)
+}
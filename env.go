@@ -0,0 +1,105 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lspcEnv returns the environment variables which describe how lspc resolves
+// the current directory. They are printed by env and exported by exec.
+func lspcEnv() ([]string, error) {
+	vars := []string{"LSPC_SOCKET=" + gSocket, "LSPC_SOCKET_SOURCE=" + gSocketSource}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	cwd, err = filepath.Abs(cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not start the daemon just to find out nothing is running.
+	var servers []ServerInfo
+	if tryRPC("Server.Ls", false, &servers) == nil {
+		if server, found := serverForPath(servers, cwd); found {
+			vars = append(vars,
+				"LSPC_SERVER_ID="+strconv.Itoa(server.ID),
+				"LSPC_SERVER_DIR="+server.Directory,
+				"LSPC_SERVER_CMD="+strings.Join(server.Args, " "))
+		}
+	}
+	return vars, nil
+}
+
+// shellQuote quotes s so that it can be safely evaluated by a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func printEnv() error {
+	vars, err := lspcEnv()
+	if err != nil {
+		return err
+	}
+	if gJSON {
+		env := make(map[string]string)
+		for _, v := range vars {
+			kv := strings.SplitN(v, "=", 2)
+			env[kv[0]] = kv[1]
+		}
+		return printJSON(env)
+	}
+	for _, v := range vars {
+		kv := strings.SplitN(v, "=", 2)
+		fmt.Printf("%s=%s\n", kv[0], shellQuote(kv[1]))
+	}
+	return nil
+}
+
+// execWithEnv runs args with the lspc environment exported and exits with
+// the exit code of the command.
+func execWithEnv(args []string) error {
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no command given")
+	}
+
+	vars, err := lspcEnv()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), vars...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
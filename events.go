@@ -0,0 +1,250 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Capacity of the event queue of each subscriber. Events are dropped for
+// subscribers that fall further behind.
+const eventQueueSize = 256
+
+// Kinds of events.
+const (
+	EventServerStarted = "started"
+	EventServerStopped = "stopped"
+	EventDiagnostics   = "diagnostics"
+	EventProgress      = "progress"
+	EventMessage       = "message"
+	EventApplyEdit     = "applyEdit"
+)
+
+// DaemonEvent is something that happened to a language server. Which of the
+// optional fields is set depends on Kind.
+type DaemonEvent struct {
+	Time   time.Time
+	Kind   string
+	Server ServerInfo
+	// Number of events dropped before this one because the subscriber did
+	// not keep up.
+	Dropped int `json:",omitempty"`
+
+	// EventServerStopped
+	ExitStatus string `json:",omitempty"`
+	// EventDiagnostics
+	Diagnostics *DiagnosticsEvent `json:",omitempty"`
+	// EventProgress. Done is set for the final report of the token.
+	Progress *ProgressInfo `json:",omitempty"`
+	Done     bool          `json:",omitempty"`
+	// EventMessage
+	Message *LsShowMessageParams `json:",omitempty"`
+	// EventApplyEdit
+	ApplyEdit *ApplyEditEvent `json:",omitempty"`
+}
+
+// ApplyEditEvent summarizes a workspace/applyEdit request from a server.
+type ApplyEditEvent struct {
+	Label string `json:",omitempty"`
+	// Documents the edit changes, creates, renames or deletes.
+	URIs []LsDocumentURI
+	// Number of text edits.
+	Edits         int
+	Applied       bool
+	FailureReason string `json:",omitempty"`
+}
+
+// newApplyEditEvent summarizes params, which was answered with result.
+func newApplyEditEvent(params LsApplyWorkspaceEditParams, result LsApplyWorkspaceEditResult) *ApplyEditEvent {
+	e := &ApplyEditEvent{Label: params.Label, Applied: result.Applied, FailureReason: result.FailureReason}
+	seen := make(map[LsDocumentURI]bool)
+	add := func(uri LsDocumentURI) {
+		if uri != "" && !seen[uri] {
+			seen[uri] = true
+			e.URIs = append(e.URIs, uri)
+		}
+	}
+	for uri, edits := range params.Edit.Changes {
+		add(uri)
+		e.Edits += len(edits)
+	}
+	for _, change := range params.Edit.DocumentChanges {
+		if change.TextDocument != nil {
+			add(change.TextDocument.URI)
+		}
+		add(change.URI)
+		add(change.OldURI)
+		add(change.NewURI)
+		e.Edits += len(change.Edits)
+	}
+	sort.Slice(e.URIs, func(i, j int) bool { return e.URIs[i] < e.URIs[j] })
+	return e
+}
+
+// daemonEvents delivers events to every subscriber, ie, gRPC StreamEvents
+// calls and WebSocket connections.
+var daemonEvents = newEventHub()
+
+// eventHub fans out events to subscribers. Publishing never blocks; events
+// are dropped for subscribers whose queue is full.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+}
+
+type eventSubscriber struct {
+	c chan DaemonEvent
+	// Events dropped since the last delivered event, and in total. Guarded
+	// by eventHub.mu.
+	dropped      int
+	totalDropped int
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[*eventSubscriber]struct{})}
+}
+
+// subscribe returns a subscriber which receives every event published from
+// now on. It must be passed to unsubscribe once it is no longer read.
+func (h *eventHub) subscribe() *eventSubscriber {
+	sub := &eventSubscriber{c: make(chan DaemonEvent, eventQueueSize)}
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *eventHub) unsubscribe(sub *eventSubscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, sub)
+	h.mu.Unlock()
+}
+
+func (h *eventHub) publish(e DaemonEvent) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		e.Dropped = sub.dropped
+		select {
+		case sub.c <- e:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+			sub.totalDropped++
+		}
+	}
+}
+
+// stats describes the queue of every subscriber.
+func (h *eventHub) stats() []QueueStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var stats []QueueStats
+	for sub := range h.subscribers {
+		stats = append(stats, QueueStats{
+			Name:     "events",
+			Depth:    len(sub.c),
+			Capacity: cap(sub.c),
+			Dropped:  sub.totalDropped,
+		})
+	}
+	return stats
+}
+
+// publishEvent publishes an event about l.
+func (l *languageServer) publishEvent(e DaemonEvent) {
+	e.Server = l.info()
+	daemonEvents.publish(e)
+}
+
+// Events opens a socket which streams events about the servers in ids, or
+// every server if ids is empty, as JSON lines and returns its path, see
+// `lspc events`.
+func (s *Server) Events(ids []int32, socket *string) error {
+	log.Printf("CMD events %v", ids)
+	path := fmt.Sprintf("%s.events-%d", gSocket, time.Now().UnixNano())
+	if err := serveOnce(path, "events client", func(conn net.Conn) { streamEvents(conn, idSet(ids)) }); err != nil {
+		return err
+	}
+	*socket = path
+	return nil
+}
+
+// streamEvents writes events to conn until the client disconnects.
+func streamEvents(conn net.Conn, ids idSet) {
+	defer conn.Close()
+	sub := daemonEvents.subscribe()
+	defer daemonEvents.unsubscribe(sub)
+
+	// The client never writes; a read returns once it has gone away.
+	gone := make(chan struct{})
+	go func() {
+		conn.Read(make([]byte, 1))
+		close(gone)
+	}()
+
+	encoder := json.NewEncoder(conn)
+	for {
+		select {
+		case <-gone:
+			return
+		case e := <-sub.c:
+			if !ids.has(e.Server.ID) {
+				continue
+			}
+			if err := encoder.Encode(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// events implements the events command. It copies the event stream of the
+// daemon to out until the daemon exits or the command is interrupted.
+func events(servers []string, out io.Writer) error {
+	if err := checkLocalDaemon("events"); err != nil {
+		return err
+	}
+	var ids []int32
+	for _, server := range servers {
+		id, err := strconv.Atoi(server)
+		if err != nil {
+			return fmt.Errorf("invalid server id %q", server)
+		}
+		ids = append(ids, int32(id))
+	}
+
+	var socket string
+	doRPC("Server.Events", ids, &socket)
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = io.Copy(out, conn)
+	return err
+}
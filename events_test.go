@@ -0,0 +1,69 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventHubDropsForSlowSubscribers(t *testing.T) {
+	hub := newEventHub()
+	sub := hub.subscribe()
+	for i := 0; i < eventQueueSize+3; i++ {
+		hub.publish(DaemonEvent{Kind: EventMessage})
+	}
+	assert.Equal(t, []QueueStats{{Name: "events", Depth: eventQueueSize, Capacity: eventQueueSize, Dropped: 3}}, hub.stats())
+
+	// The next delivered event reports what was missed.
+	for i := 0; i < eventQueueSize; i++ {
+		e := <-sub.c
+		assert.Equal(t, 0, e.Dropped)
+		assert.False(t, e.Time.IsZero())
+	}
+	hub.publish(DaemonEvent{Kind: EventServerStarted})
+	hub.publish(DaemonEvent{Kind: EventServerStopped})
+	assert.Equal(t, 3, (<-sub.c).Dropped)
+	assert.Equal(t, 0, (<-sub.c).Dropped)
+
+	hub.unsubscribe(sub)
+	hub.publish(DaemonEvent{Kind: EventMessage})
+	assert.Empty(t, sub.c)
+	assert.Empty(t, hub.stats())
+}
+
+func TestNewApplyEditEvent(t *testing.T) {
+	version := 2
+	params := LsApplyWorkspaceEditParams{
+		Label: "Rename foo",
+		Edit: LsWorkspaceEdit{
+			Changes: map[LsDocumentURI][]LsTextEdit{
+				"file:///b.c": {{NewText: "bar"}, {NewText: "bar"}},
+			},
+			DocumentChanges: []LsDocumentChange{
+				{TextDocument: &LsVersionedTextDocumentIdentifier{URI: "file:///b.c", Version: &version}, Edits: []LsTextEdit{{NewText: "bar"}}},
+				{Kind: "rename", OldURI: "file:///a.c", NewURI: "file:///c.c"},
+			},
+		},
+	}
+	e := newApplyEditEvent(params, LsApplyWorkspaceEditResult{FailureReason: "read-only"})
+	assert.Equal(t, &ApplyEditEvent{
+		Label:         "Rename foo",
+		URIs:          []LsDocumentURI{"file:///a.c", "file:///b.c", "file:///c.c"},
+		Edits:         3,
+		FailureReason: "read-only",
+	}, e)
+}
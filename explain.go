@@ -0,0 +1,343 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// How long explain waits for the documentation of a code.
+const explainFetchTimeout = 15 * time.Second
+
+// Documentation larger than this is cut off.
+const maxExplainDocumentation = 1 << 20
+
+// ExplainArgs selects the diagnostics to explain: those at a position of
+// File, or if File is empty every published diagnostic with Code.
+type ExplainArgs struct {
+	PositionArgs
+	Code string
+	// How long to wait for diagnostics of File to be published.
+	Wait time.Duration
+}
+
+// containsPosition returns true if the 1-based line and column are inside of
+// d, including its ends.
+func (d Diagnostic) containsPosition(line, column int) bool {
+	if line < d.Line || line > d.EndLine {
+		return false
+	}
+	if line == d.Line && column < d.Column {
+		return false
+	}
+	return line != d.EndLine || column <= d.EndColumn
+}
+
+// Explain returns the diagnostics selected by args along with the links to
+// their documentation and their related locations.
+func (s *Server) Explain(args ExplainArgs, diagnostics *[]Diagnostic) error {
+	if args.File == "" {
+		log.Printf("CMD explain %s", args.Code)
+		for _, server := range s.serverList() {
+			for _, published := range server.allDiagnostics(args.Unit) {
+				for _, d := range published {
+					if d.Code == args.Code {
+						*diagnostics = append(*diagnostics, d)
+					}
+				}
+			}
+		}
+		if len(*diagnostics) == 0 {
+			return fmt.Errorf("no published diagnostic has code %q", args.Code)
+		}
+		sortDiagnostics(*diagnostics)
+		return nil
+	}
+
+	log.Printf("CMD explain %s:%d:%d", args.File, args.Line, args.Column)
+	var result CheckResult
+	if err := s.Check(CheckArgs{Files: []string{args.File}, Unit: args.Unit, Wait: args.Wait}, &result); err != nil {
+		return err
+	}
+	for _, d := range result.Diagnostics[args.File] {
+		if d.containsPosition(args.Line, args.Column) {
+			*diagnostics = append(*diagnostics, d)
+		}
+	}
+	if len(*diagnostics) == 0 {
+		return fmt.Errorf("no diagnostic at %s:%d:%d", args.File, args.Line, args.Column)
+	}
+	return nil
+}
+
+// explainPosition matches a <file>:<line>:<col> argument.
+var explainPosition = regexp.MustCompile(`^(.+):(\d+):(\d+)$`)
+
+// parseExplainTarget parses the argument of explain, which is either
+// <file>:<line>:<col> of an existing file or a diagnostic code.
+func parseExplainTarget(target string, unit ColumnUnit) (ExplainArgs, error) {
+	if m := explainPosition.FindStringSubmatch(target); m != nil && fileExists(m[1]) {
+		file, err := filepath.Abs(m[1])
+		if err != nil {
+			return ExplainArgs{}, err
+		}
+		line, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		if line < 1 || column < 1 {
+			return ExplainArgs{}, fmt.Errorf("line and col must be positive, got %q", target)
+		}
+		return ExplainArgs{PositionArgs: PositionArgs{File: file, Line: line, Column: column, Unit: unit}}, nil
+	}
+	return ExplainArgs{Code: target, PositionArgs: PositionArgs{Unit: unit}}, nil
+}
+
+// fetchDocumentation downloads the documentation at href and converts it to
+// text for the terminal.
+func fetchDocumentation(href string) (string, error) {
+	client := http.Client{Timeout: explainFetchTimeout}
+	response, err := client.Get(href)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", href, response.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxExplainDocumentation))
+	if err != nil {
+		return "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return htmlToText(string(body))
+	}
+	return string(body), nil
+}
+
+// Elements whose content is not documentation.
+var skippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Nav: true,
+	atom.Header: true, atom.Footer: true, atom.Noscript: true, atom.Svg: true,
+	atom.Form: true, atom.Button: true,
+}
+
+// Elements that start on a new line.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Br: true, atom.Tr: true, atom.Table: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Blockquote: true, atom.Hr: true,
+}
+
+var headingElements = map[atom.Atom]bool{
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// textBuilder collects text, collapsing the whitespace between words and
+// the blank lines between blocks.
+type textBuilder struct {
+	text []byte
+}
+
+func (b *textBuilder) endsWith(c byte) bool {
+	return len(b.text) > 0 && b.text[len(b.text)-1] == c
+}
+
+func (b *textBuilder) space() {
+	if len(b.text) > 0 && !b.endsWith(' ') && !b.endsWith('\n') {
+		b.text = append(b.text, ' ')
+	}
+}
+
+func (b *textBuilder) newline() {
+	b.text = bytes.TrimRight(b.text, " ")
+	if len(b.text) > 0 && !b.endsWith('\n') {
+		b.text = append(b.text, '\n')
+	}
+}
+
+func (b *textBuilder) paragraph() {
+	b.newline()
+	if len(b.text) > 0 && !bytes.HasSuffix(b.text, []byte("\n\n")) {
+		b.text = append(b.text, '\n')
+	}
+}
+
+func (b *textBuilder) write(s string) {
+	b.text = append(b.text, s...)
+}
+
+// htmlToText renders the readable parts of an HTML page as plain text with
+// markdown-like headings, lists and code blocks.
+func htmlToText(page string) (string, error) {
+	root, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return "", err
+	}
+	var b textBuilder
+	var render func(n *html.Node, pre bool)
+	render = func(n *html.Node, pre bool) {
+		switch {
+		case n.Type == html.TextNode && pre:
+			b.write(n.Data)
+			return
+		case n.Type == html.TextNode:
+			text := strings.Join(strings.Fields(n.Data), " ")
+			if strings.TrimLeftFunc(n.Data, unicode.IsSpace) != n.Data {
+				b.space()
+			}
+			b.write(text)
+			if text != "" && strings.TrimRightFunc(n.Data, unicode.IsSpace) != n.Data {
+				b.space()
+			}
+			return
+		case n.Type == html.ElementNode && skippedElements[n.DataAtom]:
+			return
+		}
+
+		switch {
+		case headingElements[n.DataAtom]:
+			b.paragraph()
+			b.write(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		case n.DataAtom == atom.Pre:
+			b.paragraph()
+			b.write("```\n")
+			pre = true
+		case n.DataAtom == atom.Li:
+			b.newline()
+			b.write("- ")
+		case n.DataAtom == atom.P || n.DataAtom == atom.Table || n.DataAtom == atom.Blockquote:
+			b.paragraph()
+		case blockElements[n.DataAtom]:
+			b.newline()
+		case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+			if len(b.text) > 0 && !b.endsWith('\n') {
+				b.write("\t")
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			render(child, pre)
+		}
+		switch {
+		case n.DataAtom == atom.Pre:
+			b.newline()
+			b.write("```")
+			b.paragraph()
+		case headingElements[n.DataAtom] || n.DataAtom == atom.P:
+			b.paragraph()
+		}
+	}
+	render(root, false)
+	return strings.TrimSpace(string(b.text)) + "\n", nil
+}
+
+// openBrowser opens url with the default browser.
+func openBrowser(url string) error {
+	var command *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		command = exec.Command("open", url)
+	case "windows":
+		command = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		command = exec.Command("xdg-open", url)
+	}
+	command.Stdout = os.Stderr
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// Explanation is a diagnostic with the documentation of its code, as printed
+// by explain with --json.
+type Explanation struct {
+	Diagnostic
+	// The documentation Href links to as text.
+	Documentation string `json:"documentation,omitempty"`
+	// Why the documentation could not be fetched.
+	Error string `json:"error,omitempty"`
+}
+
+// explanations fetches the documentation of diagnostics, once per link.
+func explanations(diagnostics []Diagnostic) []Explanation {
+	fetched := make(map[string]Explanation)
+	var explained []Explanation
+	for _, d := range diagnostics {
+		e, has := fetched[d.Href]
+		if !has && d.Href != "" {
+			text, err := fetchDocumentation(d.Href)
+			e.Documentation = text
+			if err != nil {
+				e.Error = err.Error()
+			}
+			fetched[d.Href] = e
+		}
+		e.Diagnostic = d
+		explained = append(explained, e)
+	}
+	return explained
+}
+
+// explain prints diagnostics with their related locations, followed by the
+// documentation of each of their codes, which is opened in the browser
+// instead if browser is set.
+func explain(out io.Writer, diagnostics []Diagnostic, browser bool) error {
+	var links []string
+	explained := make(map[string]bool)
+	for _, d := range diagnostics {
+		writeDiagnostic(out, d, diagnosticsText)
+		if d.Href != "" && !explained[d.Href] {
+			explained[d.Href] = true
+			links = append(links, d.Href)
+		}
+	}
+	if len(links) == 0 {
+		fmt.Fprintln(out, "\nThe language server does not link documentation for this diagnostic.")
+		return nil
+	}
+	for _, href := range links {
+		if browser {
+			if err := openBrowser(href); err != nil {
+				return fmt.Errorf("unable to open %s: %s", href, err.Error())
+			}
+			continue
+		}
+		fmt.Fprintf(out, "\n%s\n\n", href)
+		text, err := fetchDocumentation(href)
+		if err != nil {
+			fmt.Fprintf(out, "Unable to fetch the documentation: %s\n", err.Error())
+			continue
+		}
+		fmt.Fprint(out, text)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticContainsPosition(t *testing.T) {
+	d := Diagnostic{Location: Location{Line: 2, Column: 5, EndLine: 3, EndColumn: 2}}
+	assert.True(t, d.containsPosition(2, 5))
+	assert.True(t, d.containsPosition(2, 80))
+	assert.True(t, d.containsPosition(3, 2))
+	assert.False(t, d.containsPosition(2, 4))
+	assert.False(t, d.containsPosition(3, 3))
+	assert.False(t, d.containsPosition(1, 5))
+}
+
+func TestParseExplainTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-explain")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.c")
+	assert.NoError(t, ioutil.WriteFile(file, nil, 0644))
+
+	args, err := parseExplainTarget(file+":3:7", ByteColumns)
+	assert.NoError(t, err)
+	assert.Equal(t, PositionArgs{File: file, Line: 3, Column: 7, Unit: ByteColumns}, args.PositionArgs)
+	assert.Empty(t, args.Code)
+
+	args, err = parseExplainTarget("E0308", ByteColumns)
+	assert.NoError(t, err)
+	assert.Equal(t, "E0308", args.Code)
+	assert.Empty(t, args.File)
+
+	// Codes may look like positions if no such file exists.
+	args, err = parseExplainTarget("missing.c:1:2", ByteColumns)
+	assert.NoError(t, err)
+	assert.Equal(t, "missing.c:1:2", args.Code)
+
+	_, err = parseExplainTarget(file+":0:1", ByteColumns)
+	assert.Error(t, err)
+}
+
+func TestHTMLToText(t *testing.T) {
+	text, err := htmlToText(`<html><head><title>x</title><style>p {}</style></head><body>
+<nav>Home | Docs</nav>
+<h1>E0308: <code>mismatched</code> types</h1>
+<p>Expected   a type,
+found another.</p>
+<pre>let x: i32 = "a";
+    x</pre>
+<ul><li>first</li><li>second <b>item</b></li></ul>
+<script>alert(1)</script>
+</body></html>`)
+	assert.NoError(t, err)
+	assert.Equal(t, "# E0308: mismatched types\n\nExpected a type, found another.\n\n```\nlet x: i32 = \"a\";\n    x\n```\n\n- first\n- second item\n", text)
+}
+
+func TestExplain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<p>Shadowing hides the <em>outer</em> variable.</p>")
+	}))
+	defer server.Close()
+
+	d := Diagnostic{
+		Location: Location{File: "/p/a.c", Line: 3, Column: 1},
+		Severity: "warning",
+		Message:  "declaration shadows a local variable",
+		Source:   "clang",
+		Code:     "-Wshadow",
+		Href:     server.URL + "/shadow",
+		Related: []RelatedInformation{
+			{Location: Location{File: "/p/a.c", Line: 1, Column: 5}, Message: "previous declaration is here"},
+		},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, explain(&out, []Diagnostic{d, d}, false))
+	assert.Equal(t, "/p/a.c:3:1: warning: declaration shadows a local variable [clang -Wshadow]\n"+
+		"    /p/a.c:1:5: previous declaration is here\n"+
+		"/p/a.c:3:1: warning: declaration shadows a local variable [clang -Wshadow]\n"+
+		"    /p/a.c:1:5: previous declaration is here\n"+
+		"\n"+server.URL+"/shadow\n\nShadowing hides the outer variable.\n", out.String())
+
+	out.Reset()
+	d.Href = server.URL + "/missing"
+	d.Related = nil
+	assert.NoError(t, explain(&out, []Diagnostic{d}, false))
+	assert.Contains(t, out.String(), "Unable to fetch the documentation")
+
+	out.Reset()
+	d.Href = ""
+	assert.NoError(t, explain(&out, []Diagnostic{d}, false))
+	assert.Contains(t, out.String(), "does not link documentation")
+}
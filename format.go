@@ -0,0 +1,190 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// formattingFlags are the flags of format and range-format.
+var formattingFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "tab-size",
+		Usage: "size of a tab in spaces",
+		Value: 4,
+	},
+	cli.BoolFlag{
+		Name:  "tabs",
+		Usage: "indent with tabs instead of spaces",
+	},
+	cli.BoolFlag{
+		Name:  "diff",
+		Usage: "print a unified diff instead of rewriting the file",
+	},
+}
+
+// formattingOptions returns the options selected by formattingFlags.
+func formattingOptions(c *cli.Context) (LsFormattingOptions, error) {
+	if c.Int("tab-size") < 1 {
+		return LsFormattingOptions{}, fmt.Errorf("--tab-size must be a positive integer, got %d", c.Int("tab-size"))
+	}
+	return LsFormattingOptions{TabSize: c.Int("tab-size"), InsertSpaces: !c.Bool("tabs")}, nil
+}
+
+// FormatArgs are the arguments of Server.Format.
+type FormatArgs struct {
+	File string
+	// Only format the 1-based lines StartLine to EndLine, inclusive, with
+	// textDocument/rangeFormatting. A zero line leaves that end of the
+	// range open.
+	Range     bool
+	StartLine int
+	EndLine   int
+	Options   LsFormattingOptions
+	// Only preview the edit instead of writing it to disk.
+	Diff bool
+}
+
+func (args FormatArgs) method() string {
+	if args.Range {
+		return "textDocument/rangeFormatting"
+	}
+	return "textDocument/formatting"
+}
+
+// lineRange returns the range of text covering the 1-based lines start to
+// end, inclusive, including the line break of end. Zero or out of range
+// lines stand for the start and end of text.
+func lineRange(text string, start, end int) LsRange {
+	lines := strings.Count(text, "\n") + 1
+	if start < 1 {
+		start = 1
+	}
+	r := LsRange{Start: LsPosition{Line: start - 1}, End: LsPosition{Line: end}}
+	if end < 1 || end >= lines {
+		// There is no line after the last one to end the range at.
+		r.End = LsPosition{Line: lines - 1}
+		for _, c := range text[strings.LastIndexByte(text, '\n')+1:] {
+			r.End.Character += utf16Len(c)
+		}
+	}
+	return r
+}
+
+// format asks the server for the edits that format args.File.
+func (l *languageServer) format(args FormatArgs) ([]LsTextEdit, error) {
+	text, err := l.openDocument(args.File)
+	if err != nil {
+		return nil, err
+	}
+	document := LsTextDocumentIdentifier{URI: pathToURI(args.File)}
+	var params interface{} = LsDocumentFormattingParams{TextDocument: document, Options: args.Options}
+	if args.Range {
+		params = LsDocumentRangeFormattingParams{
+			TextDocument: document,
+			Range:        lineRange(text, args.StartLine, args.EndLine),
+			Options:      args.Options,
+		}
+	}
+	result, err := l.call(args.method(), toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	var edits []LsTextEdit
+	if err := json.Unmarshal(result, &edits); err != nil {
+		return nil, fmt.Errorf("cannot parse %s result: %s", args.method(), err.Error())
+	}
+	return edits, nil
+}
+
+// Format formats a file. preview describes the changes, which are
+// written to disk unless args.Diff is set.
+func (s *Server) Format(args FormatArgs, preview *EditPreview) error {
+	log.Printf("CMD format %s range=%t %d..%d diff=%t", args.File, args.Range, args.StartLine, args.EndLine, args.Diff)
+	if !filepath.IsAbs(args.File) {
+		return fmt.Errorf("file must be an absolute path, got %q", args.File)
+	}
+	op := "format"
+	if args.Range {
+		op = "range-format"
+	}
+	server, err := s.serverForMethod(args.File, args.method())
+	if err != nil {
+		return err
+	}
+	if !args.Diff {
+		if err := server.checkWritable(op); err != nil {
+			return err
+		}
+	}
+	edits, err := server.format(args)
+	if err != nil {
+		return err
+	}
+	plan, err := server.planEdit("Format "+args.File, LsWorkspaceEdit{
+		Changes: map[LsDocumentURI][]LsTextEdit{pathToURI(args.File): edits},
+	})
+	if err != nil {
+		return err
+	}
+	*preview = plan.preview()
+	if args.Diff {
+		return nil
+	}
+	return server.applyPlan(op, plan)
+}
+
+// runFormat completes args with the <file> argument and formattingFlags of c
+// and formats the file.
+func runFormat(c *cli.Context, args FormatArgs) error {
+	file, err := filepath.Abs(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	if args.Options, err = formattingOptions(c); err != nil {
+		return err
+	}
+	args.File = file
+	args.Diff = c.Bool("diff")
+	var preview EditPreview
+	doRPC("Server.Format", args, &preview)
+	if gJSON {
+		return printJSON(preview)
+	}
+	if args.Diff {
+		writeDiffs(os.Stdout, preview, useColor(os.Stdout))
+	}
+	return nil
+}
+
+// writeDiffs prints the diffs of p without its label, so that they can be
+// piped to patch.
+func writeDiffs(out io.Writer, p EditPreview, color bool) {
+	for _, f := range p.Files {
+		if color {
+			fmt.Fprint(out, colorizeDiff(f.Diff))
+		} else {
+			fmt.Fprint(out, f.Diff)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineRange(t *testing.T) {
+	text := "a\nbb\nc€d"
+	pos := func(line, character int) LsPosition { return LsPosition{Line: line, Character: character} }
+	assert.Equal(t, LsRange{Start: pos(1, 0), End: pos(2, 0)}, lineRange(text, 2, 2))
+	assert.Equal(t, LsRange{Start: pos(0, 0), End: pos(2, 0)}, lineRange(text, 0, 2))
+	// The last line has no line break to end at.
+	assert.Equal(t, LsRange{Start: pos(1, 0), End: pos(2, 3)}, lineRange(text, 2, 0))
+	assert.Equal(t, LsRange{Start: pos(2, 0), End: pos(2, 3)}, lineRange(text, 3, 7))
+	assert.Equal(t, LsRange{Start: pos(0, 0), End: pos(1, 0)}, lineRange("a\n", 0, 0))
+}
@@ -0,0 +1,465 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mainLoopCalls runs functions on the daemon main loop. Server is safe to use
+// from any goroutine, but gateways such as the HTTP gateway go through the
+// main loop so that their requests keep the daemon from idling out.
+var mainLoopCalls = make(chan func())
+
+// runOnMainLoop calls f on the main loop and waits for it to finish.
+func runOnMainLoop(f func()) {
+	done := make(chan struct{})
+	mainLoopCalls <- func() {
+		f()
+		close(done)
+	}
+	<-done
+}
+
+// httpError is an error with the HTTP status to report it with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+// serveHTTP starts the HTTP gateway on addr. It serves read-only queries as
+// JSON:
+//
+//	GET /servers
+//	GET /definition?file=<path>&line=<line>&col=<col>[&unit=byte|rune|utf-16]
+//	GET /diagnostics[?file=<path>][&unit=byte|rune|utf-16]
+//	GET /hover?file=<path>&line=<line>&col=<col>[&unit=byte|rune|utf-16]
+//	GET /hover?file=<path>[&start_line=<line>][&end_line=<line>][&unit=byte|rune|utf-16]
+//	GET /inline-values?file=<path>&line=<line>&col=<col>[&start_line=<line>][&frame_id=<id>]
+//	    [&unit=byte|rune|utf-16]
+//	GET /linked-editing-ranges?file=<path>&line=<line>&col=<col>[&unit=byte|rune|utf-16]
+//	GET /moniker?file=<path>&line=<line>&col=<col>[&unit=byte|rune|utf-16]
+//	GET /prepare-rename?file=<path>&line=<line>&col=<col>[&unit=byte|rune|utf-16]
+//	GET /semantic-tokens?file=<path>[&start_line=<line>&end_line=<line>]
+//	    [&encoding=decoded|raw][&previous_result_id=<id>][&unit=byte|rune|utf-16]
+//
+// Paths must be absolute. Lines and columns are 1-based; columns count bytes
+// unless unit says otherwise. Errors are returned as {"error": "..."}, with
+// status 429 for requests over the rate limits, 421 for requests whose Host
+// is not one of the gateway's, see gatewayHosts, and 401 for requests without
+// token.
+//
+// /ws accepts WebSocket connections; see serveWebSocket.
+//
+// Requests must carry token as "Authorization: Bearer <token>", or as
+// ?token=<token> for /ws since browsers cannot set headers on WebSocket
+// connections. The token is required unless addr is a loopback address,
+// since the lsp passthrough of /ws reaches the language servers.
+func (s *Server) serveHTTP(addr, token string) error {
+	if token == "" && !isLoopbackAddress(addr) {
+		return fmt.Errorf("--http on %s, which is not a loopback address, requires --auth-token", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("Serving HTTP gateway on %s", listener.Addr())
+
+	mux := http.NewServeMux()
+	mux.Handle("/servers", gatewayHandler(s.httpServers))
+	mux.Handle("/definition", gatewayHandler(s.httpDefinition))
+	mux.Handle("/diagnostics", gatewayHandler(s.httpDiagnostics))
+	mux.Handle("/hover", gatewayHandler(s.httpHover))
+	mux.Handle("/inline-values", gatewayHandler(s.httpInlineValues))
+	mux.Handle("/linked-editing-ranges", gatewayHandler(s.httpLinkedEditingRanges))
+	mux.Handle("/moniker", gatewayHandler(s.httpMoniker))
+	mux.Handle("/prepare-rename", gatewayHandler(s.httpPrepareRename))
+	mux.Handle("/semantic-tokens", gatewayHandler(s.httpSemanticTokens))
+	mux.HandleFunc("/ws", s.serveWebSocket)
+	go func() {
+		log.Printf("HTTP gateway stopped: %s", http.Serve(listener, hostChecked(gatewayHosts(addr), tokenChecked(token, rateLimited(mux)))).Error())
+	}()
+	return nil
+}
+
+// gatewayHosts returns the host names under which the gateway listening on
+// addr may be reached: loopback, the host of addr unless it listens on every
+// interface, and those in --http-hosts.
+func gatewayHosts(addr string) map[string]bool {
+	hosts := map[string]bool{"localhost": true}
+	if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsUnspecified() {
+			hosts[strings.ToLower(host)] = true
+		}
+	}
+	for _, host := range strings.Split(gHTTPHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts[strings.ToLower(host)] = true
+		}
+	}
+	return hosts
+}
+
+// allowedHost returns if the Host header of a request, with or without a
+// port, names the gateway. Loopback addresses are always allowed.
+func allowedHost(hosts map[string]bool, header string) bool {
+	host, _, err := net.SplitHostPort(header)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(header, "["), "]")
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	return hosts[strings.ToLower(host)]
+}
+
+// hostChecked fails requests to handler whose Host is not one of hosts with
+// 421. A page on another site whose name was rebound to the address of the
+// gateway sends its own name, which guards the queries, and the lsp
+// passthrough of the WebSocket endpoint, against DNS rebinding.
+func hostChecked(hosts map[string]bool, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(hosts, r.Host) {
+			log.Printf("Rejecting HTTP %s %s for host %q; allow it with --http-hosts", r.Method, r.URL.Path, r.Host)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMisdirectedRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("unknown host %q", r.Host)})
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// tokenChecked fails requests to handler that do not carry token with 401.
+// It is sent as a bearer token, or in the query of /ws. Every request passes
+// if token is empty.
+func tokenChecked(token string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if r.URL.Path == "/ws" && given == "" {
+			given = r.URL.Query().Get("token")
+		}
+		if token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			log.Printf("Rejecting HTTP %s %s from %s: invalid or missing auth token", r.Method, r.URL.Path, r.RemoteAddr)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid or missing auth token"})
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// rateLimited fails requests to handler over the rate limits with 429. The
+// requests sent over a WebSocket connection are limited one by one instead.
+func rateLimited(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		release, err := gRateLimits.admit(addressClient("http", r.RemoteAddr))
+		if err != nil {
+			log.Printf("Rejecting HTTP %s %s: %s", r.Method, r.URL, err.Error())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		defer release()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// gatewayHandler answers a query with a JSON-serializable value. It serves
+// both HTTP requests and requests sent over the WebSocket endpoint.
+type gatewayHandler func(query url.Values) (interface{}, error)
+
+func (h gatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("HTTP %s %s", r.Method, r.URL)
+
+	var result interface{}
+	err := error(&httpError{http.StatusMethodNotAllowed, fmt.Errorf("%s is not supported", r.Method)})
+	if r.Method == http.MethodGet {
+		result, err = h(r.URL.Query())
+	}
+
+	switch fault, delay := gChaos.pick(true); fault {
+	case chaosDelay:
+		time.Sleep(delay)
+	case chaosDrop:
+		log.Printf("chaos: dropping response to %s", r.URL)
+		// Closes the connection without a response.
+		panic(http.ErrAbortHandler)
+	case chaosError:
+		err = &httpError{http.StatusServiceUnavailable, chaosErr}
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusBadGateway
+		if e, isHTTP := err.(*httpError); isHTTP {
+			status = e.status
+		}
+		result = map[string]string{"error": err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// queryFile returns the file query parameter and the language server that
+// method is routed to for it.
+func (s *Server) queryFile(query url.Values, method string) (string, *languageServer, error) {
+	file, servers, err := s.queryServers(query, method)
+	if err != nil {
+		return "", nil, err
+	}
+	return file, servers[0], nil
+}
+
+// queryServers is queryFile for methods whose results are merged.
+func (s *Server) queryServers(query url.Values, method string) (string, []*languageServer, error) {
+	file := query.Get("file")
+	if !filepath.IsAbs(file) {
+		return "", nil, badRequest("file must be an absolute path, got %q", file)
+	}
+	file = filepath.Clean(file)
+
+	var servers []*languageServer
+	var err error
+	runOnMainLoop(func() {
+		servers, err = s.serversForRequest(file, method, query.Get("group"))
+	})
+	if err != nil {
+		return "", nil, &httpError{http.StatusNotFound, err}
+	}
+	return file, servers, nil
+}
+
+func queryInt(query url.Values, name string) (int, error) {
+	value, err := strconv.Atoi(query.Get(name))
+	if err != nil || value < 1 {
+		return 0, badRequest("%s must be a positive integer, got %q", name, query.Get(name))
+	}
+	return value, nil
+}
+
+func queryUnit(query url.Values) (ColumnUnit, error) {
+	unit := query.Get("unit")
+	if unit == "" {
+		return ByteColumns, nil
+	}
+	u, err := parseColumnUnit(unit)
+	if err != nil {
+		return "", &httpError{http.StatusBadRequest, err}
+	}
+	return u, nil
+}
+
+func (s *Server) httpServers(query url.Values) (interface{}, error) {
+	servers := []ServerInfo{}
+	runOnMainLoop(func() {
+		for _, server := range s.serverList() {
+			servers = append(servers, server.info())
+		}
+	})
+	return servers, nil
+}
+
+// queryPosition returns the file, line, col and unit query parameters and the
+// language server that method is routed to for the file.
+func (s *Server) queryPosition(query url.Values, method string) (PositionArgs, *languageServer, error) {
+	position, servers, err := s.queryPositionServers(query, method)
+	if err != nil {
+		return PositionArgs{}, nil, err
+	}
+	return position, servers[0], nil
+}
+
+// queryPositionServers is queryPosition for methods whose results are merged.
+func (s *Server) queryPositionServers(query url.Values, method string) (PositionArgs, []*languageServer, error) {
+	file, servers, err := s.queryServers(query, method)
+	if err != nil {
+		return PositionArgs{}, nil, err
+	}
+	line, err := queryInt(query, "line")
+	if err != nil {
+		return PositionArgs{}, nil, err
+	}
+	column, err := queryInt(query, "col")
+	if err != nil {
+		return PositionArgs{}, nil, err
+	}
+	unit, err := queryUnit(query)
+	if err != nil {
+		return PositionArgs{}, nil, err
+	}
+	return PositionArgs{File: file, Line: line, Column: column, Unit: unit}, servers, nil
+}
+
+func (s *Server) httpDefinition(query url.Values) (interface{}, error) {
+	position, servers, err := s.queryPositionServers(query, "textDocument/definition")
+	if err != nil {
+		return nil, err
+	}
+
+	var r *rewriter
+	runOnMainLoop(func() {
+		r = s.rewriter(true)
+	})
+	locations, err := definitions(servers, position, r)
+	if locations == nil {
+		locations = []Location{}
+	}
+	return locations, err
+}
+
+func (s *Server) httpDiagnostics(query url.Values) (interface{}, error) {
+	unit, err := queryUnit(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Diagnostics of every server covering a file are merged.
+	if file := query.Get("file"); file != "" {
+		if !filepath.IsAbs(file) {
+			return nil, badRequest("file must be an absolute path, got %q", file)
+		}
+		file = filepath.Clean(file)
+		var servers []*languageServer
+		runOnMainLoop(func() {
+			servers, err = s.serversForRequest(file, diagnosticsMethod, query.Get("group"))
+		})
+		if err != nil {
+			return nil, &httpError{http.StatusNotFound, err}
+		}
+		var lists [][]Diagnostic
+		for _, server := range servers {
+			diagnostics, err := server.fileDiagnostics(file, unit)
+			if err != nil {
+				return nil, err
+			}
+			lists = append(lists, diagnostics)
+		}
+		return mergeDiagnostics(lists...), nil
+	}
+
+	var servers []*languageServer
+	runOnMainLoop(func() {
+		servers = s.serverList()
+	})
+	all := make(map[string][]Diagnostic)
+	for _, server := range servers {
+		for file, diagnostics := range server.allDiagnostics(unit) {
+			all[file] = mergeDiagnostics(all[file], diagnostics)
+		}
+	}
+	return all, nil
+}
+
+// httpSemanticTokens returns the semantic tokens of a file, or of the lines
+// between start_line and end_line. Tokens are decoded to positions unless
+// encoding is raw, in which case they are returned in the LSP encoding along
+// with the legend. Raw requests for the whole file may pass the result_id of
+// an earlier response as previous_result_id to only receive the edits to it.
+func (s *Server) httpSemanticTokens(query url.Values) (interface{}, error) {
+	file, server, err := s.queryFile(query, "textDocument/semanticTokens")
+	if err != nil {
+		return nil, err
+	}
+	unit, err := queryUnit(query)
+	if err != nil {
+		return nil, err
+	}
+	encoding := query.Get("encoding")
+	if encoding != "" && encoding != "decoded" && encoding != "raw" {
+		return nil, badRequest("encoding must be decoded or raw, got %q", encoding)
+	}
+
+	type decoded struct {
+		ResultID string          `json:"result_id,omitempty"`
+		Tokens   []SemanticToken `json:"tokens"`
+	}
+	type raw struct {
+		ResultID string                 `json:"result_id,omitempty"`
+		Legend   LsSemanticTokensLegend `json:"legend"`
+		Data     []uint32               `json:"data"`
+	}
+	type delta struct {
+		ResultID string                 `json:"result_id"`
+		Edits    []LsSemanticTokensEdit `json:"edits"`
+	}
+	legend := func() LsSemanticTokensLegend {
+		if provider := server.semanticTokensProvider(); provider != nil {
+			return provider.legend
+		}
+		return LsSemanticTokensLegend{}
+	}
+
+	if query.Get("start_line") != "" || query.Get("end_line") != "" {
+		start, err := queryInt(query, "start_line")
+		if err != nil {
+			return nil, err
+		}
+		end, err := queryInt(query, "end_line")
+		if err != nil {
+			return nil, err
+		}
+		data, err := server.semanticTokensRange(file, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if encoding == "raw" {
+			return raw{Legend: legend(), Data: data}, nil
+		}
+		return decoded{Tokens: server.decodeSemanticTokens(file, data, legend(), unit)}, nil
+	}
+
+	previous, current, err := server.semanticTokens(file)
+	if err != nil {
+		return nil, err
+	}
+	if encoding != "raw" {
+		return decoded{ResultID: current.id, Tokens: server.decodeSemanticTokens(file, current.data, legend(), unit)}, nil
+	}
+	switch query.Get("previous_result_id") {
+	case "":
+	case current.id:
+		return delta{ResultID: current.id, Edits: []LsSemanticTokensEdit{}}, nil
+	case previous.id:
+		return delta{ResultID: current.id, Edits: diffSemanticTokens(previous.data, current.data)}, nil
+	}
+	return raw{ResultID: current.id, Legend: legend(), Data: current.data}, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatewayHosts(t *testing.T) {
+	defer func(hosts string) { gHTTPHosts = hosts }(gHTTPHosts)
+	gHTTPHosts = "devbox.internal, Build-Host"
+
+	hosts := gatewayHosts("0.0.0.0:7658")
+	assert.True(t, allowedHost(hosts, "localhost:7658"))
+	assert.True(t, allowedHost(hosts, "127.0.0.1:7658"))
+	assert.True(t, allowedHost(hosts, "[::1]:7658"))
+	assert.True(t, allowedHost(hosts, "devbox.internal:7658"))
+	assert.True(t, allowedHost(hosts, "build-host"))
+	assert.False(t, allowedHost(hosts, "0.0.0.0:7658"))
+	// A page of evil.com rebound to 127.0.0.1 still sends its own name.
+	assert.False(t, allowedHost(hosts, "evil.com:7658"))
+	assert.False(t, allowedHost(hosts, ""))
+
+	hosts = gatewayHosts("10.0.0.5:7658")
+	assert.True(t, allowedHost(hosts, "10.0.0.5:7658"))
+	assert.False(t, allowedHost(hosts, "10.0.0.6:7658"))
+}
+
+func TestHostChecked(t *testing.T) {
+	handler := hostChecked(gatewayHosts("localhost:7658"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for host, status := range map[string]int{
+		"localhost:7658": http.StatusOK,
+		"127.0.0.1:7658": http.StatusOK,
+		"evil.com:7658":  http.StatusMisdirectedRequest,
+	} {
+		for _, path := range []string{"/hover?file=/src/a.c&line=1&col=1", "/diagnostics", "/ws"} {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Host = host
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, status, w.Code, host+path)
+		}
+	}
+}
+
+func TestTokenChecked(t *testing.T) {
+	handler := tokenChecked("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, test := range []struct {
+		path, authorization string
+		status              int
+	}{
+		{"/diagnostics", "Bearer secret", http.StatusOK},
+		{"/diagnostics", "", http.StatusUnauthorized},
+		{"/diagnostics", "Bearer wrong", http.StatusUnauthorized},
+		// Only /ws takes the token from the query.
+		{"/diagnostics?token=secret", "", http.StatusUnauthorized},
+		{"/ws?token=secret", "", http.StatusOK},
+		{"/ws?token=wrong", "", http.StatusUnauthorized},
+		{"/ws", "Bearer secret", http.StatusOK},
+	} {
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.authorization != "" {
+			r.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, test.status, w.Code, test.path+" "+test.authorization)
+	}
+
+	// Without a token every request passes.
+	handler = tokenChecked("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/diagnostics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServeHTTPRequiresToken(t *testing.T) {
+	s := &Server{config: &Config{}}
+	assert.EqualError(t, s.serveHTTP("0.0.0.0:0", ""), "--http on 0.0.0.0:0, which is not a loopback address, requires --auth-token")
+	assert.NoError(t, s.serveHTTP("127.0.0.1:0", ""))
+}
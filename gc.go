@@ -0,0 +1,85 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Values for --gc-policy.
+const (
+	// Return memory to the OS once the daemon has been idle for a while.
+	gcPolicyIdle = "idle"
+	// Collect after every request. Keeps memory lowest but adds latency.
+	gcPolicyRequest = "request"
+	// Leave it to the Go runtime.
+	gcPolicyOff = "off"
+)
+
+// gcScheduler runs garbage collection according to --gc-policy.
+type gcScheduler struct {
+	policy string
+	delay  time.Duration
+	// Fires once the daemon has been idle for delay. Only used by the idle
+	// policy.
+	idle *time.Timer
+}
+
+func newGCScheduler(policy string, delay time.Duration) (*gcScheduler, error) {
+	g := &gcScheduler{policy: policy, delay: delay}
+	switch policy {
+	case gcPolicyIdle:
+		g.idle = time.NewTimer(delay)
+	case gcPolicyRequest, gcPolicyOff:
+	default:
+		return nil, fmt.Errorf("unknown gc policy %q; expected %s, %s or %s", policy, gcPolicyIdle, gcPolicyRequest, gcPolicyOff)
+	}
+	return g, nil
+}
+
+// idleC fires when an idle collection is due. It is nil, and so blocks
+// forever, unless the policy is idle.
+func (g *gcScheduler) idleC() <-chan time.Time {
+	if g.idle == nil {
+		return nil
+	}
+	return g.idle.C
+}
+
+// requestHandled is called after the daemon finished serving a client.
+func (g *gcScheduler) requestHandled() {
+	switch g.policy {
+	case gcPolicyIdle:
+		if !g.idle.Stop() {
+			select {
+			case <-g.idle.C:
+			default:
+			}
+		}
+		g.idle.Reset(g.delay)
+	case gcPolicyRequest:
+		runtime.GC()
+	}
+}
+
+// collectIdle is called when idleC fires.
+func (g *gcScheduler) collectIdle() {
+	log.Print("Idle; returning memory to the OS")
+	debug.FreeOSMemory()
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGCScheduler(t *testing.T) {
+	for _, policy := range []string{gcPolicyIdle, gcPolicyRequest, gcPolicyOff} {
+		g, err := newGCScheduler(policy, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, policy, g.policy)
+		// Only the idle policy has a timer.
+		assert.Equal(t, policy == gcPolicyIdle, g.idleC() != nil, policy)
+	}
+
+	_, err := newGCScheduler("always", time.Minute)
+	assert.EqualError(t, err, `unknown gc policy "always"; expected idle, request or off`)
+	_, err = newGCScheduler("", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestGCSchedulerIdle(t *testing.T) {
+	g, err := newGCScheduler(gcPolicyIdle, 50*time.Millisecond)
+	assert.NoError(t, err)
+	select {
+	case <-g.idleC():
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle collection is not due after the delay")
+	}
+	g.collectIdle()
+
+	// Each request restarts the delay, also when the timer fired without
+	// being received.
+	g.delay = time.Hour
+	g.requestHandled()
+	select {
+	case <-g.idleC():
+		t.Fatal("idle collection is due right after a request")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	g.delay = 50 * time.Millisecond
+	g.requestHandled()
+	time.Sleep(100 * time.Millisecond)
+	g.delay = time.Hour
+	g.requestHandled()
+	select {
+	case <-g.idleC():
+		t.Fatal("a fired timer is not drained by a request")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGCSchedulerRequest(t *testing.T) {
+	g, err := newGCScheduler(gcPolicyRequest, time.Minute)
+	assert.NoError(t, err)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	g.requestHandled()
+	runtime.ReadMemStats(&after)
+	assert.True(t, after.NumGC > before.NumGC)
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+// GroupConfig names servers that are queried together, ie,
+//
+//	[group.python]
+//	servers = ["pyright", "ruff-lsp"]
+//
+// Commands run with --group python send their request to every server of the
+// group running for the file and merge the results, labeled with the server
+// each one came from.
+type GroupConfig struct {
+	// Configured languages or program names of the servers, in the order
+	// their results are listed.
+	Servers []string `toml:"servers"`
+}
+
+func (g GroupConfig) validate() map[string]error {
+	problems := make(map[string]error)
+	if len(g.Servers) == 0 {
+		problems["servers"] = fmt.Errorf("must not be empty")
+	}
+	return problems
+}
+
+// groupFlag selects a server group for commands that merge results.
+var groupFlag = cli.StringFlag{
+	Name:  "group",
+	Usage: "send the request to every server of this group in the config and merge the results",
+}
+
+// serversForGroup returns the servers of group running for the project
+// directory that contains path.
+func (s *Server) serversForGroup(path, group string) ([]*languageServer, error) {
+	g, has := s.currentConfig().Groups[group]
+	if !has {
+		return nil, fmt.Errorf("no server group named %q in the config", group)
+	}
+	running, err := s.serversForFile(path)
+	if err != nil {
+		return nil, err
+	}
+	members := serversNamed(g.Servers, running)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no server of group %s is running for %s", group, path)
+	}
+	return members, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupConfigValidate(t *testing.T) {
+	assert.Empty(t, GroupConfig{Servers: []string{"pyright", "ruff-lsp"}}.validate())
+	assert.Contains(t, GroupConfig{}.validate(), "servers")
+
+	config := &Config{Groups: map[string]GroupConfig{"python": {}, "go": {Servers: []string{"gopls"}}, "c": {}}}
+	assert.Equal(t, []string{
+		"config.toml: group.c.servers: must not be empty",
+		"config.toml: group.python.servers: must not be empty",
+	}, config.validate("config.toml"))
+}
+
+func TestServersNamed(t *testing.T) {
+	pyright := &languageServer{args: []string{"/usr/bin/pyright-langserver"}, language: "python"}
+	ruff := &languageServer{args: []string{"ruff-lsp"}}
+	gopls := &languageServer{args: []string{"gopls"}, language: "go"}
+	running := []*languageServer{pyright, ruff, gopls}
+
+	assert.Equal(t, []*languageServer{ruff, pyright}, serversNamed([]string{"ruff-lsp", "python", "pyright-langserver"}, running))
+	assert.Empty(t, serversNamed([]string{"mypy"}, running))
+}
+
+func TestCodeActionServerLabel(t *testing.T) {
+	action := CodeAction{Title: "Organize imports", Kind: "source.organizeImports", Server: "ruff-lsp"}
+	assert.Equal(t, "[source.organizeImports] Organize imports (ruff-lsp)", action.String())
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative controlpb/control.proto
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jacobdufault/lspc/controlpb"
+	easyjson "github.com/mailru/easyjson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// controlServer implements the gRPC control-plane API defined in
+// controlpb/control.proto.
+type controlServer struct {
+	controlpb.UnimplementedControlServer
+	server *Server
+}
+
+// grpcAuditEntry starts an audit log entry for a call to method. The
+// arguments of streaming calls are not recorded.
+func grpcAuditEntry(ctx context.Context, method string, req interface{}) auditEntry {
+	entry := auditEntry{Time: time.Now(), Peer: auditPeer{Transport: "grpc"}, Method: method}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.Peer.Addr = p.Addr.String()
+	}
+	if m, ok := req.(proto.Message); ok {
+		if args, err := protojson.Marshal(m); err == nil {
+			entry.Args = args
+		}
+	}
+	return entry
+}
+
+func recordGRPC(entry auditEntry, err error) {
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	entry.Duration = time.Since(entry.Time)
+	gAudit.record(entry)
+}
+
+// checkGRPCToken fails calls that do not send token, the --auth-token of the
+// daemon, as the authorization metadata "Bearer <token>". Without a token
+// every call is accepted.
+func checkGRPCToken(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		given := strings.TrimPrefix(value, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid or missing auth token")
+}
+
+// serveGRPC starts the gRPC control-plane API on addr. Calls must carry
+// token, which is required unless addr is a loopback address, since Start
+// runs any command. Unary calls are rate limited; streams, which last as long
+// as the client watches, are not.
+func (s *Server) serveGRPC(addr, token string) error {
+	if token == "" && !isLoopbackAddress(addr) {
+		return fmt.Errorf("--grpc on %s, which is not a loopback address, requires --auth-token", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("Serving gRPC on %s", listener.Addr())
+
+	g := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			log.Printf("GRPC %s", info.FullMethod)
+			entry := grpcAuditEntry(ctx, info.FullMethod, req)
+			if err := checkGRPCToken(ctx, token); err != nil {
+				log.Printf("Rejecting %s: %s", info.FullMethod, err.Error())
+				recordGRPC(entry, err)
+				return nil, err
+			}
+			release, err := gRateLimits.admit(addressClient("grpc", entry.Peer.Addr))
+			if err != nil {
+				log.Printf("Rejecting %s: %s", info.FullMethod, err.Error())
+				err = status.Error(codes.ResourceExhausted, err.Error())
+				recordGRPC(entry, err)
+				return nil, err
+			}
+			defer release()
+			reply, err := handler(ctx, req)
+			recordGRPC(entry, err)
+			return reply, err
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			log.Printf("GRPC %s", info.FullMethod)
+			entry := grpcAuditEntry(ss.Context(), info.FullMethod, nil)
+			if err := checkGRPCToken(ss.Context(), token); err != nil {
+				log.Printf("Rejecting %s: %s", info.FullMethod, err.Error())
+				recordGRPC(entry, err)
+				return err
+			}
+			err := handler(srv, ss)
+			recordGRPC(entry, err)
+			return err
+		}))
+	controlpb.RegisterControlServer(g, &controlServer{server: s})
+	go func() {
+		if err := g.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %s", err.Error())
+		}
+	}()
+	return nil
+}
+
+func (c *controlServer) Start(ctx context.Context, req *controlpb.StartRequest) (*controlpb.StartResponse, error) {
+	if !filepath.IsAbs(req.Directory) {
+		return nil, status.Errorf(codes.InvalidArgument, "directory must be an absolute path, got %q", req.Directory)
+	}
+	args := StartArgs{
+		Bin:       req.Command,
+		Directory: filepath.Clean(req.Directory),
+		InitOpts:  easyjson.RawMessage(req.InitOptions),
+		Language:  req.Language,
+	}
+
+	var ls *languageServer
+	var err error
+	runOnMainLoop(func() {
+		ls, err = c.server.start(args)
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &controlpb.StartResponse{Server: serverProto(ls.info())}, nil
+}
+
+func (c *controlServer) Stop(ctx context.Context, req *controlpb.StopRequest) (*controlpb.StopResponse, error) {
+	var ls *languageServer
+	var err error
+	runOnMainLoop(func() {
+		ls, err = c.server.stopServer(int(req.Id))
+	})
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	ls.shutdown(closeTimeout)
+	return &controlpb.StopResponse{ExitStatus: ls.exitStatus}, nil
+}
+
+func (c *controlServer) List(ctx context.Context, req *controlpb.ListRequest) (*controlpb.ListResponse, error) {
+	response := &controlpb.ListResponse{}
+	runOnMainLoop(func() {
+		for _, server := range c.server.servers {
+			response.Servers = append(response.Servers, serverProto(server.info()))
+		}
+	})
+	return response, nil
+}
+
+func (c *controlServer) Status(ctx context.Context, req *controlpb.StatusRequest) (*controlpb.StatusResponse, error) {
+	var stats []ServerStats
+	var queues []QueueStats
+	runOnMainLoop(func() {
+		c.server.Stats(false, &stats)
+		c.server.Queues(false, &queues)
+	})
+
+	ids := idSet(req.Ids)
+	response := &controlpb.StatusResponse{}
+	for _, s := range stats {
+		if ids.has(s.ID) {
+			response.Servers = append(response.Servers, serverStatusProto(s))
+		}
+	}
+	for _, q := range queues {
+		response.Queues = append(response.Queues, queueProto(q))
+	}
+	return response, nil
+}
+
+func (c *controlServer) StreamEvents(req *controlpb.StreamEventsRequest, stream controlpb.Control_StreamEventsServer) error {
+	sub := daemonEvents.subscribe()
+	defer daemonEvents.unsubscribe(sub)
+
+	ids := idSet(req.Ids)
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-sub.c:
+			if !ids.has(e.Server.ID) {
+				continue
+			}
+			event := eventProto(e)
+			if event.Kind == nil {
+				// Not part of the gRPC protocol, ie, applyEdit.
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// idSet filters by server id. An empty set contains every id.
+type idSet []int32
+
+func (ids idSet) has(id int) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, i := range ids {
+		if int(i) == id {
+			return true
+		}
+	}
+	return false
+}
+
+func serverProto(info ServerInfo) *controlpb.Server {
+	return &controlpb.Server{
+		Id:        int32(info.ID),
+		Pid:       int32(info.Pid),
+		Args:      info.Args,
+		Directory: info.Directory,
+		Language:  info.Language,
+		Version:   info.Version,
+	}
+}
+
+func queueProto(q QueueStats) *controlpb.Queue {
+	return &controlpb.Queue{
+		Name:     q.Name,
+		Depth:    int64(q.Depth),
+		Capacity: int64(q.Capacity),
+		Blocked:  int64(q.Blocked),
+		Dropped:  int64(q.Dropped),
+	}
+}
+
+func progressProto(p ProgressInfo) *controlpb.Progress {
+	return &controlpb.Progress{
+		Token:      p.Token,
+		Title:      p.Title,
+		Message:    p.Message,
+		Percentage: int32(p.Percentage),
+	}
+}
+
+func diagnosticsProto(d DiagnosticsEvent) *controlpb.Diagnostics {
+	return &controlpb.Diagnostics{
+		Uri:      string(d.URI),
+		Errors:   int32(d.Errors),
+		Warnings: int32(d.Warnings),
+		Other:    int32(d.Other),
+		Time:     timestamppb.New(d.Time),
+	}
+}
+
+func serverStatusProto(s ServerStats) *controlpb.ServerStatus {
+	status := &controlpb.ServerStatus{
+		Server:  serverProto(s.ServerInfo),
+		Pending: queueProto(s.Pending),
+	}
+	for _, m := range s.Methods {
+		status.Methods = append(status.Methods, &controlpb.MethodStats{
+			Method: m.Method,
+			Count:  int64(m.Count),
+			Errors: int64(m.Errors),
+			Total:  durationpb.New(m.Total),
+			Max:    durationpb.New(m.Max),
+		})
+	}
+	for _, p := range s.Progress {
+		status.Progress = append(status.Progress, progressProto(p))
+	}
+	for _, d := range s.Diagnostics {
+		status.Diagnostics = append(status.Diagnostics, diagnosticsProto(d))
+	}
+	return status
+}
+
+func eventProto(e DaemonEvent) *controlpb.Event {
+	event := &controlpb.Event{
+		Time:    timestamppb.New(e.Time),
+		Server:  serverProto(e.Server),
+		Dropped: int64(e.Dropped),
+	}
+	switch e.Kind {
+	case EventServerStarted:
+		event.Kind = &controlpb.Event_Started{Started: &controlpb.ServerStarted{}}
+	case EventServerStopped:
+		event.Kind = &controlpb.Event_Stopped{Stopped: &controlpb.ServerStopped{ExitStatus: e.ExitStatus}}
+	case EventDiagnostics:
+		event.Kind = &controlpb.Event_Diagnostics{Diagnostics: diagnosticsProto(*e.Diagnostics)}
+	case EventProgress:
+		event.Kind = &controlpb.Event_Progress{Progress: &controlpb.ProgressUpdated{
+			Progress: progressProto(*e.Progress),
+			Done:     e.Done,
+		}}
+	case EventMessage:
+		event.Kind = &controlpb.Event_Message{Message: &controlpb.MessageShown{
+			Type:    int32(e.Message.Type),
+			Message: e.Message.Message,
+		}}
+	}
+	return event
+}
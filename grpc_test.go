@@ -0,0 +1,72 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestEventProto(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	server := ServerInfo{ID: 2, Pid: 10, Args: []string{"clangd"}, Directory: "/src", Language: "cpp"}
+
+	e := eventProto(DaemonEvent{Time: now, Kind: EventProgress, Server: server, Dropped: 1,
+		Progress: &ProgressInfo{Token: "index", Title: "Indexing", Percentage: 40}})
+	assert.Equal(t, now, e.Time.AsTime().Local())
+	assert.Equal(t, int32(2), e.Server.Id)
+	assert.Equal(t, "cpp", e.Server.Language)
+	assert.Equal(t, int64(1), e.Dropped)
+	assert.Equal(t, "Indexing", e.GetProgress().Progress.Title)
+	assert.Equal(t, int32(40), e.GetProgress().Progress.Percentage)
+	assert.False(t, e.GetProgress().Done)
+
+	e = eventProto(DaemonEvent{Kind: EventServerStopped, Server: server, ExitStatus: "exit status 1"})
+	assert.Equal(t, "exit status 1", e.GetStopped().ExitStatus)
+	assert.Nil(t, e.GetProgress())
+
+	e = eventProto(DaemonEvent{Kind: EventDiagnostics, Server: server,
+		Diagnostics: &DiagnosticsEvent{URI: "file:///src/a.c", Errors: 1, Warnings: 2}})
+	assert.Equal(t, "file:///src/a.c", e.GetDiagnostics().Uri)
+	assert.Equal(t, int32(2), e.GetDiagnostics().Warnings)
+}
+
+func TestIDSet(t *testing.T) {
+	assert.True(t, idSet(nil).has(3))
+	assert.True(t, idSet{1, 3}.has(3))
+	assert.False(t, idSet{1, 3}.has(2))
+}
+
+func TestCheckGRPCToken(t *testing.T) {
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	assert.NoError(t, checkGRPCToken(context.Background(), ""))
+	assert.NoError(t, checkGRPCToken(withToken("secret"), "secret"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(checkGRPCToken(withToken("guess"), "secret")))
+	assert.Equal(t, codes.Unauthenticated, status.Code(checkGRPCToken(context.Background(), "secret")))
+}
+
+func TestServeGRPCRequiresToken(t *testing.T) {
+	s := &Server{config: &Config{}}
+	assert.Error(t, s.serveGRPC("0.0.0.0:0", ""))
+	assert.Error(t, s.serveGRPC(":0", ""))
+}
@@ -0,0 +1,332 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mailru/easyjson"
+)
+
+// Hover is the documentation of a symbol as shown to users.
+type Hover struct {
+	Location
+	// The name and kind of the symbol, when hovering the symbols of a file.
+	Symbol string `json:"symbol,omitempty"`
+	Kind   string `json:"kind,omitempty"`
+	// Markdown.
+	Contents string `json:"contents"`
+}
+
+// String formats h as its location and symbol followed by the documentation
+// indented by four spaces, so that a list of hovers can be split apart again.
+func (h Hover) String() string {
+	header := h.Location.String()
+	if h.Symbol != "" {
+		header += " " + h.Symbol
+	}
+	return header + "\n    " + strings.Replace(h.Contents, "\n", "\n    ", -1)
+}
+
+// HoverArgs selects what to hover. Either the position, or if Symbols is
+// set every symbol in File whose name starts between the 1-based StartLine
+// and EndLine. A zero line leaves that end of the range open.
+type HoverArgs struct {
+	PositionArgs
+	Symbols   bool
+	StartLine int
+	EndLine   int
+}
+
+// parseLineRange parses a range of 1-based lines written as <start>..<end>.
+// Either end may be left out.
+func parseLineRange(s string) (start, end int, err error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected <start>..<end>, got %q", s)
+	}
+	bounds := []*int{&start, &end}
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		line, err := strconv.Atoi(part)
+		if err != nil || line < 1 {
+			return 0, 0, fmt.Errorf("lines must be positive integers, got %q", s)
+		}
+		*bounds[i] = line
+	}
+	if end != 0 && end < start {
+		return 0, 0, fmt.Errorf("range %q ends before it starts", s)
+	}
+	return start, end, nil
+}
+
+// hoverText converts the contents of a Hover to markdown.
+func hoverText(contents easyjson.RawMessage) (string, error) {
+	markdown, err := hoverMarkup(contents)
+	if err != nil {
+		return "", err
+	}
+	var markup LsMarkupContent
+	if err := json.Unmarshal(markdown, &markup); err != nil {
+		return "", err
+	}
+	return markup.Value, nil
+}
+
+// markdownText converts the markdown of a hover to plain text. Code fences,
+// heading markers, rules, the backticks of inline code, bold markers and
+// backslash escapes are removed; code blocks are kept as is.
+func markdownText(markdown string) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, line)
+			continue
+		}
+		if trimmed == "---" || trimmed == "***" || trimmed == "___" {
+			out = append(out, "")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			line = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+		out = append(out, markdownInline.Replace(line))
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+// markdownInline removes the markup within a line of markdown.
+var markdownInline = strings.NewReplacer(
+	"`", "", "**", "",
+	`\\`, `\`, "\\`", "`", `\*`, "*", `\_`, "_", `\#`, "#", `\[`, "[", `\]`, "]",
+	`\<`, "<", `\>`, ">", `\(`, "(", `\)`, ")", `\-`, "-", `\.`, ".", `\!`, "!", `\|`, "|",
+)
+
+// hoverAt sends textDocument/hover. Returns nil if the server has nothing to
+// show.
+func (l *languageServer) hoverAt(params LsTextDocumentPositionParams) (*LsHover, error) {
+	result, err := l.call("textDocument/hover", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	if string(result) == "null" {
+		return nil, nil
+	}
+	hover := LsHover{}
+	if err := fromJSON(result, &hover); err != nil {
+		return nil, fmt.Errorf("cannot parse hover: %s", err.Error())
+	}
+	if len(hover.Contents) == 0 || string(hover.Contents) == "null" {
+		return nil, nil
+	}
+	return &hover, nil
+}
+
+// hover returns the documentation of the symbol at the 1-based line and
+// column of path, or nil if there is none.
+func (l *languageServer) hover(path string, line, column int, unit ColumnUnit) (*Hover, error) {
+	params, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return nil, err
+	}
+	hover, err := l.hoverAt(params)
+	if hover == nil {
+		return nil, err
+	}
+	text, err := hoverText(hover.Contents)
+	if err != nil {
+		return nil, err
+	}
+	r := LsRange{Start: params.Position, End: params.Position}
+	if hover.Range != nil {
+		r = *hover.Range
+	}
+	return &Hover{Location: l.userRange(path, r, unit), Contents: text}, nil
+}
+
+// documentSymbols returns the symbols of path. Flat SymbolInformation results
+// are converted to DocumentSymbols without children.
+func (l *languageServer) documentSymbols(path string) ([]LsDocumentSymbol, error) {
+	symbols, flat, err := l.documentSymbolsResult(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, information := range flat {
+		symbols = append(symbols, LsDocumentSymbol{
+			Name:           information.Name,
+			Kind:           information.Kind,
+			Range:          information.Location.Range,
+			SelectionRange: information.Location.Range,
+		})
+	}
+	return symbols, nil
+}
+
+// documentSymbolsResult sends textDocument/documentSymbol for path. Servers
+// answer with either hierarchical DocumentSymbols or flat SymbolInformation,
+// which is returned as flat.
+func (l *languageServer) documentSymbolsResult(path string) (symbols []LsDocumentSymbol, flat []LsSymbolInformation, err error) {
+	if _, err := l.openDocument(path); err != nil {
+		return nil, nil, err
+	}
+	params := LsDocumentSymbolParams{TextDocument: LsTextDocumentIdentifier{URI: pathToURI(path)}}
+	result, err := l.call("textDocument/documentSymbol", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, nil, fmt.Errorf("cannot parse document symbols: %s", err.Error())
+	}
+	for _, r := range raw {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			return nil, nil, fmt.Errorf("unexpected symbol %s", string(r))
+		}
+		if _, isFlat := fields["location"]; isFlat {
+			information := LsSymbolInformation{}
+			if err := fromJSON(r, &information); err != nil {
+				return nil, nil, err
+			}
+			flat = append(flat, information)
+			continue
+		}
+		symbol := LsDocumentSymbol{}
+		if err := fromJSON(r, &symbol); err != nil {
+			return nil, nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, flat, nil
+}
+
+// flattenSymbols lists symbols and all of their children, parents first.
+func flattenSymbols(symbols []LsDocumentSymbol) []LsDocumentSymbol {
+	var out []LsDocumentSymbol
+	for _, symbol := range symbols {
+		out = append(out, symbol)
+		out = append(out, flattenSymbols(symbol.Children)...)
+	}
+	return out
+}
+
+// hoverSymbols hovers the name of every symbol of path that starts between
+// the 1-based startLine and endLine, where zero leaves that end open.
+// Symbols without documentation are left out.
+func (l *languageServer) hoverSymbols(path string, startLine, endLine int, unit ColumnUnit) ([]Hover, error) {
+	symbols, err := l.documentSymbols(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hovers := []Hover{}
+	for _, symbol := range flattenSymbols(symbols) {
+		line := symbol.SelectionRange.Start.Line + 1
+		if line < startLine || (endLine != 0 && line > endLine) {
+			continue
+		}
+		params := LsTextDocumentPositionParams{
+			TextDocument: LsTextDocumentIdentifier{URI: pathToURI(path)},
+			Position:     symbol.SelectionRange.Start,
+		}
+		hover, err := l.hoverAt(params)
+		if err != nil {
+			return nil, fmt.Errorf("hovering %s: %s", symbol.Name, err.Error())
+		}
+		if hover == nil {
+			continue
+		}
+		text, err := hoverText(hover.Contents)
+		if err != nil {
+			return nil, fmt.Errorf("hovering %s: %s", symbol.Name, err.Error())
+		}
+		hovers = append(hovers, Hover{
+			Location: l.userRange(path, symbol.SelectionRange, unit),
+			Symbol:   symbol.Name,
+			Kind:     symbol.Kind.String(),
+			Contents: text,
+		})
+	}
+	return hovers, nil
+}
+
+// Hover returns the documentation of the symbol at a position, or of every
+// symbol in a range of lines.
+func (s *Server) Hover(args HoverArgs, hovers *[]Hover) error {
+	server, err := s.serverForPosition(args.PositionArgs, "textDocument/hover")
+	if err != nil {
+		return err
+	}
+	if args.Symbols {
+		log.Printf("CMD hover %s:%d..%d", args.File, args.StartLine, args.EndLine)
+		*hovers, err = server.hoverSymbols(args.File, args.StartLine, args.EndLine, args.Unit)
+		return err
+	}
+
+	log.Printf("CMD hover %s:%d:%d", args.File, args.Line, args.Column)
+	hover, err := server.hover(args.File, args.Line, args.Column, args.Unit)
+	if hover != nil {
+		*hovers = []Hover{*hover}
+	}
+	return err
+}
+
+// httpHover returns the hover at line and col, or null, or if there is no
+// line the hovers of the symbols between the optional start_line and
+// end_line.
+func (s *Server) httpHover(query url.Values) (interface{}, error) {
+	if query.Get("line") != "" {
+		position, server, err := s.queryPosition(query, "textDocument/hover")
+		if err != nil {
+			return nil, err
+		}
+		return server.hover(position.File, position.Line, position.Column, position.Unit)
+	}
+
+	file, server, err := s.queryFile(query, "textDocument/hover")
+	if err != nil {
+		return nil, err
+	}
+	unit, err := queryUnit(query)
+	if err != nil {
+		return nil, err
+	}
+	var start, end int
+	if query.Get("start_line") != "" {
+		if start, err = queryInt(query, "start_line"); err != nil {
+			return nil, err
+		}
+	}
+	if query.Get("end_line") != "" {
+		if end, err = queryInt(query, "end_line"); err != nil {
+			return nil, err
+		}
+	}
+	return server.hoverSymbols(file, start, end, unit)
+}
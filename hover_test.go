@@ -0,0 +1,73 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLineRange(t *testing.T) {
+	check := func(s string, start, end int) {
+		gotStart, gotEnd, err := parseLineRange(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, start, gotStart, s)
+		assert.Equal(t, end, gotEnd, s)
+	}
+	check("3..10", 3, 10)
+	check("3..", 3, 0)
+	check("..10", 0, 10)
+	check("5..5", 5, 5)
+
+	for _, s := range []string{"", "3", "3-10", "0..2", "a..b", "10..3", "1..2..3"} {
+		_, _, err := parseLineRange(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestHoverText(t *testing.T) {
+	text, err := hoverText(easyjson.RawMessage(`[{"language":"c","value":"int foo()"},"Does foo."]`))
+	assert.NoError(t, err)
+	assert.Equal(t, "```c\nint foo()\n```\n\nDoes foo.", text)
+
+	text, err = hoverText(easyjson.RawMessage(`{"kind":"plaintext","value":"int foo"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "int foo", text)
+}
+
+func TestMarkdownText(t *testing.T) {
+	markdown := "### function `foo`\n\n---\n→ `int`\n**Does** foo\\_bar.\n\n```c\n// In a.h\nint *foo(int **p);\n```"
+	assert.Equal(t, "function foo\n\n\n→ int\nDoes foo_bar.\n\n// In a.h\nint *foo(int **p);", markdownText(markdown))
+	assert.Equal(t, "int foo", markdownText("```c\nint foo\n```\n"))
+}
+
+func TestHoverString(t *testing.T) {
+	hover := Hover{Location: Location{File: "/a.c", Line: 2, Column: 3}, Symbol: "foo", Contents: "int foo\n\nDoes foo."}
+	assert.Equal(t, "/a.c:2:3 foo\n    int foo\n    \n    Does foo.", hover.String())
+}
+
+func TestFlattenSymbols(t *testing.T) {
+	symbols := []LsDocumentSymbol{
+		{Name: "A", Children: []LsDocumentSymbol{{Name: "a1"}, {Name: "a2", Children: []LsDocumentSymbol{{Name: "x"}}}}},
+		{Name: "B"},
+	}
+	var names []string
+	for _, symbol := range flattenSymbols(symbols) {
+		names = append(names, symbol.Name)
+	}
+	assert.Equal(t, []string{"A", "a1", "a2", "x", "B"}, names)
+}
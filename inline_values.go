@@ -0,0 +1,142 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+)
+
+// Kinds of inline values.
+const (
+	// Text to show as-is.
+	InlineText = "text"
+	// A variable whose value the debugger looks up.
+	InlineVariable = "variable"
+	// An expression the debugger evaluates.
+	InlineExpression = "expression"
+)
+
+// InlineValue is a value a debugger should show next to the code, as shown
+// to users.
+type InlineValue struct {
+	Location
+	Kind string `json:"kind"`
+	// The text to show, the name of the variable or the expression. If empty
+	// the variable or expression is the text at Location.
+	Text string `json:"text,omitempty"`
+	// Whether variables are looked up case-sensitively.
+	CaseSensitive bool `json:"case_sensitive,omitempty"`
+}
+
+func (v InlineValue) String() string {
+	s := fmt.Sprintf("%s %s", v.Location, v.Kind)
+	if v.Text != "" {
+		s += " " + v.Text
+	}
+	if v.CaseSensitive {
+		s += " case-sensitive"
+	}
+	return s
+}
+
+// InlineValuesArgs holds arguments for InlineValues. The position is where
+// the debugger stopped.
+type InlineValuesArgs struct {
+	PositionArgs
+	// 1-based line from which on values are returned.
+	StartLine int
+	FrameID   int
+}
+
+// inlineValues returns the values to show between the start of line
+// args.StartLine and the position the debugger stopped at.
+func (l *languageServer) inlineValues(args InlineValuesArgs) ([]InlineValue, error) {
+	if args.StartLine < 1 || args.StartLine > args.Line {
+		return nil, fmt.Errorf("start line %d must be between 1 and the stopped line %d", args.StartLine, args.Line)
+	}
+	position, err := l.positionParams(args.File, args.Line, args.Column, args.Unit)
+	if err != nil {
+		return nil, err
+	}
+	stopped := LsRange{Start: position.Position, End: position.Position}
+	result, err := l.call("textDocument/inlineValue", toJSON(LsInlineValueParams{
+		TextDocument: position.TextDocument,
+		Range:        LsRange{Start: LsPosition{Line: args.StartLine - 1}, End: position.Position},
+		Context:      LsInlineValueContext{FrameID: args.FrameID, StoppedLocation: stopped},
+	}), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	var values []LsInlineValue
+	if err := fromJSON(result, &values); err != nil {
+		return nil, fmt.Errorf("cannot parse inline values: %s", err.Error())
+	}
+
+	out := []InlineValue{}
+	for _, v := range values {
+		value := InlineValue{Location: l.userRange(args.File, v.Range, args.Unit)}
+		switch {
+		case v.Text != nil:
+			value.Kind = InlineText
+			value.Text = *v.Text
+		case v.CaseSensitiveLookup != nil:
+			value.Kind = InlineVariable
+			value.Text = v.VariableName
+			value.CaseSensitive = *v.CaseSensitiveLookup
+		default:
+			value.Kind = InlineExpression
+			value.Text = v.Expression
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+// InlineValues returns the values a debugger stopped at a position should
+// show.
+func (s *Server) InlineValues(args InlineValuesArgs, values *[]InlineValue) error {
+	log.Printf("CMD inline-values %s:%d:%d", args.File, args.Line, args.Column)
+	server, err := s.serverForPosition(args.PositionArgs, "textDocument/inlineValue")
+	if err != nil {
+		return err
+	}
+	*values, err = server.inlineValues(args)
+	return err
+}
+
+func (s *Server) httpInlineValues(query url.Values) (interface{}, error) {
+	position, server, err := s.queryPosition(query, "textDocument/inlineValue")
+	if err != nil {
+		return nil, err
+	}
+	args := InlineValuesArgs{PositionArgs: position, StartLine: 1}
+	if query.Get("start_line") != "" {
+		if args.StartLine, err = queryInt(query, "start_line"); err != nil {
+			return nil, err
+		}
+	}
+	if frame := query.Get("frame_id"); frame != "" {
+		if args.FrameID, err = strconv.Atoi(frame); err != nil {
+			return nil, badRequest("frame_id must be an integer, got %q", frame)
+		}
+	}
+	if args.StartLine > args.Line {
+		return nil, badRequest("start_line must not be after line %d, got %d", args.Line, args.StartLine)
+	}
+	return server.inlineValues(args)
+}
@@ -0,0 +1,28 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineValueString(t *testing.T) {
+	location := Location{File: "/src/a.c", Line: 3, Column: 5}
+	assert.Equal(t, "/src/a.c:3:5 text x = 1", InlineValue{Location: location, Kind: InlineText, Text: "x = 1"}.String())
+	assert.Equal(t, "/src/a.c:3:5 variable x case-sensitive", InlineValue{Location: location, Kind: InlineVariable, Text: "x", CaseSensitive: true}.String())
+	assert.Equal(t, "/src/a.c:3:5 expression", InlineValue{Location: location, Kind: InlineExpression}.String())
+}
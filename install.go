@@ -0,0 +1,475 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	shellwords "github.com/mattn/go-shellwords"
+)
+
+// Name of the file in the install directory which records installed servers.
+const installManifestName = "installed.toml"
+
+// serverPackage describes how to install a language server.
+type serverPackage struct {
+	// The config language the server is used for.
+	language    string
+	description string
+	// Version installed when none is given.
+	version string
+	// sha256 of the release of version that is downloaded for each
+	// GOOS/GOARCH, ie, "linux/amd64".
+	checksums map[string]string
+	// install installs version into dir and returns the command which runs
+	// the server and the sha256 of what was downloaded, if known. Downloads
+	// must match checksum unless verify is false, which is the case for
+	// versions other than the pinned one. Package managers check what they
+	// install themselves.
+	install func(version, dir, checksum string, verify bool) (command, sha string, err error)
+}
+
+// serverRegistry lists the language servers lspc knows how to install.
+var serverRegistry = map[string]serverPackage{
+	"clangd": {
+		language:    "cpp",
+		description: "C/C++ language server from LLVM",
+		version:     "17.0.3",
+		install: downloadInstall(clangdRelease, extractZip, func(version string) string {
+			return filepath.Join("clangd_"+version, "bin", "clangd")
+		}),
+	},
+	"gopls": {
+		language:    "go",
+		description: "Go language server",
+		version:     "v0.14.2",
+		install:     goInstall("golang.org/x/tools/gopls", "gopls"),
+	},
+	"pyright": {
+		language:    "python",
+		description: "Python language server from Microsoft",
+		version:     "1.1.335",
+		install:     npmInstall("pyright", "pyright-langserver --stdio"),
+	},
+	"rust-analyzer": {
+		language:    "rust",
+		description: "Rust language server",
+		version:     "2023-11-13",
+		install: downloadInstall(rustAnalyzerRelease, gunzip("rust-analyzer"), func(string) string {
+			return "rust-analyzer"
+		}),
+	},
+	"typescript-language-server": {
+		language:    "typescript",
+		description: "TypeScript and JavaScript language server",
+		version:     "4.1.2",
+		install:     npmInstall("typescript-language-server", "typescript-language-server --stdio", "typescript"),
+	},
+}
+
+// installedServer is recorded in the manifest for every installed server.
+type installedServer struct {
+	Version   string    `toml:"version"`
+	Language  string    `toml:"language"`
+	Command   string    `toml:"command"`
+	SHA256    string    `toml:"sha256,omitempty"`
+	Installed time.Time `toml:"installed"`
+}
+
+type installManifest struct {
+	Servers map[string]installedServer `toml:"server"`
+}
+
+// defaultInstallDir returns the directory servers are installed to.
+func defaultInstallDir() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "lspc", "servers")
+}
+
+// loadInstallManifest reads the manifest in dir. A missing manifest has no
+// servers.
+func loadInstallManifest(dir string) (*installManifest, error) {
+	manifest := &installManifest{Servers: make(map[string]installedServer)}
+	path := filepath.Join(dir, installManifestName)
+	if dir == "" || !fileExists(path) {
+		return manifest, nil
+	}
+	if _, err := toml.DecodeFile(path, manifest); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %s", path, err.Error())
+	}
+	if manifest.Servers == nil {
+		manifest.Servers = make(map[string]installedServer)
+	}
+	return manifest, nil
+}
+
+func (m *installManifest) save(dir string) error {
+	f, err := os.Create(filepath.Join(dir, installManifestName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(m)
+}
+
+// useInstalled sets the command of every language without one to the server
+// installed for it, so installed servers work without editing the config.
+func (c *Config) useInstalled(manifest *installManifest) {
+	var names []string
+	for name := range manifest.Servers {
+		names = append(names, name)
+	}
+	// Apply in a stable order in case several servers are installed for a
+	// language.
+	sort.Strings(names)
+	for _, name := range names {
+		installed := manifest.Servers[name]
+		language := c.Languages[installed.Language]
+		if language.Command != "" {
+			continue
+		}
+		language.Command = installed.Command
+		if c.Languages == nil {
+			c.Languages = make(map[string]LanguageConfig)
+		}
+		c.Languages[installed.Language] = language
+	}
+}
+
+// resolveBin replaces the program of bin with the program of the configured
+// command for the same server if it is not on PATH, so that `lspc start
+// clangd` runs a clangd installed by `lspc install`.
+func (c *Config) resolveBin(bin string) string {
+	words, err := shellwords.Parse(bin)
+	if err != nil || len(words) == 0 {
+		return bin
+	}
+	if _, err := exec.LookPath(words[0]); err == nil {
+		return bin
+	}
+	language := c.languageFor(bin, "")
+	if language == "" {
+		return bin
+	}
+	configured, err := shellwords.Parse(c.Languages[language].Command)
+	if err != nil || len(configured) == 0 || configured[0] == words[0] {
+		return bin
+	}
+
+	quoted := []string{quoteWord(configured[0])}
+	for _, word := range words[1:] {
+		quoted = append(quoted, quoteWord(word))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Installable describes a server of the registry for install --json.
+type Installable struct {
+	Name        string
+	Version     string
+	Language    string
+	Description string
+	// Empty if the server is not installed.
+	Installed string `json:",omitempty"`
+}
+
+// listInstallable prints the registry along with installed versions.
+func listInstallable(dir string) error {
+	manifest, err := loadInstallManifest(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range serverRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if gJSON {
+		var list []Installable
+		for _, name := range names {
+			pkg := serverRegistry[name]
+			list = append(list, Installable{name, pkg.version, pkg.language, pkg.description, manifest.Servers[name].Version})
+		}
+		return printJSON(list)
+	}
+	for _, name := range names {
+		pkg := serverRegistry[name]
+		line := fmt.Sprintf("%s %s (%s): %s", name, pkg.version, pkg.language, pkg.description)
+		if installed, has := manifest.Servers[name]; has {
+			line += fmt.Sprintf(" [installed %s]", installed.Version)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// installServer installs version of the server called name into dir. An
+// empty version installs the version pinned in the registry.
+func installServer(dir, name, version string, force bool) error {
+	pkg, has := serverRegistry[name]
+	if !has {
+		return fmt.Errorf("unknown language server %q; run lspc install to list known servers", name)
+	}
+	if version == "" {
+		version = pkg.version
+	}
+
+	manifest, err := loadInstallManifest(dir)
+	if err != nil {
+		return err
+	}
+	if installed, has := manifest.Servers[name]; has && installed.Version == version && !force {
+		if gJSON {
+			return printJSON(installed)
+		}
+		fmt.Printf("%s %s is already installed\n", name, version)
+		return nil
+	}
+
+	target := filepath.Join(dir, name, version)
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	checksum := pkg.checksums[runtime.GOOS+"/"+runtime.GOARCH]
+	command, sha, err := pkg.install(version, target, checksum, version == pkg.version)
+	if err != nil {
+		os.RemoveAll(target)
+		return fmt.Errorf("installing %s %s failed: %s", name, version, err.Error())
+	}
+
+	manifest.Servers[name] = installedServer{
+		Version:   version,
+		Language:  pkg.language,
+		Command:   command,
+		SHA256:    sha,
+		Installed: time.Now(),
+	}
+	if err := manifest.save(dir); err != nil {
+		return err
+	}
+	if gJSON {
+		return printJSON(manifest.Servers[name])
+	}
+	fmt.Printf("Installed %s %s to %s\n", name, version, target)
+	fmt.Printf("It is used for language %s unless language.%s.command is set in the config\n", pkg.language, pkg.language)
+	return nil
+}
+
+// quoteWord quotes s so that it is a single shell word.
+func quoteWord(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// runInstaller runs a package manager, showing its output.
+func runInstaller(env []string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = textOutput()
+	cmd.Stderr = os.Stderr
+	fmt.Fprintf(textOutput(), "Running %s %s\n", name, strings.Join(args, " "))
+	return cmd.Run()
+}
+
+// goInstall installs a Go module with go install.
+func goInstall(module, bin string) func(version, dir, checksum string, verify bool) (string, string, error) {
+	return func(version, dir, _ string, _ bool) (string, string, error) {
+		binDir := filepath.Join(dir, "bin")
+		if err := runInstaller([]string{"GOBIN=" + binDir}, "go", "install", module+"@"+version); err != nil {
+			return "", "", err
+		}
+		return quoteWord(filepath.Join(binDir, bin)), "", nil
+	}
+}
+
+// npmInstall installs pkg and its peer dependencies with npm. command is the
+// binary in pkg to run followed by its arguments.
+func npmInstall(pkg, command string, peers ...string) func(version, dir, checksum string, verify bool) (string, string, error) {
+	return func(version, dir, _ string, _ bool) (string, string, error) {
+		args := append([]string{"install", "--prefix", dir, "--no-save", pkg + "@" + version}, peers...)
+		if err := runInstaller(nil, "npm", args...); err != nil {
+			return "", "", err
+		}
+		return quoteWord(filepath.Join(dir, "node_modules", ".bin")+string(filepath.Separator)) + command, "", nil
+	}
+}
+
+// downloadInstall downloads the release returned by url, unpacks it with
+// extract and runs bin, a path inside of the unpacked release. The release is
+// unpacked only if its sha256 is checksum, unless verify is false.
+func downloadInstall(url func(version string) (string, error), extract func(archive, dir string) error, bin func(version string) string) func(version, dir, checksum string, verify bool) (string, string, error) {
+	return func(version, dir, checksum string, verify bool) (string, string, error) {
+		if verify && checksum == "" {
+			return "", "", fmt.Errorf("no checksum of version %s is known for %s/%s; pass --version to install it without verification", version, runtime.GOOS, runtime.GOARCH)
+		}
+		u, err := url(version)
+		if err != nil {
+			return "", "", err
+		}
+		archive := filepath.Join(dir, "download")
+		sha, err := download(u, archive)
+		if err != nil {
+			return "", "", err
+		}
+		defer os.Remove(archive)
+		if !verify {
+			fmt.Fprintf(textOutput(), "Not verifying %s, which is not the pinned version\n", u)
+		} else if !strings.EqualFold(sha, checksum) {
+			return "", "", fmt.Errorf("%s has sha256 %s instead of %s", u, sha, checksum)
+		}
+		if err := extract(archive, dir); err != nil {
+			return "", "", err
+		}
+		return quoteWord(filepath.Join(dir, bin(version))), sha, nil
+	}
+}
+
+// download saves url to path and returns its sha256.
+func download(url, path string) (string, error) {
+	fmt.Fprintf(textOutput(), "Downloading %s\n", url)
+	response, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s failed: %s", url, response.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), response.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), f.Close()
+}
+
+func clangdRelease(version string) (string, error) {
+	platforms := map[string]string{"linux": "linux", "darwin": "mac", "windows": "windows"}
+	platform, has := platforms[runtime.GOOS]
+	if !has || (runtime.GOARCH != "amd64" && runtime.GOOS != "darwin") {
+		return "", fmt.Errorf("clangd releases are not available for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf("https://github.com/clangd/clangd/releases/download/%s/clangd-%s-%s.zip", version, platform, version), nil
+}
+
+func rustAnalyzerRelease(version string) (string, error) {
+	arches := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}
+	systems := map[string]string{"linux": "unknown-linux-gnu", "darwin": "apple-darwin"}
+	arch, hasArch := arches[runtime.GOARCH]
+	system, hasSystem := systems[runtime.GOOS]
+	if !hasArch || !hasSystem {
+		return "", fmt.Errorf("rust-analyzer releases are not available for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf("https://github.com/rust-lang/rust-analyzer/releases/download/%s/rust-analyzer-%s-%s.gz", version, arch, system), nil
+}
+
+// extractZip unpacks the zip file archive into dir.
+func extractZip(archive, dir string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		path := filepath.Join(dir, f.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("%s escapes the install directory", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractZipFile(f, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode()|0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// gunzip returns an extract function which decompresses a gzipped executable
+// to dir/name.
+func gunzip(name string) func(archive, dir string) error {
+	return func(archive, dir string) error {
+		in, err := os.Open(archive)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		r, err := gzip.NewReader(in)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, name), data, 0755)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	manifest, err := loadInstallManifest(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, manifest.Servers)
+
+	installed := installedServer{Version: "17.0.3", Language: "cpp", Command: "/opt/clangd", SHA256: "abc", Installed: time.Unix(1500000000, 0).UTC()}
+	manifest.Servers["clangd"] = installed
+	assert.NoError(t, manifest.save(dir))
+
+	manifest, err = loadInstallManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, installed, manifest.Servers["clangd"])
+}
+
+func TestUseInstalled(t *testing.T) {
+	manifest := &installManifest{Servers: map[string]installedServer{
+		"clangd": {Language: "cpp", Command: "/opt/clangd"},
+		"gopls":  {Language: "go", Command: "/opt/gopls"},
+	}}
+	config := &Config{Languages: map[string]LanguageConfig{
+		"go":  {Command: "gopls serve"},
+		"cpp": {Settings: `{"a": 1}`},
+	}}
+	config.useInstalled(manifest)
+	assert.Equal(t, "gopls serve", config.Languages["go"].Command)
+	assert.Equal(t, "/opt/clangd", config.Languages["cpp"].Command)
+	assert.Equal(t, `{"a": 1}`, config.Languages["cpp"].Settings)
+
+	config = &Config{}
+	config.useInstalled(manifest)
+	assert.Equal(t, "/opt/gopls", config.Languages["go"].Command)
+}
+
+func TestResolveBin(t *testing.T) {
+	config := &Config{Languages: map[string]LanguageConfig{
+		"test": {Command: "'/opt/lspc servers/lspc-test-server' --background-index"},
+		"sh":   {Command: "sh"},
+	}}
+	assert.Equal(t, "'/opt/lspc servers/lspc-test-server' --log=verbose", config.resolveBin("lspc-test-server --log=verbose"))
+	// Programs on PATH and unknown programs are left alone.
+	assert.Equal(t, "sh -c true", config.resolveBin("sh -c true"))
+	assert.Equal(t, "cquery", config.resolveBin("cquery"))
+}
+
+func TestQuoteWord(t *testing.T) {
+	assert.Equal(t, "/usr/bin/clangd", quoteWord("/usr/bin/clangd"))
+	assert.Equal(t, "'/my servers/clangd'", quoteWord("/my servers/clangd"))
+	assert.Equal(t, `'it'\''s'`, quoteWord("it's"))
+	assert.Equal(t, "''", quoteWord(""))
+}
+
+func TestExtractZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeZip := func(name string) string {
+		var buffer bytes.Buffer
+		w := zip.NewWriter(&buffer)
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		f.Write([]byte("binary"))
+		assert.NoError(t, w.Close())
+		path := filepath.Join(dir, "archive.zip")
+		assert.NoError(t, ioutil.WriteFile(path, buffer.Bytes(), 0644))
+		return path
+	}
+
+	assert.NoError(t, extractZip(writeZip("clangd_1/bin/clangd"), filepath.Join(dir, "out")))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "out", "clangd_1", "bin", "clangd"))
+	assert.NoError(t, err)
+	assert.Equal(t, "binary", string(data))
+
+	assert.Error(t, extractZip(writeZip("../escape"), filepath.Join(dir, "out")))
+	assert.False(t, fileExists(filepath.Join(dir, "escape")))
+}
+
+func TestDownloadInstall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte("#!/bin/sh\n"))
+	w.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/ra.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(gz.Bytes())
+	}))
+	defer server.Close()
+
+	install := downloadInstall(func(version string) (string, error) {
+		return server.URL + "/" + version + "/ra.gz", nil
+	}, gunzip("rust-analyzer"), func(string) string { return "rust-analyzer" })
+
+	sum := sha256.Sum256(gz.Bytes())
+	checksum := hex.EncodeToString(sum[:])
+	command, sha, err := install("v1", dir, checksum, true)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "rust-analyzer"), command)
+	assert.Equal(t, checksum, sha)
+	info, err := os.Stat(filepath.Join(dir, "rust-analyzer"))
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100)
+	assert.False(t, fileExists(filepath.Join(dir, "download")))
+
+	_, _, err = install("v2", dir, "", false)
+	assert.Error(t, err)
+
+	// Pinned versions are unpacked only if they match their checksum.
+	os.Remove(filepath.Join(dir, "rust-analyzer"))
+	_, _, err = install("v1", dir, strings.Repeat("0", 64), true)
+	assert.Error(t, err)
+	assert.False(t, fileExists(filepath.Join(dir, "rust-analyzer")))
+	_, _, err = install("v1", dir, "", true)
+	assert.Error(t, err)
+	_, sha, err = install("v1", dir, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, checksum, sha)
+}
@@ -0,0 +1,350 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Number of applied edits the journal keeps; older ones cannot be undone.
+const maxJournalEntries = 100
+
+// journalMu serializes access to the journal file.
+var journalMu sync.Mutex
+
+// journalHunk replaces the lines New, which start at the 0-based Line of the
+// file after the edit, with Old, the lines before it. Lines keep their
+// terminator.
+type journalHunk struct {
+	Line int
+	Old  []string `json:",omitempty"`
+	New  []string `json:",omitempty"`
+}
+
+// journalFile records how an edit changed one file.
+type journalFile struct {
+	Path string
+	// Set if the edit renamed the file from OldPath to Path.
+	OldPath string `json:",omitempty"`
+	Created bool   `json:",omitempty"`
+	Deleted bool   `json:",omitempty"`
+	Mode    os.FileMode
+	// Name of the encoding of the file, see fileEncoding.
+	Encoding string
+	Hunks    []journalHunk
+}
+
+// journalEntry is an edit lspc applied to disk.
+type journalEntry struct {
+	Time  time.Time
+	Label string
+	// Directory of the language server the edit was applied for.
+	Directory string
+	Files     []journalFile
+}
+
+// defaultEditJournal returns the location of the journal of applied edits.
+func defaultEditJournal() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "lspc", "edits.jsonl")
+}
+
+// encodingNamed returns the encoding with the given name.
+func encodingNamed(name string) (fileEncoding, error) {
+	for _, e := range []fileEncoding{utf8Encoding, latin1Encoding, utf16LEEncoding, utf16BEEncoding} {
+		if e.name == name {
+			return e, nil
+		}
+	}
+	e, canonical, err := lookupEncoding(name)
+	return fileEncoding{e, canonical}, err
+}
+
+// diffHunks returns the hunks which turn b back into a.
+func diffHunks(a, b string) []journalHunk {
+	var hunks []journalHunk
+	// The current hunk, nil between hunks.
+	var current *journalHunk
+	line := 0
+	for _, op := range diffLines(splitLines(a), splitLines(b)) {
+		if op.kind == ' ' {
+			current = nil
+			line++
+			continue
+		}
+		if current == nil {
+			hunks = append(hunks, journalHunk{Line: line})
+			current = &hunks[len(hunks)-1]
+		}
+		if op.kind == '-' {
+			current.Old = append(current.Old, op.line)
+		} else {
+			current.New = append(current.New, op.line)
+			line++
+		}
+	}
+	return hunks
+}
+
+// revertHunks applies hunks to text, the file as the edit left it. Fails if
+// the lines the edit wrote have changed since.
+func revertHunks(text string, hunks []journalHunk) (string, error) {
+	lines := splitLines(text)
+	for i := len(hunks) - 1; i >= 0; i-- {
+		h := hunks[i]
+		end := h.Line + len(h.New)
+		if end > len(lines) || strings.Join(lines[h.Line:end], "") != strings.Join(h.New, "") {
+			return "", errors.New("changed since the edit was applied")
+		}
+		reverted := append(append([]string(nil), lines[:h.Line]...), h.Old...)
+		lines = append(reverted, lines[end:]...)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// newJournalEntry records the changes of p, applied for directory.
+func newJournalEntry(directory string, p *editPlan) journalEntry {
+	entry := journalEntry{Time: time.Now(), Label: p.label, Directory: directory}
+	renamed := make(map[string]bool)
+	for newPath, oldPath := range p.renamedFrom {
+		if p.after[newPath] != nil {
+			renamed[oldPath] = true
+		}
+	}
+	for _, path := range p.paths {
+		before, after := p.before[path], p.after[path]
+		if oldPath, has := p.renamedFrom[path]; has && after != nil {
+			before = p.before[oldPath]
+			entry.Files = append(entry.Files, journalFile{
+				Path: path, OldPath: oldPath, Mode: before.mode, Encoding: after.encoding.name,
+				Hunks: diffHunks(before.text, after.text),
+			})
+			continue
+		}
+		switch {
+		case before == nil && after == nil, renamed[path]:
+		case before == nil:
+			entry.Files = append(entry.Files, journalFile{
+				Path: path, Created: true, Mode: after.mode, Encoding: after.encoding.name,
+				Hunks: diffHunks("", after.text),
+			})
+		case after == nil:
+			entry.Files = append(entry.Files, journalFile{
+				Path: path, Deleted: true, Mode: before.mode, Encoding: before.encoding.name,
+				Hunks: diffHunks(before.text, ""),
+			})
+		case before.text != after.text:
+			entry.Files = append(entry.Files, journalFile{
+				Path: path, Mode: before.mode, Encoding: before.encoding.name,
+				Hunks: diffHunks(before.text, after.text),
+			})
+		}
+	}
+	return entry
+}
+
+// readJournal returns the entries of the journal at path, oldest first. A
+// missing journal is empty.
+func readJournal(path string) ([]journalEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []journalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<30)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("cannot read %s: %s", path, err.Error())
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// writeJournal replaces the journal at path with the newest
+// maxJournalEntries of entries.
+func writeJournal(path string, entries []journalEntry) error {
+	if len(entries) > maxJournalEntries {
+		entries = entries[len(entries)-maxJournalEntries:]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	var content []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		content = append(append(content, line...), '\n')
+	}
+	// Write a copy and rename it so that the journal is never left half
+	// written.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// recordEdit adds p, which was applied for directory, to the journal at
+// path. Does nothing if path is empty.
+func recordEdit(path, directory string, p *editPlan) error {
+	if path == "" {
+		return nil
+	}
+	entry := newJournalEntry(directory, p)
+	if len(entry.Files) == 0 {
+		return nil
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	entries, err := readJournal(path)
+	if err != nil {
+		return err
+	}
+	return writeJournal(path, append(entries, entry))
+}
+
+// planUndo computes the plan which reverts entry.
+func (l *languageServer) planUndo(entry journalEntry) (*editPlan, error) {
+	p := newEditPlan("Undo " + entry.Label)
+	p.undo = true
+	for i := len(entry.Files) - 1; i >= 0; i-- {
+		f := entry.Files[i]
+		current, err := p.load(l, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if f.Deleted {
+			if current != nil {
+				return nil, fmt.Errorf("cannot restore %s: file exists", f.Path)
+			}
+			e, err := encodingNamed(f.Encoding)
+			if err != nil {
+				return nil, fmt.Errorf("cannot restore %s: %s", f.Path, err.Error())
+			}
+			text, _ := revertHunks("", f.Hunks)
+			p.after[f.Path] = &planFile{text: text, encoding: e, mode: f.Mode}
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("cannot undo changes to %s: no such file", f.Path)
+		}
+		text, err := revertHunks(current.text, f.Hunks)
+		if err != nil {
+			return nil, fmt.Errorf("cannot undo changes to %s: %s", f.Path, err.Error())
+		}
+		switch {
+		case f.Created:
+			if text != "" {
+				return nil, fmt.Errorf("cannot remove %s: changed since the edit created it", f.Path)
+			}
+			p.after[f.Path] = nil
+		case f.OldPath != "":
+			existing, err := p.load(l, f.OldPath)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, fmt.Errorf("cannot rename %s back to %s: file exists", f.Path, f.OldPath)
+			}
+			p.after[f.Path] = nil
+			p.after[f.OldPath] = &planFile{text: text, encoding: current.encoding, mode: current.mode}
+			p.renamedFrom[f.OldPath] = f.Path
+		default:
+			current.text = text
+		}
+	}
+	return p, nil
+}
+
+// UndoArgs selects whether UndoLastEdit only previews the undo.
+type UndoArgs struct {
+	DryRun bool
+}
+
+// serverForDirectory returns a running language server for directory, or if
+// there is none one which is only used to read and write its files.
+func (s *Server) serverForDirectory(directory string) *languageServer {
+	for _, server := range s.serverList() {
+		if server.directory == directory {
+			return server
+		}
+	}
+	config := s.currentConfig()
+	l := &languageServer{directory: directory, readOnly: config.readOnlyFor(directory)}
+	l.setEncodingRules(config.encodingRules())
+	l.setDocumentLimit(config.documentLimit())
+	return l
+}
+
+// UndoLastEdit reverts the most recent edit in the journal and removes it
+// from the journal. preview describes the reverting changes.
+func (s *Server) UndoLastEdit(args UndoArgs, preview *EditPreview) error {
+	log.Printf("CMD undo-last-edit dry-run=%t", args.DryRun)
+	if gEditJournal == "" {
+		return errors.New("the edit journal is disabled")
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	entries, err := readJournal(gEditJournal)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no applied edit to undo")
+	}
+	entry := entries[len(entries)-1]
+	server := s.serverForDirectory(entry.Directory)
+	plan, err := server.planUndo(entry)
+	if err != nil {
+		return err
+	}
+	*preview = plan.preview()
+	if args.DryRun {
+		return nil
+	}
+	if err := server.applyPlan("undo-last-edit", plan); err != nil {
+		return err
+	}
+	return writeJournal(gEditJournal, entries[:len(entries)-1])
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffAndRevertHunks(t *testing.T) {
+	for _, c := range [][2]string{
+		{"a\nb\nc\n", "a\nx\nc\nd\n"},
+		{"", "new\nfile"},
+		{"old\n", ""},
+		{"1\n2\n3\n4\n5\n", "0\n2\n4\n5\n6\n"},
+	} {
+		hunks := diffHunks(c[0], c[1])
+		reverted, err := revertHunks(c[1], hunks)
+		assert.NoError(t, err)
+		assert.Equal(t, c[0], reverted)
+	}
+
+	hunks := diffHunks("a\nb\nc\n", "a\nx\nc\n")
+	assert.Equal(t, []journalHunk{{Line: 1, Old: []string{"b\n"}, New: []string{"x\n"}}}, hunks)
+	_, err := revertHunks("a\ny\nc\n", hunks)
+	assert.Error(t, err)
+	_, err = revertHunks("a\n", hunks)
+	assert.Error(t, err)
+}
+
+func TestJournalTrimsEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state", "edits.jsonl")
+	entries, err := readJournal(path)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for i := 0; i < maxJournalEntries+5; i++ {
+		entries = append(entries, journalEntry{Label: string(rune('a' + i%26))})
+	}
+	assert.NoError(t, writeJournal(path, entries))
+	read, err := readJournal(path)
+	assert.NoError(t, err)
+	assert.Len(t, read, maxJournalEntries)
+	assert.Equal(t, entries[len(entries)-1].Label, read[len(read)-1].Label)
+}
+
+func TestUndoLastEdit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(journal string) { gEditJournal = journal }(gEditJournal)
+	gEditJournal = filepath.Join(dir, "edits.jsonl")
+
+	a := filepath.Join(dir, "a.c")
+	b := filepath.Join(dir, "b.c")
+	c := filepath.Join(dir, "c.c")
+	created := filepath.Join(dir, "new.c")
+	gone := filepath.Join(dir, "gone.c")
+	assert.NoError(t, ioutil.WriteFile(a, []byte("int foo;\nint x;\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(b, []byte("caf\xe9\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(gone, []byte("x\n"), 0755))
+
+	l := &languageServer{directory: dir}
+	s := &Server{config: &Config{}, servers: []*languageServer{l}}
+	var preview EditPreview
+	assert.Error(t, s.UndoLastEdit(UndoArgs{}, &preview))
+
+	plan, err := l.planEdit("Refactor", LsWorkspaceEdit{DocumentChanges: []LsDocumentChange{
+		{TextDocument: &LsVersionedTextDocumentIdentifier{URI: pathToURI(a)}, Edits: []LsTextEdit{textEdit(0, 4, 0, 7, "bar")}},
+		{Kind: "rename", OldURI: pathToURI(b), NewURI: pathToURI(c)},
+		{Kind: "create", URI: pathToURI(created)},
+		{TextDocument: &LsVersionedTextDocumentIdentifier{URI: pathToURI(created)}, Edits: []LsTextEdit{textEdit(0, 0, 0, 0, "new\n")}},
+		{Kind: "delete", URI: pathToURI(gone)},
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, l.applyPlan("test", plan))
+	entries, err := readJournal(gEditJournal)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Len(t, entries[0].Files, 4)
+
+	// A dry run changes nothing.
+	assert.NoError(t, s.UndoLastEdit(UndoArgs{DryRun: true}, &preview))
+	assert.Equal(t, "Undo Refactor", preview.Label)
+	assert.Len(t, preview.Files, 4)
+	assert.True(t, fileExists(c))
+
+	// Files modified since the edit are not touched.
+	assert.NoError(t, ioutil.WriteFile(a, []byte("int baz;\nint x;\n"), 0644))
+	assert.Error(t, s.UndoLastEdit(UndoArgs{}, &preview))
+	assert.True(t, fileExists(c))
+	assert.NoError(t, ioutil.WriteFile(a, []byte("int bar;\nint x;\n"), 0644))
+
+	assert.NoError(t, s.UndoLastEdit(UndoArgs{}, &preview))
+	content, err := ioutil.ReadFile(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "int foo;\nint x;\n", string(content))
+	content, err = ioutil.ReadFile(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "caf\xe9\n", string(content))
+	info, err := os.Stat(gone)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	assert.False(t, fileExists(c))
+	assert.False(t, fileExists(created))
+
+	// The undo itself is not journaled.
+	entries, err = readJournal(gEditJournal)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
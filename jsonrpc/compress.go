@@ -0,0 +1,170 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoding compresses message bodies sent with a Content-Encoding header.
+type Encoding struct {
+	NewWriter func(w io.Writer) io.WriteCloser
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var encodings = map[string]Encoding{
+	"gzip": {
+		NewWriter: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		NewReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	},
+}
+
+// RegisterEncoding makes an encoding available to Compression and the
+// splitters. gzip is always available.
+func RegisterEncoding(name string, e Encoding) {
+	encodings[name] = e
+}
+
+// decode decompresses body. If limit is not negative bodies that decompress
+// to more than limit bytes are rejected.
+func decode(encoding string, body []byte, limit int) ([]byte, error) {
+	if encoding == "" || encoding == "identity" {
+		return body, nil
+	}
+	e, has := encodings[encoding]
+	if !has {
+		return nil, fmt.Errorf("Unsupported Content-Encoding %q", encoding)
+	}
+	r, err := e.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var limited io.Reader = r
+	if limit >= 0 {
+		limited = io.LimitReader(r, int64(limit)+1)
+	}
+	decoded, err := ioutil.ReadAll(limited)
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && len(decoded) > limit {
+		return nil, fmt.Errorf("Message decompresses to more than %d bytes", limit)
+	}
+	return decoded, nil
+}
+
+// Compression writes messages with compressed bodies once the peer has said
+// it can read them. Each side lists the encodings it reads in an
+// Accept-Encoding header on the first message it writes; pass PeerAccepts as
+// Splitter.OnAcceptEncoding so the peer's list is picked up. Peers that do
+// not send the header always get uncompressed messages, so plain language
+// servers are unaffected.
+type Compression struct {
+	// Encodings this side reads, in order of preference.
+	Accept []string
+	// Bodies smaller than this are not worth compressing.
+	MinSize int
+
+	mu        sync.Mutex
+	announced bool
+	peer      string
+}
+
+// PeerAccepts selects the first of names that is an available encoding.
+func (c *Compression) PeerAccepts(names []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if _, has := encodings[name]; has {
+			c.peer = name
+			return
+		}
+	}
+	c.peer = ""
+}
+
+// Encoding returns the encoding used for messages written to the peer, or an
+// empty string if they are not compressed.
+func (c *Compression) Encoding() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peer
+}
+
+// WriteMessage writes body to w like the package level WriteMessage, adding
+// the headers for compression.
+func (c *Compression) WriteMessage(w io.Writer, body []byte) (int, error) {
+	c.mu.Lock()
+	announce := !c.announced && len(c.Accept) > 0
+	c.announced = true
+	encoding := c.peer
+	c.mu.Unlock()
+
+	if len(body) < c.MinSize {
+		encoding = ""
+	}
+	if encoding == "" && !announce {
+		return WriteMessage(w, body)
+	}
+
+	frame := framePool.Get().(*bytes.Buffer)
+	defer func() {
+		if frame.Cap() <= maxPooledBufferSize {
+			frame.Reset()
+			framePool.Put(frame)
+		}
+	}()
+
+	if encoding != "" {
+		compressed := framePool.Get().(*bytes.Buffer)
+		defer func() {
+			if compressed.Cap() <= maxPooledBufferSize {
+				compressed.Reset()
+				framePool.Put(compressed)
+			}
+		}()
+		cw := encodings[encoding].NewWriter(compressed)
+		if _, err := cw.Write(body); err != nil {
+			return 0, err
+		}
+		if err := cw.Close(); err != nil {
+			return 0, err
+		}
+		body = compressed.Bytes()
+	}
+
+	frame.WriteString("Content-Length: ")
+	frame.WriteString(strconv.Itoa(len(body)))
+	frame.WriteString("\r\n")
+	if encoding != "" {
+		frame.WriteString("Content-Encoding: " + encoding + "\r\n")
+	}
+	if announce {
+		frame.WriteString("Accept-Encoding: " + strings.Join(c.Accept, ", ") + "\r\n")
+	}
+	frame.WriteString("\r\n")
+	frame.Write(body)
+	return w.Write(frame.Bytes())
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionNegotiation(t *testing.T) {
+	body := []byte(strings.Repeat(`{"uri": "file:///a/b/c.cc"}`, 100))
+	c := &Compression{Accept: []string{"gzip"}, MinSize: 100}
+
+	// Nothing is compressed until the peer accepts an encoding, but the first
+	// message announces what this side reads.
+	var out bytes.Buffer
+	c.WriteMessage(&out, body)
+	assert.True(t, strings.HasPrefix(out.String(), "Content-Length: 2700\r\nAccept-Encoding: gzip\r\n\r\n"))
+	out.Reset()
+	c.WriteMessage(&out, body)
+	assert.True(t, strings.HasPrefix(out.String(), "Content-Length: 2700\r\n\r\n"))
+
+	c.PeerAccepts([]string{"br", " gzip"})
+	assert.Equal(t, "gzip", c.Encoding())
+	out.Reset()
+	c.WriteMessage(&out, body)
+	c.WriteMessage(&out, []byte("small"))
+	assert.Contains(t, out.String(), "Content-Encoding: gzip\r\n")
+	assert.True(t, out.Len() < len(body))
+
+	var accepted []string
+	splitter := &Splitter{
+		MaxContentLength: 10000,
+		OnAcceptEncoding: func(encodings []string) { accepted = encodings },
+	}
+	scanner := bufio.NewScanner(&out)
+	scanner.Split(splitter.Split)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, body, scanner.Bytes())
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "small", scanner.Text())
+	assert.False(t, scanner.Scan())
+	assert.NoError(t, scanner.Err())
+	assert.Nil(t, accepted)
+
+	c.PeerAccepts([]string{"br"})
+	assert.Equal(t, "", c.Encoding())
+}
+
+func TestSplitterReadsAcceptEncoding(t *testing.T) {
+	input := "Content-Length: 2\r\nAccept-Encoding: gzip, zstd\r\n\r\n{}"
+	var accepted []string
+	splitter := &Splitter{
+		MaxContentLength: 100,
+		OnAcceptEncoding: func(encodings []string) { accepted = encodings },
+	}
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(splitter.Split)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, []string{"gzip", " zstd"}, accepted)
+}
+
+func TestDecompressedSizeLimit(t *testing.T) {
+	var out bytes.Buffer
+	c := &Compression{}
+	c.PeerAccepts([]string{"gzip"})
+	c.WriteMessage(&out, bytes.Repeat([]byte("x"), 1000))
+
+	splitter := &Splitter{MaxContentLength: 500}
+	scanner := bufio.NewScanner(&out)
+	scanner.Split(splitter.Split)
+	assert.False(t, scanner.Scan())
+	assert.Error(t, scanner.Err())
+}
+
+func TestReadUnknownEncoding(t *testing.T) {
+	input := "Content-Length: 3\r\nContent-Encoding: br\r\n\r\nabc"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(SplitFunc)
+	assert.False(t, scanner.Scan())
+	assert.Error(t, scanner.Err())
+}
+
+func TestReadExtraHeaders(t *testing.T) {
+	input := "Content-Type: application/vscode-jsonrpc; charset=utf-8\r\nContent-Length: 3\r\n\r\nabc"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(SplitFunc)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "abc", scanner.Text())
+}
@@ -0,0 +1,42 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build zstd
+// +build zstd
+
+package jsonrpc
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Build with `go build -tags zstd` to support zstd compressed messages.
+func init() {
+	RegisterEncoding("zstd", Encoding{
+		NewWriter: func(w io.Writer) io.WriteCloser {
+			// Only fails for invalid options.
+			e, _ := zstd.NewWriter(w)
+			return e
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			d, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return d.IOReadCloser(), nil
+		},
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Framing is how messages are delimited in a stream.
+type Framing string
+
+const (
+	// FramingLSP prefixes every message with a Content-Length header.
+	FramingLSP Framing = "lsp"
+	// FramingJSONL puts every message on its own line, as a few servers that
+	// do not follow the LSP base protocol do.
+	FramingJSONL Framing = "jsonl"
+)
+
+// ParseFraming returns the framing called name. Empty is FramingLSP.
+func ParseFraming(name string) (Framing, error) {
+	switch Framing(name) {
+	case "", FramingLSP:
+		return FramingLSP, nil
+	case FramingJSONL:
+		return FramingJSONL, nil
+	}
+	return "", fmt.Errorf("unknown framing %q; expected lsp or jsonl", name)
+}
+
+// WriteFramed writes body to w delimited as framing requires.
+func WriteFramed(w io.Writer, body []byte, framing Framing) (int, error) {
+	if framing == FramingJSONL {
+		return WriteLine(w, body)
+	}
+	return WriteMessage(w, body)
+}
+
+// WriteLine writes body followed by a newline with a single call to w.Write.
+// body must not contain a newline, which JSON encoders never emit outside of
+// strings.
+func WriteLine(w io.Writer, body []byte) (int, error) {
+	frame := framePool.Get().(*bytes.Buffer)
+	defer func() {
+		if frame.Cap() <= maxPooledBufferSize {
+			frame.Reset()
+			framePool.Put(frame)
+		}
+	}()
+
+	frame.Write(body)
+	frame.WriteByte('\n')
+	return w.Write(frame.Bytes())
+}
+
+// splitLine splits messages framed with FramingJSONL. Blank lines are
+// skipped and lines above MaxContentLength are dropped like oversized
+// messages.
+func (s *Splitter) splitLine(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	end := bytes.IndexByte(data, '\n')
+	if s.skipping {
+		n := len(data)
+		if end >= 0 {
+			n = end + 1
+		}
+		s.keepSample(data[:n])
+		s.contentLength += n
+		if end >= 0 || atEOF {
+			s.skipping = false
+			if s.OnOversized != nil {
+				s.OnOversized(s.contentLength, s.head, s.tail)
+			}
+		}
+		return n, nil, nil
+	}
+
+	if end < 0 {
+		switch {
+		case len(data) > s.MaxContentLength:
+			s.skipping = true
+			s.contentLength = 0
+			s.head = s.head[:0]
+			s.tail = s.tail[:0]
+			return s.splitLine(data, atEOF)
+		case atEOF && len(bytes.TrimSpace(data)) > 0:
+			// The last message does not need a newline.
+			end = len(data)
+		default:
+			if atEOF {
+				return len(data), nil, nil
+			}
+			return 0, nil, nil
+		}
+	}
+
+	advance = end + 1
+	if advance > len(data) {
+		advance = len(data)
+	}
+	line := bytes.TrimSpace(data[:end])
+	if len(line) == 0 {
+		return advance, nil, nil
+	}
+	if len(line) > s.MaxContentLength {
+		sample := oversizedSampleSize
+		if sample > len(line) {
+			sample = len(line)
+		}
+		s.head = append(s.head[:0], line[:sample]...)
+		s.tail = append(s.tail[:0], line[len(line)-sample:]...)
+		if s.OnOversized != nil {
+			s.OnOversized(len(line), s.head, s.tail)
+		}
+		return advance, nil, nil
+	}
+	return advance, line, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFraming(t *testing.T) {
+	framing, err := ParseFraming("")
+	assert.NoError(t, err)
+	assert.Equal(t, FramingLSP, framing)
+	framing, err = ParseFraming("jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, FramingJSONL, framing)
+	_, err = ParseFraming("ndjson")
+	assert.Error(t, err)
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	var out bytes.Buffer
+	_, err := WriteFramed(&out, []byte(`{"id":1}`), FramingJSONL)
+	assert.NoError(t, err)
+	_, err = WriteFramed(&out, []byte(`{"id":2}`), FramingJSONL)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", out.String())
+
+	scanner := bufio.NewScanner(&out)
+	scanner.Split((&Splitter{MaxContentLength: 100, Framing: FramingJSONL}).Split)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{`{"id":1}`, `{"id":2}`}, tokens)
+}
+
+func TestSplitterJSONL(t *testing.T) {
+	long := `{"x":"` + strings.Repeat("x", 300) + `"}`
+	input := "{\"id\":1}\r\n\n  \n" + long + "\n{\"id\":2}\n{\"id\":3}"
+
+	var dropped []int
+	splitter := &Splitter{
+		MaxContentLength: 100,
+		Framing:          FramingJSONL,
+		OnOversized:      func(n int, head, tail []byte) { dropped = append(dropped, n) },
+	}
+	for _, reader := range []func(string) *bufio.Scanner{
+		func(s string) *bufio.Scanner { return bufio.NewScanner(strings.NewReader(s)) },
+		func(s string) *bufio.Scanner { return bufio.NewScanner(iotest.OneByteReader(strings.NewReader(s))) },
+	} {
+		dropped = nil
+		scanner := reader(input)
+		scanner.Buffer(make([]byte, 0, 16), 200)
+		scanner.Split(splitter.Split)
+		var tokens []string
+		for scanner.Scan() {
+			tokens = append(tokens, scanner.Text())
+		}
+		assert.NoError(t, scanner.Err())
+		assert.Equal(t, []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}, tokens)
+		assert.Len(t, dropped, 1)
+	}
+}
@@ -15,77 +15,341 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"strconv"
-	"unicode"
+	"strings"
 )
 
 // SplitFunc is a bufio.SplitFunc implementation that splits JsonRPC messages.
+// Bodies sent with a Content-Encoding header are decompressed.
 func SplitFunc(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	i := 0
+	headerLength, h, err := readHeader(data, atEOF)
+	if err != nil || headerLength == 0 {
+		return
+	}
 
-	maybeAddEOFError := func() {
+	// Not enough input yet; try again later.
+	i := headerLength
+	if i+h.contentLength > len(data) {
 		if atEOF {
 			err = errors.New("Expected more content")
 		}
+		return
+	}
+
+	// Return the token
+	advance = i + h.contentLength
+	token, err = decode(h.contentEncoding, data[i:i+h.contentLength], -1)
+	return
+}
+
+// Longest header line that is accepted.
+const maxHeaderLineLength = 1024
+
+// header holds the fields of a message header that lspc understands. Others,
+// ie, Content-Type, are ignored.
+type header struct {
+	contentLength   int
+	contentEncoding string
+	acceptEncoding  string
+}
+
+// readHeader parses the header at the start of data. headerLength is 0 if
+// more input is needed.
+func readHeader(data []byte, atEOF bool) (headerLength int, h header, err error) {
+	// The stream ended cleanly between messages.
+	if atEOF && len(data) == 0 {
+		return
 	}
 
-	readString := func(content string) {
-		for _, c := range content {
-			// Not enough input yet; try again later.
-			if i >= len(data) {
-				maybeAddEOFError()
-				return
+	h.contentLength = -1
+	i := 0
+	for {
+		end := bytes.Index(data[i:], []byte("\r\n"))
+		if end < 0 {
+			// Not enough input yet; fail early if this cannot be a header.
+			partial := data[i:]
+			if colon := bytes.IndexByte(partial, ':'); colon >= 0 {
+				partial = partial[:colon]
 			}
+			// The \r of the next \r\n may have arrived without the \n.
+			partial = bytes.TrimSuffix(partial, []byte("\r"))
+			for _, c := range partial {
+				if !isHeaderNameByte(c) {
+					return 0, h, fmt.Errorf("Unexpected token '%c'", c)
+				}
+			}
+			if len(data)-i > maxHeaderLineLength {
+				return 0, h, errors.New("Header line too long")
+			}
+			if atEOF {
+				err = errors.New("Expected more content")
+			}
+			return 0, h, err
+		}
+
+		line := data[i : i+end]
+		i += end + 2
+		if len(line) == 0 {
+			break
+		}
 
-			if data[i] != byte(c) {
-				err = fmt.Errorf("Unexpected token '%c'", data[i])
-				return
+		colon := bytes.IndexByte(line, ':')
+		if colon <= 0 {
+			return 0, h, fmt.Errorf("Invalid header line %q", line)
+		}
+		for _, c := range line[:colon] {
+			if !isHeaderNameByte(c) {
+				return 0, h, fmt.Errorf("Unexpected token '%c'", c)
 			}
-			i++
+		}
+		value := string(bytes.Trim(line[colon+1:], " \t"))
+		switch strings.ToLower(string(line[:colon])) {
+		case "content-length":
+			if h.contentLength, err = strconv.Atoi(value); err != nil || h.contentLength < 0 {
+				return 0, h, fmt.Errorf("Invalid Content-Length %q", value)
+			}
+		case "content-encoding":
+			h.contentEncoding = value
+		case "accept-encoding":
+			h.acceptEncoding = value
 		}
 	}
 
-	// Read Content-Length:
-	if readString("Content-Length: "); err != nil {
-		return
+	if h.contentLength < 0 {
+		return 0, h, errors.New("Missing Content-Length header")
 	}
+	return i, h, nil
+}
+
+func isHeaderNameByte(c byte) bool {
+	return c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Number of bytes from the start and end of an oversized message passed to
+// Splitter.OnOversized.
+const oversizedSampleSize = 256
+
+// Splitter splits JsonRPC messages like SplitFunc, except that messages with a
+// Content-Length above MaxContentLength are consumed and dropped instead of
+// failing the scanner. The scanner buffer only needs to hold MaxContentLength
+// plus the header.
+type Splitter struct {
+	MaxContentLength int
 
-	// Read the number.
-	digitStart := i
+	// How messages are delimited. Empty is FramingLSP.
+	Framing Framing
+
+	// OnOversized is called after a message has been dropped with its
+	// declared length and up to 256 bytes from its start and end, which can
+	// be used to find the request the message belongs to.
+	OnOversized func(contentLength int, head, tail []byte)
+
+	// OnAcceptEncoding is called with the encodings listed in an
+	// Accept-Encoding header, see Compression.PeerAccepts.
+	OnAcceptEncoding func(encodings []string)
+
+	// Resync skips input that is not a valid header up to the next
+	// Content-Length header instead of failing the scanner, ie, when a server
+	// writes garbage between messages. OnSkipped is called with the bytes
+	// that were skipped, possibly in several pieces.
+	Resync    bool
+	OnSkipped func(skipped []byte)
+
+	// SkipStartupNoise discards lines before the first message that are not
+	// part of a header, ie, banners some servers print to stdout on startup.
+	// OnNoise is called with each discarded line.
+	SkipStartupNoise bool
+	OnNoise          func(line []byte)
+	sawHeader        bool
+
+	// State of the message being dropped.
+	skipping      bool
+	remaining     int
+	contentLength int
+	head          []byte
+	tail          []byte
+}
+
+// Split implements bufio.SplitFunc.
+func (s *Splitter) Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// bufio.Scanner reads more input after data is skipped without a token
+	// even if it already holds the next message, which would then wait for
+	// the server to write again. Keep going while there is data.
 	for {
-		// Not enough input yet; try again later.
-		if i >= len(data) {
-			maybeAddEOFError()
-			return
+		n, token, err := s.split(data[advance:], atEOF)
+		advance += n
+		if err != nil || token != nil || n == 0 || advance == len(data) {
+			return advance, token, err
 		}
+	}
+}
 
-		// Read until we have a number
-		if !unicode.IsDigit(rune(data[i])) {
-			break
+func (s *Splitter) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if s.Framing == FramingJSONL {
+		return s.splitLine(data, atEOF)
+	}
+
+	if s.SkipStartupNoise && !s.sawHeader {
+		switch n := noiseLength(data, atEOF); {
+		case n < 0:
+			return 0, nil, nil
+		case n > 0:
+			if s.OnNoise != nil {
+				s.OnNoise(data[:n])
+			}
+			return n, nil, nil
 		}
+	}
 
-		i++
+	if !s.skipping {
+		headerLength, h, err := readHeader(data, atEOF)
+		if err != nil && s.Resync {
+			return s.resync(data, atEOF)
+		}
+		if err != nil || headerLength == 0 {
+			return 0, nil, err
+		}
+		s.sawHeader = true
+		if h.contentLength <= s.MaxContentLength {
+			if headerLength+h.contentLength > len(data) {
+				if atEOF {
+					err = errors.New("Expected more content")
+				}
+				return 0, nil, err
+			}
+			// Also limit the decompressed size.
+			token, err := decode(h.contentEncoding, data[headerLength:headerLength+h.contentLength], s.MaxContentLength)
+			if err != nil {
+				return 0, nil, err
+			}
+			s.acceptEncoding(h)
+			return headerLength + h.contentLength, token, nil
+		}
+		s.acceptEncoding(h)
+		s.skipping = true
+		s.remaining = h.contentLength
+		s.contentLength = h.contentLength
+		s.head = s.head[:0]
+		s.tail = s.tail[:0]
+		return headerLength, nil, nil
 	}
-	contentLength, err := strconv.Atoi(string(data[digitStart:i]))
-	if err != nil {
-		return
+
+	if atEOF && len(data) < s.remaining {
+		return 0, nil, errors.New("Expected more content")
 	}
 
-	// Read \r\n\r\n
-	if readString("\r\n\r\n"); err != nil {
-		return
+	n := len(data)
+	if n > s.remaining {
+		n = s.remaining
 	}
+	s.keepSample(data[:n])
+	s.remaining -= n
 
-	// Not enough input yet; try again later.
-	if i+contentLength > len(data) {
-		maybeAddEOFError()
-		return
+	if s.remaining == 0 {
+		s.skipping = false
+		if s.OnOversized != nil {
+			s.OnOversized(s.contentLength, s.head, s.tail)
+		}
 	}
+	return n, nil, nil
+}
 
-	// Return the token
-	advance = i + contentLength
-	token = data[i : i+contentLength]
-	return
+// keepSample remembers the start and end of the oversized message being
+// skipped for OnOversized.
+func (s *Splitter) keepSample(skipped []byte) {
+	if missing := oversizedSampleSize - len(s.head); missing > 0 {
+		if missing > len(skipped) {
+			missing = len(skipped)
+		}
+		s.head = append(s.head, skipped[:missing]...)
+	}
+	if len(skipped) > oversizedSampleSize {
+		s.tail = append(s.tail[:0], skipped[len(skipped)-oversizedSampleSize:]...)
+	} else {
+		s.tail = append(s.tail, skipped...)
+	}
+	if len(s.tail) > oversizedSampleSize {
+		s.tail = append(s.tail[:0], s.tail[len(s.tail)-oversizedSampleSize:]...)
+	}
+}
+
+func (s *Splitter) acceptEncoding(h header) {
+	if h.acceptEncoding != "" && s.OnAcceptEncoding != nil {
+		s.OnAcceptEncoding(strings.Split(h.acceptEncoding, ","))
+	}
+}
+
+var contentLengthHeader = []byte("content-length:")
+
+// resync skips data up to the next Content-Length header after its first
+// byte, which is where the next message most likely starts.
+func (s *Splitter) resync(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	n := indexContentLength(data[1:])
+	switch {
+	case n >= 0:
+		n++
+	case atEOF:
+		n = len(data)
+	default:
+		// The end of data may be the start of a header.
+		n = len(data) - len(contentLengthHeader) + 1
+		if n <= 0 {
+			return 0, nil, nil
+		}
+	}
+	if s.OnSkipped != nil {
+		s.OnSkipped(data[:n])
+	}
+	return n, nil, nil
+}
+
+// indexContentLength returns the index of the first Content-Length header
+// name in data, ignoring case, or -1. Unlike bytes.ToLower this does not
+// change the length of data if it is not valid UTF-8.
+func indexContentLength(data []byte) int {
+	for i := 0; i+len(contentLengthHeader) <= len(data); i++ {
+		if data[i]|0x20 == 'c' && bytes.EqualFold(data[i:i+len(contentLengthHeader)], contentLengthHeader) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Every header an LSP server sends starts with this, ignoring case.
+var contentHeaderPrefix = []byte("content-")
+
+// noiseLength returns the length of the line at the start of data, including
+// its newline, if it cannot start a header. Returns 0 if it can and -1 if
+// more input is needed to tell.
+func noiseLength(data []byte, atEOF bool) int {
+	if len(data) == 0 {
+		return 0
+	}
+	end := bytes.IndexByte(data, '\n')
+	line := data
+	if end >= 0 {
+		line = data[:end]
+	}
+	n := len(line)
+	if n > len(contentHeaderPrefix) {
+		n = len(contentHeaderPrefix)
+	}
+	if bytes.EqualFold(line[:n], contentHeaderPrefix[:n]) {
+		if n == len(contentHeaderPrefix) {
+			return 0
+		}
+		if end < 0 && !atEOF {
+			return -1
+		}
+	}
+	switch {
+	case end >= 0:
+		return end + 1
+	case atEOF:
+		return len(data)
+	}
+	return -1
 }
@@ -16,12 +16,22 @@ package jsonrpc
 
 import (
 	"bufio"
+	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestReadNoMessages(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	scanner.Split(SplitFunc)
+	assert.False(t, scanner.Scan())
+	assert.NoError(t, scanner.Err())
+}
+
 func TestReadBadHeader(t *testing.T) {
 	input := "foobar"
 	scanner := bufio.NewScanner(strings.NewReader(input))
@@ -83,3 +93,149 @@ func TestReadMultipleMessages(t *testing.T) {
 	scanner.Scan()
 	assert.Error(t, scanner.Err())
 }
+
+func TestSplitterSkipsOversizedMessages(t *testing.T) {
+	large := "{" + strings.Repeat("x", 1000) + "}"
+	input := "Content-Length: 3\r\n\r\nabc" +
+		"Content-Length: 1002\r\n\r\n" + large +
+		"Content-Length: 5\r\n\r\n12345"
+
+	var skipped []int
+	var head, tail string
+	splitter := &Splitter{
+		MaxContentLength: 100,
+		OnOversized: func(contentLength int, h, t []byte) {
+			skipped = append(skipped, contentLength)
+			head, tail = string(h), string(t)
+		},
+	}
+	// Use a reader that returns little data at a time so the message is
+	// dropped over several calls.
+	scanner := bufio.NewScanner(iotest.HalfReader(strings.NewReader(input)))
+	scanner.Buffer(make([]byte, 0, 16), 200)
+	scanner.Split(splitter.Split)
+
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"abc", "12345"}, tokens)
+	assert.Equal(t, []int{1002}, skipped)
+	assert.Equal(t, large[:256], head)
+	assert.Equal(t, large[len(large)-256:], tail)
+}
+
+func TestSplitterTruncatedOversizedMessage(t *testing.T) {
+	input := "Content-Length: 1000\r\n\r\nabc"
+	splitter := &Splitter{MaxContentLength: 100}
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(splitter.Split)
+	assert.False(t, scanner.Scan())
+	assert.Error(t, scanner.Err())
+}
+
+func TestSplitterResyncsAfterGarbage(t *testing.T) {
+	input := "Content-Length: 3\r\n\r\nabc" +
+		"garbage\r\n\x00\xff" +
+		"content-length: 5\r\n\r\n12345" +
+		"Content-Length: xx\r\n\r\n" +
+		"Content-Length: 2\r\n\r\nok" +
+		"trailing"
+
+	var skipped string
+	splitter := &Splitter{
+		MaxContentLength: 100,
+		Resync:           true,
+		OnSkipped:        func(b []byte) { skipped += string(b) },
+	}
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	scanner.Split(splitter.Split)
+
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"abc", "12345", "ok"}, tokens)
+	assert.Equal(t, "garbage\r\n\x00\xffContent-Length: xx\r\n\r\ntrailing", skipped)
+}
+
+func TestSplitterWithoutResyncFailsOnGarbage(t *testing.T) {
+	input := "Content-Length: 3\r\n\r\nabcgarbage\r\n"
+	splitter := &Splitter{MaxContentLength: 100}
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(splitter.Split)
+	assert.True(t, scanner.Scan())
+	assert.False(t, scanner.Scan())
+	assert.Error(t, scanner.Err())
+}
+
+func TestReadHeaderArrivingByteByByte(t *testing.T) {
+	input := "Content-Length: 3\r\n\r\nabc"
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	scanner.Split(SplitFunc)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "abc", scanner.Text())
+	assert.False(t, scanner.Scan())
+	assert.NoError(t, scanner.Err())
+}
+
+func TestSplitterSkipsStartupNoise(t *testing.T) {
+	input := "server v1.2 starting\n" +
+		"INFO: loading config\r\n" +
+		"\n" +
+		"Con\n" +
+		"Content-Type: application/vscode-jsonrpc\r\nContent-Length: 3\r\n\r\nabc" +
+		"Content-Length: 2\r\n\r\nok"
+
+	var noise []string
+	splitter := &Splitter{
+		MaxContentLength: 100,
+		SkipStartupNoise: true,
+		OnNoise:          func(line []byte) { noise = append(noise, string(line)) },
+	}
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	scanner.Split(splitter.Split)
+
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"abc", "ok"}, tokens)
+	assert.Equal(t, []string{"server v1.2 starting\n", "INFO: loading config\r\n", "\n", "Con\n"}, noise)
+}
+
+func TestSplitterOnlySkipsNoiseBeforeFirstMessage(t *testing.T) {
+	input := "Content-Length: 3\r\n\r\nabcbanner\n"
+	splitter := &Splitter{MaxContentLength: 100, SkipStartupNoise: true}
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(splitter.Split)
+	assert.True(t, scanner.Scan())
+	assert.False(t, scanner.Scan())
+	assert.Error(t, scanner.Err())
+}
+
+func TestSplitterDoesNotWaitAfterSkipping(t *testing.T) {
+	// The server writes a banner and its first message at once and then
+	// waits for a reply.
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("banner\nContent-Length: 2\r\n\r\nok"))
+
+	splitter := &Splitter{MaxContentLength: 100, SkipStartupNoise: true}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(splitter.Split)
+	scanned := make(chan string)
+	go func() {
+		scanner.Scan()
+		scanned <- scanner.Text()
+	}()
+	select {
+	case token := <-scanned:
+		assert.Equal(t, "ok", token)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not returned until more input arrived")
+	}
+}
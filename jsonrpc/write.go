@@ -0,0 +1,50 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Buffers larger than this are not returned to the pool so that one huge
+// message does not pin memory for the lifetime of the process.
+const maxPooledBufferSize = 1024 * 1024
+
+var framePool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// WriteMessage writes body to w prefixed with its Content-Length header. The
+// message is assembled in a pooled buffer and written with a single call to
+// w.Write.
+func WriteMessage(w io.Writer, body []byte) (int, error) {
+	frame := framePool.Get().(*bytes.Buffer)
+	defer func() {
+		if frame.Cap() <= maxPooledBufferSize {
+			frame.Reset()
+			framePool.Put(frame)
+		}
+	}()
+
+	var length [20]byte
+	frame.WriteString("Content-Length: ")
+	frame.Write(strconv.AppendInt(length[:0], int64(len(body)), 10))
+	frame.WriteString("\r\n\r\n")
+	frame.Write(body)
+	return w.Write(frame.Bytes())
+}
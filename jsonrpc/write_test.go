@@ -0,0 +1,50 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMessageRoundTrip(t *testing.T) {
+	var out bytes.Buffer
+	n, err := WriteMessage(&out, []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, out.Len(), n)
+	_, err = WriteMessage(&out, []byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Content-Length: 8\r\n\r\n{\"id\":1}Content-Length: 0\r\n\r\n", out.String())
+
+	scanner := bufio.NewScanner(&out)
+	scanner.Split(SplitFunc)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, `{"id":1}`, scanner.Text())
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "", scanner.Text())
+	assert.False(t, scanner.Scan())
+}
+
+func BenchmarkWriteMessage(b *testing.B) {
+	body := bytes.Repeat([]byte("x"), 4096)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteMessage(ioutil.Discard, body)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KindConfig changes how a symbol or completion kind is printed.
+type KindConfig struct {
+	// Printed instead of the kind's name, ie, "fn" for function.
+	Name string `toml:"name,omitempty"`
+
+	// Printed before the name with --icons. Replaces the default nerd font
+	// icon.
+	Icon string `toml:"icon,omitempty"`
+}
+
+// Nerd font icons printed with --icons, the codicons VS Code uses, keyed by
+// the names of symbolKindNames and completionItemKindNames.
+var defaultKindIcons = map[string]string{
+	"array":          "\uea8a",
+	"boolean":        "\uea8f",
+	"class":          "\ueb5b",
+	"color":          "\ueb5c",
+	"constant":       "\ueb5d",
+	"constructor":    "\uea8c",
+	"enum":           "\uea95",
+	"enum member":    "\ueb5e",
+	"event":          "\uea86",
+	"field":          "\ueb5f",
+	"file":           "\uea7b",
+	"folder":         "\uea83",
+	"function":       "\uea8c",
+	"interface":      "\ueb61",
+	"key":            "\uea93",
+	"keyword":        "\ueb62",
+	"method":         "\uea8c",
+	"module":         "\ueb29",
+	"namespace":      "\uea8b",
+	"null":           "\ueb63",
+	"number":         "\uea90",
+	"object":         "\uea8b",
+	"operator":       "\ueb64",
+	"package":        "\ueb29",
+	"property":       "\ueb65",
+	"reference":      "\ueb36",
+	"snippet":        "\ueb66",
+	"string":         "\ueb8d",
+	"struct":         "\uea91",
+	"text":           "\uea93",
+	"type parameter": "\uea92",
+	"unit":           "\uea96",
+	"value":          "\uea95",
+	"variable":       "\uea88",
+}
+
+// normalizeKindName lets kinds be written as "enum-member" or "enum_member",
+// which unlike "enum member" need no quotes as toml keys.
+func normalizeKindName(name string) string {
+	return strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// isKindName returns true if name is a symbol or completion kind.
+func isKindName(name string) bool {
+	for _, names := range [][]string{symbolKindNames, completionItemKindNames} {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateKinds reports every key of kinds that is not a kind name.
+func validateKinds(kinds map[string]KindConfig) map[string]error {
+	problems := make(map[string]error)
+	for key := range kinds {
+		if !isKindName(normalizeKindName(key)) {
+			problems[key] = fmt.Errorf("unknown kind")
+		}
+	}
+	return problems
+}
+
+// kindLabel returns how the kind called name is printed, ie, its configured
+// name and, if icons is set, its icon in front.
+func (c *Config) kindLabel(name string, icons bool) string {
+	label, icon := name, defaultKindIcons[name]
+	for key, kind := range c.Kinds {
+		if normalizeKindName(key) != name {
+			continue
+		}
+		if kind.Name != "" {
+			label = kind.Name
+		}
+		if kind.Icon != "" {
+			icon = kind.Icon
+		}
+	}
+	if icons && icon != "" {
+		return icon + " " + label
+	}
+	return label
+}
+
+// kindNames lists every symbol and completion kind, for `lspc kinds`.
+func kindNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, list := range [][]string{symbolKindNames, completionItemKindNames} {
+		for _, name := range list {
+			if !seen[name] && name != "unknown" {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
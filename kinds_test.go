@@ -0,0 +1,53 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindLabel(t *testing.T) {
+	config := &Config{Kinds: map[string]KindConfig{
+		"function":    {Name: "fn"},
+		"enum-member": {Icon: "E"},
+	}}
+	assert.Equal(t, "fn", config.kindLabel("function", false))
+	assert.Equal(t, "\uea8c fn", config.kindLabel("function", true))
+	assert.Equal(t, "E enum member", config.kindLabel("enum member", true))
+	assert.Equal(t, "class", config.kindLabel("class", false))
+	assert.Equal(t, "unknown", config.kindLabel("unknown", true))
+	assert.Equal(t, "class", (&Config{}).kindLabel("class", false))
+}
+
+func TestValidateKinds(t *testing.T) {
+	problems := validateKinds(map[string]KindConfig{
+		"Enum_Member": {Name: "member"},
+		"operator":    {},
+		"widget":      {Name: "w"},
+	})
+	assert.Len(t, problems, 1)
+	assert.Contains(t, problems, "widget")
+}
+
+func TestDefaultKindIcons(t *testing.T) {
+	for _, name := range kindNames() {
+		assert.NotEmpty(t, defaultKindIcons[name], name)
+	}
+	for name := range defaultKindIcons {
+		assert.True(t, isKindName(name), name)
+	}
+}
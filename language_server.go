@@ -16,10 +16,17 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/jacobdufault/lspc/jsonrpc"
 	easyjson "github.com/mailru/easyjson"
@@ -27,42 +34,198 @@ import (
 	shellwords "github.com/mattn/go-shellwords"
 )
 
-// When a language server has been closed it is sent to this channel.
-// If this ever blocks the daemon may deadlock.
-var languageServerClosed = make(chan *languageServer, 1000)
+// Largest serialization buffer kept by a language server for reuse.
+const maxReusedBodySize = 1024 * 1024
 
-type responseHandler func(json easyjson.RawMessage)
+// When a language server has been closed it is sent to this queue. If this
+// ever blocks the daemon may deadlock, which shows up in `lspc top`.
+var languageServerClosed = newServerQueue("closed servers", closedQueueSize)
+
+// responseHandler is called with the result of a request. If the request
+// failed err is non-nil and result should be ignored.
+type responseHandler func(result easyjson.RawMessage, err *LsResponseError)
 
 type languageServer struct {
 	cmd *exec.Cmd
+	// The command line of the server. Differs from cmd.Args if the server
+	// runs in a sandbox.
+	args []string
+
+	// Unique id of the language server; ids are never reused within a daemon.
+	id int
+
+	// The arguments the server was started with.
+	startArgs StartArgs
+	// The effective init options sent with initialize.
+	initOpts easyjson.RawMessage
+	// Name of the configured language, or empty.
+	language string
 
 	// Directory the language server is running in. Used to determine which
 	// language server instance to send a message to.
-	directory     string
+	directory string
+	// Files in directory must not be modified, see checkWritable.
+	readOnly bool
+	// How messages to and from the server are delimited.
+	framing jsonrpc.Framing
+
+	// mu guards lastUsed, settings, nextRequestID, onResponse, initialized,
+	// initErr, pending, initializeResult, version, compat and diagnostics,
+	// which are
+	// accessed from both rpc handlers and stdoutReader.
+	mu sync.Mutex
+	// When a client last sent a request to the server.
+	lastUsed time.Time
+	// Settings from the config, used to answer workspace/configuration.
+	settings      easyjson.RawMessage
 	nextRequestID RequestID
 	onResponse    map[RequestID]responseHandler
 
+	// Set once the initialize response has been received and the initialized
+	// notification sent. Until then messages other than initialize are queued
+	// in pending, since the protocol does not allow sending them.
+	initialized bool
+	// The error initialize failed with. Messages are not queued once it is
+	// set, since the server never finishes initializing.
+	initErr *LsResponseError
+	pending []JSONRPCHeader
+	// Number of messages dropped because pending was full.
+	pendingDropped int
+	// The initialize result, nil until it has been received.
+	initializeResult *LsInitializeResult
+	// Name and version of the server from the initialize result or
+	// --version, or empty if unknown.
+	version string
+	// Protocol features used with the server. Narrowed by the capabilities in
+	// the initialize result.
+	compat compat
+
+	// The latest diagnostics published for each document. Guarded by mu.
+	diagnostics map[LsDocumentURI][]LsDiagnostic
+	// Applied to diagnostics as they are published. Guarded by mu.
+	diagnosticRules []diagnosticRule
+	// Decide how documents are read. Guarded by mu.
+	encodingRules []encodingRule
+	documentLimit documentLimit
+	// Receive the requests and notifications of the server for raw-io
+	// sessions. Guarded by mu.
+	taps map[chan []byte]struct{}
+
+	// docMu guards documents, the text of every document that has been sent
+	// with didOpen, versions, the version of the text the server has,
+	// encodings, the encoding each document was read with, and tokens, the
+	// latest semantic tokens of each document.
+	docMu     sync.Mutex
+	documents map[LsDocumentURI]string
+	versions  map[LsDocumentURI]int
+	encodings map[LsDocumentURI]fileEncoding
+	tokens    map[LsDocumentURI]semanticTokensState
+	// Incremented whenever the tokens of a document change; used as the id
+	// of tokens given to clients.
+	tokensVersion int
+
+	// editMu orders requests against edits to files of the project: requests
+	// that only read, ie, everything but writingMethods, hold it for reading
+	// and run in parallel, while applyPlan holds it for writing so that no
+	// request sees a half-applied edit and edits are applied one at a time.
+	editMu sync.RWMutex
+
+	// writeMu serializes writes to stdin so that messages do not interleave.
+	// stdinClosed, err, jw and body are also guarded by writeMu.
+	writeMu     sync.Mutex
+	stdinClosed bool
+	// The first error writing to, reading from or waiting on the server.
+	// Nothing is written to the server once it is set.
 	err error
+	// Reused for every message written to the server to avoid allocating.
+	jw   jwriter.Writer
+	body []byte
+
+	stats *serverStats
+
+	// readers is done once stdout and stderr have been drained; the process
+	// cannot be waited on before that.
+	readers sync.WaitGroup
+	// done is closed after the process has exited and been reaped. exitStatus
+	// is set before done is closed.
+	done       chan struct{}
+	exitStatus string
 
 	stdin  io.WriteCloser
 	stdout io.ReadCloser
 	stderr io.ReadCloser
 }
 
-func startLanguageServer(bin, directory string, initOpts easyjson.RawMessage) (*languageServer, error) {
-	exe, e := shellwords.Parse(bin)
+// processOptions configures the process of a language server.
+type processOptions struct {
+	sandbox  SandboxConfig
+	process  ProcessConfig
+	readOnly bool
+	// NAME=value added to the environment the language server inherits from the
+	// daemon.
+	env []string
+}
+
+// startLanguageServer runs the language server described by args. language is
+// the configured language, if any. initOpts are the effective init options,
+// which may differ from args.InitOpts.
+func startLanguageServer(id int, args StartArgs, language string, initOpts easyjson.RawMessage, c compat, opts processOptions) (*languageServer, error) {
+	exe, e := shellwords.Parse(args.Bin)
+	if e != nil {
+		return nil, fmt.Errorf("cannot parse <%s>; error=%s", args.Bin, e.Error())
+	}
+	if len(exe) == 0 {
+		return nil, fmt.Errorf("no program in <%s>", args.Bin)
+	}
+	framing, e := jsonrpc.ParseFraming(args.Framing)
+	if e != nil {
+		return nil, e
+	}
+	argv, e := sandboxCommand(opts.sandbox, exe, args.Directory, opts.readOnly)
 	if e != nil {
-		return nil, fmt.Errorf("cannot parse <%s>; error=%s", bin, e.Error())
+		return nil, e
+	}
+	if opts.process.Umask != "" {
+		if argv, e = umaskCommand(opts.process.Umask, argv); e != nil {
+			return nil, e
+		}
+	}
+	if opts.process.CloseFds {
+		if argv, e = closeFilesCommand(argv); e != nil {
+			return nil, e
+		}
 	}
 
 	ls := languageServer{
-		directory:  directory,
-		onResponse: make(map[RequestID]responseHandler),
+		id:          id,
+		args:        exe,
+		startArgs:   args,
+		initOpts:    initOpts,
+		language:    language,
+		directory:   args.Directory,
+		readOnly:    opts.readOnly,
+		framing:     framing,
+		lastUsed:    time.Now(),
+		onResponse:  make(map[RequestID]responseHandler),
+		stats:       newServerStats(),
+		done:        make(chan struct{}),
+		compat:      c,
+		diagnostics: make(map[LsDocumentURI][]LsDiagnostic),
+		documents:   make(map[LsDocumentURI]string),
+		versions:    make(map[LsDocumentURI]int),
+		encodings:   make(map[LsDocumentURI]fileEncoding),
+		tokens:      make(map[LsDocumentURI]semanticTokensState),
 	}
 
 	// Start the binary.
-	ls.cmd = exec.Command(exe[0], exe[1:]...)
-	ls.cmd.Dir = directory
+	ls.cmd = exec.Command(argv[0], argv[1:]...)
+	ls.cmd.Dir = args.Directory
+	if len(opts.env) > 0 {
+		ls.cmd.Env = append(os.Environ(), opts.env...)
+	}
+	if e := setCredentials(ls.cmd, opts.process.User, opts.process.Group); e != nil {
+		return nil, e
+	}
 	ls.stdin, e = ls.cmd.StdinPipe()
 	if e != nil {
 		return nil, e
@@ -82,8 +245,10 @@ func startLanguageServer(bin, directory string, initOpts easyjson.RawMessage) (*
 	}
 
 	// Handle all process input/output on goroutines.
+	ls.readers.Add(2)
 	go ls.stdoutReader()
 	go ls.stderrReader()
+	go ls.wait()
 
 	ls.writeInitialize(initOpts)
 
@@ -91,32 +256,117 @@ func startLanguageServer(bin, directory string, initOpts easyjson.RawMessage) (*
 }
 
 // Write a request, which will have an associated response.
-func (l *languageServer) writeRequest(method string, params easyjson.RawMessage, onResponse responseHandler) {
-
-	id := l.nextRequestID
-	l.nextRequestID++
-
+func (l *languageServer) writeRequest(method string, params easyjson.RawMessage, onResponse responseHandler) RequestID {
 	// Use a dummy handler if the user does not care about the result. This
 	// prevents log spam from unexpected responses.
 	if onResponse == nil {
-		onResponse = func(_ easyjson.RawMessage) {}
+		onResponse = func(_ easyjson.RawMessage, _ *LsResponseError) {}
+	}
+
+	start := time.Now()
+	handler := func(result easyjson.RawMessage, err *LsResponseError) {
+		l.stats.recordResponse(method, time.Since(start), err != nil)
+		onResponse(result, err)
 	}
 
-	l.onResponse[id] = onResponse
+	l.mu.Lock()
+	id := l.nextRequestID
+	l.nextRequestID++
+	l.onResponse[id] = handler
+	l.mu.Unlock()
+
 	l.rawWriteMsg(method, params, id)
+	return id
 }
 
-func (l *languageServer) writeNotification(method string, params easyjson.RawMessage) {
-	l.rawWriteMsg(method, params, -1)
+// cancelRequest gives up on request id unless it was already answered: its
+// handler is called with a RequestCancelled error and the server is sent
+// $/cancelRequest, so that it can stop working on it. Without removing the
+// handler a server that never answers would leak it.
+func (l *languageServer) cancelRequest(id RequestID, reason string) {
+	l.mu.Lock()
+	handler, has := l.onResponse[id]
+	delete(l.onResponse, id)
+	l.mu.Unlock()
+	if !has {
+		return
+	}
+	l.writeNotification("$/cancelRequest", toJSON(LsCancelParams{ID: id}))
+	handler(nil, &LsResponseError{Code: RequestCancelled, Message: reason})
 }
 
-// id will only be written to json if it is >= 0
-func (l *languageServer) rawWriteMsg(method string, params easyjson.RawMessage, id RequestID) {
-	if l.err != nil {
-		log.Printf("Attempt to write message while language server has error %s", l.err.Error())
-		return
+// call writes a request and blocks until the language server responds or
+// timeout elapses, in which case the request is cancelled. A null result is
+// returned as null rather than empty. Requests the server did not announce
+// support for fail without being sent.
+func (l *languageServer) call(method string, params easyjson.RawMessage, timeout time.Duration) (easyjson.RawMessage, error) {
+	type response struct {
+		result easyjson.RawMessage
+		err    *LsResponseError
+	}
+	if err := l.checkSupported(method); err != nil {
+		return nil, err
 	}
+	l.mu.Lock()
+	l.lastUsed = time.Now()
+	l.mu.Unlock()
+
+	// Writing methods may make the server send workspace/applyEdit, which
+	// takes editMu for writing before the request returns.
+	if !writingMethods[method] {
+		l.editMu.RLock()
+		defer l.editMu.RUnlock()
+	}
+
+	done := make(chan response, 1)
+	id := l.writeRequest(method, params, func(result easyjson.RawMessage, err *LsResponseError) {
+		done <- response{result, err}
+	})
 
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		if len(r.result) == 0 {
+			return easyjson.RawMessage("null"), nil
+		}
+		return r.result, nil
+	case <-time.After(timeout):
+		err := fmt.Errorf("%s timed out after %s", method, timeout)
+		l.cancelRequest(id, err.Error())
+		return nil, err
+	}
+}
+
+// writeNotification writes a notification. Returns false if it was dropped,
+// see rawWriteMsg.
+func (l *languageServer) writeNotification(method string, params easyjson.RawMessage) bool {
+	return l.rawWriteMsg(method, params, -1)
+}
+
+// writeResponse answers a request sent by the language server. If err is nil
+// result is sent, with a nil result being written as null.
+func (l *languageServer) writeResponse(id RequestID, result easyjson.RawMessage, err *LsResponseError) {
+	content := JSONRPCHeader{
+		JSONRPC: "2.0",
+		ID:      id,
+	}
+	if err != nil {
+		content.Error = err
+	} else if result == nil {
+		content.Result = easyjson.RawMessage("null")
+	} else {
+		content.Result = result
+	}
+	l.writeContent(content)
+}
+
+// id will only be written to json if it is >= 0. Until the server has
+// finished initializing messages are queued in pending; once it is full, or
+// if initialize failed, they are dropped, requests fail and false is
+// returned.
+func (l *languageServer) rawWriteMsg(method string, params easyjson.RawMessage, id RequestID) bool {
 	// content.ID is not written if it is less than 0
 	content := JSONRPCHeader{
 		JSONRPC: "2.0",
@@ -125,85 +375,606 @@ func (l *languageServer) rawWriteMsg(method string, params easyjson.RawMessage,
 		Params:  params,
 	}
 
-	if _, e := marshalToWriter(content, l.stdin); e != nil {
-		l.err = e
-		languageServerClosed <- l
+	l.mu.Lock()
+	if !l.initialized && method != "initialize" {
+		if l.initErr != nil {
+			handler := l.onResponse[id]
+			delete(l.onResponse, id)
+			initErr := l.initErr
+			l.mu.Unlock()
+
+			log.Printf("Dropping %s; %s failed to initialize", method, l.name())
+			if handler != nil {
+				handler(nil, initFailedError(initErr))
+			}
+			return false
+		}
+		if len(l.pending) < maxPendingMessages {
+			l.pending = append(l.pending, content)
+			l.mu.Unlock()
+			return true
+		}
+		l.pendingDropped++
+		handler := l.onResponse[id]
+		delete(l.onResponse, id)
+		l.mu.Unlock()
+
+		log.Printf("Dropping %s; %d messages are already queued for %s", method, maxPendingMessages, l.name())
+		if handler != nil {
+			handler(nil, &LsResponseError{Code: RequestFailed, Message: l.queueFullError().Error()})
+		}
+		return false
 	}
+	l.mu.Unlock()
 
-	// Uncomment to write the written request to stderr.
-	// marshalToWriter(content, os.Stderr)
+	l.writeContent(content)
+	return true
 }
 
-func marshalToWriter(v easyjson.Marshaler, w io.Writer) (written int, err error) {
-	jw := jwriter.Writer{}
-	jw.Flags = jwriter.NilMapAsEmpty | jwriter.NilSliceAsEmpty
-	v.MarshalEasyJSON(&jw)
+// queueFullError is the error of messages rawWriteMsg drops.
+func (l *languageServer) queueFullError() error {
+	return fmt.Errorf("%s has not finished initializing and its queue is full", l.name())
+}
 
-	// Write header, and then the content
-	fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", jw.Size())
-	return jw.DumpTo(w)
+func (l *languageServer) writeContent(content JSONRPCHeader) {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	l.writeContentLocked(content)
 }
 
-func toJSON(m easyjson.Marshaler) easyjson.RawMessage {
-	jw := jwriter.Writer{}
-	jw.Flags = jwriter.NilMapAsEmpty | jwriter.NilSliceAsEmpty
-	m.MarshalEasyJSON(&jw)
+// writeContentLocked writes content to the language server. writeMu must be
+// held.
+func (l *languageServer) writeContentLocked(content JSONRPCHeader) {
+	if l.err != nil {
+		log.Printf("Attempt to write message while language server has error %s", l.err.Error())
+		return
+	}
+	if l.stdinClosed {
+		log.Printf("Attempt to write %s after closing %s", content.Method, l.name())
+		return
+	}
+
+	var body []byte
+	var e error
+	if c, isBuffered := gCodec.(bufferedCodec); isBuffered {
+		body, e = c.marshalBuffered(content, &l.jw, l.body)
+	} else {
+		body, e = gCodec.marshal(content)
+	}
+	if e != nil {
+		log.Printf("Unable to serialize %s: %s", content.Method, e.Error())
+		return
+	}
+	// Keep the buffer unless a huge message grew it.
+	if cap(body) <= maxReusedBodySize {
+		l.body = body[:0]
+	}
+
+	// The process exiting is reported by wait, so only remember the error.
+	if _, e := jsonrpc.WriteFramed(l.stdin, body, l.framing); e != nil {
+		l.err = e
+	}
 
-	r, e := jw.BuildBytes()
-	panicIfError(e)
-	return r
+	// Uncomment to write the written request to stderr.
+	// jsonrpc.WriteMessage(os.Stderr, body)
 }
 
 func (l *languageServer) writeInitialize(initOpts easyjson.RawMessage) {
+	// Servers watch this process and exit if it dies, so they are not orphaned
+	// if the daemon crashes.
+	pid := os.Getpid()
+
 	// Send input.
+	capabilities := l.compat.clientCapabilities()
+	capabilities.Workspace.WorkspaceFolders = len(l.startArgs.WorkspaceFolders) > 0
+	// Edits are applied while lspc code-action waits for confirmation.
+	capabilities.Workspace.ApplyEdit = true
+	capabilities.Workspace.WorkspaceEdit = &LsWorkspaceEditClientCapabilities{
+		DocumentChanges:    true,
+		ResourceOperations: []string{"create", "rename", "delete"},
+	}
 	l.writeRequest("initialize", toJSON(LsInitializeParams{
+		ProcessID:             &pid,
 		RootURI:               pathToURI(l.directory),
 		InitializationOptions: initOpts,
-	}), func(json easyjson.RawMessage) {
+		Capabilities:          capabilities,
+		WorkspaceFolders:      workspaceFolders(l.startArgs.WorkspaceFolders),
+	}), func(result easyjson.RawMessage, err *LsResponseError) {
+		if err != nil {
+			log.Printf("initialize failed: %s", err.Error())
+			l.failPending(err)
+			return
+		}
 		log.Print("Got initialize response")
+
+		r := &LsInitializeResult{}
+		if e := fromJSON(result, r); e != nil {
+			log.Printf("Unable to parse initialize result %s", string(result))
+		}
+		l.mu.Lock()
+		l.initializeResult = r
+		l.version = serverVersion(r.ServerInfo)
+		l.compat = l.compat.narrow(r.Capabilities)
+		log.Printf("Protocol features for %s: %s", l.name(), l.compat)
+		l.mu.Unlock()
+		if l.version == "" {
+			go l.probeVersion()
+		}
+
+		l.finishInitialize()
+	})
+}
+
+// finishInitialize sends the initialized notification and then everything
+// that was queued while waiting for the initialize response.
+func (l *languageServer) finishInitialize() {
+	// Hold writeMu for the entire flush so that messages written concurrently
+	// are sent after the queued ones.
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	l.mu.Lock()
+	l.initialized = true
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.writeContentLocked(JSONRPCHeader{
+		JSONRPC: "2.0",
+		ID:      -1,
+		Method:  "initialized",
+		Params:  toJSON(NotificationInitialized{}),
 	})
+	for _, content := range pending {
+		l.writeContentLocked(content)
+	}
+}
+
+// failPending drops queued messages after initialize failed and makes
+// rawWriteMsg drop later ones. Queued requests are answered with err.
+func (l *languageServer) failPending(err *LsResponseError) {
+	l.mu.Lock()
+	l.initErr = err
+	pending := l.pending
+	l.pending = nil
+	var handlers []responseHandler
+	for _, content := range pending {
+		if handler, has := l.onResponse[content.ID]; has && content.ID >= 0 {
+			handlers = append(handlers, handler)
+			delete(l.onResponse, content.ID)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(nil, initFailedError(err))
+	}
+}
+
+// initFailedError is the error of requests to a server whose initialize
+// request failed with err.
+func initFailedError(err *LsResponseError) *LsResponseError {
+	return &LsResponseError{
+		Code:    ServerNotInitialized,
+		Message: "initialize failed: " + err.Message,
+	}
+}
+
+// Prepended to message bodies by some servers built on windows.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// trimPayload strips a UTF-8 byte order mark and surrounding whitespace from a
+// message body. JSON parsers reject the byte order mark.
+func trimPayload(message []byte) []byte {
+	message = bytes.TrimSpace(message)
+	message = bytes.TrimPrefix(message, utf8BOM)
+	return bytes.TrimSpace(message)
 }
 
 func (l *languageServer) stdoutReader() {
-	// Build scanner which will process LSP messages.
+	// Build scanner which will process LSP messages. Messages above the limit
+	// are dropped without losing the connection.
+	splitter := &jsonrpc.Splitter{
+		MaxContentLength: gMaxMessageSize * 1024 * 1024,
+		Framing:          l.framing,
+		OnOversized:      l.dropOversized,
+		Resync:           gResyncOutput,
+		OnSkipped:        l.logSkipped,
+		SkipStartupNoise: gSkipStartupNoise,
+		OnNoise:          l.logNoise,
+	}
 	scanner := bufio.NewScanner(l.stdout)
-	scanner.Split(jsonrpc.SplitFunc)
-	// Increase maximum token length; the default is 64 * 1024, which is probably
-	// too low.
-	const maxScanTokenSize = 1024 * 1024
-	scanner.Buffer(make([]byte, 0), maxScanTokenSize)
+	scanner.Split(splitter.Split)
+	// Leave room for the header.
+	scanner.Buffer(make([]byte, 0), splitter.MaxContentLength+1024)
 
 	for scanner.Scan() {
+		// The scanner reuses its buffer, but easyjson.RawMessage fields
+		// refer to the message they were parsed from and outlive the scan.
+		message := trimPayload(append([]byte(nil), scanner.Bytes()...))
 		header := JSONRPCHeader{}
 		header.ID = -1
-		header.UnmarshalJSON(scanner.Bytes())
-		if header.ID >= 0 {
-			if response, has := l.onResponse[header.ID]; has {
-				response(header.Params)
+		if err := fromJSON(message, &header); err != nil {
+			log.Printf("Ignoring message from %s; unable to parse it: %s: %q", l.name(), err.Error(), outputSample(message))
+			continue
+		}
+		// Requests from the server also have an id, but responses never have a
+		// method.
+		switch {
+		case header.ID >= 0 && header.Method == "":
+			l.mu.Lock()
+			response, has := l.onResponse[header.ID]
+			delete(l.onResponse, header.ID)
+			l.mu.Unlock()
+
+			if has {
+				response(header.Result, header.Error)
 			} else {
 				log.Printf("No handler for response id %d", header.ID)
 			}
+		case header.ID >= 0:
+			l.tapMessage(message)
+			l.handleRequest(header.ID, header.Method, header.Params)
+		default:
+			l.tapMessage(message)
+			l.handleNotification(header.Method, header.Params)
+		}
+	}
+
+	l.readers.Done()
+
+	// The server is unusable if we cannot parse its output. Kill it, otherwise
+	// it could block forever writing to a pipe nobody reads.
+	if err := scanner.Err(); err != nil {
+		l.setErr(err)
+		log.Printf("Killing %s; unable to read output: %s", l.name(), err.Error())
+		l.cmd.Process.Kill()
+	}
+}
+
+// setErr records err unless the server already failed.
+func (l *languageServer) setErr(err error) {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// failure returns the first error of the server, or nil.
+func (l *languageServer) failure() error {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	return l.err
+}
+
+// Matches the id of a response at the start or end of a message. Requests
+// from the server also have ids, so messages with a method are excluded.
+var (
+	responseIDAtStart = regexp.MustCompile(`^\s*\{\s*(?:"jsonrpc"\s*:\s*"2\.0"\s*,\s*)?"id"\s*:\s*(\d+)`)
+	responseIDAtEnd   = regexp.MustCompile(`"id"\s*:\s*(\d+)\s*\}\s*$`)
+	methodAtStart     = regexp.MustCompile(`^[^{]*\{[^{\[]*"method"\s*:`)
+)
+
+// oversizedResponseID finds the id of the response an oversized message
+// belongs to from the start and end of the message.
+func oversizedResponseID(head, tail []byte) (RequestID, bool) {
+	if methodAtStart.Match(head) {
+		return 0, false
+	}
+	match := responseIDAtStart.FindSubmatch(head)
+	if match == nil {
+		match = responseIDAtEnd.FindSubmatch(tail)
+	}
+	if match == nil {
+		return 0, false
+	}
+	id, err := strconv.Atoi(string(match[1]))
+	if err != nil {
+		return 0, false
+	}
+	return RequestID(id), true
+}
+
+// dropOversized is called after a message above --max-message-size has been
+// skipped. If it was a response the request fails instead of waiting forever.
+func (l *languageServer) dropOversized(contentLength int, head, tail []byte) {
+	id, isResponse := oversizedResponseID(head, tail)
+	var handler responseHandler
+	if isResponse {
+		l.mu.Lock()
+		handler = l.onResponse[id]
+		delete(l.onResponse, id)
+		l.mu.Unlock()
+	}
+	if handler == nil {
+		log.Printf("Dropped %d byte message from %s; it exceeds --max-message-size", contentLength, l.name())
+		return
+	}
+
+	log.Printf("Dropped %d byte response to request %d from %s; it exceeds --max-message-size", contentLength, id, l.name())
+	handler(nil, &LsResponseError{
+		Code:    RequestFailed,
+		Message: fmt.Sprintf("response is %d bytes, above the limit of %d MiB set by --max-message-size", contentLength, gMaxMessageSize),
+	})
+}
+
+// Most bytes of unparseable output that are logged.
+const maxOutputSample = 200
+
+// outputSample returns the start of output for logging.
+func outputSample(output []byte) []byte {
+	if len(output) > maxOutputSample {
+		return output[:maxOutputSample]
+	}
+	return output
+}
+
+// logNoise is called with a line the server printed to stdout before its
+// first message, see --skip-startup-noise.
+func (l *languageServer) logNoise(line []byte) {
+	log.Printf("%s stdout: %q", l.name(), outputSample(bytes.TrimRight(line, "\r\n")))
+}
+
+// logSkipped is called with output of the server that was skipped because it
+// is not part of a message, see --resync-output.
+func (l *languageServer) logSkipped(skipped []byte) {
+	log.Printf("Skipped %d byte(s) of unparseable output from %s: %q", len(skipped), l.name(), outputSample(skipped))
+}
+
+// shutdown stops the language server as the protocol asks clients to: with a
+// shutdown request, which gives it the chance to save its caches and index,
+// and then an exit notification. The connection is closed afterwards, or
+// right away if the server has not initialized, already exited or does not
+// answer shutdown. If the server has not exited once timeout has passed
+// since the shutdown request it is killed, see close.
+func (l *languageServer) shutdown(timeout time.Duration) {
+	start := time.Now()
+	l.mu.Lock()
+	initialized := l.initialized
+	l.mu.Unlock()
+	select {
+	case <-l.done:
+		initialized = false
+	default:
+	}
+
+	if initialized {
+		if _, err := l.call("shutdown", nil, timeout); err != nil {
+			log.Printf("%s in %s did not shut down: %s", l.name(), l.directory, err.Error())
+		} else {
+			l.writeNotification("exit", nil)
+		}
+	}
+	remaining := timeout - time.Since(start)
+	if remaining < 0 {
+		remaining = 0
+	}
+	l.close(remaining)
+}
+
+// close shuts down the connection to the language server. Writes in progress
+// are finished before stdin is closed, which signals EOF to the server. The
+// server then has until timeout to exit and for its output to be drained
+// before it is killed.
+func (l *languageServer) close(timeout time.Duration) {
+	l.writeMu.Lock()
+	l.mu.Lock()
+	if len(l.pending) > 0 {
+		log.Printf("Dropping %d message(s) queued for %s; it never finished initializing", len(l.pending), l.name())
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	if !l.stdinClosed {
+		l.stdinClosed = true
+		l.stdin.Close()
+	}
+	l.writeMu.Unlock()
+
+	select {
+	case <-l.done:
+		return
+	case <-time.After(timeout):
+	}
+
+	log.Printf("Killing %s in %s; it did not exit within %s", l.name(), l.directory, timeout)
+	l.cmd.Process.Kill()
+
+	// Children of the server may still hold its stdout/stderr open, in which
+	// case the readers never see EOF. Stop reading so the process is reaped.
+	select {
+	case <-l.done:
+	case <-time.After(time.Second):
+		l.stdout.Close()
+		l.stderr.Close()
+		<-l.done
+	}
+}
+
+// wait reaps the process once its output has been drained, records the exit
+// status and reports the server as closed.
+func (l *languageServer) wait() {
+	l.readers.Wait()
+	e := l.cmd.Wait()
+	if l.cmd.ProcessState != nil {
+		l.exitStatus = l.cmd.ProcessState.String()
+	}
+	if _, isExit := e.(*exec.ExitError); e != nil && !isExit {
+		l.setErr(e)
+	}
+	close(l.done)
+
+	// Nobody will respond to outstanding requests anymore.
+	l.mu.Lock()
+	handlers := l.onResponse
+	l.onResponse = make(map[RequestID]responseHandler)
+	l.mu.Unlock()
+	for _, handler := range handlers {
+		handler(nil, &LsResponseError{
+			Code:    InternalError,
+			Message: fmt.Sprintf("%s exited (%s)", l.name(), l.exitStatus),
+		})
+	}
+
+	l.publishEvent(DaemonEvent{Kind: EventServerStopped, ExitStatus: l.exitStatus})
+	languageServerClosed.push(l)
+}
+
+// pendingStats describes the queue of messages waiting for initialize.
+func (l *languageServer) pendingStats() QueueStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return QueueStats{
+		Name:     "pending",
+		Depth:    len(l.pending),
+		Capacity: maxPendingMessages,
+		Dropped:  l.pendingDropped,
+	}
+}
+
+// handleRequest responds to a request sent by the language server.
+func (l *languageServer) handleRequest(id RequestID, method string, params easyjson.RawMessage) {
+	switch method {
+	case "window/workDoneProgress/create":
+		// Progress is tracked when $/progress arrives, nothing to prepare.
+		l.writeResponse(id, nil, nil)
+	case "workspace/configuration":
+		p := LsConfigurationParams{}
+		if e := fromJSON(params, &p); e != nil {
+			l.writeResponse(id, nil, &LsResponseError{Code: InvalidParams, Message: e.Error()})
+			return
+		}
+		l.mu.Lock()
+		settings := l.settings
+		l.mu.Unlock()
+
+		result := []byte("[")
+		for i, item := range p.Items {
+			if i > 0 {
+				result = append(result, ',')
+			}
+			result = append(result, settingsSection(settings, item.Section)...)
+		}
+		result = append(result, ']')
+		l.writeResponse(id, result, nil)
+	case "workspace/workspaceFolders":
+		l.writeResponse(id, toJSON(workspaceFolders(l.startArgs.WorkspaceFolders)), nil)
+	case "workspace/applyEdit":
+		p := LsApplyWorkspaceEditParams{}
+		fromJSON(params, &p)
+		if err := l.checkWritable("workspace/applyEdit"); err != nil {
+			result := LsApplyWorkspaceEditResult{FailureReason: err.Error()}
+			l.publishEvent(DaemonEvent{Kind: EventApplyEdit, ApplyEdit: newApplyEditEvent(p, result)})
+			l.writeResponse(id, toJSON(result), nil)
+			return
 		}
+		// Edits are only applied while lspc code-action --apply can ask the
+		// user to confirm them.
+		if session := editSessionFor(l); session != nil {
+			session.answerApplyEdit(id, p)
+			return
+		}
+		l.publishEvent(DaemonEvent{Kind: EventApplyEdit, ApplyEdit: newApplyEditEvent(p, LsApplyWorkspaceEditResult{
+			FailureReason: fmt.Sprintf("lspc does not support %s", method),
+		})})
+		l.writeResponse(id, nil, &LsResponseError{
+			Code:    MethodNotFound,
+			Message: fmt.Sprintf("lspc does not support %s", method),
+		})
+	default:
+		l.writeResponse(id, nil, &LsResponseError{
+			Code:    MethodNotFound,
+			Message: fmt.Sprintf("lspc does not support %s", method),
+		})
 	}
+}
 
-	if scanner.Err() != nil {
-		l.err = scanner.Err()
+func (l *languageServer) handleNotification(method string, params easyjson.RawMessage) {
+	switch method {
+	case "$/progress":
+		p := LsProgressParams{}
+		if e := fromJSON(params, &p); e == nil {
+			progress, finished := l.stats.recordProgress(p)
+			if progress != nil {
+				l.publishEvent(DaemonEvent{Kind: EventProgress, Progress: progress, Done: finished})
+			}
+			if finished && gNotify {
+				notifyProgressFinished(l, progress)
+			}
+		}
+	case "window/showMessage":
+		p := LsShowMessageParams{}
+		if e := fromJSON(params, &p); e == nil {
+			log.Printf("%s: %s", l.name(), p.Message)
+			l.publishEvent(DaemonEvent{Kind: EventMessage, Message: &p})
+			if gNotify {
+				notifyShowMessage(l, p)
+			}
+		}
+	case "textDocument/publishDiagnostics":
+		p := LsPublishDiagnosticsParams{}
+		if e := fromJSON(params, &p); e == nil {
+			p.URI = canonicalURI(p.URI)
+			p.Diagnostics = l.filterDiagnostics(p.URI, p.Diagnostics)
+			event := l.stats.recordDiagnostics(p)
+			// Stored first so that subscribers see them once notified.
+			l.mu.Lock()
+			l.diagnostics[p.URI] = p.Diagnostics
+			l.mu.Unlock()
+			l.publishEvent(DaemonEvent{Kind: EventDiagnostics, Diagnostics: &event})
+		}
+	}
+}
+
+// setSettings stores settings and notifies the server that they changed.
+func (l *languageServer) setSettings(settings easyjson.RawMessage) {
+	l.mu.Lock()
+	l.settings = settings
+	l.mu.Unlock()
+
+	if settings == nil {
+		settings = easyjson.RawMessage("{}")
+	}
+	l.writeNotification("workspace/didChangeConfiguration", toJSON(LsDidChangeConfigurationParams{
+		Settings: settings,
+	}))
+}
+
+// name is a short human readable name for the language server.
+func (l *languageServer) name() string {
+	return filepath.Base(l.args[0])
+}
+
+func (l *languageServer) info() ServerInfo {
+	l.mu.Lock()
+	version := l.version
+	initialized := l.initialized
+	l.mu.Unlock()
+	return ServerInfo{
+		ID:          l.id,
+		Pid:         l.cmd.Process.Pid,
+		Args:        l.args,
+		Directory:   l.directory,
+		Language:    l.language,
+		Version:     version,
+		ReadOnly:    l.readOnly,
+		Initialized: initialized,
+		Progress:    l.stats.progressSnapshot(time.Now()),
 	}
-	languageServerClosed <- l
 }
 
 func (l *languageServer) stderrReader() {
+	defer l.readers.Done()
+
 	var buffer [256]byte
 	for {
 		n, e := l.stderr.Read(buffer[:])
+		if n > 0 {
+			// For the time being just echo output to our stdout.
+			fmt.Printf("stderr: %s", buffer[:n])
+		}
 		if e != nil {
-			l.err = e
-			languageServerClosed <- l
-			break
+			return
 		}
-		// For the time being just echo output to our stdout.
-		fmt.Printf("stderr: %s", buffer[:n])
 	}
-
-	languageServerClosed <- l
 }
@@ -0,0 +1,355 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacobdufault/lspc/jsonrpc"
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOversizedResponseID(t *testing.T) {
+	id := func(head, tail string) RequestID {
+		id, ok := oversizedResponseID([]byte(head), []byte(tail))
+		if !ok {
+			return -1
+		}
+		return id
+	}
+
+	assert.Equal(t, RequestID(4), id(`{"jsonrpc": "2.0", "id": 4, "result": [{"id": 7,`, `}]}`))
+	assert.Equal(t, RequestID(4), id(`{"id":4,"result":`, `"x"}`))
+	assert.Equal(t, RequestID(9), id(`{"jsonrpc":"2.0","result":[{"id": 1`, `"x"}],"id":9}`))
+	assert.Equal(t, RequestID(-1), id(`{"jsonrpc":"2.0","result":[{"id": 1`, `"x"}]}`))
+	// Requests from the server are not responses.
+	assert.Equal(t, RequestID(-1), id(`{"jsonrpc":"2.0","method":"workspace/applyEdit","params":{`, `}},"id":3}`))
+	assert.Equal(t, RequestID(-1), id(`{"jsonrpc":"2.0","id":3,"method":"workspace/applyEdit"`, `}}`))
+}
+
+func TestTrimPayload(t *testing.T) {
+	assert.Equal(t, `{"id":1}`, string(trimPayload([]byte("\xef\xbb\xbf{\"id\":1}"))))
+	assert.Equal(t, `{"id":1}`, string(trimPayload([]byte(" \r\n\xef\xbb\xbf {\"id\":1}\n"))))
+	assert.Equal(t, `{"id":1}`, string(trimPayload([]byte(`{"id":1}`))))
+
+	header := JSONRPCHeader{}
+	assert.NoError(t, fromJSON(trimPayload([]byte("\xef\xbb\xbf{\"id\":3,\"method\":\"x\"}\r\n")), &header))
+	assert.Equal(t, RequestID(3), header.ID)
+	assert.Equal(t, "x", header.Method)
+}
+
+func TestCall(t *testing.T) {
+	l := newTestLanguageServer(0, "/p")
+	// respond answers request id as the reader of the server's output would.
+	respond := func(id RequestID, result easyjson.RawMessage, err *LsResponseError) {
+		for {
+			l.mu.Lock()
+			handler, has := l.onResponse[id]
+			delete(l.onResponse, id)
+			l.mu.Unlock()
+			if has {
+				handler(result, err)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	go respond(0, easyjson.RawMessage(`{"a":1}`), nil)
+	result, err := l.call("a", nil, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(result))
+
+	go respond(1, nil, nil)
+	result, err = l.call("b", nil, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(result))
+
+	go respond(2, nil, &LsResponseError{Code: RequestFailed, Message: "failed"})
+	_, err = l.call("c", nil, time.Second)
+	assert.Equal(t, &LsResponseError{Code: RequestFailed, Message: "failed"}, err)
+
+	_, err = l.call("d", nil, 10*time.Millisecond)
+	assert.EqualError(t, err, "d timed out after 10ms")
+	// The handler of the request is dropped and the server told to stop.
+	assert.Empty(t, l.onResponse)
+	assert.Contains(t, l.stdin.(*stdinBuffer).String(), `{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":3}}`)
+}
+
+// newInitializingServer returns a test server that has not initialized yet.
+// Every message written to it is sent to the returned channel. It answers
+// initialize once answer is closed, with initErr if it is not empty, and
+// ignores everything else.
+func newInitializingServer(t *testing.T, initErr string) (l *languageServer, received <-chan JSONRPCHeader, answer chan<- struct{}) {
+	l = newTestLanguageServer(0, "/p")
+	l.initialized = false
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	l.stdin, l.stdout = stdinWriter, stdoutReader
+	messages := make(chan JSONRPCHeader, 16)
+	initialize := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(stdinReader)
+		scanner.Split((&jsonrpc.Splitter{MaxContentLength: 1024 * 1024}).Split)
+		for scanner.Scan() {
+			var message JSONRPCHeader
+			assert.NoError(t, fromJSON(scanner.Bytes(), &message))
+			messages <- message
+			if message.Method != "initialize" {
+				continue
+			}
+			<-initialize
+			response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"capabilities":{},"serverInfo":{"name":"fake"}}}`, message.ID)
+			if initErr != "" {
+				response = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32603,"message":%q}}`, message.ID, initErr)
+			}
+			jsonrpc.WriteFramed(stdoutWriter, []byte(response), jsonrpc.FramingLSP)
+		}
+		stdoutWriter.Close()
+	}()
+	l.readers.Add(1)
+	go l.stdoutReader()
+	return l, messages, initialize
+}
+
+// nextMessage returns the next message received by a server of
+// newInitializingServer.
+func nextMessage(t *testing.T, received <-chan JSONRPCHeader) JSONRPCHeader {
+	select {
+	case message := <-received:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		return JSONRPCHeader{}
+	}
+}
+
+func TestInitializeHandshake(t *testing.T) {
+	defer func(size int) { gMaxMessageSize = size }(gMaxMessageSize)
+	gMaxMessageSize = 1
+
+	l, received, answer := newInitializingServer(t, "")
+	defer func() {
+		l.stdin.Close()
+		l.readers.Wait()
+	}()
+	l.writeInitialize(nil)
+	initialize := nextMessage(t, received)
+	assert.Equal(t, "initialize", initialize.Method)
+	// Servers watch the daemon so that they exit if it dies.
+	var params LsInitializeParams
+	assert.NoError(t, fromJSON(initialize.Params, &params))
+	if assert.NotNil(t, params.ProcessID) {
+		assert.Equal(t, os.Getpid(), *params.ProcessID)
+	}
+
+	// Messages written before the initialize response are queued...
+	assert.True(t, l.writeNotification("textDocument/didOpen", easyjson.RawMessage(`{"n":1}`)))
+	l.writeRequest("textDocument/hover", easyjson.RawMessage(`{"n":2}`), nil)
+	assert.True(t, l.writeNotification("textDocument/didChange", easyjson.RawMessage(`{"n":3}`)))
+	select {
+	case message := <-received:
+		t.Fatalf("%s was sent before initialize finished", message.Method)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// ...and sent in order after the initialized notification.
+	close(answer)
+	assert.Equal(t, "initialized", nextMessage(t, received).Method)
+	for i, method := range []string{"textDocument/didOpen", "textDocument/hover", "textDocument/didChange"} {
+		message := nextMessage(t, received)
+		assert.Equal(t, method, message.Method)
+		assert.Equal(t, fmt.Sprintf(`{"n":%d}`, i+1), string(message.Params))
+	}
+	l.mu.Lock()
+	assert.True(t, l.initialized)
+	assert.Empty(t, l.pending)
+	l.mu.Unlock()
+
+	// Later messages are sent right away.
+	assert.True(t, l.writeNotification("textDocument/didClose", nil))
+	assert.Equal(t, "textDocument/didClose", nextMessage(t, received).Method)
+}
+
+func TestFailedInitialize(t *testing.T) {
+	defer func(size int) { gMaxMessageSize = size }(gMaxMessageSize)
+	gMaxMessageSize = 1
+
+	l, received, answer := newInitializingServer(t, "bad root")
+	defer func() {
+		l.stdin.Close()
+		l.readers.Wait()
+	}()
+	l.writeInitialize(nil)
+	assert.Equal(t, "initialize", nextMessage(t, received).Method)
+
+	// Queued requests fail once initialize does.
+	queued := make(chan error, 1)
+	go func() {
+		_, err := l.call("textDocument/hover", nil, 5*time.Second)
+		queued <- err
+	}()
+	for {
+		l.mu.Lock()
+		pending := len(l.pending)
+		l.mu.Unlock()
+		if pending > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(answer)
+	initErr := &LsResponseError{Code: ServerNotInitialized, Message: "initialize failed: bad root"}
+	assert.Equal(t, initErr, <-queued)
+
+	// Later requests fail right away instead of being queued until they time
+	// out, and nothing is sent to the server.
+	_, err := l.call("textDocument/definition", nil, 5*time.Second)
+	assert.Equal(t, initErr, err)
+	assert.False(t, l.writeNotification("textDocument/didOpen", nil))
+	l.mu.Lock()
+	assert.False(t, l.initialized)
+	assert.Empty(t, l.pending)
+	assert.Zero(t, l.pendingDropped)
+	assert.Empty(t, l.onResponse)
+	l.mu.Unlock()
+	select {
+	case message := <-received:
+		t.Fatalf("%s was sent after initialize failed", message.Method)
+	default:
+	}
+}
+
+// Tests run the test binary as a language server with LSPC_FAKE_SERVER set,
+// see runFakeServer.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("LSPC_FAKE_SERVER"); mode != "" {
+		runFakeServer(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runFakeServer answers initialize and, unless mode is ignore-shutdown,
+// shutdown. It writes the methods it receives and the EOF of stdin to the
+// file LSPC_FAKE_SERVER_LOG names. A server which ignores shutdown ignores
+// EOF too, so it has to be killed.
+func runFakeServer(mode string) {
+	received, err := os.Create(os.Getenv("LSPC_FAKE_SERVER_LOG"))
+	if err != nil {
+		os.Exit(1)
+	}
+	respond := func(id RequestID, result string) {
+		response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, id, result)
+		jsonrpc.WriteFramed(os.Stdout, []byte(response), jsonrpc.FramingLSP)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Split((&jsonrpc.Splitter{MaxContentLength: 1024 * 1024}).Split)
+	for scanner.Scan() {
+		var message JSONRPCHeader
+		fromJSON(scanner.Bytes(), &message)
+		fmt.Fprintln(received, message.Method)
+		switch {
+		case message.Method == "initialize":
+			respond(message.ID, `{"capabilities":{}}`)
+		case message.Method == "shutdown" && mode != "ignore-shutdown":
+			respond(message.ID, "null")
+		}
+	}
+	fmt.Fprintln(received, "EOF")
+	if mode == "ignore-shutdown" {
+		time.Sleep(time.Hour)
+	}
+}
+
+// startFakeServer starts runFakeServer in mode and waits until it is
+// initialized. Returns the file it logs the messages it receives to.
+func startFakeServer(t *testing.T, mode string) (*languageServer, string) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	received := filepath.Join(dir, "received")
+	l, err := startLanguageServer(0, StartArgs{Bin: strconv.Quote(os.Args[0]), Directory: dir}, "", nil, compat{}, processOptions{
+		env: []string{"LSPC_FAKE_SERVER=" + mode, "LSPC_FAKE_SERVER_LOG=" + received},
+	})
+	assert.NoError(t, err)
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		l.mu.Lock()
+		initialized := l.initialized
+		l.mu.Unlock()
+		if initialized {
+			return l, received
+		}
+	}
+	l.cmd.Process.Kill()
+	t.Fatal("the fake server did not initialize")
+	return nil, ""
+}
+
+func TestShutdown(t *testing.T) {
+	defer func(size int) { gMaxMessageSize = size }(gMaxMessageSize)
+	gMaxMessageSize = 1
+	l, received := startFakeServer(t, "graceful")
+	defer os.RemoveAll(filepath.Dir(received))
+
+	start := time.Now()
+	l.shutdown(5 * time.Second)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	// The server got exit after answering shutdown, then EOF, and exited by
+	// itself once it did; it was reaped after its output was drained.
+	select {
+	case <-l.done:
+	default:
+		t.Fatal("the server was not reaped")
+	}
+	assert.Equal(t, "exit status 0", l.exitStatus)
+	assert.True(t, l.stdinClosed)
+	content, err := ioutil.ReadFile(received)
+	assert.NoError(t, err)
+	methods := strings.Fields(string(content))
+	assert.Equal(t, "initialize", methods[0])
+	assert.Equal(t, []string{"shutdown", "exit", "EOF"}, methods[len(methods)-3:])
+}
+
+func TestShutdownKillsServerIgnoringIt(t *testing.T) {
+	defer func(size int) { gMaxMessageSize = size }(gMaxMessageSize)
+	gMaxMessageSize = 1
+	l, received := startFakeServer(t, "ignore-shutdown")
+	defer os.RemoveAll(filepath.Dir(received))
+
+	start := time.Now()
+	l.shutdown(500 * time.Millisecond)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 500*time.Millisecond, elapsed)
+	assert.True(t, elapsed < 3*time.Second, elapsed)
+
+	// exit is only sent once shutdown is answered.
+	assert.Equal(t, "signal: killed", l.exitStatus)
+	content, err := ioutil.ReadFile(received)
+	assert.NoError(t, err)
+	methods := strings.Fields(string(content))
+	assert.Contains(t, methods, "shutdown")
+	assert.NotContains(t, methods, "exit")
+}
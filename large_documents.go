@@ -0,0 +1,131 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// What lspc does with documents larger than max_document_size.
+const (
+	largeDocumentsRefuse   = "refuse"
+	largeDocumentsTruncate = "truncate"
+)
+
+// parseByteSize parses a size such as 4096, 512KB, 20MB or 1GB. Units are
+// powers of 1024.
+func parseByteSize(s string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size such as 20MB, got %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// documentLimit is the parsed max_document_size and large_documents.
+type documentLimit struct {
+	// 0 if documents of any size are sent.
+	size     int64
+	truncate bool
+}
+
+func (c *Config) validateDocumentLimit() map[string]error {
+	problems := make(map[string]error)
+	if c.MaxDocumentSize != "" {
+		if _, err := parseByteSize(c.MaxDocumentSize); err != nil {
+			problems["max_document_size"] = err
+		}
+	}
+	switch c.LargeDocuments {
+	case "", largeDocumentsRefuse, largeDocumentsTruncate:
+	default:
+		problems["large_documents"] = fmt.Errorf("unknown value %q; expected refuse or truncate", c.LargeDocuments)
+	}
+	return problems
+}
+
+// documentLimit returns the limit on documents sent to language servers.
+// Invalid settings, which config validate reports, are ignored.
+func (c *Config) documentLimit() documentLimit {
+	size, _ := parseByteSize(c.MaxDocumentSize)
+	return documentLimit{size: size, truncate: c.LargeDocuments == largeDocumentsTruncate}
+}
+
+// setDocumentLimit sets the limit on documents opened after this.
+func (l *languageServer) setDocumentLimit(limit documentLimit) {
+	l.mu.Lock()
+	l.documentLimit = limit
+	l.mu.Unlock()
+}
+
+// readFileLimited reads the file at path. If it is larger than limit, either
+// returns an error or only reads as much as limit allows and sets truncated.
+func readFileLimited(path string, limit documentLimit) (content []byte, truncated bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	if limit.size == 0 || info.Size() <= limit.size {
+		content, err = ioutil.ReadAll(file)
+		return content, false, err
+	}
+	if !limit.truncate {
+		return nil, false, fmt.Errorf("%s is %d bytes, more than max_document_size allows (%d); set large_documents = \"truncate\" to send its beginning", path, info.Size(), limit.size)
+	}
+	log.Printf("Only sending the first %d of %d bytes of %s", limit.size, info.Size(), path)
+	content, err = ioutil.ReadAll(io.LimitReader(file, limit.size))
+	return content, true, err
+}
+
+// truncateContent cuts content, the first bytes of a larger file, after its
+// last complete line, or if there is none before an incomplete UTF-8
+// character at its end, so that the server does not see a partial line or
+// character.
+func truncateContent(content []byte) []byte {
+	if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+		return content[:i+1]
+	}
+	for n := 1; n < utf8.UTFMax && n <= len(content); n++ {
+		if utf8.Valid(content[:len(content)-n]) && !utf8.Valid(content) {
+			return content[:len(content)-n]
+		}
+	}
+	return content
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for s, expected := range map[string]int64{"4096": 4096, "512KB": 512 << 10, "20mb": 20 << 20, "1 G": 1 << 30, "7B": 7} {
+		size, err := parseByteSize(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, size, s)
+	}
+	for _, s := range []string{"", "MB", "-1", "1TB", "1.5MB"} {
+		_, err := parseByteSize(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestValidateDocumentLimit(t *testing.T) {
+	assert.Empty(t, (&Config{}).validateDocumentLimit())
+	assert.Empty(t, (&Config{MaxDocumentSize: "20MB", LargeDocuments: "truncate"}).validateDocumentLimit())
+	problems := (&Config{MaxDocumentSize: "big", LargeDocuments: "skip"}).validateDocumentLimit()
+	assert.Contains(t, problems, "max_document_size")
+	assert.Contains(t, problems, "large_documents")
+}
+
+func TestTruncateContent(t *testing.T) {
+	assert.Equal(t, "a\nb\n", string(truncateContent([]byte("a\nb\nc"))))
+	assert.Equal(t, "caf", string(truncateContent([]byte("caf\xc3"))))
+	assert.Equal(t, "日", string(truncateContent([]byte("日\xe6\x9c"))))
+	assert.Equal(t, "caf\xe9\n", string(truncateContent([]byte("caf\xe9\nx"))))
+}
+
+func TestReadDocumentLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-large")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.c")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int a;\nint b;\nint c;\n"), 0644))
+
+	l := &languageServer{directory: dir}
+	text, _, err := l.readDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "int a;\nint b;\nint c;\n", text)
+
+	l.setDocumentLimit(documentLimit{size: 10})
+	_, _, err = l.readDocument(path)
+	assert.Error(t, err)
+
+	l.setDocumentLimit(documentLimit{size: 10, truncate: true})
+	text, _, err = l.readDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "int a;\n", text)
+
+	// UTF-16 is cut after decoding.
+	assert.NoError(t, ioutil.WriteFile(path, []byte("\xff\xfea\x00\n\x00b\x00\n\x00"), 0644))
+	l.setDocumentLimit(documentLimit{size: 9, truncate: true})
+	text, e, err := l.readDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "UTF-16LE", e.name)
+	assert.Equal(t, "a\n", text)
+}
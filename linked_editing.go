@@ -0,0 +1,87 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// LinkedEditingRanges is the result of textDocument/linkedEditingRange as
+// shown to users.
+type LinkedEditingRanges struct {
+	Ranges []Location `json:"ranges"`
+	// Regular expression matching valid content of the ranges, if the
+	// server has one.
+	WordPattern string `json:"word_pattern,omitempty"`
+}
+
+// String returns a line per range, as
+// <file>:<line>:<col>-<end line>:<end col>, or nothing if there are none.
+func (r LinkedEditingRanges) String() string {
+	var s strings.Builder
+	for _, location := range r.Ranges {
+		fmt.Fprintf(&s, "%s-%d:%d\n", location, location.EndLine, location.EndColumn)
+	}
+	return s.String()
+}
+
+// linkedEditingRanges returns the ranges which are edited together with the
+// 1-based line and column of path. Ranges is empty if there are none.
+func (l *languageServer) linkedEditingRanges(path string, line, column int, unit ColumnUnit) (LinkedEditingRanges, error) {
+	out := LinkedEditingRanges{Ranges: []Location{}}
+	params, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return out, err
+	}
+	result, err := l.call("textDocument/linkedEditingRange", toJSON(params), queryTimeout())
+	if err != nil {
+		return out, err
+	}
+	var ranges *LsLinkedEditingRanges
+	if err := fromJSON(result, &ranges); err != nil {
+		return out, fmt.Errorf("cannot parse linked editing ranges: %s", err.Error())
+	}
+	if ranges == nil {
+		return out, nil
+	}
+	for _, r := range ranges.Ranges {
+		out.Ranges = append(out.Ranges, l.userRange(path, r, unit))
+	}
+	out.WordPattern = ranges.WordPattern
+	return out, nil
+}
+
+// LinkedEditingRanges returns the ranges which are edited together with a
+// position.
+func (s *Server) LinkedEditingRanges(args PositionArgs, ranges *LinkedEditingRanges) error {
+	log.Printf("CMD linked-editing-ranges %s:%d:%d", args.File, args.Line, args.Column)
+	server, err := s.serverForPosition(args, "textDocument/linkedEditingRange")
+	if err != nil {
+		return err
+	}
+	*ranges, err = server.linkedEditingRanges(args.File, args.Line, args.Column, args.Unit)
+	return err
+}
+
+func (s *Server) httpLinkedEditingRanges(query url.Values) (interface{}, error) {
+	position, server, err := s.queryPosition(query, "textDocument/linkedEditingRange")
+	if err != nil {
+		return nil, err
+	}
+	return server.linkedEditingRanges(position.File, position.Line, position.Column, position.Unit)
+}
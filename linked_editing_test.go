@@ -0,0 +1,112 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkedEditingRangesString(t *testing.T) {
+	ranges := LinkedEditingRanges{Ranges: []Location{
+		{File: "/a.html", Line: 1, Column: 2, EndLine: 1, EndColumn: 5},
+		{File: "/a.html", Line: 1, Column: 8, EndLine: 1, EndColumn: 11},
+	}}
+	assert.Equal(t, "/a.html:1:2-1:5\n/a.html:1:8-1:11\n", ranges.String())
+	assert.Equal(t, "", LinkedEditingRanges{Ranges: []Location{}}.String())
+}
+
+func TestLinkedEditingRanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.html")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("<div></div>\n"), 0644))
+	l := newTestLanguageServer(0, dir)
+	l.cmd = &exec.Cmd{Process: &os.Process{Pid: 1234}}
+	s := &Server{config: &Config{}, servers: []*languageServer{l}}
+
+	// answer answers the next request to l with result.
+	answer := func(result string) {
+		for {
+			l.mu.Lock()
+			for id, handler := range l.onResponse {
+				delete(l.onResponse, id)
+				l.mu.Unlock()
+				handler(easyjson.RawMessage(result), nil)
+				return
+			}
+			l.mu.Unlock()
+			time.Sleep(time.Millisecond)
+		}
+	}
+	div := `{"ranges": [
+		{"start": {"line": 0, "character": 1}, "end": {"line": 0, "character": 4}},
+		{"start": {"line": 0, "character": 7}, "end": {"line": 0, "character": 10}}
+	], "wordPattern": "[a-z]+"}`
+	expected := LinkedEditingRanges{
+		Ranges: []Location{
+			{File: file, Line: 1, Column: 2, EndLine: 1, EndColumn: 5},
+			{File: file, Line: 1, Column: 8, EndLine: 1, EndColumn: 11},
+		},
+		WordPattern: "[a-z]+",
+	}
+
+	go answer(div)
+	var ranges LinkedEditingRanges
+	assert.NoError(t, s.LinkedEditingRanges(PositionArgs{File: file, Line: 1, Column: 2}, &ranges))
+	assert.Equal(t, expected, ranges)
+	assert.Contains(t, l.stdin.(*stdinBuffer).String(), `"method":"textDocument/linkedEditingRange","id":0,"params":{"textDocument":{"uri":"`+string(pathToURI(file))+`"},"position":{"line":0,"character":1}}`)
+
+	// Positions without linked ranges have none rather than null.
+	go answer("null")
+	assert.NoError(t, s.LinkedEditingRanges(PositionArgs{File: file, Line: 1, Column: 6}, &ranges))
+	assert.Equal(t, LinkedEditingRanges{Ranges: []Location{}}, ranges)
+
+	go answer(`{"ranges": 1}`)
+	assert.Error(t, s.LinkedEditingRanges(PositionArgs{File: file, Line: 1, Column: 2}, &ranges))
+	assert.EqualError(t, s.LinkedEditingRanges(PositionArgs{File: "a.html", Line: 1, Column: 2}, &ranges), `file must be an absolute path, got "a.html"`)
+
+	// The gateway finds the server on the daemon main loop.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case f := <-mainLoopCalls:
+				f()
+			case <-stop:
+				return
+			}
+		}
+	}()
+	go answer(div)
+	w := httptest.NewRecorder()
+	gatewayHandler(s.httpLinkedEditingRanges).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/linked-editing-ranges?file="+file+"&line=1&col=2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ranges": [
+		{"file": "`+file+`", "line": 1, "column": 2, "end_line": 1, "end_column": 5},
+		{"file": "`+file+`", "line": 1, "column": 8, "end_line": 1, "end_column": 11}
+	], "word_pattern": "[a-z]+"}`, w.Body.String())
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	shellwords "github.com/mattn/go-shellwords"
+)
+
+// Directory holding the server locks of every daemon of the user. If empty,
+// next to the default socket.
+var gLockDir string
+
+// serverLock is written to the lock file of a project and server binary by
+// the daemon running that server, so that other daemons, ie, with a
+// different --socket, do not start a second copy of an expensive indexer for
+// the same checkout.
+type serverLock struct {
+	// Socket and pid of the owning daemon.
+	Socket    string    `json:"socket"`
+	Pid       int       `json:"pid"`
+	Bin       string    `json:"bin"`
+	Directory string    `json:"directory"`
+	Started   time.Time `json:"started"`
+}
+
+func lockDir() string {
+	if gLockDir != "" {
+		return gLockDir
+	}
+	return getSocketFilename() + ".locks"
+}
+
+// lockPath returns the lock file for running bin in directory. The program
+// is identified by its name, so "clangd" and "/usr/bin/clangd" conflict.
+func lockPath(bin, directory string) string {
+	command := bin
+	if words, err := shellwords.Parse(bin); err == nil && len(words) > 0 {
+		words[0] = filepath.Base(words[0])
+		command = strings.Join(words, "\x00")
+	}
+	sum := sha1.Sum([]byte(filepath.Clean(directory) + "\x00" + command))
+	return filepath.Join(lockDir(), hex.EncodeToString(sum[:8])+".json")
+}
+
+// readServerLock returns the owner of the lock at path.
+func readServerLock(path string) (serverLock, error) {
+	var owner serverLock
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return owner, err
+	}
+	err = json.Unmarshal(data, &owner)
+	return owner, err
+}
+
+// acquireServerLock takes the lock for running bin in directory for this
+// daemon. Locks of daemons which are no longer running are taken over. If
+// another daemon holds the lock the error describes it.
+func acquireServerLock(bin, directory string) error {
+	if err := os.MkdirAll(lockDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(serverLock{
+		Socket:    gSocket,
+		Pid:       os.Getpid(),
+		Bin:       bin,
+		Directory: directory,
+		Started:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := lockPath(bin, directory)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err == nil {
+		defer file.Close()
+		_, err = file.Write(data)
+		return err
+	}
+	if !os.IsExist(err) {
+		return err
+	}
+
+	owner, err := readServerLock(path)
+	if err == nil && owner.Pid == os.Getpid() {
+		return nil
+	}
+	if err == nil && processAlive(owner.Pid) {
+		return fmt.Errorf("%s is already running in %s for the daemon at %s (pid %d) since %s",
+			owner.Bin, owner.Directory, owner.Socket, owner.Pid, owner.Started.Format("2006-01-02 15:04:05"))
+	}
+	log.Printf("Taking over stale lock %s", path)
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// releaseServerLock removes the lock for running bin in directory if this
+// daemon holds it.
+func releaseServerLock(bin, directory string) {
+	path := lockPath(bin, directory)
+	if owner, err := readServerLock(path); err == nil && owner.Pid == os.Getpid() {
+		os.Remove(path)
+	}
+}
+
+// releaseLock releases the lock for running bin in directory unless another
+// server of this daemon still holds it, ie, another instance.
+func (s *Server) releaseLock(bin, directory string) {
+	path := lockPath(bin, directory)
+	for _, server := range s.serverList() {
+		if lockPath(server.startArgs.Bin, server.directory) == path {
+			return
+		}
+	}
+	releaseServerLock(bin, directory)
+}
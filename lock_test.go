@@ -0,0 +1,68 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockPath(t *testing.T) {
+	gLockDir = "/locks"
+	defer func() { gLockDir = "" }()
+
+	assert.Equal(t, lockPath("clangd", "/src"), lockPath("/usr/bin/clangd", "/src/"))
+	assert.NotEqual(t, lockPath("clangd", "/src"), lockPath("clangd", "/other"))
+	assert.NotEqual(t, lockPath("node tsserver.js", "/src"), lockPath("node pyright.js", "/src"))
+}
+
+func TestServerLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	gLockDir = dir
+	defer func() { gLockDir = "" }()
+
+	// Instances within a daemon share the lock.
+	assert.NoError(t, acquireServerLock("clangd", "/src"))
+	assert.NoError(t, acquireServerLock("clangd", "/src"))
+	owner, err := readServerLock(lockPath("clangd", "/src"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), owner.Pid)
+
+	// Another daemon that is still running keeps its lock.
+	other, _ := json.Marshal(serverLock{Socket: "/tmp/lspc.other", Pid: os.Getppid(), Bin: "pyls", Directory: "/src"})
+	assert.NoError(t, ioutil.WriteFile(lockPath("pyls", "/src"), other, 0600))
+	err = acquireServerLock("pyls", "/src")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "/tmp/lspc.other")
+	}
+
+	// The lock of a daemon that exited is taken over.
+	stale, _ := json.Marshal(serverLock{Pid: -1})
+	assert.NoError(t, ioutil.WriteFile(lockPath("gopls", "/src"), stale, 0600))
+	assert.NoError(t, acquireServerLock("gopls", "/src"))
+
+	// Only locks this daemon holds are released.
+	releaseServerLock("pyls", "/src")
+	assert.FileExists(t, lockPath("pyls", "/src"))
+	s := &Server{}
+	s.releaseLock("clangd", "/src")
+	assert.NoFileExists(t, lockPath("clangd", "/src"))
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Number of evicted servers remembered for revive.
+const maxEvicted = 20
+
+// EvictedServer is a server shut down by enforceMaxServers.
+type EvictedServer struct {
+	StartArgs
+	// Paths of the documents that were open in the server, which revive
+	// opens again.
+	Documents []string `json:",omitempty"`
+}
+
+// evictedPath is where the daemon keeps the servers it evicted, next to its
+// socket, so that they can be revived after it restarts.
+func evictedPath() string {
+	return gSocket + ".evicted.json"
+}
+
+// readEvicted returns the evicted servers saved at path, or none if it does
+// not exist.
+func readEvicted(path string) ([]EvictedServer, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var evicted []EvictedServer
+	if err := json.Unmarshal(data, &evicted); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %s", path, err.Error())
+	}
+	return evicted, nil
+}
+
+// saveEvicted writes s.evicted to s.evictedPath unless it is empty. s.mu must
+// be held.
+func (s *Server) saveEvicted() {
+	if s.evictedPath == "" {
+		return
+	}
+	data, err := json.Marshal(s.evicted)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.evictedPath), 0700)
+	}
+	// Write a copy and rename it so that the file is never left half
+	// written.
+	tmp := s.evictedPath + ".tmp"
+	if err == nil {
+		err = ioutil.WriteFile(tmp, data, 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.evictedPath)
+	}
+	if err != nil {
+		log.Printf("Unable to save evicted servers: %s", err.Error())
+	}
+}
+
+// enforceMaxServers shuts down least recently used servers until no more than
+// gMaxServers are running.
+func (s *Server) enforceMaxServers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for gMaxServers > 0 && len(s.servers) > gMaxServers {
+		lru := 0
+		for i, ls := range s.servers {
+			if ls.lastUsedTime().Before(s.servers[lru].lastUsedTime()) {
+				lru = i
+			}
+		}
+
+		ls := s.servers[lru]
+		log.Printf("More than %d language servers running; shutting down least recently used %+v in %s", gMaxServers, ls.args, ls.directory)
+		s.servers = append(s.servers[:lru], s.servers[lru+1:]...)
+		s.evicted = append(s.evicted, EvictedServer{StartArgs: ls.startArgs, Documents: ls.openFiles()})
+		if len(s.evicted) > maxEvicted {
+			s.evicted = s.evicted[len(s.evicted)-maxEvicted:]
+		}
+		s.saveEvicted()
+		// Shutting down can take a while; do not hold up the request.
+		go ls.shutdown(closeTimeout)
+	}
+}
+
+// Evicted lists servers that were shut down by enforceMaxServers.
+func (s *Server) Evicted(_ bool, evicted *[]EvictedServer) error {
+	log.Print("CMD evicted")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	*evicted = append([]EvictedServer(nil), s.evicted...)
+	return nil
+}
+
+// Revive starts an evicted server again and opens the documents that were
+// open in it. Documents that can no longer be opened, ie, because they were
+// deleted, are skipped.
+func (s *Server) Revive(index int, _ *bool) error {
+	log.Printf("CMD revive %d", index)
+	s.mu.Lock()
+	if index < 0 || index >= len(s.evicted) {
+		s.mu.Unlock()
+		return fmt.Errorf("no evicted language server with index %d", index)
+	}
+	evicted := s.evicted[index]
+	s.evicted = append(s.evicted[:index], s.evicted[index+1:]...)
+	s.saveEvicted()
+	s.mu.Unlock()
+
+	var ids []int
+	if err := s.Start(evicted.StartArgs, &ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		ls, err := s.findServer(id)
+		if err != nil {
+			return err
+		}
+		for _, path := range evicted.Documents {
+			if _, err := ls.openDocument(path); err != nil {
+				log.Printf("Unable to reopen %s in %s: %s", path, ls.name(), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (l *languageServer) lastUsedTime() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastUsed
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newEvictableServer returns a test server last used at lastUsed which has
+// already exited, so that shutting it down returns at once.
+func newEvictableServer(id int, directory string, lastUsed time.Time) *languageServer {
+	l := newTestLanguageServer(id, directory)
+	l.lastUsed = lastUsed
+	l.done = make(chan struct{})
+	close(l.done)
+	return l
+}
+
+func TestEnforceMaxServers(t *testing.T) {
+	defer func(max int) { gMaxServers = max }(gMaxServers)
+	gMaxServers = 2
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	s := &Server{config: &Config{}, evictedPath: filepath.Join(dir, "lspc.evicted.json"), servers: []*languageServer{
+		newEvictableServer(0, "/a", now.Add(-time.Minute)),
+		newEvictableServer(1, "/b", now.Add(-time.Hour)),
+		newEvictableServer(2, "/c", now),
+	}}
+	s.servers[1].documents[pathToURI("/b/main.go")] = "package main\n"
+	s.enforceMaxServers()
+	var ids []int
+	for _, server := range s.serverList() {
+		ids = append(ids, server.id)
+	}
+	assert.Equal(t, []int{0, 2}, ids)
+
+	// The least recently used server goes first.
+	s.mu.Lock()
+	s.servers = append(s.servers, newEvictableServer(3, "/d", now.Add(time.Second)))
+	s.mu.Unlock()
+	s.enforceMaxServers()
+	var evicted []EvictedServer
+	assert.NoError(t, s.Evicted(false, &evicted))
+	assert.Equal(t, []EvictedServer{
+		{StartArgs: StartArgs{Bin: "fake", Directory: "/b"}, Documents: []string{"/b/main.go"}},
+		{StartArgs: StartArgs{Bin: "fake", Directory: "/a"}},
+	}, evicted)
+	// A restarted daemon can revive them.
+	saved, err := readEvicted(s.evictedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, evicted, saved)
+
+	// No limit evicts nothing.
+	gMaxServers = 0
+	s.mu.Lock()
+	s.servers = append(s.servers, newEvictableServer(4, "/e", now))
+	s.mu.Unlock()
+	s.enforceMaxServers()
+	assert.Len(t, s.serverList(), 3)
+}
+
+func TestEvictedIsBounded(t *testing.T) {
+	defer func(max int) { gMaxServers = max }(gMaxServers)
+	gMaxServers = 1
+
+	s := &Server{config: &Config{}}
+	now := time.Now()
+	for i := 0; i <= maxEvicted+5; i++ {
+		s.mu.Lock()
+		s.servers = append(s.servers, newEvictableServer(i, fmt.Sprintf("/p%d", i), now.Add(time.Duration(i)*time.Second)))
+		s.mu.Unlock()
+		s.enforceMaxServers()
+	}
+	var evicted []EvictedServer
+	assert.NoError(t, s.Evicted(false, &evicted))
+	assert.Len(t, evicted, maxEvicted)
+	// The most recently evicted are kept, oldest first.
+	assert.Equal(t, "/p5", evicted[0].Directory)
+	assert.Equal(t, fmt.Sprintf("/p%d", maxEvicted+4), evicted[maxEvicted-1].Directory)
+}
+
+func TestRevive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	gLockDir = dir
+	defer func() { gLockDir = "" }()
+
+	file := filepath.Join(dir, "a.py")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("pass\n"), 0644))
+	s := &Server{config: &Config{}, evictedPath: filepath.Join(dir, "lspc.evicted.json"), evicted: []EvictedServer{
+		{StartArgs: StartArgs{Bin: "sleep 30", Directory: dir}},
+		// The deleted document is skipped.
+		{StartArgs: StartArgs{Bin: "sleep 31", Directory: dir}, Documents: []string{file, filepath.Join(dir, "deleted.py")}},
+	}}
+	assert.Error(t, s.Revive(2, nil))
+	assert.Error(t, s.Revive(-1, nil))
+
+	assert.NoError(t, s.Revive(1, nil))
+	servers := s.serverList()
+	for _, server := range servers {
+		s.stopServer(server.id)
+		server.close(0)
+	}
+	assert.Len(t, servers, 1)
+	assert.Equal(t, "sleep 31", servers[0].startArgs.Bin)
+	assert.Equal(t, []string{file}, servers[0].openFiles())
+	var evicted []EvictedServer
+	assert.NoError(t, s.Evicted(false, &evicted))
+	assert.Equal(t, []EvictedServer{{StartArgs: StartArgs{Bin: "sleep 30", Directory: dir}}}, evicted)
+	saved, err := readEvicted(s.evictedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, evicted, saved)
+}
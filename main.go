@@ -15,18 +15,26 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"net"
 	"net/rpc"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/jacobdufault/lspc/jsonrpc"
 	"github.com/mailru/easyjson"
 
+	"github.com/BurntSushi/toml"
 	"github.com/urfave/cli"
 )
 
@@ -41,26 +49,72 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-func getSocketFilename() string {
-	user := os.Getenv("USER")
-	if user == "" {
-		user = "all"
+// Server contains methods which the client can call over rpc. Every
+// connection is served on its own goroutine, so methods run concurrently.
+type Server struct {
+	// mu guards servers, nextID, evicted, config, instanceTurns, startLocks
+	// and gMaxServers. Methods take snapshots with serverList and currentConfig
+	// rather than holding it while talking to language servers.
+	mu      sync.RWMutex
+	servers []*languageServer
+	// id to assign to the next language server that is started.
+	nextID int
+	// Servers shut down by enforceMaxServers, most recent last.
+	evicted []EvictedServer
+	// Where evicted is saved, see evictedPath. Not saved if empty.
+	evictedPath string
+	config      *Config
+	// Number of requests routed to each group of instances started with
+	// --instances, keyed by command and directory. Used for round-robin.
+	instanceTurns map[string]int
+	// Serializes starting servers on demand for queries, so that concurrent
+	// queries about one project start one server.
+	startMu sync.Mutex
+	// Serializes Start per command and directory, keyed by lockPath, so that
+	// concurrent starts of one server reuse it instead of each starting one.
+	startLocks map[string]*sync.Mutex
+}
+
+// serverList returns a copy of the running language servers.
+func (s *Server) serverList() []*languageServer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*languageServer(nil), s.servers...)
+}
+
+// serverInfos returns the info of the running language servers.
+func (s *Server) serverInfos() []ServerInfo {
+	var infos []ServerInfo
+	for _, server := range s.serverList() {
+		infos = append(infos, server.info())
 	}
-	return filepath.Join(os.TempDir(), fmt.Sprintf("lspc.%s", user))
+	return infos
 }
 
-// Server contains methods which the client can call over rpc.
-type Server struct {
-	servers []*languageServer
+// currentConfig returns the active config, which reload-config replaces.
+func (s *Server) currentConfig() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// findServer returns the running language server with the given id.
+func (s *Server) findServer(id int) (*languageServer, error) {
+	for _, server := range s.serverList() {
+		if server.id == id {
+			return server, nil
+		}
+	}
+	return nil, fmt.Errorf("no language server with id %d", id)
 }
 
 func (s *Server) clean() {
-	// This is likely not needed now that we properly shutdown language servers with the languageServerClosed channel.
+	// This is likely not needed now that we properly shutdown language servers with the languageServerClosed queue.
 	/*
 		i := 0
 		for i < len(s.servers) {
 			if s.servers[i].err != nil {
-				log.Printf("Removing language server %+v in %s", s.servers[i].cmd.Args, s.servers[i].directory)
+				log.Printf("Removing language server %+v in %s", s.servers[i].args, s.servers[i].directory)
 				s.servers = append(s.servers[:i], s.servers[i+1:]...)
 			} else {
 				i++
@@ -77,13 +131,19 @@ func (s *Server) KeepAlive(_ bool, _ *bool) error {
 	return nil
 }
 
-var gShutdown bool
+// Set to 1 once the daemon should shut down. Accessed atomically since rpc
+// connections are served concurrently.
+var gShutdown int32
+
+func shutdownRequested() bool {
+	return atomic.LoadInt32(&gShutdown) != 0
+}
 
 // Kill shuts the server down after a short delay.
 // TODO: make kill configurable; kill a specific PID; ls should list the PID to kill (or maybe we want to do `lspc kill 0, lspc kill 1`, etc)
 func (s *Server) Kill(_ bool, _ *bool) error {
 	log.Print("CMD kill")
-	gShutdown = true
+	atomic.StoreInt32(&gShutdown, 1)
 	return nil
 }
 
@@ -94,12 +154,41 @@ func (s *Server) ServerPid(_ bool, pid *int) error {
 	return nil
 }
 
+// ServerInfo describes a running language server.
+type ServerInfo struct {
+	ID        int
+	Pid       int
+	Args      []string
+	Directory string
+	// Name of the configured language, or empty.
+	Language string
+	// Name and version reported by the server, ie, "clangd 17.0.3", or empty.
+	Version string
+	// Files in Directory are never modified, see --read-only.
+	ReadOnly bool
+	// Set once the server has answered initialize.
+	Initialized bool
+	// Active $/progress tokens, ie, indexing.
+	Progress []ProgressInfo
+}
+
+func (info ServerInfo) String() string {
+	s := fmt.Sprintf("%d: %+v in %s", info.ID, info.Args, info.Directory)
+	if info.Version != "" {
+		s += fmt.Sprintf(" (%s)", info.Version)
+	}
+	if info.ReadOnly {
+		s += " [read-only]"
+	}
+	return s
+}
+
 // Ls lists running servers.
-func (s *Server) Ls(_ bool, servers *[]string) error {
+func (s *Server) Ls(_ bool, servers *[]ServerInfo) error {
 	log.Print("CMD ls")
 	s.clean()
-	for _, server := range s.servers {
-		*servers = append(*servers, fmt.Sprintf("%+v in %s", server.cmd.Args, server.directory))
+	for _, server := range s.serverList() {
+		*servers = append(*servers, server.info())
 	}
 	return nil
 }
@@ -108,40 +197,208 @@ func (s *Server) Ls(_ bool, servers *[]string) error {
 type StartArgs struct {
 	Bin       string
 	Directory string
-	InitOpts  easyjson.RawMessage
+	InitOpts  easyjson.RawMessage `json:",omitempty"`
+	// Name of the configured language. If empty it is detected from Bin.
+	Language string
+	// Number of instances to run. Requests for the directory are spread over
+	// them. Zero means one.
+	Instances int
+	// How messages are delimited, lsp or jsonl. If empty the framing of the
+	// language is used, which defaults to lsp.
+	Framing string
+	// Start new servers even if matching ones are running.
+	ForceNew bool
+	// Directories sent as workspace folders. If empty they are detected for
+	// servers that need them, see detectWorkspace.
+	WorkspaceFolders []string
 }
 
-// Start runs a new language server.
-func (s *Server) Start(args StartArgs, _ *bool) error {
+// Start runs a new language server and returns the ids of its instances.
+// Editors may call it whenever they open a project, so if servers with the
+// same command, directory and init options are running their ids are
+// returned instead, unless args.ForceNew is set.
+func (s *Server) Start(args StartArgs, ids *[]int) error {
 	log.Printf("CMD start %s in %s", args.Bin, args.Directory)
+	if ids == nil {
+		ids = new([]int)
+	}
+	args = s.currentConfig().detectWorkspace(args)
+	startLock := s.startLock(args)
+	startLock.Lock()
+	defer startLock.Unlock()
+	if !args.ForceNew {
+		matching, err := s.matchingServers(args)
+		if err != nil {
+			return err
+		}
+		for _, ls := range matching {
+			log.Printf("Reusing language server %d for %s in %s", ls.id, ls.startArgs.Bin, ls.directory)
+			*ids = append(*ids, ls.id)
+		}
+		if len(matching) > 0 {
+			return nil
+		}
+	}
+
+	// Each instance is revived on its own.
+	instances := args.Instances
+	args.Instances = 0
+	args.ForceNew = false
+	for i := 0; i < instances || i == 0; i++ {
+		ls, err := s.start(args)
+		if err != nil {
+			return err
+		}
+		*ids = append(*ids, ls.id)
+	}
+	return nil
+}
+
+// startLock returns the lock which serializes starting the server args
+// describes.
+func (s *Server) startLock(args StartArgs) *sync.Mutex {
+	key := lockPath(s.currentConfig().resolveBin(args.Bin), args.Directory)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.startLocks == nil {
+		s.startLocks = make(map[string]*sync.Mutex)
+	}
+	lock, has := s.startLocks[key]
+	if !has {
+		lock = new(sync.Mutex)
+		s.startLocks[key] = lock
+	}
+	return lock
+}
+
+// matchingServers returns the running servers which args would start again,
+// those with the same command, directory and effective init options.
+func (s *Server) matchingServers(args StartArgs) ([]*languageServer, error) {
+	config := s.currentConfig()
+	initOpts, err := config.initOptionsFor(args)
+	if err != nil {
+		return nil, err
+	}
+	bin := config.resolveBin(args.Bin)
+	var matching []*languageServer
+	for _, ls := range s.serverList() {
+		if ls.startArgs.Bin == bin && filepath.Clean(ls.directory) == filepath.Clean(args.Directory) && bytes.Equal(ls.initOpts, initOpts) {
+			matching = append(matching, ls)
+		}
+	}
+	return matching, nil
+}
+
+func (s *Server) start(args StartArgs) (*languageServer, error) {
+	config := s.currentConfig()
+	args = config.detectWorkspace(args)
+	args.Bin = config.resolveBin(args.Bin)
+	initOpts, err := config.initOptionsFor(args)
+	if err != nil {
+		return nil, err
+	}
 
-	ls, err := startLanguageServer(args.Bin, args.Directory, args.InitOpts)
+	language := config.languageFor(args.Bin, args.Language)
+	if args.Framing == "" {
+		args.Framing = config.Languages[language].Framing
+	}
+	env, err := config.envFor(language, args.Directory)
+	if err != nil {
+		return nil, err
+	}
+	opts := processOptions{
+		sandbox:  config.sandboxFor(language),
+		process:  config.processFor(language),
+		readOnly: config.readOnlyFor(args.Directory),
+		env:      env,
+	}
+	if err := acquireServerLock(args.Bin, args.Directory); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.mu.Unlock()
+	ls, err := startLanguageServer(id, args, language, initOpts, config.compatFor(language), opts)
 	if err != nil {
-		return err
+		s.releaseLock(args.Bin, args.Directory)
+		return nil, err
+	}
+
+	if settings := config.settingsFor(ls.language); settings != nil {
+		ls.setSettings(settings)
 	}
+	ls.setDiagnosticRules(config.diagnosticRulesFor(ls.language))
+	ls.setEncodingRules(config.encodingRules())
+	ls.setDocumentLimit(config.documentLimit())
 
+	s.mu.Lock()
 	s.servers = append(s.servers, ls)
-	return nil
+	s.mu.Unlock()
+	ls.publishEvent(DaemonEvent{Kind: EventServerStarted})
+	s.enforceMaxServers()
+	return ls, nil
+}
+
+// stopServer removes the language server with the given id and returns it.
+// The caller is responsible for closing it.
+func (s *Server) stopServer(id int) (*languageServer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, server := range s.servers {
+		if server.id == id {
+			s.servers = append(s.servers[:i], s.servers[i+1:]...)
+			return server, nil
+		}
+	}
+	return nil, fmt.Errorf("no language server with id %d", id)
+}
+
+// removeServer removes closed from the running language servers. Returns
+// false if it was not one of them, ie, because it was stopped.
+func (s *Server) removeServer(closed *languageServer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, server := range s.servers {
+		if server == closed {
+			s.servers = append(s.servers[:i], s.servers[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
 
 var countdown *time.Timer
 
 func daemonMainLoop() {
-	if gSocket == "" {
-		gSocket = getSocketFilename()
+	config, err := loadConfig(gConfig)
+	panicIfError(err)
+
+	if gChaos != nil {
+		log.Printf("Injecting faults into %.0f%% of client messages (--chaos-seed %d)", gChaos.fraction*100, gChaos.seed)
 	}
 
 	// Register RPC
-	server := new(Server)
+	server := &Server{config: &Config{}, evictedPath: evictedPath()}
+	server.applyConfig(config)
+	if server.evicted, err = readEvicted(server.evictedPath); err != nil {
+		log.Printf("Unable to restore evicted servers: %s", err.Error())
+	}
 	rpc.Register(server)
 
+	if gAuditLog != "" {
+		gAudit, err = openAuditLog(gAuditLog)
+		panicIfError(err)
+		defer gAudit.close()
+	}
+
 	// Open the socket.
 	if !gDisableRemoveSocket {
 		if err := os.Remove(gSocket); err == nil {
 			log.Printf("Removed existing socket")
 		}
 	}
-	log.Printf("Opening socket at %s", gSocket)
+	log.Printf("Opening socket at %s (%s)", gSocket, gSocketSource)
 	listener, err := net.Listen("unix", gSocket)
 	panicIfError(err)
 	defer func() {
@@ -154,51 +411,123 @@ func daemonMainLoop() {
 		for {
 			c, e := listener.Accept()
 			if e != nil {
-				if !gShutdown {
+				if !shutdownRequested() {
 					log.Printf("%s", e.Error())
 				}
-				gShutdown = true
+				atomic.StoreInt32(&gShutdown, 1)
 				return
 			}
 			conn <- c
 		}
 	}()
 
-	// Main loop. Handles incoming requests.
+	if gListen != "" {
+		tcp, err := listenTCP(gListen, gAuthToken, conn)
+		panicIfError(err)
+		defer tcp.Close()
+	}
+	if gHTTP != "" {
+		panicIfError(server.serveHTTP(gHTTP, gAuthToken))
+	}
+	if gGRPC != "" {
+		panicIfError(server.serveGRPC(gGRPC, gAuthToken))
+	}
+
+	gc, err := newGCScheduler(gGCPolicy, time.Duration(gGCIdleDelay)*time.Second)
+	panicIfError(err)
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	// Shut the language servers down instead of leaving them behind.
+	terminate := make(chan os.Signal, 1)
+	signal.Notify(terminate, syscall.SIGTERM, syscall.SIGINT)
+
+	// Main loop. Handles incoming requests. Each connection is served on its
+	// own goroutine so that a slow request does not hold up other clients;
+	// served is signaled once it is closed.
 	timeout := time.Duration(gTimeout) * time.Second
 	countdown = time.NewTimer(timeout)
+	served := make(chan struct{})
+	active := 0
 loop:
 	for {
 		select {
+		case <-hangup:
+			log.Print("SIGHUP")
+			server.reloadConfig()
+
+		case sig := <-terminate:
+			log.Printf("%s; shutting down", sig)
+			atomic.StoreInt32(&gShutdown, 1)
+			break loop
+
 		case c := <-conn:
-			rpc.ServeConn(c)
+			active++
+			go func() {
+				serveRPC(c)
+				served <- struct{}{}
+			}()
 
-			if gShutdown {
+		case <-served:
+			active--
+			if shutdownRequested() {
 				break loop
 			}
 
 			countdown.Reset(timeout)
-			runtime.GC()
-
-		case closed := <-languageServerClosed:
-			i := 0
-			for i < len(server.servers) {
-				if server.servers[i] == closed {
-					if closed.err == nil {
-						log.Printf("Language server %+v in %s has closed", closed.cmd.Args, closed.directory)
-					} else {
-						log.Printf("Language server %+v in %s has closed (err=%s)", closed.cmd.Args, closed.directory, closed.err.Error())
-					}
-					server.servers = append(server.servers[:i], server.servers[i+1:]...)
+			gc.requestHandled()
+
+		case f := <-mainLoopCalls:
+			f()
+			countdown.Reset(timeout)
+			gc.requestHandled()
+
+		case <-gc.idleC():
+			gc.collectIdle()
+
+		case closed := <-languageServerClosed.c:
+			if server.removeServer(closed) {
+				if err := closed.failure(); err == nil {
+					log.Printf("Language server %+v in %s has closed (%s)", closed.args, closed.directory, closed.exitStatus)
 				} else {
-					i++
+					log.Printf("Language server %+v in %s has closed (%s, err=%s)", closed.args, closed.directory, closed.exitStatus, err.Error())
 				}
 			}
+			server.releaseLock(closed.startArgs.Bin, closed.directory)
 
 		case <-countdown.C:
+			// Requests still being served count as activity.
+			if active > 0 {
+				countdown.Reset(timeout)
+				continue
+			}
 			break loop
 		}
 	}
+
+	servers := server.serverList()
+	closeServers(servers)
+	for _, ls := range servers {
+		releaseServerLock(ls.startArgs.Bin, ls.directory)
+	}
+}
+
+// How long language servers get to shut down and exit before they are
+// killed.
+const closeTimeout = 5 * time.Second
+
+// closeServers shuts down every language server in parallel and waits for
+// them to exit, killing those that do not within closeTimeout.
+func closeServers(servers []*languageServer) {
+	var wg sync.WaitGroup
+	for _, ls := range servers {
+		wg.Add(1)
+		go func(ls *languageServer) {
+			defer wg.Done()
+			ls.shutdown(closeTimeout)
+		}(ls)
+	}
+	wg.Wait()
 }
 
 func ensureDaemon() {
@@ -209,50 +538,131 @@ func ensureDaemon() {
 		return
 	}
 
-	log.Printf("Starting daemon")
+	notef("Starting daemon")
 
 	path, err := os.Executable()
 	panicIfError(err)
 
-	p := exec.Command(path, "-socket", gSocket, "daemon")
+	p := exec.Command(path, append(daemonFlags(), "daemon")...)
+	if gAuthToken != "" {
+		// Not passed as a flag, which other users could see.
+		p.Env = append(os.Environ(), "LSPC_AUTH_TOKEN="+gAuthToken)
+	}
 	err = p.Start()
 	panicIfError(err)
 }
 
-func doRPC(serviceMethod string, args interface{}, reply interface{}) {
-	// Fetch the socket filename if not specified
-	if len(gSocket) == 0 {
-		gSocket = getSocketFilename()
+// tryRPC calls serviceMethod on an already running daemon. Unlike doRPC it
+// does not start the daemon and returns errors instead of exiting.
+func tryRPC(serviceMethod string, args interface{}, reply interface{}) error {
+	c, e := dialDaemon()
+	if e != nil {
+		return e
 	}
+	conn := rpc.NewClient(c)
+	defer conn.Close()
+	return conn.Call(serviceMethod, args, reply)
+}
 
-	// Try to connect. If it fails, start a server.
-	conn, e := rpc.Dial("unix", gSocket)
-	if e != nil {
+// daemonFlags returns the global flags which configure the daemon, so that a
+// daemon started by the client behaves as requested.
+func daemonFlags() []string {
+	args := []string{
+		"-socket", gSocket,
+		"-timeout", strconv.Itoa(gTimeout),
+		"-gc-policy", gGCPolicy,
+		"-gc-idle-delay", strconv.Itoa(gGCIdleDelay),
+		"-max-servers", strconv.Itoa(gMaxServers),
+		"-max-message-size", strconv.Itoa(gMaxMessageSize),
+		"-codec", gCodecName,
+		"-http", gHTTP,
+		"-http-hosts", gHTTPHosts,
+		"-http-origins", gHTTPOrigins,
+		"-grpc", gGRPC,
+		"-listen", gListen,
+		"-config", gConfig,
+		"-install-dir", gInstallDir,
+		"-audit-log", gAuditLog,
+		"-edit-journal", gEditJournal,
+		"-chaos", strconv.FormatFloat(gChaosFraction, 'g', -1, 64),
+		"-chaos-delay", strconv.Itoa(gChaosDelay),
+		"-chaos-seed", strconv.Itoa(gChaosSeed),
+	}
+	if gNotify {
+		args = append(args, "-notify")
+	}
+	if gReadOnly {
+		args = append(args, "-read-only")
+	}
+	if gResyncOutput {
+		args = append(args, "-resync-output")
+	}
+	if gSkipStartupNoise {
+		args = append(args, "-skip-startup-noise")
+	}
+	return args
+}
+
+func doRPC(serviceMethod string, args interface{}, reply interface{}) {
+	// Try to connect. If it fails, start a server, unless it is remote.
+	logf(verbosityVerbose, "Connecting to %s", daemonAddress())
+	c, e := dialDaemon()
+	if e != nil && gRemote == "" {
 		ensureDaemon()
 
 		// Try to connect again if we after waiting a bit for the server to start.
 		// FIXME: is there a more robust approach here?
 		time.Sleep(time.Millisecond * 250)
 
-		conn, e = rpc.Dial("unix", gSocket)
-		if e != nil {
-			fmt.Printf("Unable to connect to socket: %s\n", e.Error())
-			os.Exit(2)
-		}
+		c, e = dialDaemon()
+	}
+	if e != nil {
+		fmt.Printf("Unable to connect to %s: %s\n", daemonAddress(), e.Error())
+		os.Exit(2)
 	}
+	conn := rpc.NewClient(c)
 
+	logf(verbosityDebug, "%s %s", serviceMethod, debugValue(args))
+	start := time.Now()
 	e = conn.Call(serviceMethod, args, reply)
 	conn.Close()
+	logf(verbosityVerbose, "%s took %s", serviceMethod, time.Since(start).Round(time.Millisecond))
 
 	if e != nil {
-		fmt.Printf("error during rpc: %s", e.Error())
+		if gJSON {
+			printJSONError(e)
+		} else {
+			fmt.Printf("error during rpc: %s", e.Error())
+		}
 		os.Exit(1)
 	}
+	if reply != nil {
+		logf(verbosityDebug, "%s replied %s", serviceMethod, debugValue(reply))
+	}
 }
 
-var gSocket string
 var gDisableRemoveSocket bool
 var gTimeout int
+var gNotify bool
+var gGCPolicy string
+var gGCIdleDelay int
+var gMaxServers int
+var gConfig string
+var gMaxMessageSize int
+var gCodecName string
+var gHTTP string
+var gHTTPHosts string
+var gHTTPOrigins string
+var gGRPC string
+var gInstallDir string
+var gAuditLog string
+var gEditJournal string
+var gReadOnly bool
+var gResyncOutput bool
+var gSkipStartupNoise bool
+var gChaosFraction float64
+var gChaosDelay int
+var gChaosSeed int
 
 func main() {
 	app := cli.NewApp()
@@ -265,9 +675,47 @@ func main() {
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:        "socket",
-			Usage:       "Path to a socket that lspc will use to communicate with the daemon.",
-			EnvVar:      "LSPC_SOCKET",
-			Destination: &gSocket,
+			Usage:       "Path to a socket that lspc will use to communicate with the daemon. Defaults to $LSPC_SOCKET.",
+			Destination: &gSocketFlag,
+		},
+		cli.StringFlag{
+			Name:        "profile",
+			Usage:       "Use a separate daemon with this name, ie, one started with other flags. Ignored if a socket is given.",
+			EnvVar:      "LSPC_PROFILE",
+			Destination: &gProfile,
+		},
+		cli.BoolFlag{
+			Name:        "per-workspace",
+			Usage:       "Use a separate daemon for each workspace, the closest directory with .lspc.toml or .git. Ignored if a socket or profile is given.",
+			EnvVar:      "LSPC_PER_WORKSPACE",
+			Destination: &gPerWorkspace,
+		},
+		cli.BoolFlag{
+			Name:        "plain",
+			Usage:       "Print plain ASCII for scripts and logs: no colors, icons or screen redraws, and table columns separated by a tab instead of aligned",
+			EnvVar:      "LSPC_PLAIN",
+			Destination: &gPlain,
+		},
+		cli.BoolFlag{
+			Name:        "json",
+			Usage:       "Print the results of commands as JSON for editor plugins and scripts; notices and progress go to stderr",
+			EnvVar:      "LSPC_JSON",
+			Destination: &gJSON,
+		},
+		cli.BoolFlag{
+			Name:        "quiet, q",
+			Usage:       "Only print results and errors, not notices such as that a list is incomplete",
+			Destination: &gQuiet,
+		},
+		cli.BoolFlag{
+			Name:        "verbose, v",
+			Usage:       "Also print the daemon requests a command sends and how long they take to stderr",
+			Destination: &gVerbose,
+		},
+		cli.BoolFlag{
+			Name:        "vv",
+			Usage:       "Like -v, and also print the arguments and replies of the requests",
+			Destination: &gDebug,
 		},
 		cli.BoolFlag{
 			Name:        "disable-remove-socket",
@@ -282,6 +730,152 @@ func main() {
 			Value:       60 * 30,
 			Destination: &gTimeout,
 		},
+		cli.BoolFlag{
+			Name:        "notify",
+			Usage:       "Show desktop notifications for language server errors, warnings and finished background tasks.",
+			EnvVar:      "LSPC_NOTIFY",
+			Destination: &gNotify,
+		},
+		cli.StringFlag{
+			Name:        "gc-policy",
+			Usage:       "When the daemon collects garbage: idle (after --gc-idle-delay without requests), request (after every request, lowest memory use) or off",
+			EnvVar:      "LSPC_GC_POLICY",
+			Value:       gcPolicyIdle,
+			Destination: &gGCPolicy,
+		},
+		cli.IntFlag{
+			Name:        "gc-idle-delay",
+			Usage:       "Seconds without requests before the idle gc policy returns memory to the OS",
+			EnvVar:      "LSPC_GC_IDLE_DELAY",
+			Value:       10,
+			Destination: &gGCIdleDelay,
+		},
+		cli.IntFlag{
+			Name:        "max-servers",
+			Usage:       "Maximum number of running language servers; the least recently used server is shut down when exceeded. 0 means no limit",
+			EnvVar:      "LSPC_MAX_SERVERS",
+			Destination: &gMaxServers,
+		},
+		cli.IntFlag{
+			Name:        "max-message-size",
+			Usage:       "Largest message in MiB accepted from a language server. Larger messages are dropped and the request they answer fails",
+			EnvVar:      "LSPC_MAX_MESSAGE_SIZE",
+			Value:       16,
+			Destination: &gMaxMessageSize,
+		},
+		cli.StringFlag{
+			Name:        "codec",
+			Usage:       "JSON codec used for messages: " + strings.Join(codecNames(), ", "),
+			EnvVar:      "LSPC_CODEC",
+			Value:       "easyjson",
+			Destination: &gCodecName,
+		},
+		cli.StringFlag{
+			Name:        "http",
+			Usage:       "Address, ie, localhost:7658, on which the daemon serves queries over HTTP and WebSocket. Requests must send --auth-token as \"Authorization: Bearer <token>\", or as ?token=<token> to /ws, if it is set, which is required for addresses other than loopback. Disabled if empty",
+			EnvVar:      "LSPC_HTTP",
+			Destination: &gHTTP,
+		},
+		cli.StringFlag{
+			Name:        "http-hosts",
+			Usage:       "Comma separated host names, besides localhost and loopback addresses, under which clients reach --http, ie, devbox.internal. Requests for other hosts are rejected",
+			EnvVar:      "LSPC_HTTP_HOSTS",
+			Destination: &gHTTPHosts,
+		},
+		cli.StringFlag{
+			Name:        "http-origins",
+			Usage:       "Comma separated origins of web pages, ie, http://localhost:5173, which may connect to the WebSocket endpoint of --http",
+			EnvVar:      "LSPC_HTTP_ORIGINS",
+			Destination: &gHTTPOrigins,
+		},
+		cli.StringFlag{
+			Name:        "grpc",
+			Usage:       "Address, ie, localhost:7659, on which the daemon serves the gRPC control API in controlpb/control.proto. Calls must send --auth-token as \"authorization: Bearer <token>\" metadata if it is set, which is required for addresses other than loopback. Disabled if empty",
+			EnvVar:      "LSPC_GRPC",
+			Destination: &gGRPC,
+		},
+		cli.StringFlag{
+			Name:        "listen",
+			Usage:       "Address, ie, tcp://0.0.0.0:7660, on which the daemon also accepts clients authenticated with --auth-token, for lspc --remote on other machines. Disabled if empty",
+			EnvVar:      "LSPC_LISTEN",
+			Destination: &gListen,
+		},
+		cli.StringFlag{
+			Name:        "remote",
+			Usage:       "Address, ie, tcp://build-host:7660, of a daemon started with --listen to connect to instead of the local daemon",
+			EnvVar:      "LSPC_REMOTE",
+			Destination: &gRemote,
+		},
+		cli.StringFlag{
+			Name:        "auth-token",
+			Usage:       "Token clients connecting over --listen, --http or --grpc must present, and that --remote presents. Best set with $LSPC_AUTH_TOKEN, since arguments are visible to other users",
+			EnvVar:      "LSPC_AUTH_TOKEN",
+			Destination: &gAuthToken,
+		},
+		cli.StringFlag{
+			Name:        "config",
+			Usage:       "Path to the config file.",
+			EnvVar:      "LSPC_CONFIG",
+			Value:       defaultConfigPath(),
+			Destination: &gConfig,
+		},
+		cli.StringFlag{
+			Name:        "install-dir",
+			Usage:       "Directory lspc install puts language servers in.",
+			EnvVar:      "LSPC_INSTALL_DIR",
+			Value:       defaultInstallDir(),
+			Destination: &gInstallDir,
+		},
+		cli.StringFlag{
+			Name:        "audit-log",
+			Usage:       "File the daemon appends a JSON line to for every control command, recording who sent it, its arguments and result. Disabled if empty",
+			EnvVar:      "LSPC_AUDIT_LOG",
+			Destination: &gAuditLog,
+		},
+		cli.StringFlag{
+			Name:        "edit-journal",
+			Usage:       "File recording the edits lspc applies to files, so that lspc undo-last-edit can revert them. Disabled if empty",
+			EnvVar:      "LSPC_EDIT_JOURNAL",
+			Value:       defaultEditJournal(),
+			Destination: &gEditJournal,
+		},
+		cli.BoolFlag{
+			Name:        "read-only",
+			Usage:       "Never modify files in projects; edits sent by language servers and commands which write files are rejected while queries still work. Can also be set with read_only in the config or a project's .lspc.toml",
+			EnvVar:      "LSPC_READ_ONLY",
+			Destination: &gReadOnly,
+		},
+		cli.BoolFlag{
+			Name:        "resync-output",
+			Usage:       "When a language server writes bytes that are not part of a message, skip ahead to the next Content-Length header and log what was skipped instead of killing the server",
+			EnvVar:      "LSPC_RESYNC_OUTPUT",
+			Destination: &gResyncOutput,
+		},
+		cli.BoolFlag{
+			Name:        "skip-startup-noise",
+			Usage:       "Discard and log lines a language server prints to stdout before its first message, ie, banners, instead of killing the server",
+			EnvVar:      "LSPC_SKIP_STARTUP_NOISE",
+			Destination: &gSkipStartupNoise,
+		},
+		cli.Float64Flag{
+			Name:        "chaos",
+			Usage:       "Fraction of the responses and events sent to HTTP, WebSocket and control socket clients, but not gRPC clients, that are delayed, dropped or turned into errors, to test how editor integrations handle flaky servers. 0 disables",
+			EnvVar:      "LSPC_CHAOS",
+			Destination: &gChaosFraction,
+		},
+		cli.IntFlag{
+			Name:        "chaos-delay",
+			Usage:       "Longest delay in milliseconds injected by --chaos",
+			EnvVar:      "LSPC_CHAOS_DELAY",
+			Value:       3000,
+			Destination: &gChaosDelay,
+		},
+		cli.IntFlag{
+			Name:        "chaos-seed",
+			Usage:       "Seed for the faults injected by --chaos, to reproduce a run. 0 picks a random seed, which is logged",
+			EnvVar:      "LSPC_CHAOS_SEED",
+			Destination: &gChaosSeed,
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -307,69 +901,1843 @@ func main() {
 			Action: func(c *cli.Context) error {
 				var pid int
 				doRPC("Server.ServerPid", false, &pid)
+				if gJSON {
+					return printJSON(pid)
+				}
 				println(pid)
 				return nil
 			},
 		},
+		{
+			Name:      "ping",
+			Usage:     "measure round-trip time to the daemon and language servers",
+			UsageText: "lspc ping [--server <id>]...",
+			Description: `Measures how long a request to the daemon takes. If --server is given the
+   daemon also sends a trivially cheap request to the language server with
+   that id (as listed by ls) and reports how long the server took to answer.
+   Use --server all to ping every running language server.`,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "server",
+					Usage: "id of a language server to ping, or all",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return ping(c.StringSlice("server"))
+			},
+		},
 		{
 			Name:        "ls",
 			Description: "List all running language servers",
 			Action: func(c *cli.Context) error {
-				var servers []string
+				var servers []ServerInfo
 				doRPC("Server.Ls", false, &servers)
+				if gJSON {
+					return printJSON(servers)
+				}
 				for _, server := range servers {
-					println(server)
+					println(server.String())
+					for _, p := range server.Progress {
+						println("    " + p.String())
+					}
 				}
 				return nil
 			},
 		},
 		{
-			Name:      "start",
-			Usage:     "start a new language server",
-			UsageText: "lspc start <bin> <project-dir> [<init>]",
-			Description: `<bin> can be a quoted string which will be parsed as shell words, ie,
-   "cquery --log-file log.txt" will run cquery with the arguments [--log-file, log.txt]
+			Name:  "status",
+			Usage: "show whether language servers are still busy, ie, indexing",
+			Description: `Prints one line per running language server: ready, or the progress
+   it reports with an estimate of the time left. The estimate assumes the
+   server keeps progressing at its average rate so far.`,
+			Action: func(c *cli.Context) error {
+				var servers []ServerInfo
+				doRPC("Server.Ls", false, &servers)
+				if gJSON {
+					return printJSON(servers)
+				}
+				for _, server := range servers {
+					fmt.Print(serverStatus(server))
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "reload-config",
+			Usage: "re-read the config file",
+			Description: `Re-reads the config file. Logging and limit settings apply immediately and
+   language servers whose settings changed are sent
+   workspace/didChangeConfiguration. Init options only apply to servers
+   started afterwards. Sending SIGHUP to the daemon does the same.`,
+			Action: func(c *cli.Context) error {
+				var changes []string
+				doRPC("Server.ReloadConfig", false, &changes)
+				if gJSON {
+					return printJSON(changes)
+				}
+				for _, change := range changes {
+					fmt.Println(change)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "revive",
+			Usage:     "restart a language server that was shut down by --max-servers",
+			UsageText: "lspc revive [<index>]",
+			Description: `Without arguments lists language servers which were shut down because
+   --max-servers was exceeded. With an index, starts that server again using
+   the arguments it was originally started with and opens the documents that
+   were open in it again. The list is kept next to the socket, so servers can
+   be revived after the daemon restarts.`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					var evicted []EvictedServer
+					doRPC("Server.Evicted", false, &evicted)
+					if gJSON {
+						return printJSON(evicted)
+					}
+					for i, server := range evicted {
+						fmt.Printf("%d: %s in %s (%d open documents)\n", i, server.Bin, server.Directory, len(server.Documents))
+					}
+					return nil
+				}
+				index, err := strconv.Atoi(c.Args().Get(0))
+				if err != nil {
+					return fmt.Errorf("invalid index %q", c.Args().Get(0))
+				}
+				doRPC("Server.Revive", index, nil)
+				return nil
+			},
+		},
+		{
+			Name:      "top",
+			Usage:     "live dashboard of language server activity",
+			UsageText: "lspc top [--interval <seconds>]",
+			Description: `Shows running language servers along with per-method request rates and
+   latencies, active progress and recently published diagnostics. It
+   refreshes as soon as servers start, stop, report progress or publish
+   diagnostics, and every --interval otherwise. Press Ctrl-C to exit.`,
+			Flags: []cli.Flag{
+				cli.Float64Flag{
+					Name:  "interval",
+					Usage: "seconds between refreshes without events",
+					Value: 1,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return top(time.Duration(c.Float64("interval") * float64(time.Second)))
+			},
+		},
+		{
+			Name:      "check",
+			Usage:     "print the diagnostics of files",
+			UsageText: "lspc check [--baseline <file> [--update-baseline]] [--fail-on <severity>] [--wait <seconds>] [--format text|quickfix|json|fzf] [--group <name>] <file or dir>...",
+			Description: `Opens every file, and every source file in the given directories, waits for
+   the language servers to publish diagnostics and prints them. Exits with
+   status 1 if any diagnostic is at least as severe as --fail-on.
 
-   <project-dir> is the directory containing the content that the language server will
-   analyze
+   With --baseline only diagnostics that are not in the baseline file are
+   printed, so that strict checks can be adopted on existing code. Run with
+   --update-baseline to replace the baseline with the current diagnostics.
 
-   <init> can be a raw json literal passed to the language server in the initialization
-	 message, ex, '{"cacheDirectory": "/ssd/cquery_cache/"}'. Defaults to {}
+   Locations a diagnostic refers to, ie, a previous declaration, are printed
+   after it: indented with --format text, as notes with --format quickfix,
+   which vim and emacs can jump to, as lines of their own with --format fzf
+   and in the related list with --format json.
 
-   Example:
-    $ lspc start "cquery --log-all-to-stderr" /work/chrome '{"cacheDirectory": "/ssd/cquery_cache"}'`,
+   Diagnostics of every server running for a file are merged and labeled with
+   the servers that reported them. --group only reports those of the servers
+   of a [group.<name>] in the config.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				groupFlag,
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "text, quickfix, json (one object per line) or fzf (tab separated)",
+					Value: diagnosticsText,
+				},
+				cli.StringFlag{
+					Name:  "baseline",
+					Usage: "JSON file of accepted diagnostics",
+				},
+				cli.BoolFlag{
+					Name:  "update-baseline",
+					Usage: "write the current diagnostics to the baseline file instead of checking",
+				},
+				cli.StringFlag{
+					Name:  "fail-on",
+					Usage: "least severe diagnostic that fails the check: error, warning, information or hint",
+					Value: "error",
+				},
+				cli.Float64Flag{
+					Name:  "wait",
+					Usage: "seconds to wait for diagnostics",
+					Value: 30,
+				},
+			},
 			Action: func(c *cli.Context) error {
-				if c.NArg() != 2 && c.NArg() != 3 {
-					return cli.ShowCommandHelp(c, "start")
+				if c.NArg() == 0 {
+					return cli.ShowCommandHelp(c, "check")
 				}
-
-				init := c.Args().Get(2)
-				if init == "" {
-					init = "{}"
+				failOn, err := parseSeverity(c.String("fail-on"))
+				if err != nil {
+					return err
 				}
-				args := StartArgs{
-					Bin:       c.Args().Get(0),
-					Directory: c.Args().Get(1),
-					InitOpts:  easyjson.RawMessage(init),
+				format := outputFormat(c.String("format"), diagnosticsJSON)
+				if err := checkDiagnosticsFormat(format); err != nil {
+					return err
+				}
+				unit, err := parseColumnUnit(c.String("unit"))
+				if err != nil {
+					return err
+				}
+				baselinePath := c.String("baseline")
+				if c.Bool("update-baseline") && baselinePath == "" {
+					return fmt.Errorf("--update-baseline requires --baseline")
+				}
+				var accepted *baseline
+				if baselinePath != "" {
+					// Entries are relative to the baseline.
+					if baselinePath, err = filepath.Abs(baselinePath); err != nil {
+						return err
+					}
+				}
+				if baselinePath != "" && !c.Bool("update-baseline") {
+					accepted, err = loadBaseline(baselinePath)
+					if os.IsNotExist(err) {
+						return fmt.Errorf("baseline %s does not exist; create it with --update-baseline", baselinePath)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				files, err := checkFiles(c.Args())
+				if err != nil {
+					return err
+				}
+
+				args := CheckArgs{Files: files, Unit: unit, Wait: time.Duration(c.Float64("wait") * float64(time.Second)), Group: c.String("group")}
+				var result CheckResult
+				doRPC("Server.Check", args, &result)
+				for _, file := range result.TimedOut {
+					notef("No diagnostics received for %s", file)
 				}
 
-				doRPC("Server.Start", args, nil)
+				diagnostics := result.Diagnostics
+				if c.Bool("update-baseline") {
+					b := newBaseline(baselinePath, diagnostics)
+					if err := b.save(baselinePath); err != nil {
+						return err
+					}
+					if gJSON {
+						return printJSON(struct {
+							Baseline    string
+							Diagnostics int
+						}{baselinePath, b.total()})
+					}
+					fmt.Printf("Wrote %d diagnostic(s) to %s\n", b.total(), baselinePath)
+					return nil
+				}
+				if accepted != nil {
+					diagnostics = accepted.newDiagnostics(baselinePath, diagnostics)
+				}
+				if printDiagnostics(diagnostics, failOn, format) > 0 {
+					os.Exit(1)
+				}
 				return nil
 			},
 		},
 		{
-			Name:        "daemon",
-			Usage:       "run the lspc daemon",
-			UsageText:   "lspc daemon",
-			Description: "Run the lspc daemon. In typical operation lspc will start the daemon for you.",
+			Name:      "diagnostics",
+			Usage:     "print the diagnostics the language servers published",
+			UsageText: "lspc diagnostics [--watch] [--format text|quickfix|json|fzf] [--group <name>] (<file> | --all)",
+			Description: `Prints the diagnostics the language servers last published for <file>, or
+   with --all for every file, with their severity, location, code and
+   message. Unlike check it does not wait for the servers to analyze <file>;
+   it only opens it.
+
+   With --watch the diagnostics of a file are printed again every time a
+   server publishes new ones, until interrupted. Files whose diagnostics were
+   cleared print "no diagnostics"; with --format json every update is printed
+   as an object with the file and its diagnostics instead.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				groupFlag,
+				cli.BoolFlag{
+					Name:  "all",
+					Usage: "print the diagnostics of every file",
+				},
+				cli.BoolFlag{
+					Name:  "watch",
+					Usage: "keep printing diagnostics as they are published",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "output format: text, quickfix, json or fzf",
+					Value: diagnosticsText,
+				},
+			},
 			Action: func(c *cli.Context) error {
-				daemonMainLoop()
+				if c.Bool("all") && c.NArg() != 0 || !c.Bool("all") && c.NArg() != 1 {
+					return fmt.Errorf("expected <file> or --all")
+				}
+				format := outputFormat(c.String("format"), diagnosticsJSON)
+				if err := checkDiagnosticsFormat(format); err != nil {
+					return err
+				}
+				unit, err := parseColumnUnit(c.String("unit"))
+				if err != nil {
+					return err
+				}
+				args := DiagnosticsArgs{Unit: unit, Group: c.String("group")}
+				if !c.Bool("all") {
+					if args.File, err = filepath.Abs(c.Args().First()); err != nil {
+						return err
+					}
+				}
+				if c.Bool("watch") {
+					return watchDiagnostics(args, format, os.Stdout)
+				}
+				var diagnostics map[string][]Diagnostic
+				doRPC("Server.Diagnostics", args, &diagnostics)
+				printDiagnostics(diagnostics, 0, format)
 				return nil
 			},
 		},
-	}
-
-	err := app.Run(os.Args)
+		{
+			Name:      "explain",
+			Usage:     "print the documentation of a diagnostic",
+			UsageText: "lspc explain [--unit byte|rune|utf-16] [--browser] [--wait <seconds>] <file:line:col | code>",
+			Description: `Prints the diagnostics at <file:line:col>, or every published diagnostic
+   with <code>, with the locations they refer to, ie, a previous declaration.
+   Then fetches the documentation the language server links for their code
+   and prints it as text, or with --browser opens it in the browser.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				cli.BoolFlag{
+					Name:  "browser",
+					Usage: "open the documentation in the browser instead of printing it",
+				},
+				cli.Float64Flag{
+					Name:  "wait",
+					Usage: "seconds to wait for diagnostics",
+					Value: 30,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "explain")
+				}
+				unit, err := parseColumnUnit(c.String("unit"))
+				if err != nil {
+					return err
+				}
+				args, err := parseExplainTarget(c.Args().First(), unit)
+				if err != nil {
+					return err
+				}
+				args.Wait = time.Duration(c.Float64("wait") * float64(time.Second))
+				var diagnostics []Diagnostic
+				doRPC("Server.Explain", args, &diagnostics)
+				if gJSON && !c.Bool("browser") {
+					return printJSON(explanations(diagnostics))
+				}
+				return explain(os.Stdout, diagnostics, c.Bool("browser"))
+			},
+		},
+		{
+			Name:  "bench",
+			Usage: "measure language servers",
+			Subcommands: []cli.Command{
+				{
+					Name:      "index",
+					Usage:     "time how long a language server takes to index a project",
+					UsageText: "lspc bench index [--language <name>] [--settle <seconds>] [--timeout <seconds>] [--out <file>] <project-dir> [<bin>]",
+					Description: `Starts a new instance of <bin>, or of the command configured for
+   --language, in <project-dir> and waits until it has finished indexing: it
+   has initialized and no $/progress token has been active for --settle
+   seconds. Then the server is stopped and the wall-clock time until the last
+   progress report and the peak RSS of the server are written as JSON, ie,
+   to compare across commits in CI. Caches the server keeps on disk are not
+   removed; delete them first for a truly cold start.`,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "language",
+							Usage: "name of the language in the config file whose command and settings apply",
+						},
+						cli.Float64Flag{
+							Name:  "settle",
+							Usage: "seconds without progress after which indexing counts as finished",
+							Value: 2,
+						},
+						cli.Float64Flag{
+							Name:  "timeout",
+							Usage: "seconds to wait for indexing to finish",
+							Value: 1800,
+						},
+						cli.StringFlag{
+							Name:  "out",
+							Usage: "write the JSON result to this file instead of stdout",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 && c.NArg() != 2 {
+							return cli.ShowCommandHelp(c, "index")
+						}
+						if c.NArg() == 1 && c.String("language") == "" {
+							return fmt.Errorf("either <bin> or --language is required")
+						}
+						directory, err := filepath.Abs(c.Args().Get(0))
+						if err != nil {
+							return err
+						}
+						args := BenchIndexArgs{
+							Bin:       c.Args().Get(1),
+							Directory: directory,
+							Language:  c.String("language"),
+							Settle:    time.Duration(c.Float64("settle") * float64(time.Second)),
+							Timeout:   time.Duration(c.Float64("timeout") * float64(time.Second)),
+						}
+						var result BenchIndexResult
+						doRPC("Server.BenchIndex", args, &result)
+						if err := writeBenchResult(result, c.String("out")); err != nil {
+							return err
+						}
+						if result.TimedOut {
+							fmt.Fprintln(os.Stderr, "Indexing did not finish within --timeout")
+							os.Exit(1)
+						}
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:      "docgen",
+			Usage:     "generate reference documentation for a project",
+			UsageText: "lspc docgen [--out <dir>] [--format markdown|html] <project-dir>",
+			Description: `Writes a page for every source file in <project-dir> listing the symbols the
+   language server reports for it with their hover documentation and links to
+   where they are defined, and an index page of all files and symbols. A
+   language server must be running for <project-dir>.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "out",
+					Usage: "directory to write the documentation to",
+					Value: "site",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "markdown or html",
+					Value: "markdown",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "docgen")
+				}
+				dir, err := filepath.Abs(c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				out, err := filepath.Abs(c.String("out"))
+				if err != nil {
+					return err
+				}
+				return docgen(dir, out, c.String("format"))
+			},
+		},
+		{
+			Name:      "open",
+			Usage:     "open files in their language servers",
+			UsageText: "lspc open <file>...",
+			Description: `Sends textDocument/didOpen for each <file> to every language server running
+   for its project, so that they start analyzing it. Queries open the files
+   they are about anyway. Prints the version of the document each server
+   has; it goes up every time the file is modified and sent again.`,
+			Action: func(c *cli.Context) error {
+				return runDocumentCommand(c, "Server.OpenDocument")
+			},
+		},
+		{
+			Name:      "change",
+			Usage:     "send the current text of open files to their language servers",
+			UsageText: "lspc change <file>...",
+			Description: `Sends textDocument/didChange with the text on disk of each <file> to the
+   language servers that have it open, if it was modified since they were
+   last sent it, and prints the resulting versions. Queries do the same
+   before they are sent.`,
+			Action: func(c *cli.Context) error {
+				return runDocumentCommand(c, "Server.ChangeDocument")
+			},
+		},
+		{
+			Name:      "close",
+			Usage:     "close files in their language servers",
+			UsageText: "lspc close <file>...",
+			Description: `Sends textDocument/didClose for each <file> to the language servers that
+   have it open and prints the last version they had. Fails if no server has
+   it open.`,
+			Action: func(c *cli.Context) error {
+				return runDocumentCommand(c, "Server.CloseDocument")
+			},
+		},
+		{
+			Name:      "definition",
+			Usage:     "print where the symbol at a position is defined",
+			UsageText: "lspc definition [--unit byte|rune|utf-16] [--group <name>] <file> <line> <col>",
+			Description: `Prints <file>:<line>:<col> of each definition of the symbol at the 1-based
+   <line> and <col> of <file>, as answered by the language server running for
+   the project that contains <file>. Prints nothing if the symbol has no
+   definition.
+
+   [[rewrite]] rules in the config apply, and the definitions of several
+   servers are merged if a [[route]] sends textDocument/definition to them or
+   --group is given.`,
+			Flags: []cli.Flag{unitFlag, groupFlag},
+			Action: func(c *cli.Context) error {
+				args, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				var locations []Location
+				doRPC("Server.Definition", args, &locations)
+				if gJSON {
+					return printJSON(locations)
+				}
+				for _, location := range locations {
+					fmt.Println(location)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "references",
+			Usage:     "print the references to the symbol at a position",
+			UsageText: "lspc references [--include-declaration] [--format plain|grep|json] [--unit byte|rune|utf-16] [--group <name>] <file> <line> <col>",
+			Description: `Prints the references to the symbol at the 1-based <line> and <col> of <file>,
+   sorted by file and position, as
+    plain: <file>:<line>:<col>
+    grep:  <file>:<line>:<col>:<text of the line>, which vim's errorformat
+           reads, ie, lspc references --format grep ... | vim -q -
+    json:  one {"file", "line", "column", "end_line", "end_column"} object
+           per line
+
+   References of several servers are merged if a [[route]] sends
+   textDocument/references to them or --group is given.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				groupFlag,
+				cli.BoolFlag{
+					Name:  "include-declaration",
+					Usage: "also print the declaration of the symbol",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "plain, grep or json (one object per line)",
+					Value: referencesPlain,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				format := outputFormat(c.String("format"), referencesJSON)
+				if err := checkReferencesFormat(format); err != nil {
+					return err
+				}
+				position, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				args := ReferencesArgs{PositionArgs: position, IncludeDeclaration: c.Bool("include-declaration")}
+				var locations []Location
+				doRPC("Server.References", args, &locations)
+				return writeReferences(os.Stdout, locations, format)
+			},
+		},
+		{
+			Name:      "symbols",
+			Usage:     "search the workspaces of language servers for symbols",
+			UsageText: "lspc symbols [--dir <directory>] [--kind <kind>]... [--icons] [--unit byte|rune|utf-16] [--group <name>] <query>",
+			Description: `Sends workspace/symbol with <query> to the language server running for
+   --dir, or to every running server if it is not given, and prints the
+   location, kind and name of each symbol found, separated by tabs. The name
+   is followed by the symbol containing it, if any.
+   Servers usually match <query> fuzzily; an empty query asks for every
+   symbol, which not every server supports.
+
+   When several servers are searched each symbol is followed by the server
+   that found it; without --dir servers that fail are skipped. --kind keeps
+   only symbols of a kind, ie, class, function or enum-member. Kinds are
+   printed as for lspc complete. --group searches every server of a
+   [group.<name>] in the config.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				groupFlag,
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "directory of the project to search",
+				},
+				cli.StringSliceFlag{
+					Name:  "kind",
+					Usage: "only print symbols of this kind; may be repeated or comma separated",
+				},
+				cli.BoolFlag{
+					Name:  "icons",
+					Usage: "print a nerd font icon before each kind",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return fmt.Errorf("expected <query>, got %d arguments", c.NArg())
+				}
+				kinds, err := parseSymbolKinds(c.StringSlice("kind"))
+				if err != nil {
+					return err
+				}
+				unit, err := parseColumnUnit(c.String("unit"))
+				if err != nil {
+					return err
+				}
+				args := WorkspaceSymbolsArgs{Query: c.Args().Get(0), Unit: unit, Group: c.String("group")}
+				if c.IsSet("dir") {
+					if args.Directory, err = filepath.Abs(c.String("dir")); err != nil {
+						return err
+					}
+				}
+				config, err := loadConfig(gConfig)
+				if err != nil {
+					return err
+				}
+				var symbols []Symbol
+				doRPC("Server.WorkspaceSymbols", args, &symbols)
+				if gJSON {
+					return printJSON(matchingSymbols(symbols, kinds))
+				}
+				writeSymbols(os.Stdout, symbols, kinds, config, useIcons(c.Bool("icons")))
+				return nil
+			},
+		},
+		{
+			Name:      "document-symbols",
+			Usage:     "print an outline of the symbols of a file",
+			UsageText: "lspc document-symbols [--icons] [--unit byte|rune|utf-16] <file>",
+			Description: `Prints the 1-based line and column, kind and name of each symbol of <file>,
+   separated by tabs. Symbols nested in others, ie, the methods of a class,
+   are indented by two spaces below them if the language server reports a
+   hierarchy; otherwise each name is followed by the symbol containing it.
+   Kinds are printed as for lspc complete.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				cli.BoolFlag{
+					Name:  "icons",
+					Usage: "print a nerd font icon before each kind",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return fmt.Errorf("expected <file>, got %d arguments", c.NArg())
+				}
+				file, err := filepath.Abs(c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				unit, err := parseColumnUnit(c.String("unit"))
+				if err != nil {
+					return err
+				}
+				config, err := loadConfig(gConfig)
+				if err != nil {
+					return err
+				}
+				var symbols []DocumentSymbol
+				doRPC("Server.DocumentSymbols", DocumentSymbolsArgs{File: file, Unit: unit}, &symbols)
+				if gJSON {
+					return printJSON(symbols)
+				}
+				writeOutline(os.Stdout, symbols, config, useIcons(c.Bool("icons")))
+				return nil
+			},
+		},
+		{
+			Name:      "hover",
+			Usage:     "print the documentation of a symbol, or of every symbol in a file",
+			UsageText: "lspc hover [--format markdown|text] [--unit byte|rune|utf-16] <file> <line> <col>\n   lspc hover (--symbols | --range <start>..<end>) [--format markdown|text] [--unit byte|rune|utf-16] <file>",
+			Description: `Prints the hover documentation of the symbol at the 1-based <line> and <col>
+   of <file>. With --symbols every symbol the language server reports for
+   <file> is hovered instead, or with --range only those starting between the
+   given lines, and each is printed as
+    <file>:<line>:<col> <symbol>
+        <documentation, indented by four spaces>
+
+   The documentation is printed as markdown, or with --format text without
+   code fences, headings and other markup.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "markdown or text",
+					Value: "markdown",
+				},
+				cli.BoolFlag{
+					Name:  "symbols",
+					Usage: "hover every symbol in the file",
+				},
+				cli.StringFlag{
+					Name:  "range",
+					Usage: "hover the symbols starting in lines <start>..<end>; either end may be left out",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				format := c.String("format")
+				if format != "markdown" && format != "text" {
+					return fmt.Errorf("unknown format %q; expected markdown or text", format)
+				}
+				var args HoverArgs
+				if c.Bool("symbols") || c.IsSet("range") {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected <file>, got %d arguments", c.NArg())
+					}
+					file, err := filepath.Abs(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					unit, err := parseColumnUnit(c.String("unit"))
+					if err != nil {
+						return err
+					}
+					args = HoverArgs{PositionArgs: PositionArgs{File: file, Unit: unit}, Symbols: true}
+					if c.IsSet("range") {
+						if args.StartLine, args.EndLine, err = parseLineRange(c.String("range")); err != nil {
+							return err
+						}
+					}
+				} else {
+					position, err := positionArgs(c)
+					if err != nil {
+						return err
+					}
+					args = HoverArgs{PositionArgs: position}
+				}
+
+				var hovers []Hover
+				doRPC("Server.Hover", args, &hovers)
+				if format == "text" {
+					for i := range hovers {
+						hovers[i].Contents = markdownText(hovers[i].Contents)
+					}
+				}
+				if gJSON {
+					return printJSON(hovers)
+				}
+				if !args.Symbols {
+					for _, hover := range hovers {
+						fmt.Println(hover.Contents)
+					}
+					return nil
+				}
+				for _, hover := range hovers {
+					fmt.Println(hover)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "inline-values",
+			Usage:     "print the values a debugger should show inline",
+			UsageText: "lspc inline-values [--start-line <line>] [--frame-id <id>] [--unit byte|rune|utf-16] <file> <line> <col>",
+			Description: `For a debugger stopped at the 1-based <line> and <col> of <file>, asks the
+   language server which values to show next to the code from --start-line up
+   to the stopped line. Each value is printed as one of
+    <location> text <text to show>
+    <location> variable <name> [case-sensitive]
+    <location> expression <expression>
+   where an empty name or expression means the text at the location.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				cli.IntFlag{
+					Name:  "start-line",
+					Usage: "first line to show values for",
+					Value: 1,
+				},
+				cli.IntFlag{
+					Name:  "frame-id",
+					Usage: "id of the stack frame execution stopped in, as known to the debugger",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				position, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				args := InlineValuesArgs{PositionArgs: position, StartLine: c.Int("start-line"), FrameID: c.Int("frame-id")}
+				var values []InlineValue
+				doRPC("Server.InlineValues", args, &values)
+				if gJSON {
+					return printJSON(values)
+				}
+				for _, value := range values {
+					fmt.Println(value)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "linked-editing-ranges",
+			Usage:     "print the ranges edited together with a position",
+			UsageText: "lspc linked-editing-ranges [--unit byte|rune|utf-16] <file> <line> <col>",
+			Description: `Prints the ranges which have the same content as the one at the 1-based
+   <line> and <col> of <file> and should be edited along with it, ie, the
+   name of the closing tag when on an opening HTML tag, as
+   <file>:<line>:<col>-<end line>:<end col>. Prints nothing if the position
+   has no linked ranges.`,
+			Flags: []cli.Flag{unitFlag},
+			Action: func(c *cli.Context) error {
+				args, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				var ranges LinkedEditingRanges
+				doRPC("Server.LinkedEditingRanges", args, &ranges)
+				if gJSON {
+					return printJSON(ranges)
+				}
+				fmt.Print(ranges)
+				return nil
+			},
+		},
+		{
+			Name:      "moniker",
+			Usage:     "print the monikers of the symbol at a position",
+			UsageText: "lspc moniker [--unit byte|rune|utf-16] <file> <line> <col>",
+			Description: `Prints scheme:identifier of each moniker of the symbol at the 1-based <line>
+   and <col> of <file>, followed by how unique the identifier is and whether
+   the symbol is imported, exported or local. Monikers identify the symbol
+   across projects, ie, in LSIF or SCIP indexes.`,
+			Flags: []cli.Flag{unitFlag},
+			Action: func(c *cli.Context) error {
+				args, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				var monikers []LsMoniker
+				doRPC("Server.Moniker", args, &monikers)
+				if gJSON {
+					return printJSON(monikers)
+				}
+				for _, moniker := range monikers {
+					fmt.Println(moniker)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "complete",
+			Aliases:   []string{"completion"},
+			Usage:     "print completions at a position",
+			UsageText: "lspc complete [--unit byte|rune|utf-16] [--filter <text>] [--kind <kind>]... [--sort fuzzy|server] [--max <n>] [--resolve] [--icons] [--group <name>] <file> <line> <col>",
+			Description: `Prints the label, kind and detail of each completion the language server
+   offers at the 1-based <line> and <col> of <file>, separated by tabs.
+
+   Servers often return thousands of items and leave filtering to the
+   editor, so lspc does it: items must fuzzy match the identifier before
+   <col>, or --filter if given, ie, "gsv" matches getServerVersion. With
+   --sort fuzzy, the default, the best matches come first; with --sort server
+   items keep the order the server asked for with sortText. --kind keeps only
+   items of a kind, ie, function, method, variable or enum-member. Kinds are
+   printed as named by [kind.<kind>] in the config, with --icons after their
+   nerd font icon. --group merges the completions of every server of a
+   [group.<name>] in the config.
+
+   Servers often leave the detail and documentation out of long lists. With
+   --resolve every printed item is resolved with completionItem/resolve and
+   the first line of its documentation is printed after the detail.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				groupFlag,
+				cli.StringFlag{
+					Name:  "filter",
+					Usage: "text items must fuzzy match instead of the identifier before <col>; empty keeps every item",
+				},
+				cli.StringSliceFlag{
+					Name:  "kind",
+					Usage: "only print items of this kind; may be repeated or comma separated",
+				},
+				cli.StringFlag{
+					Name:  "sort",
+					Usage: "fuzzy (best match first) or server (by sortText)",
+					Value: sortByScore,
+				},
+				cli.IntFlag{
+					Name:  "max, limit",
+					Usage: "print at most this many items; 0 prints all",
+				},
+				cli.BoolFlag{
+					Name:  "resolve",
+					Usage: "resolve the printed items to print their documentation",
+				},
+				cli.BoolFlag{
+					Name:  "icons",
+					Usage: "print a nerd font icon before each kind",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				args, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				options := completeOptions{Sort: c.String("sort"), Max: c.Int("max")}
+				if options.Sort != sortByScore && options.Sort != sortByServer {
+					return fmt.Errorf("unknown --sort %q; expected fuzzy or server", options.Sort)
+				}
+				if options.Kinds, err = parseCompletionKinds(c.StringSlice("kind")); err != nil {
+					return err
+				}
+				config, err := loadConfig(gConfig)
+				if err != nil {
+					return err
+				}
+
+				var result CompleteResult
+				doRPC("Server.Complete", args, &result)
+				options.Filter = result.Prefix
+				if c.IsSet("filter") {
+					options.Filter = c.String("filter")
+				}
+				var resolve ResolveCompletionsArgs
+				for _, index := range rankCompletionIndices(result.Items, options) {
+					resolve.Items = append(resolve.Items, result.Items[index])
+					resolve.Servers = append(resolve.Servers, result.Servers[index])
+				}
+				items := resolve.Items
+				if c.Bool("resolve") && len(items) > 0 {
+					doRPC("Server.ResolveCompletions", resolve, &items)
+				}
+				if gJSON {
+					if items == nil {
+						items = []LsCompletionItem{}
+					}
+					return printJSON(LsCompletionList{IsIncomplete: result.IsIncomplete, Items: items})
+				}
+				w := newTable(os.Stdout)
+				for _, item := range items {
+					fmt.Fprintf(w, "%s\t%s\t%s", item.Label, config.kindLabel(item.Kind.String(), useIcons(c.Bool("icons"))), item.Detail)
+					if c.Bool("resolve") {
+						fmt.Fprintf(w, "\t%s", completionDocumentation(item))
+					}
+					fmt.Fprintln(w)
+				}
+				w.Flush()
+				if result.IsIncomplete {
+					notef("The list is incomplete; the server may return more items for a longer prefix")
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "kinds",
+			Usage:     "print how symbol and completion kinds are named",
+			UsageText: "lspc kinds [--icons]",
+			Description: `Prints each symbol and completion kind with the name lspc prints for it,
+   which [kind.<kind>] in the config may change, ie,
+
+     [kind.function]
+     name = "fn"
+     icon = "λ"`,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "icons",
+					Usage: "print the nerd font icon of each kind",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				config, err := loadConfig(gConfig)
+				if err != nil {
+					return err
+				}
+				if gJSON {
+					labels := make(map[string]string)
+					for _, name := range kindNames() {
+						labels[strings.Replace(name, " ", "-", -1)] = config.kindLabel(name, useIcons(c.Bool("icons")))
+					}
+					return printJSON(labels)
+				}
+				w := newTable(os.Stdout)
+				for _, name := range kindNames() {
+					fmt.Fprintf(w, "%s\t%s\n", strings.Replace(name, " ", "-", -1), config.kindLabel(name, useIcons(c.Bool("icons"))))
+				}
+				w.Flush()
+				return nil
+			},
+		},
+		{
+			Name:      "prepare-rename",
+			Usage:     "check whether the symbol at a position can be renamed",
+			UsageText: "lspc prepare-rename [--unit byte|rune|utf-16] <file> <line> <col>",
+			Description: `Asks the language server whether the symbol at the 1-based <line> and <col>
+   of <file> can be renamed. If so prints the range that would be renamed as
+   <file>:<line>:<col>-<end line>:<end col> followed by the suggested new
+   name, or just renameable if the server leaves that to the client.
+   Otherwise prints why not and exits with status 3.`,
+			Flags: []cli.Flag{unitFlag},
+			Action: func(c *cli.Context) error {
+				args, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				var result PrepareRename
+				doRPC("Server.PrepareRename", args, &result)
+				if gJSON {
+					if err := printJSON(result); err != nil {
+						return err
+					}
+					if !result.Renameable {
+						os.Exit(exitNotRenameable)
+					}
+					return nil
+				}
+				if !result.Renameable {
+					message := fmt.Sprintf("cannot rename at %s:%d:%d", args.File, args.Line, args.Column)
+					if result.Reason != "" {
+						message += ": " + result.Reason
+					}
+					fmt.Fprintln(os.Stderr, message)
+					os.Exit(exitNotRenameable)
+				}
+				fmt.Println(result)
+				return nil
+			},
+		},
+		{
+			Name:      "rename",
+			Usage:     "rename the symbol at a position",
+			UsageText: "lspc rename [--unit byte|rune|utf-16] [--dry-run | --apply] <file> <line> <col> <new-name>",
+			Description: `Asks the language server to rename the symbol at the 1-based <line> and
+   <col> of <file> to <new-name> and prints the resulting edit as a unified
+   diff of every file it changes. The files are left unchanged unless --apply
+   is given, which writes the edit to disk; it can then be reverted with
+   undo-last-edit. --dry-run, the default, only prints the edit.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "only print the edit",
+				},
+				cli.BoolFlag{
+					Name:  "apply",
+					Usage: "write the edit to disk",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 4 {
+					return fmt.Errorf("expected <file> <line> <col> <new-name>, got %d arguments", c.NArg())
+				}
+				if c.Bool("dry-run") && c.Bool("apply") {
+					return fmt.Errorf("--dry-run and --apply cannot be used together")
+				}
+				position, err := leadingPositionArgs(c)
+				if err != nil {
+					return err
+				}
+				args := RenameArgs{PositionArgs: position, NewName: c.Args().Get(3), Apply: c.Bool("apply")}
+				var preview EditPreview
+				doRPC("Server.Rename", args, &preview)
+				if gJSON {
+					return printJSON(preview)
+				}
+				writeEditPreview(os.Stdout, preview, useColor(os.Stdout))
+				return nil
+			},
+		},
+		{
+			Name:      "format",
+			Usage:     "format a file",
+			UsageText: "lspc format [--tab-size <n>] [--tabs] [--diff] <file>",
+			Description: `Asks the language server to format <file> and rewrites it with the result.
+   With --diff the file is left unchanged and the changes are printed as a
+   unified diff instead. Formatting can be reverted with undo-last-edit.`,
+			Flags: formattingFlags,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return fmt.Errorf("expected <file>, got %d arguments", c.NArg())
+				}
+				return runFormat(c, FormatArgs{})
+			},
+		},
+		{
+			Name:      "range-format",
+			Usage:     "format some lines of a file",
+			UsageText: "lspc range-format [--tab-size <n>] [--tabs] [--diff] <file> <start>..<end>",
+			Description: `As format, but only formats the 1-based lines <start> to <end> of <file>.
+   Either end may be left out to format from the first or to the last line.`,
+			Flags: formattingFlags,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 {
+					return fmt.Errorf("expected <file> <start>..<end>, got %d arguments", c.NArg())
+				}
+				start, end, err := parseLineRange(c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				return runFormat(c, FormatArgs{Range: true, StartLine: start, EndLine: end})
+			},
+		},
+		{
+			Name:      "code-action",
+			Usage:     "list or apply the code actions at a position",
+			UsageText: "lspc code-action [--unit byte|rune|utf-16] [--only <kind>] [--group <name>] [--apply <n>] [--yes] <file> <line> <col>",
+			Description: `Lists the code actions the language server offers at the 1-based <line>
+   and <col> of <file>, numbered from 1. --only limits them to a kind, ie,
+   quickfix or refactor.extract, and may be repeated. With --group the actions
+   of every server of a [group.<name>] in the config are listed, each labeled
+   with its server.
+
+   --apply <n> applies the n-th action. Every edit it makes, including those
+   the server requests while running the command of the action, is first
+   shown as a unified diff. When stdin is a terminal lspc then asks whether to
+   apply it; otherwise edits are only applied with --yes. Rejecting an edit of
+   an action skips its command. Applied edits can be reverted with
+   undo-last-edit.`,
+			Flags: []cli.Flag{
+				unitFlag,
+				groupFlag,
+				cli.StringSliceFlag{
+					Name:  "only",
+					Usage: "only list actions of this kind",
+				},
+				cli.IntFlag{
+					Name:  "apply",
+					Usage: "apply the n-th code action",
+				},
+				cli.BoolFlag{
+					Name:  "yes",
+					Usage: "apply edits without asking for confirmation",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				position, err := positionArgs(c)
+				if err != nil {
+					return err
+				}
+				args := CodeActionArgs{PositionArgs: position, Kinds: c.StringSlice("only")}
+				if !c.IsSet("apply") {
+					var actions []CodeAction
+					doRPC("Server.CodeActions", args, &actions)
+					if gJSON {
+						return printJSON(actions)
+					}
+					for i, action := range actions {
+						fmt.Printf("%d: %s\n", i+1, action)
+					}
+					return nil
+				}
+
+				// Prompts would mix with the JSON.
+				interactive := isTerminal(os.Stdin) && !gJSON
+				color := useColor(os.Stdout)
+				var step EditStep
+				doRPC("Server.ApplyCodeAction", ApplyCodeActionArgs{CodeActionArgs: args, Index: c.Int("apply")}, &step)
+				for step.Edit != nil {
+					if gJSON {
+						if err := printJSON(step.Edit); err != nil {
+							return err
+						}
+					} else {
+						writeEditPreview(os.Stdout, *step.Edit, color)
+					}
+					apply := c.Bool("yes")
+					if !apply && interactive {
+						apply = confirmEdit(os.Stdin, os.Stdout)
+					} else if !apply {
+						fmt.Fprintln(os.Stderr, "Not applied since stdin is not a terminal; pass --yes to apply edits without confirmation")
+					}
+					session := step.Session
+					// Fields missing from the reply would keep their value.
+					step = EditStep{}
+					doRPC("Server.ConfirmEdit", ConfirmEditArgs{Session: session, Apply: apply}, &step)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "undo-last-edit",
+			Usage:     "revert the most recent edit lspc applied",
+			UsageText: "lspc undo-last-edit [--yes]",
+			Description: `Reverts the most recent edit lspc applied to files, ie, with
+   code-action --apply, which is recorded in the --edit-journal. The reverting
+   changes are shown as a unified diff first and, as for code-action, only
+   applied once confirmed or with --yes. Fails without changing anything if
+   a file was modified since the edit. Repeat to undo earlier edits.`,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "yes",
+					Usage: "undo without asking for confirmation",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				var preview EditPreview
+				doRPC("Server.UndoLastEdit", UndoArgs{DryRun: true}, &preview)
+				if gJSON {
+					if err := printJSON(preview); err != nil {
+						return err
+					}
+				} else {
+					writeEditPreview(os.Stdout, preview, useColor(os.Stdout))
+				}
+				apply := c.Bool("yes")
+				if !apply && isTerminal(os.Stdin) && !gJSON {
+					apply = confirmEdit(os.Stdin, os.Stdout)
+				} else if !apply {
+					fmt.Fprintln(os.Stderr, "Not undone since stdin is not a terminal; pass --yes to undo without confirmation")
+				}
+				if apply {
+					doRPC("Server.UndoLastEdit", UndoArgs{}, &preview)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "start",
+			Usage:     "start a new language server",
+			UsageText: "lspc start [--language <name>] [--instances <n>] <bin> <project-dir> [<init>]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "language",
+					Usage: "name of the language in the config file whose settings apply",
+				},
+				cli.IntFlag{
+					Name:  "instances",
+					Usage: "number of instances of the server to run; requests for <project-dir> are spread over them round-robin",
+					Value: 1,
+				},
+				cli.StringFlag{
+					Name:  "framing",
+					Usage: "how the server delimits messages: lsp (Content-Length headers) or jsonl (one JSON message per line); defaults to the framing of the language in the config file, or lsp",
+				},
+				cli.BoolFlag{
+					Name:  "force-new",
+					Usage: "start a new server even if one with the same command, directory and init options is running",
+				},
+			},
+			Description: `<bin> can be a quoted string which will be parsed as shell words, ie,
+   "cquery --log-file log.txt" will run cquery with the arguments [--log-file, log.txt]
+
+   <project-dir> is the directory containing the content that the language server will
+   analyze
+
+   <init> can be a raw json literal passed to the language server in the initialization
+	 message, ex, '{"cacheDirectory": "/ssd/cquery_cache/"}'. Defaults to {}
+
+   <init> is merged over the init_options from the config file: first the
+   global init_options, then those of the language (selected with --language
+   or detected from <bin>), then those in <project-dir>/.lspc.toml. Nested
+   objects are merged; other values are replaced.
+
+   Strings in init options may use ${workspaceRoot} for <project-dir>,
+   ${userHome} and ${env:NAME}, ie, '{"cacheDirectory": "${userHome}/.cache"}'.
+
+   gopls is started at the root of the go.work workspace <project-dir> is in,
+   if any, so that it can navigate across modules; go.work workspaces inside
+   of <project-dir> are sent as its workspace folders.
+
+   Likewise JavaScript and TypeScript servers are started at the root of the
+   monorepo <project-dir> is in, found by pnpm-workspace.yaml, turbo.json or
+   the workspaces of package.json, with its packages as workspace folders.
+
+   --instances runs several copies of the server, which speeds up batches of
+   independent requests, ie, crawling hover or documentSymbol for every file,
+   on machines with many cores. Each instance indexes the project separately.
+
+   Prints the id of each instance. If servers with the same command,
+   <project-dir> and merged init options are already running their ids are
+   printed instead and nothing is started, unless --force-new is given.
+
+   Only one daemon may run a command in <project-dir>; starting it from a
+   daemon with a different --socket fails and names the daemon that owns it.
+   The locks are kept in $TMPDIR/lspc.$USER.locks.
+
+   Example:
+    $ lspc start "cquery --log-all-to-stderr" /work/chrome '{"cacheDirectory": "/ssd/cquery_cache"}'`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 && c.NArg() != 3 {
+					return cli.ShowCommandHelp(c, "start")
+				}
+
+				init := c.Args().Get(2)
+				if init == "" {
+					init = "{}"
+				}
+				// The daemon may be running in a different directory.
+				directory, err := filepath.Abs(c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				args := StartArgs{
+					Bin:       c.Args().Get(0),
+					Directory: directory,
+					InitOpts:  easyjson.RawMessage(init),
+					Language:  c.String("language"),
+					Instances: c.Int("instances"),
+					Framing:   c.String("framing"),
+					ForceNew:  c.Bool("force-new"),
+				}
+				if _, err := jsonrpc.ParseFraming(args.Framing); err != nil {
+					return err
+				}
+
+				var ids []int
+				doRPC("Server.Start", args, &ids)
+				if gJSON {
+					return printJSON(ids)
+				}
+				for _, id := range ids {
+					fmt.Println(id)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "raw-io",
+			Usage:     "exchange raw protocol messages with a running language server",
+			UsageText: "lspc raw-io <id>",
+			Description: `Connects stdin and stdout to the protocol stream of the language server
+   with the given id, as listed by lspc ls, for hand-crafting requests while
+   debugging. Write one JSON-RPC request or notification per line, ie,
+
+    {"jsonrpc": "2.0", "id": 1, "method": "workspace/symbol", "params": {"query": "foo"}}
+
+   Responses are printed one per line with the id that was sent, along with
+   every request and notification from the server while connected. lspc
+   handles the framing and keeps answering requests from the server itself.
+   The server is already initialized; initialize, shutdown and exit are
+   rejected. Documents opened this way are not known to lspc. End the session
+   with EOF (ctrl-d).`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "raw-io")
+				}
+				id, err := strconv.Atoi(c.Args().Get(0))
+				if err != nil {
+					return fmt.Errorf("<id> must be a server id, got %q", c.Args().Get(0))
+				}
+				if err := checkLocalDaemon("raw-io"); err != nil {
+					return err
+				}
+				var socket string
+				doRPC("Server.RawIO", id, &socket)
+				return rawIO(socket, os.Stdin, os.Stdout)
+			},
+		},
+		{
+			Name:      "events",
+			Usage:     "print a stream of daemon events as JSON lines",
+			UsageText: "lspc events [--server <id>]...",
+			Description: `Prints one JSON object per line for everything that happens to the
+   running language servers until interrupted, for status bars and monitors.
+   Kind is one of started, stopped, diagnostics, progress, message and
+   applyEdit; Server describes the language server. Events are dropped if the
+   reader does not keep up, which is reported in Dropped of the next event.`,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "server",
+					Usage: "only print events about the language server with this id",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return events(c.StringSlice("server"), os.Stdout)
+			},
+		},
+		{
+			Name:      "swap",
+			Usage:     "replace a running language server with a different binary",
+			UsageText: "lspc swap [--timeout <seconds>] <id> <bin>",
+			Description: `Starts <bin> in the project of the language server with the given id, as
+   listed by lspc ls, with the init options the old server was started with.
+   Once it has initialized, every document open in the old server is opened
+   in it with the same text and version, requests are routed to it instead
+   and the old server is shut down. Useful to compare two versions of a
+   server, ie,
+
+    $ lspc swap 0 "clangd-18 --background-index"
+
+   Swapping back works the same way. If <bin> fails to initialize within
+   --timeout it is stopped and the old server keeps running.`,
+			Flags: []cli.Flag{
+				cli.Float64Flag{
+					Name:  "timeout",
+					Usage: "seconds to wait for the new server to initialize",
+					Value: 60,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 {
+					return cli.ShowCommandHelp(c, "swap")
+				}
+				id, err := strconv.Atoi(c.Args().Get(0))
+				if err != nil {
+					return fmt.Errorf("<id> must be a server id, got %q", c.Args().Get(0))
+				}
+				args := SwapArgs{
+					ID:      id,
+					Bin:     c.Args().Get(1),
+					Timeout: time.Duration(c.Float64("timeout") * float64(time.Second)),
+				}
+				var result SwapResult
+				doRPC("Server.Swap", args, &result)
+				if gJSON {
+					return printJSON(result)
+				}
+				fmt.Printf("Replaced %s\n    with %s\n", result.Old, result.New)
+				fmt.Printf("Opened %d document(s)\n", result.Documents)
+				return nil
+			},
+		},
+		{
+			Name:      "restart",
+			Usage:     "restart a language server",
+			UsageText: "lspc restart [--timeout <seconds>] <id | pid | project-dir | name>",
+			Description: `Starts the language server with the id lspc ls lists, with the given pid,
+   running in <project-dir> or with the given language or program name again
+   with the command, directory and init options it was started with. Config
+   changes since then apply. Once the new server has initialized it is
+   given every document open in the old one and answers requests instead,
+   and the old server is shut down.
+
+   If the new server fails to initialize within --timeout it is stopped and
+   the old server keeps running.`,
+			Flags: []cli.Flag{
+				cli.Float64Flag{
+					Name:  "timeout",
+					Usage: "seconds to wait for the new server to initialize",
+					Value: 60,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "restart")
+				}
+				target, err := serverTarget(c.Args().First())
+				if err != nil {
+					return err
+				}
+				args := RestartArgs{Target: target, Timeout: time.Duration(c.Float64("timeout") * float64(time.Second))}
+				var result SwapResult
+				doRPC("Server.Restart", args, &result)
+				if gJSON {
+					return printJSON(result)
+				}
+				fmt.Printf("Restarted %s\n       as %s\n", result.Old, result.New)
+				fmt.Printf("Opened %d document(s)\n", result.Documents)
+				return nil
+			},
+		},
+		{
+			Name:      "up",
+			Usage:     "start every language server of a project",
+			UsageText: "lspc up [--settle <seconds>] [--timeout <seconds>] [<project-dir>]",
+			Description: `Starts the language servers listed in <project-dir>/.lspc.toml, which
+   defaults to the current directory, that are not already running, waits
+   until they are ready and prints a summary. A server is ready once it has
+   initialized and no $/progress token has been active for --settle seconds.
+   Servers are listed as [[server]] entries, ie,
+
+    [[server]]
+    language = "cpp"
+
+    [[server]]
+    command = "pyright-langserver --stdio"
+    path = "tools"
+
+   path is relative to <project-dir>, which is the default. The servers of
+   [[root]] entries are started too. Exits with status 1 if a server did not
+   become ready within --timeout.`,
+			Flags: []cli.Flag{
+				cli.Float64Flag{
+					Name:  "settle",
+					Usage: "seconds without progress after which a server counts as ready",
+					Value: 1,
+				},
+				cli.Float64Flag{
+					Name:  "timeout",
+					Usage: "seconds to wait for the servers to become ready",
+					Value: 300,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() > 1 {
+					return cli.ShowCommandHelp(c, "up")
+				}
+				directory, err := filepath.Abs(c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				settle := time.Duration(c.Float64("settle") * float64(time.Second))
+				timeout := time.Duration(c.Float64("timeout") * float64(time.Second))
+				if !up(os.Stdout, "Server.Up", directory, settle, timeout) {
+					os.Exit(1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "start-auto",
+			Usage:     "start the auto-start language servers for the files of a directory",
+			UsageText: "lspc start-auto [--settle <seconds>] [--timeout <seconds>] [<dir>]",
+			Description: `Starts the language servers of the [[autostart]] rules of the config file
+   that apply to the files in <dir>, which defaults to the current directory,
+   as if each file had been queried, then waits until they are ready and
+   prints a summary like lspc up. Hidden directories are skipped. Servers
+   already running in their project directory are not started again. Exits
+   with status 1 if a server did not become ready within --timeout.`,
+			Flags: []cli.Flag{
+				cli.Float64Flag{
+					Name:  "settle",
+					Usage: "seconds without progress after which a server counts as ready",
+					Value: 1,
+				},
+				cli.Float64Flag{
+					Name:  "timeout",
+					Usage: "seconds to wait for the servers to become ready",
+					Value: 300,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() > 1 {
+					return cli.ShowCommandHelp(c, "start-auto")
+				}
+				directory, err := filepath.Abs(c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				settle := time.Duration(c.Float64("settle") * float64(time.Second))
+				timeout := time.Duration(c.Float64("timeout") * float64(time.Second))
+				if !up(os.Stdout, "Server.StartAuto", directory, settle, timeout) {
+					os.Exit(1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "down",
+			Usage:     "stop every language server of a project",
+			UsageText: "lspc down [<project-dir>]",
+			Description: `Gracefully stops the language servers running in <project-dir>, which
+   defaults to the current directory, or in a subdirectory of it, ie, those
+   started by lspc up. Servers of other projects keep running.`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() > 1 {
+					return cli.ShowCommandHelp(c, "down")
+				}
+				directory, err := filepath.Abs(c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				var stopped []DownServer
+				doRPC("Server.Down", directory, &stopped)
+				if gJSON {
+					return printJSON(stopped)
+				}
+				if len(stopped) == 0 {
+					fmt.Printf("No language servers are running in %s\n", directory)
+				}
+				for _, server := range stopped {
+					fmt.Printf("Stopped %s (%s)\n", server.Info, server.ExitStatus)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "stop",
+			Usage:     "stop one language server",
+			UsageText: "lspc stop <id | pid | project-dir | name>",
+			Description: `Stops the language server with the id lspc ls lists, with the given pid,
+   running in <project-dir> or with the given language or program name, which
+   must then be the only such server. The
+   server is sent the shutdown request and exit notification of the protocol
+   so that it can save its caches, and is killed if it does not exit in
+   time. The other servers and the daemon keep running.`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "stop")
+				}
+				target, err := serverTarget(c.Args().First())
+				if err != nil {
+					return err
+				}
+				var stopped DownServer
+				doRPC("Server.Stop", target, &stopped)
+				if gJSON {
+					return printJSON(stopped)
+				}
+				fmt.Printf("Stopped %s (%s)\n", stopped.Info, stopped.ExitStatus)
+				return nil
+			},
+		},
+		{
+			Name:      "capabilities",
+			Usage:     "print or compare the capabilities of language servers",
+			UsageText: "lspc capabilities [<id|pid|project-dir|name>]",
+			Description: `Prints which requests the language server supports according to the
+   capabilities it returned from initialize, or those of every running server
+   without an argument. lspc fails requests a server does not support without
+   sending them.`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() > 1 {
+					return cli.ShowCommandHelp(c, "capabilities")
+				}
+				target := ""
+				if c.NArg() == 1 {
+					var err error
+					if target, err = serverTarget(c.Args().First()); err != nil {
+						return err
+					}
+				}
+				var servers []ServerFeatures
+				doRPC("Server.Features", target, &servers)
+				if gJSON {
+					return printJSON(servers)
+				}
+				if len(servers) == 0 {
+					notef("No language servers are running")
+					return nil
+				}
+				printFeatures(os.Stdout, servers)
+				return nil
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:      "show",
+					Usage:     "print the initialize result of a language server",
+					UsageText: "lspc capabilities show <server>",
+					Description: `Prints the capabilities and server info the language server with id
+   <server> returned from initialize. Save the output to compare against later
+   with capabilities diff.`,
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							return cli.ShowCommandHelp(c, "show")
+						}
+						return showCapabilities(c.Args().First())
+					},
+				},
+				{
+					Name:      "diff",
+					Usage:     "compare the capabilities of two language servers",
+					UsageText: "lspc capabilities diff <a> <b>",
+					Description: `<a> and <b> are ids of running language servers or files saved from
+   capabilities show. Prints capabilities only in <a> prefixed with -, only in
+   <b> with + and changed values with ~.
+
+   Example:
+    $ lspc capabilities show 0 > clangd-16.json
+    $ lspc capabilities diff clangd-16.json 1`,
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 2 {
+							return cli.ShowCommandHelp(c, "diff")
+						}
+						return diffCapabilities(c.Args().Get(0), c.Args().Get(1))
+					},
+				},
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "check or print the configuration",
+			Subcommands: []cli.Command{
+				{
+					Name:      "validate",
+					Aliases:   []string{"check"},
+					Usage:     "check the config file for errors",
+					UsageText: "lspc config validate [<project-dir>]",
+					Description: `Reports syntax errors, unknown keys and init_options or settings that are
+   not JSON objects. If <project-dir> is given its .lspc.toml is checked too.`,
+					Action: func(c *cli.Context) error {
+						problems := validateConfig(gConfig, c.Args().First())
+						if gJSON {
+							if err := printJSON(problems); err != nil {
+								return err
+							}
+						} else {
+							for _, problem := range problems {
+								fmt.Println(problem)
+							}
+						}
+						if len(problems) > 0 {
+							os.Exit(1)
+						}
+						return nil
+					},
+				},
+				{
+					Name:      "show",
+					Usage:     "print the effective configuration for a project",
+					UsageText: "lspc config show [--show-secrets] [<project-dir>]",
+					Description: `Prints the config as it applies to language servers started in <project-dir>,
+   which defaults to the current directory. The init_options of every language
+   are merged with the global init_options and those in <project-dir>/.lspc.toml.
+   Init options given to lspc start are merged over these.
+
+   Values of secret keys in init_options and settings, ie, token or apiKey and
+   those matching the redact setting, are printed as <redacted> unless
+   --show-secrets is given.`,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "show-secrets",
+							Usage: "print secret values instead of <redacted>",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						directory, err := filepath.Abs(c.Args().First())
+						if err != nil {
+							return err
+						}
+						config, err := loadConfig(gConfig)
+						if err != nil {
+							return err
+						}
+						effective, err := config.effectiveConfig(directory)
+						if err != nil {
+							return err
+						}
+						if effective.MaxServers == nil {
+							effective.MaxServers = &gMaxServers
+						}
+						if !c.Bool("show-secrets") {
+							effective.redactSecrets(config.redactor())
+						}
+						if gJSON {
+							return printTOMLAsJSON(effective)
+						}
+						return toml.NewEncoder(os.Stdout).Encode(effective)
+					},
+				},
+			},
+		},
+		{
+			Name:      "install",
+			Usage:     "install a language server",
+			UsageText: "lspc install [--version <version>] [--force] [<server>]",
+			Description: `Without arguments lists the language servers lspc can install. Otherwise
+   installs <server> into --install-dir, using the version lspc is pinned to
+   unless --version is given. Some servers are installed with go or npm,
+   which must be available.
+
+   Installed servers are used for their language unless the config sets
+   language.<name>.command; lspc config show prints the result.
+
+   Example:
+    $ lspc install clangd
+    $ lspc start clangd ~/src/project`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "version",
+					Usage: "version to install instead of the pinned one; its download is not verified",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "reinstall even if the version is already installed",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					return listInstallable(gInstallDir)
+				}
+				return installServer(gInstallDir, c.Args().First(), c.String("version"), c.Bool("force"))
+			},
+		},
+		{
+			Name:      "env",
+			Usage:     "print the lspc environment for the current directory",
+			UsageText: "lspc env",
+			Description: `Prints LSPC_SOCKET and, if a running language server covers the current
+   directory, LSPC_SERVER_ID, LSPC_SERVER_DIR and LSPC_SERVER_CMD. The output
+   can be evaluated by a shell, ie, eval "$(lspc env)"
+
+   LSPC_SOCKET_SOURCE tells what selected the socket: --socket, LSPC_SOCKET,
+   --profile, the workspace with --per-workspace, or the default.`,
+			Action: func(c *cli.Context) error {
+				return printEnv()
+			},
+		},
+		{
+			Name:            "exec",
+			Usage:           "run a command with the lspc environment exported",
+			UsageText:       "lspc exec -- <cmd> [<args>...]",
+			Description:     "Runs <cmd> with the variables printed by env exported.",
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				return execWithEnv(c.Args())
+			},
+		},
+		{
+			Name:      "shell-completion",
+			Usage:     "print a shell completion script",
+			UsageText: "lspc shell-completion <bash|zsh|fish>",
+			Description: `Prints a completion script for the given shell. Completions for server ids
+   are fetched from the daemon if it is running.
+
+   Example:
+    $ source <(lspc shell-completion bash)
+    $ lspc shell-completion fish > ~/.config/fish/completions/lspc.fish`,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "shell-completion")
+				}
+				script, has := completionScripts[c.Args().Get(0)]
+				if !has {
+					return fmt.Errorf("unsupported shell %q", c.Args().Get(0))
+				}
+				fmt.Print(script)
+				return nil
+			},
+		},
+		{
+			Name:            completeCommandName,
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				for _, candidate := range complete(c.App, c.Args()) {
+					fmt.Println(candidate)
+				}
+				return nil
+			},
+		},
+		{
+			Name:            closeFilesCommandName,
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				return execClosingFiles(c.Args())
+			},
+		},
+		{
+			Name:        "daemon",
+			Usage:       "run the lspc daemon",
+			UsageText:   "lspc daemon",
+			Description: "Run the lspc daemon. In typical operation lspc will start the daemon for you.",
+			Action: func(c *cli.Context) error {
+				daemonMainLoop()
+				return nil
+			},
+		},
+	}
+
+	app.Before = func(c *cli.Context) error {
+		if err := setVerbosity(gQuiet, gVerbose, gDebug); err != nil {
+			return err
+		}
+		if err := initSocket(); err != nil {
+			return err
+		}
+		if err := setCodec(gCodecName); err != nil {
+			return err
+		}
+		return setChaos(gChaosFraction, time.Duration(gChaosDelay)*time.Millisecond, int64(gChaosSeed))
+	}
+
+	err := app.Run(os.Args)
+	if err != nil && gJSON {
+		printJSONError(err)
+		os.Exit(1)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,45 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchingServers(t *testing.T) {
+	server := func(id int, bin, directory, initOpts string) *languageServer {
+		return &languageServer{id: id, directory: directory, startArgs: StartArgs{Bin: bin, Directory: directory}, initOpts: easyjson.RawMessage(initOpts)}
+	}
+	a := server(0, "clangd", "/nonexistent/src", `{}`)
+	b := server(1, "clangd", "/nonexistent/src", `{}`)
+	other := server(2, "clangd", "/nonexistent/src", `{"cache":"/ssd"}`)
+	elsewhere := server(3, "clangd", "/nonexistent/other", `{}`)
+	s := &Server{config: &Config{}, servers: []*languageServer{a, b, other, elsewhere}}
+
+	matching, err := s.matchingServers(StartArgs{Bin: "clangd", Directory: "/nonexistent/src/", InitOpts: easyjson.RawMessage(`{}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, []*languageServer{a, b}, matching)
+
+	matching, err = s.matchingServers(StartArgs{Bin: "clangd", Directory: "/nonexistent/src", InitOpts: easyjson.RawMessage(`{"cache": "/ssd"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, []*languageServer{other}, matching)
+
+	matching, err = s.matchingServers(StartArgs{Bin: "ccls", Directory: "/nonexistent/src"})
+	assert.NoError(t, err)
+	assert.Empty(t, matching)
+}
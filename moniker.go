@@ -0,0 +1,69 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+)
+
+func (m LsMoniker) String() string {
+	s := fmt.Sprintf("%s:%s (%s", m.Scheme, m.Identifier, m.Unique)
+	if m.Kind != "" {
+		s += ", " + m.Kind
+	}
+	return s + ")"
+}
+
+// monikers returns the monikers of the symbol at the 1-based line and column
+// of path.
+func (l *languageServer) monikers(path string, line, column int, unit ColumnUnit) ([]LsMoniker, error) {
+	params, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return nil, err
+	}
+	result, err := l.call("textDocument/moniker", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	var monikers []LsMoniker
+	if err := fromJSON(result, &monikers); err != nil {
+		return nil, fmt.Errorf("cannot parse monikers: %s", err.Error())
+	}
+	if monikers == nil {
+		monikers = []LsMoniker{}
+	}
+	return monikers, nil
+}
+
+// Moniker returns the monikers of the symbol at a position.
+func (s *Server) Moniker(args PositionArgs, monikers *[]LsMoniker) error {
+	log.Printf("CMD moniker %s:%d:%d", args.File, args.Line, args.Column)
+	server, err := s.serverForPosition(args, "textDocument/moniker")
+	if err != nil {
+		return err
+	}
+	*monikers, err = server.monikers(args.File, args.Line, args.Column, args.Unit)
+	return err
+}
+
+func (s *Server) httpMoniker(query url.Values) (interface{}, error) {
+	position, server, err := s.queryPosition(query, "textDocument/moniker")
+	if err != nil {
+		return nil, err
+	}
+	return server.monikers(position.File, position.Line, position.Column, position.Unit)
+}
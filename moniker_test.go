@@ -0,0 +1,26 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonikerString(t *testing.T) {
+	assert.Equal(t, "tsc:lib/a:Foo (project, export)", LsMoniker{Scheme: "tsc", Identifier: "lib/a:Foo", Unique: "project", Kind: "export"}.String())
+	assert.Equal(t, "npm:pkg::Foo (global)", LsMoniker{Scheme: "npm", Identifier: "pkg::Foo", Unique: "global"}.String())
+}
@@ -15,6 +15,8 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/mailru/easyjson"
 )
 
@@ -70,6 +72,62 @@ const (
 	TypeParameter LsSymbolKind = 26
 )
 
+var symbolKindNames = []string{
+	"unknown", "file", "module", "namespace", "package", "class", "method",
+	"property", "field", "constructor", "enum", "interface", "function",
+	"variable", "constant", "string", "number", "boolean", "array", "object",
+	"key", "null", "enum member", "struct", "event", "operator",
+	"type parameter",
+}
+
+func (k LsSymbolKind) String() string {
+	if k < 0 || int(k) >= len(symbolKindNames) {
+		return "unknown"
+	}
+	return symbolKindNames[k]
+}
+
+// LsCompletionItemKind is the kind of a completion item.
+type LsCompletionItemKind int
+
+var completionItemKindNames = []string{
+	"unknown", "text", "method", "function", "constructor", "field",
+	"variable", "class", "interface", "module", "property", "unit", "value",
+	"enum", "keyword", "snippet", "color", "file", "reference", "folder",
+	"enum member", "constant", "struct", "event", "operator",
+	"type parameter",
+}
+
+func (k LsCompletionItemKind) String() string {
+	if k < 0 || int(k) >= len(completionItemKindNames) {
+		return "unknown"
+	}
+	return completionItemKindNames[k]
+}
+
+// LsCompletionItem is one suggestion of textDocument/completion.
+type LsCompletionItem struct {
+	Label      string               `json:"label"`
+	Kind       LsCompletionItemKind `json:"kind,omitempty"`
+	Detail     string               `json:"detail,omitempty"`
+	SortText   string               `json:"sortText,omitempty"`
+	FilterText string               `json:"filterText,omitempty"`
+	InsertText string               `json:"insertText,omitempty"`
+	// string | MarkupContent, usually only filled in by
+	// completionItem/resolve.
+	Documentation easyjson.RawMessage `json:"documentation,omitempty"`
+	// Kept by the server between textDocument/completion and
+	// completionItem/resolve.
+	Data easyjson.RawMessage `json:"data,omitempty"`
+}
+
+// LsCompletionList is the result of textDocument/completion, unless the
+// server returned just the items.
+type LsCompletionList struct {
+	IsIncomplete bool               `json:"isIncomplete"`
+	Items        []LsCompletionItem `json:"items"`
+}
+
 type LsTextDocumentIdentifier struct {
 	URI LsDocumentURI `json:"uri"`
 }
@@ -87,6 +145,47 @@ type LsTextDocumentPositionParams struct {
 	Position LsPosition `json:"position"`
 }
 
+// LsReferenceParams are the parameters of textDocument/references.
+type LsReferenceParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	Position     LsPosition               `json:"position"`
+	Context      LsReferenceContext       `json:"context"`
+}
+
+type LsReferenceContext struct {
+	// Include the declaration of the symbol.
+	IncludeDeclaration bool `json:"includeDeclaration"`
+}
+
+// LsRenameParams are the parameters of textDocument/rename.
+type LsRenameParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	Position     LsPosition               `json:"position"`
+	NewName      string                   `json:"newName"`
+}
+
+// LsFormattingOptions describes how the document should be formatted.
+type LsFormattingOptions struct {
+	// Size of a tab in spaces.
+	TabSize int `json:"tabSize"`
+	// Prefer spaces over tabs.
+	InsertSpaces bool `json:"insertSpaces"`
+}
+
+// LsDocumentFormattingParams are the parameters of textDocument/formatting.
+type LsDocumentFormattingParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	Options      LsFormattingOptions      `json:"options"`
+}
+
+// LsDocumentRangeFormattingParams are the parameters of
+// textDocument/rangeFormatting.
+type LsDocumentRangeFormattingParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	Range        LsRange                  `json:"range"`
+	Options      LsFormattingOptions      `json:"options"`
+}
+
 type LsTextEdit struct {
 	// The range of the text document to be manipulated. To insert
 	// text into a document create a range where start === end.
@@ -112,13 +211,82 @@ type LsTextDocumentItem struct {
 	Text string `json:"text"`
 }
 
+type LsDidOpenTextDocumentParams struct {
+	// The document that was opened.
+	TextDocument LsTextDocumentItem `json:"textDocument"`
+}
+
+type LsDidCloseTextDocumentParams struct {
+	// The document that was closed.
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+}
+
+// LsTextDocumentContentChangeEvent replaces the whole text of a document;
+// lspc does not send incremental changes.
+type LsTextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type LsDidChangeTextDocumentParams struct {
+	TextDocument   LsVersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []LsTextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// LsLocationLink is returned instead of LsLocation by servers that support
+// it. Since 3.14.0
+type LsLocationLink struct {
+	// Span of the origin of this link.
+	OriginSelectionRange *LsRange `json:"originSelectionRange,omitempty"`
+
+	// The target resource identifier of this link.
+	TargetURI LsDocumentURI `json:"targetUri"`
+
+	// The full target range of this link, ie, the body of a function.
+	TargetRange LsRange `json:"targetRange"`
+
+	// The range that should be selected and revealed when this link is
+	// being followed, ie, the name of a function.
+	TargetSelectionRange LsRange `json:"targetSelectionRange"`
+}
+
+type LsDocumentSymbolParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+}
+
+// LsDocumentSymbol is returned by textDocument/documentSymbol if the client
+// supports hierarchical symbols.
+type LsDocumentSymbol struct {
+	Name   string       `json:"name"`
+	Detail string       `json:"detail,omitempty"`
+	Kind   LsSymbolKind `json:"kind"`
+	// The range enclosing the symbol, ie, including its body.
+	Range LsRange `json:"range"`
+	// The range of the name of the symbol.
+	SelectionRange LsRange            `json:"selectionRange"`
+	Children       []LsDocumentSymbol `json:"children,omitempty"`
+}
+
+type LsWorkspaceSymbolParams struct {
+	// An empty query asks for all symbols, which not every server supports.
+	Query string `json:"query"`
+}
+
+// LsSymbolInformation is the flat form of a symbol, returned by
+// workspace/symbol and by textDocument/documentSymbol for older clients.
+type LsSymbolInformation struct {
+	Name          string       `json:"name"`
+	Kind          LsSymbolKind `json:"kind"`
+	Location      LsLocation   `json:"location"`
+	ContainerName string       `json:"containerName,omitempty"`
+}
+
 type LsInitializeParams struct {
 	/**
 	 * The process Id of the parent process that started
 	 * the server. Is null if the process has not been started by another process.
 	 * If the parent process is not alive then the server should exit (see exit notification) its process.
 	 */
-	// processId number | null;
+	ProcessID *int `json:"processId"`
 
 	/**
 	 * The rootPath of the workspace. Is null
@@ -143,7 +311,7 @@ type LsInitializeParams struct {
 	/**
 	 * The capabilities provided by the client (editor or tool)
 	 */
-	// capabilities: ClientCapabilities;
+	Capabilities LsClientCapabilities `json:"capabilities"`
 
 	/**
 	 * The initial trace setting. If omitted trace is disabled ('off').
@@ -158,7 +326,444 @@ type LsInitializeParams struct {
 	 *
 	 * Since 3.6.0
 	 */
-	// workspaceFolders?: WorkspaceFolder[] | null;
+	WorkspaceFolders []LsWorkspaceFolder `json:"workspaceFolders,omitempty"`
+}
+
+type LsWorkspaceFolder struct {
+	URI  LsDocumentURI `json:"uri"`
+	Name string        `json:"name"`
+}
+
+type LsServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type LsInitializeResult struct {
+	// The capabilities the language server provides. Kept raw so that servers
+	// can be compared on capabilities lspc does not know about.
+	Capabilities easyjson.RawMessage `json:"capabilities"`
+
+	// Information about the server. Since 3.15.0
+	ServerInfo *LsServerInfo `json:"serverInfo,omitempty"`
+}
+
+type LsWindowClientCapabilities struct {
+	// Whether the client supports server initiated progress using the
+	// window/workDoneProgress/create request.
+	WorkDoneProgress bool `json:"workDoneProgress"`
+}
+
+type LsHoverClientCapabilities struct {
+	// Content formats supported for the hover contents, in order of
+	// preference. Omitted for servers that only know MarkedString.
+	ContentFormat []LsMarkupKind `json:"contentFormat,omitempty"`
+}
+
+type LsSymbolKindClientCapabilities struct {
+	// Symbol kinds the client can show. Without it servers only use the
+	// kinds up to Array of the initial protocol.
+	ValueSet []LsSymbolKind `json:"valueSet,omitempty"`
+}
+
+type LsDocumentSymbolClientCapabilities struct {
+	SymbolKind LsSymbolKindClientCapabilities `json:"symbolKind"`
+	// Whether the client supports DocumentSymbol results with children.
+	HierarchicalDocumentSymbolSupport bool `json:"hierarchicalDocumentSymbolSupport"`
+}
+
+type LsWorkspaceSymbolClientCapabilities struct {
+	SymbolKind LsSymbolKindClientCapabilities `json:"symbolKind"`
+}
+
+type LsTextDocumentSyncClientCapabilities struct {
+	// Whether the client sends textDocument/willSave,
+	// textDocument/willSaveWaitUntil and textDocument/didSave.
+	WillSave          bool `json:"willSave"`
+	WillSaveWaitUntil bool `json:"willSaveWaitUntil"`
+	DidSave           bool `json:"didSave"`
+}
+
+type LsCompletionItemClientCapabilities struct {
+	// Whether insert texts may be snippets with tab stops and
+	// placeholders.
+	SnippetSupport bool `json:"snippetSupport"`
+	// Formats supported for the documentation of items, in order of
+	// preference.
+	DocumentationFormat []LsMarkupKind `json:"documentationFormat,omitempty"`
+}
+
+type LsCompletionItemKindClientCapabilities struct {
+	ValueSet []LsCompletionItemKind `json:"valueSet,omitempty"`
+}
+
+type LsCompletionClientCapabilities struct {
+	CompletionItem     LsCompletionItemClientCapabilities     `json:"completionItem"`
+	CompletionItemKind LsCompletionItemKindClientCapabilities `json:"completionItemKind"`
+}
+
+type LsSemanticTokensFullRequests struct {
+	// Whether the client supports textDocument/semanticTokens/full/delta.
+	Delta bool `json:"delta"`
+}
+
+type LsSemanticTokensRequests struct {
+	Range bool                         `json:"range"`
+	Full  LsSemanticTokensFullRequests `json:"full"`
+}
+
+type LsSemanticTokensClientCapabilities struct {
+	Requests       LsSemanticTokensRequests `json:"requests"`
+	TokenTypes     []string                 `json:"tokenTypes"`
+	TokenModifiers []string                 `json:"tokenModifiers"`
+	// Always "relative".
+	Formats []string `json:"formats"`
+}
+
+type LsTextDocumentClientCapabilities struct {
+	Synchronization LsTextDocumentSyncClientCapabilities `json:"synchronization"`
+	Completion      LsCompletionClientCapabilities       `json:"completion"`
+	Hover           LsHoverClientCapabilities            `json:"hover"`
+	DocumentSymbol  LsDocumentSymbolClientCapabilities   `json:"documentSymbol"`
+	// Since 3.16.0
+	SemanticTokens *LsSemanticTokensClientCapabilities `json:"semanticTokens,omitempty"`
+}
+
+type LsClientCapabilities struct {
+	Workspace    LsWorkspaceClientCapabilities    `json:"workspace"`
+	Window       LsWindowClientCapabilities       `json:"window"`
+	TextDocument LsTextDocumentClientCapabilities `json:"textDocument"`
+}
+
+type LsWorkspaceClientCapabilities struct {
+	// Whether the client answers workspace/applyEdit requests.
+	ApplyEdit bool `json:"applyEdit,omitempty"`
+	// What workspace edits the client can apply.
+	WorkspaceEdit *LsWorkspaceEditClientCapabilities `json:"workspaceEdit,omitempty"`
+	// Whether the client supports workspace folders. Since 3.6.0
+	WorkspaceFolders bool                                `json:"workspaceFolders,omitempty"`
+	Symbol           LsWorkspaceSymbolClientCapabilities `json:"symbol"`
+}
+
+type LsWorkspaceEditClientCapabilities struct {
+	// Whether documentChanges are supported.
+	DocumentChanges bool `json:"documentChanges,omitempty"`
+	// The resource operations supported, ie, "create", "rename" and "delete".
+	ResourceOperations []string `json:"resourceOperations,omitempty"`
+}
+
+type LsMarkupKind string
+
+const (
+	PlainText LsMarkupKind = "plaintext"
+	Markdown  LsMarkupKind = "markdown"
+)
+
+// LsMarkupContent replaces MarkedString since 3.0.
+type LsMarkupContent struct {
+	Kind  LsMarkupKind `json:"kind"`
+	Value string       `json:"value"`
+}
+
+// LsMarkedString is the object form of a MarkedString, which may also be a
+// plain markdown string.
+type LsMarkedString struct {
+	Language string `json:"language"`
+	Value    string `json:"value"`
+}
+
+type LsHover struct {
+	// A MarkedString, a MarkedString array or MarkupContent.
+	Contents easyjson.RawMessage `json:"contents"`
+	Range    *LsRange            `json:"range,omitempty"`
+}
+
+type LsDiagnosticSeverity int
+
+const (
+	Error       LsDiagnosticSeverity = 1
+	Warning     LsDiagnosticSeverity = 2
+	Information LsDiagnosticSeverity = 3
+	Hint        LsDiagnosticSeverity = 4
+)
+
+func (s LsDiagnosticSeverity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Information:
+		return "information"
+	case Hint:
+		return "hint"
+	}
+	return "unknown"
+}
+
+type LsDiagnostic struct {
+	// The range at which the message applies.
+	Range LsRange `json:"range"`
+
+	// The diagnostic's severity. Can be omitted. If omitted it is up to the
+	// client to interpret diagnostics as error, warning, info or hint.
+	Severity LsDiagnosticSeverity `json:"severity,omitempty"`
+
+	// The diagnostic's code. number | string
+	Code easyjson.RawMessage `json:"code,omitempty"`
+
+	// A human-readable string describing the source of this
+	// diagnostic, e.g. 'typescript' or 'super lint'.
+	Source string `json:"source,omitempty"`
+
+	// The diagnostic's message.
+	Message string `json:"message"`
+
+	// Describes the code, ie, with a link to its documentation. Since 3.16.0
+	CodeDescription *LsCodeDescription `json:"codeDescription,omitempty"`
+
+	// Related locations, ie, of a previous declaration.
+	RelatedInformation []LsDiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type LsCodeDescription struct {
+	// URI of the documentation of the code.
+	Href string `json:"href"`
+}
+
+type LsDiagnosticRelatedInformation struct {
+	Location LsLocation `json:"location"`
+	Message  string     `json:"message"`
+}
+
+type LsPublishDiagnosticsParams struct {
+	// The URI for which diagnostic information is reported.
+	URI LsDocumentURI `json:"uri"`
+
+	// An array of diagnostic information items.
+	Diagnostics []LsDiagnostic `json:"diagnostics"`
+}
+
+type LsProgressParams struct {
+	// The progress token provided by the client or server. number | string
+	Token easyjson.RawMessage `json:"token"`
+
+	// The progress data.
+	Value easyjson.RawMessage `json:"value"`
+}
+
+// LsWorkDoneProgress is the value of a $/progress notification. It merges the
+// begin, report and end payloads, which are distinguished by Kind.
+type LsWorkDoneProgress struct {
+	Kind string `json:"kind"` // "begin" | "report" | "end"
+
+	// Only set for "begin".
+	Title string `json:"title,omitempty"`
+
+	Message string `json:"message,omitempty"`
+
+	// Optional progress percentage in the range [0, 100].
+	Percentage *int `json:"percentage,omitempty"`
+}
+
+type LsMessageType int
+
+const (
+	ErrorMessage   LsMessageType = 1
+	WarningMessage LsMessageType = 2
+	InfoMessage    LsMessageType = 3
+	LogMessage     LsMessageType = 4
+)
+
+type LsShowMessageParams struct {
+	// The message type.
+	Type LsMessageType `json:"type"`
+
+	// The actual message.
+	Message string `json:"message"`
+}
+
+type LsDidChangeConfigurationParams struct {
+	// The actual changed settings
+	Settings easyjson.RawMessage `json:"settings"`
+}
+
+type LsConfigurationItem struct {
+	// The scope to get the configuration section for.
+	ScopeURI LsDocumentURI `json:"scopeUri,omitempty"`
+
+	// The configuration section asked for.
+	Section string `json:"section,omitempty"`
+}
+
+type LsConfigurationParams struct {
+	Items []LsConfigurationItem `json:"items"`
+}
+
+// LsSemanticTokensLegend names the token types and modifiers a server uses.
+// Tokens refer to types by index and to modifiers by bit.
+type LsSemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+type LsSemanticTokensParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+}
+
+type LsSemanticTokensDeltaParams struct {
+	TextDocument     LsTextDocumentIdentifier `json:"textDocument"`
+	PreviousResultID string                   `json:"previousResultId"`
+}
+
+type LsSemanticTokensRangeParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	Range        LsRange                  `json:"range"`
+}
+
+// LsSemanticTokens encodes each token as five integers: line delta, start
+// delta, length, type index and modifier bits. Lines are relative to the
+// previous token, and so is the start if the token is on the same line.
+type LsSemanticTokens struct {
+	ResultID string   `json:"resultId,omitempty"`
+	Data     []uint32 `json:"data"`
+}
+
+// LsSemanticTokensEdit replaces DeleteCount integers of the previous data at
+// Start with Data.
+type LsSemanticTokensEdit struct {
+	Start       int      `json:"start"`
+	DeleteCount int      `json:"deleteCount"`
+	Data        []uint32 `json:"data,omitempty"`
+}
+
+type LsSemanticTokensDelta struct {
+	ResultID string                 `json:"resultId,omitempty"`
+	Edits    []LsSemanticTokensEdit `json:"edits"`
+}
+
+// LsMoniker identifies a symbol across projects, ie, for cross-repository
+// code intelligence.
+type LsMoniker struct {
+	// The scheme of the identifier, ie, tsc or npm.
+	Scheme     string `json:"scheme"`
+	Identifier string `json:"identifier"`
+	// How unique the identifier is: document, project, group, scheme or
+	// global.
+	Unique string `json:"unique"`
+	// import, export or local.
+	Kind string `json:"kind,omitempty"`
+}
+
+// LsLinkedEditingRanges are ranges that have the same content and are edited
+// together, ie, the names of an opening and a closing tag.
+type LsLinkedEditingRanges struct {
+	Ranges []LsRange `json:"ranges"`
+	// Regular expression matching valid content of the ranges.
+	WordPattern string `json:"wordPattern,omitempty"`
+}
+
+// LsInlineValueContext describes where a debugger stopped.
+type LsInlineValueContext struct {
+	// The stack frame the execution stopped in, as known to the debugger.
+	FrameID         int     `json:"frameId"`
+	StoppedLocation LsRange `json:"stoppedLocation"`
+}
+
+type LsInlineValueParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	// The visible part of the document to compute inline values for.
+	Range   LsRange              `json:"range"`
+	Context LsInlineValueContext `json:"context"`
+}
+
+// LsInlineValue is one of InlineValueText, which has Text,
+// InlineValueVariableLookup, which has CaseSensitiveLookup, and
+// InlineValueEvaluatableExpression.
+type LsInlineValue struct {
+	Range               LsRange `json:"range"`
+	Text                *string `json:"text,omitempty"`
+	VariableName        string  `json:"variableName,omitempty"`
+	CaseSensitiveLookup *bool   `json:"caseSensitiveLookup,omitempty"`
+	Expression          string  `json:"expression,omitempty"`
+}
+
+// LsWorkspaceEdit is a set of changes to many documents.
+type LsWorkspaceEdit struct {
+	Changes         map[LsDocumentURI][]LsTextEdit `json:"changes,omitempty"`
+	DocumentChanges []LsDocumentChange             `json:"documentChanges,omitempty"`
+}
+
+// LsDocumentChange is a TextDocumentEdit, which has TextDocument and Edits,
+// or a resource operation, which has Kind "create", "rename" or "delete".
+type LsDocumentChange struct {
+	TextDocument *LsVersionedTextDocumentIdentifier `json:"textDocument,omitempty"`
+	Edits        []LsTextEdit                       `json:"edits,omitempty"`
+	Kind         string                             `json:"kind,omitempty"`
+	// Set for create and delete.
+	URI LsDocumentURI `json:"uri,omitempty"`
+	// Set for rename.
+	OldURI LsDocumentURI `json:"oldUri,omitempty"`
+	NewURI LsDocumentURI `json:"newUri,omitempty"`
+}
+
+// LsCommand is a command the server can execute with
+// workspace/executeCommand.
+type LsCommand struct {
+	Title     string                `json:"title"`
+	Command   string                `json:"command"`
+	Arguments []easyjson.RawMessage `json:"arguments,omitempty"`
+}
+
+type LsExecuteCommandParams struct {
+	Command   string                `json:"command"`
+	Arguments []easyjson.RawMessage `json:"arguments,omitempty"`
+}
+
+type LsCodeActionContext struct {
+	// The diagnostics at the range the actions are for.
+	Diagnostics []LsDiagnostic `json:"diagnostics"`
+	// Only return actions of these kinds, ie, "quickfix".
+	Only []string `json:"only,omitempty"`
+}
+
+type LsCodeActionParams struct {
+	TextDocument LsTextDocumentIdentifier `json:"textDocument"`
+	Range        LsRange                  `json:"range"`
+	Context      LsCodeActionContext      `json:"context"`
+}
+
+// LsCodeAction is a CodeAction or a Command, which are both results of
+// textDocument/codeAction. For a Command, Command is the command identifier
+// as a string; for a CodeAction it is an LsCommand.
+type LsCodeAction struct {
+	Title       string                `json:"title"`
+	Kind        string                `json:"kind,omitempty"`
+	Diagnostics []LsDiagnostic        `json:"diagnostics,omitempty"`
+	IsPreferred bool                  `json:"isPreferred,omitempty"`
+	Disabled    *LsCodeActionDisabled `json:"disabled,omitempty"`
+	Edit        *LsWorkspaceEdit      `json:"edit,omitempty"`
+	Command     easyjson.RawMessage   `json:"command,omitempty"`
+	Arguments   []easyjson.RawMessage `json:"arguments,omitempty"`
+	// Preserved for codeAction/resolve.
+	Data easyjson.RawMessage `json:"data,omitempty"`
+}
+
+type LsCodeActionDisabled struct {
+	// Why the action cannot be applied.
+	Reason string `json:"reason"`
+}
+
+// LsApplyWorkspaceEditParams is sent by the server with workspace/applyEdit.
+type LsApplyWorkspaceEditParams struct {
+	Label string          `json:"label,omitempty"`
+	Edit  LsWorkspaceEdit `json:"edit"`
+}
+
+// LsApplyWorkspaceEditResult answers a workspace/applyEdit request.
+type LsApplyWorkspaceEditResult struct {
+	Applied       bool   `json:"applied"`
+	FailureReason string `json:"failureReason,omitempty"`
 }
 
 // RequestID is the id of a request/response
@@ -169,12 +774,48 @@ func (r *RequestID) IsDefined() bool {
 	return *r >= 0
 }
 
+// LsCancelParams are the parameters of $/cancelRequest.
+type LsCancelParams struct {
+	// The request to cancel.
+	ID RequestID `json:"id"`
+}
+
+// LsErrorCode is the code of a response error.
+type LsErrorCode int
+
+const (
+	ParseError           LsErrorCode = -32700
+	InvalidRequest       LsErrorCode = -32600
+	MethodNotFound       LsErrorCode = -32601
+	InvalidParams        LsErrorCode = -32602
+	InternalError        LsErrorCode = -32603
+	ServerNotInitialized LsErrorCode = -32002
+	UnknownErrorCode     LsErrorCode = -32001
+	RequestCancelled     LsErrorCode = -32800
+	RequestFailed        LsErrorCode = -32803
+)
+
+// LsResponseError is sent by the language server when a request fails.
+type LsResponseError struct {
+	Code    LsErrorCode         `json:"code"`
+	Message string              `json:"message"`
+	Data    easyjson.RawMessage `json:"data,omitempty"`
+}
+
+func (e *LsResponseError) Error() string {
+	return fmt.Sprintf("%s (code=%d)", e.Message, e.Code)
+}
+
 // JSONRPCHeader is used to identify a message.
 type JSONRPCHeader struct {
-	JSONRPC string              `json:"jsonrpc"` // Should be "2.0"
-	Method  string              `json:"method"`  // ie, "textDocument/codeLens"
+	JSONRPC string              `json:"jsonrpc"`          // Should be "2.0"
+	Method  string              `json:"method,omitempty"` // ie, "textDocument/codeLens"
 	ID      RequestID           `json:"id,omitempty"`
-	Params  easyjson.RawMessage `json:"params"`
+	Params  easyjson.RawMessage `json:"params,omitempty"`
+
+	// Only set on responses.
+	Result easyjson.RawMessage `json:"result,omitempty"`
+	Error  *LsResponseError    `json:"error,omitempty"`
 }
 
 // NotificationInitialized is sent from the server to the client after the
@@ -183,28 +824,6 @@ type NotificationInitialized struct{}
 
 /*
 
-struct lsResponseError {
-  enum class lsErrorCodes : int {
-    ParseError = -32700,
-    InvalidRequest = -32600,
-    MethodNotFound = -32601,
-    InvalidParams = -32602,
-    InternalError = -32603,
-    serverErrorStart = -32099,
-    serverErrorEnd = -32000,
-    ServerNotInitialized = -32002,
-    UnknownErrorCode = -32001,
-    RequestCancelled = -32800,
-  };
-
-  lsErrorCodes code;
-  // Short description.
-  std::string message;
-
-  void Write(Writer& visitor);
-};
-
-
 // cquery extension
 struct lsLocationEx : lsLocation {
   optional<std::string_view> containerName;
@@ -303,14 +922,6 @@ MAKE_REFLECT_STRUCT(lsTextDocumentDidChangeParams,
                     textDocument,
                     contentChanges);
 
-// Show a message to the user.
-enum class lsMessageType : int { Error = 1, Warning = 2, Info = 3, Log = 4 };
-MAKE_REFLECT_TYPE_PROXY(lsMessageType)
-struct Out_ShowLogMessageParams {
-  lsMessageType type = lsMessageType::Error;
-  std::string message;
-};
-MAKE_REFLECT_STRUCT(Out_ShowLogMessageParams, type, message);
 struct Out_ShowLogMessage : public lsOutMessage<Out_ShowLogMessage> {
   enum class DisplayType { Show, Log };
   DisplayType display_type = DisplayType::Show;
@@ -76,7 +76,7 @@ func (v *NotificationInitialized) UnmarshalJSON(data []byte) error {
 func (v *NotificationInitialized) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc1(in *jlexer.Lexer, out *LsVersionedTextDocumentIdentifier) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc1(in *jlexer.Lexer, out *LsWorkspaceSymbolParams) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -95,18 +95,8 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc1(in *jlexer.Lexer, out *LsVe
 			continue
 		}
 		switch key {
-		case "uri":
-			out.URI = LsDocumentURI(in.String())
-		case "version":
-			if in.IsNull() {
-				in.Skip()
-				out.Version = nil
-			} else {
-				if out.Version == nil {
-					out.Version = new(int)
-				}
-				*out.Version = int(in.Int())
-			}
+		case "query":
+			out.Query = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -117,61 +107,47 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc1(in *jlexer.Lexer, out *LsVe
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc1(out *jwriter.Writer, in LsVersionedTextDocumentIdentifier) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc1(out *jwriter.Writer, in LsWorkspaceSymbolParams) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"uri\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.URI))
-	}
-	{
-		const prefix string = ",\"version\":"
+		const prefix string = ",\"query\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		if in.Version == nil {
-			out.RawString("null")
-		} else {
-			out.Int(int(*in.Version))
-		}
+		out.String(string(in.Query))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsVersionedTextDocumentIdentifier) MarshalJSON() ([]byte, error) {
+func (v LsWorkspaceSymbolParams) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsVersionedTextDocumentIdentifier) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkspaceSymbolParams) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsVersionedTextDocumentIdentifier) UnmarshalJSON(data []byte) error {
+func (v *LsWorkspaceSymbolParams) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsVersionedTextDocumentIdentifier) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkspaceSymbolParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc1(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc2(in *jlexer.Lexer, out *LsTextEdit) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc2(in *jlexer.Lexer, out *LsWorkspaceSymbolClientCapabilities) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -190,10 +166,8 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc2(in *jlexer.Lexer, out *LsTe
 			continue
 		}
 		switch key {
-		case "range":
-			(out.Range).UnmarshalEasyJSON(in)
-		case "newText":
-			out.NewText = string(in.String())
+		case "symbolKind":
+			(out.SymbolKind).UnmarshalEasyJSON(in)
 		default:
 			in.SkipRecursive()
 		}
@@ -204,57 +178,47 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc2(in *jlexer.Lexer, out *LsTe
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc2(out *jwriter.Writer, in LsTextEdit) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc2(out *jwriter.Writer, in LsWorkspaceSymbolClientCapabilities) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"range\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		(in.Range).MarshalEasyJSON(out)
-	}
-	{
-		const prefix string = ",\"newText\":"
+		const prefix string = ",\"symbolKind\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.NewText))
+		(in.SymbolKind).MarshalEasyJSON(out)
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsTextEdit) MarshalJSON() ([]byte, error) {
+func (v LsWorkspaceSymbolClientCapabilities) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsTextEdit) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkspaceSymbolClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsTextEdit) UnmarshalJSON(data []byte) error {
+func (v *LsWorkspaceSymbolClientCapabilities) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsTextEdit) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkspaceSymbolClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc2(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc3(in *jlexer.Lexer, out *LsTextDocumentPositionParams) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc3(in *jlexer.Lexer, out *LsWorkspaceFolder) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -273,10 +237,10 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc3(in *jlexer.Lexer, out *LsTe
 			continue
 		}
 		switch key {
-		case "textDocument":
-			(out.TextDocument).UnmarshalEasyJSON(in)
-		case "position":
-			(out.Position).UnmarshalEasyJSON(in)
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		case "name":
+			out.Name = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -287,57 +251,57 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc3(in *jlexer.Lexer, out *LsTe
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc3(out *jwriter.Writer, in LsTextDocumentPositionParams) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc3(out *jwriter.Writer, in LsWorkspaceFolder) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"textDocument\":"
+		const prefix string = ",\"uri\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		(in.TextDocument).MarshalEasyJSON(out)
+		out.String(string(in.URI))
 	}
 	{
-		const prefix string = ",\"position\":"
+		const prefix string = ",\"name\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		(in.Position).MarshalEasyJSON(out)
+		out.String(string(in.Name))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsTextDocumentPositionParams) MarshalJSON() ([]byte, error) {
+func (v LsWorkspaceFolder) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsTextDocumentPositionParams) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkspaceFolder) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsTextDocumentPositionParams) UnmarshalJSON(data []byte) error {
+func (v *LsWorkspaceFolder) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsTextDocumentPositionParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkspaceFolder) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc3(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc4(in *jlexer.Lexer, out *LsTextDocumentItem) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc4(in *jlexer.Lexer, out *LsWorkspaceEditClientCapabilities) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -356,14 +320,31 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc4(in *jlexer.Lexer, out *LsTe
 			continue
 		}
 		switch key {
-		case "uri":
-			out.URI = LsDocumentURI(in.String())
-		case "languageId":
-			out.LanguageID = string(in.String())
-		case "version":
-			out.Version = int(in.Int())
-		case "text":
-			out.Text = string(in.String())
+		case "documentChanges":
+			out.DocumentChanges = bool(in.Bool())
+		case "resourceOperations":
+			if in.IsNull() {
+				in.Skip()
+				out.ResourceOperations = nil
+			} else {
+				in.Delim('[')
+				if out.ResourceOperations == nil {
+					if !in.IsDelim(']') {
+						out.ResourceOperations = make([]string, 0, 4)
+					} else {
+						out.ResourceOperations = []string{}
+					}
+				} else {
+					out.ResourceOperations = (out.ResourceOperations)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.ResourceOperations = append(out.ResourceOperations, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -374,77 +355,66 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc4(in *jlexer.Lexer, out *LsTe
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc4(out *jwriter.Writer, in LsTextDocumentItem) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc4(out *jwriter.Writer, in LsWorkspaceEditClientCapabilities) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"uri\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.URI))
-	}
-	{
-		const prefix string = ",\"languageId\":"
+	if in.DocumentChanges {
+		const prefix string = ",\"documentChanges\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.LanguageID))
+		out.Bool(bool(in.DocumentChanges))
 	}
-	{
-		const prefix string = ",\"version\":"
+	if len(in.ResourceOperations) != 0 {
+		const prefix string = ",\"resourceOperations\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int(int(in.Version))
-	}
-	{
-		const prefix string = ",\"text\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.ResourceOperations {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
 		}
-		out.String(string(in.Text))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsTextDocumentItem) MarshalJSON() ([]byte, error) {
+func (v LsWorkspaceEditClientCapabilities) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsTextDocumentItem) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkspaceEditClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsTextDocumentItem) UnmarshalJSON(data []byte) error {
+func (v *LsWorkspaceEditClientCapabilities) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsTextDocumentItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkspaceEditClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc4(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc5(in *jlexer.Lexer, out *LsTextDocumentIdentifier) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc5(in *jlexer.Lexer, out *LsWorkspaceEdit) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -463,8 +433,70 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc5(in *jlexer.Lexer, out *LsTe
 			continue
 		}
 		switch key {
-		case "uri":
-			out.URI = LsDocumentURI(in.String())
+		case "changes":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Changes = make(map[LsDocumentURI][]LsTextEdit)
+				} else {
+					out.Changes = nil
+				}
+				for !in.IsDelim('}') {
+					key := LsDocumentURI(in.String())
+					in.WantColon()
+					var v4 []LsTextEdit
+					if in.IsNull() {
+						in.Skip()
+						v4 = nil
+					} else {
+						in.Delim('[')
+						if v4 == nil {
+							if !in.IsDelim(']') {
+								v4 = make([]LsTextEdit, 0, 1)
+							} else {
+								v4 = []LsTextEdit{}
+							}
+						} else {
+							v4 = (v4)[:0]
+						}
+						for !in.IsDelim(']') {
+							var v5 LsTextEdit
+							(v5).UnmarshalEasyJSON(in)
+							v4 = append(v4, v5)
+							in.WantComma()
+						}
+						in.Delim(']')
+					}
+					(out.Changes)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "documentChanges":
+			if in.IsNull() {
+				in.Skip()
+				out.DocumentChanges = nil
+			} else {
+				in.Delim('[')
+				if out.DocumentChanges == nil {
+					if !in.IsDelim(']') {
+						out.DocumentChanges = make([]LsDocumentChange, 0, 1)
+					} else {
+						out.DocumentChanges = []LsDocumentChange{}
+					}
+				} else {
+					out.DocumentChanges = (out.DocumentChanges)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v6 LsDocumentChange
+					(v6).UnmarshalEasyJSON(in)
+					out.DocumentChanges = append(out.DocumentChanges, v6)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -475,47 +507,91 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc5(in *jlexer.Lexer, out *LsTe
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc5(out *jwriter.Writer, in LsTextDocumentIdentifier) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc5(out *jwriter.Writer, in LsWorkspaceEdit) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"uri\":"
+	if len(in.Changes) != 0 {
+		const prefix string = ",\"changes\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.URI))
+		{
+			out.RawByte('{')
+			v7First := true
+			for v7Name, v7Value := range in.Changes {
+				if v7First {
+					v7First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v7Name))
+				out.RawByte(':')
+				if v7Value == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+					out.RawString("null")
+				} else {
+					out.RawByte('[')
+					for v8, v9 := range v7Value {
+						if v8 > 0 {
+							out.RawByte(',')
+						}
+						(v9).MarshalEasyJSON(out)
+					}
+					out.RawByte(']')
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.DocumentChanges) != 0 {
+		const prefix string = ",\"documentChanges\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v10, v11 := range in.DocumentChanges {
+				if v10 > 0 {
+					out.RawByte(',')
+				}
+				(v11).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsTextDocumentIdentifier) MarshalJSON() ([]byte, error) {
+func (v LsWorkspaceEdit) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsTextDocumentIdentifier) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkspaceEdit) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsTextDocumentIdentifier) UnmarshalJSON(data []byte) error {
+func (v *LsWorkspaceEdit) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsTextDocumentIdentifier) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkspaceEdit) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc5(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc6(in *jlexer.Lexer, out *LsRange) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc6(in *jlexer.Lexer, out *LsWorkspaceClientCapabilities) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -534,10 +610,22 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc6(in *jlexer.Lexer, out *LsRa
 			continue
 		}
 		switch key {
-		case "start":
-			(out.Start).UnmarshalEasyJSON(in)
-		case "end":
-			(out.End).UnmarshalEasyJSON(in)
+		case "applyEdit":
+			out.ApplyEdit = bool(in.Bool())
+		case "workspaceEdit":
+			if in.IsNull() {
+				in.Skip()
+				out.WorkspaceEdit = nil
+			} else {
+				if out.WorkspaceEdit == nil {
+					out.WorkspaceEdit = new(LsWorkspaceEditClientCapabilities)
+				}
+				(*out.WorkspaceEdit).UnmarshalEasyJSON(in)
+			}
+		case "workspaceFolders":
+			out.WorkspaceFolders = bool(in.Bool())
+		case "symbol":
+			(out.Symbol).UnmarshalEasyJSON(in)
 		default:
 			in.SkipRecursive()
 		}
@@ -548,57 +636,77 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc6(in *jlexer.Lexer, out *LsRa
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc6(out *jwriter.Writer, in LsRange) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc6(out *jwriter.Writer, in LsWorkspaceClientCapabilities) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"start\":"
+	if in.ApplyEdit {
+		const prefix string = ",\"applyEdit\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		(in.Start).MarshalEasyJSON(out)
+		out.Bool(bool(in.ApplyEdit))
+	}
+	if in.WorkspaceEdit != nil {
+		const prefix string = ",\"workspaceEdit\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.WorkspaceEdit).MarshalEasyJSON(out)
+	}
+	if in.WorkspaceFolders {
+		const prefix string = ",\"workspaceFolders\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.WorkspaceFolders))
 	}
 	{
-		const prefix string = ",\"end\":"
+		const prefix string = ",\"symbol\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		(in.End).MarshalEasyJSON(out)
+		(in.Symbol).MarshalEasyJSON(out)
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsRange) MarshalJSON() ([]byte, error) {
+func (v LsWorkspaceClientCapabilities) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsRange) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkspaceClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsRange) UnmarshalJSON(data []byte) error {
+func (v *LsWorkspaceClientCapabilities) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsRange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkspaceClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc6(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc7(in *jlexer.Lexer, out *LsPosition) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc7(in *jlexer.Lexer, out *LsWorkDoneProgress) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -617,10 +725,22 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc7(in *jlexer.Lexer, out *LsPo
 			continue
 		}
 		switch key {
-		case "line":
-			out.Line = int(in.Int())
-		case "character":
-			out.Character = int(in.Int())
+		case "kind":
+			out.Kind = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "percentage":
+			if in.IsNull() {
+				in.Skip()
+				out.Percentage = nil
+			} else {
+				if out.Percentage == nil {
+					out.Percentage = new(int)
+				}
+				*out.Percentage = int(in.Int())
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -631,57 +751,77 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc7(in *jlexer.Lexer, out *LsPo
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc7(out *jwriter.Writer, in LsPosition) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc7(out *jwriter.Writer, in LsWorkDoneProgress) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"line\":"
+		const prefix string = ",\"kind\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int(int(in.Line))
+		out.String(string(in.Kind))
 	}
-	{
-		const prefix string = ",\"character\":"
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int(int(in.Character))
+		out.String(string(in.Title))
+	}
+	if in.Message != "" {
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	if in.Percentage != nil {
+		const prefix string = ",\"percentage\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(*in.Percentage))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsPosition) MarshalJSON() ([]byte, error) {
+func (v LsWorkDoneProgress) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsPosition) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWorkDoneProgress) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsPosition) UnmarshalJSON(data []byte) error {
+func (v *LsWorkDoneProgress) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsPosition) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWorkDoneProgress) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc7(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc8(in *jlexer.Lexer, out *LsLocation) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc8(in *jlexer.Lexer, out *LsWindowClientCapabilities) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -700,10 +840,8 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc8(in *jlexer.Lexer, out *LsLo
 			continue
 		}
 		switch key {
-		case "uri":
-			out.URI = LsDocumentURI(in.String())
-		case "range":
-			(out.Range).UnmarshalEasyJSON(in)
+		case "workDoneProgress":
+			out.WorkDoneProgress = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -714,57 +852,47 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc8(in *jlexer.Lexer, out *LsLo
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc8(out *jwriter.Writer, in LsLocation) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc8(out *jwriter.Writer, in LsWindowClientCapabilities) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"uri\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.URI))
-	}
-	{
-		const prefix string = ",\"range\":"
+		const prefix string = ",\"workDoneProgress\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		(in.Range).MarshalEasyJSON(out)
+		out.Bool(bool(in.WorkDoneProgress))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsLocation) MarshalJSON() ([]byte, error) {
+func (v LsWindowClientCapabilities) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsLocation) MarshalEasyJSON(w *jwriter.Writer) {
+func (v LsWindowClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonC38a4abEncodeGithubComJacobdufaultLspc8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsLocation) UnmarshalJSON(data []byte) error {
+func (v *LsWindowClientCapabilities) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsLocation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *LsWindowClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC38a4abDecodeGithubComJacobdufaultLspc8(l, v)
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(in *jlexer.Lexer, out *LsInitializeParams) {
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(in *jlexer.Lexer, out *LsVersionedTextDocumentIdentifier) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -783,10 +911,18 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(in *jlexer.Lexer, out *LsIn
 			continue
 		}
 		switch key {
-		case "rootUri":
-			out.RootURI = LsDocumentURI(in.String())
-		case "initializationOptions":
-			(out.InitializationOptions).UnmarshalEasyJSON(in)
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		case "version":
+			if in.IsNull() {
+				in.Skip()
+				out.Version = nil
+			} else {
+				if out.Version == nil {
+					out.Version = new(int)
+				}
+				*out.Version = int(in.Int())
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -797,57 +933,7516 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(in *jlexer.Lexer, out *LsIn
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc9(out *jwriter.Writer, in LsInitializeParams) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc9(out *jwriter.Writer, in LsVersionedTextDocumentIdentifier) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"rootUri\":"
+		const prefix string = ",\"uri\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.RootURI))
+		out.String(string(in.URI))
 	}
 	{
-		const prefix string = ",\"initializationOptions\":"
+		const prefix string = ",\"version\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		(in.InitializationOptions).MarshalEasyJSON(out)
-	}
+		if in.Version == nil {
+			out.RawString("null")
+		} else {
+			out.Int(int(*in.Version))
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsVersionedTextDocumentIdentifier) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsVersionedTextDocumentIdentifier) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsVersionedTextDocumentIdentifier) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsVersionedTextDocumentIdentifier) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(in *jlexer.Lexer, out *LsTextEdit) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "newText":
+			out.NewText = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(out *jwriter.Writer, in LsTextEdit) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"newText\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NewText))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextEdit) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextEdit) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextEdit) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextEdit) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc11(in *jlexer.Lexer, out *LsTextDocumentSyncClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "willSave":
+			out.WillSave = bool(in.Bool())
+		case "willSaveWaitUntil":
+			out.WillSaveWaitUntil = bool(in.Bool())
+		case "didSave":
+			out.DidSave = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc11(out *jwriter.Writer, in LsTextDocumentSyncClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"willSave\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.WillSave))
+	}
+	{
+		const prefix string = ",\"willSaveWaitUntil\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.WillSaveWaitUntil))
+	}
+	{
+		const prefix string = ",\"didSave\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.DidSave))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextDocumentSyncClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextDocumentSyncClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextDocumentSyncClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextDocumentSyncClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc11(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc12(in *jlexer.Lexer, out *LsTextDocumentPositionParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "position":
+			(out.Position).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc12(out *jwriter.Writer, in LsTextDocumentPositionParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"position\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Position).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextDocumentPositionParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextDocumentPositionParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextDocumentPositionParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextDocumentPositionParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc12(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc13(in *jlexer.Lexer, out *LsTextDocumentItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		case "languageId":
+			out.LanguageID = string(in.String())
+		case "version":
+			out.Version = int(in.Int())
+		case "text":
+			out.Text = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc13(out *jwriter.Writer, in LsTextDocumentItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"uri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URI))
+	}
+	{
+		const prefix string = ",\"languageId\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.LanguageID))
+	}
+	{
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Version))
+	}
+	{
+		const prefix string = ",\"text\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Text))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextDocumentItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextDocumentItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextDocumentItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextDocumentItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc13(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc14(in *jlexer.Lexer, out *LsTextDocumentIdentifier) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc14(out *jwriter.Writer, in LsTextDocumentIdentifier) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"uri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URI))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextDocumentIdentifier) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextDocumentIdentifier) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextDocumentIdentifier) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextDocumentIdentifier) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc14(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc15(in *jlexer.Lexer, out *LsTextDocumentContentChangeEvent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "text":
+			out.Text = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc15(out *jwriter.Writer, in LsTextDocumentContentChangeEvent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"text\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Text))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextDocumentContentChangeEvent) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextDocumentContentChangeEvent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextDocumentContentChangeEvent) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextDocumentContentChangeEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc15(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc16(in *jlexer.Lexer, out *LsTextDocumentClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "synchronization":
+			(out.Synchronization).UnmarshalEasyJSON(in)
+		case "completion":
+			(out.Completion).UnmarshalEasyJSON(in)
+		case "hover":
+			(out.Hover).UnmarshalEasyJSON(in)
+		case "documentSymbol":
+			(out.DocumentSymbol).UnmarshalEasyJSON(in)
+		case "semanticTokens":
+			if in.IsNull() {
+				in.Skip()
+				out.SemanticTokens = nil
+			} else {
+				if out.SemanticTokens == nil {
+					out.SemanticTokens = new(LsSemanticTokensClientCapabilities)
+				}
+				(*out.SemanticTokens).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc16(out *jwriter.Writer, in LsTextDocumentClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"synchronization\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Synchronization).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"completion\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Completion).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"hover\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Hover).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"documentSymbol\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.DocumentSymbol).MarshalEasyJSON(out)
+	}
+	if in.SemanticTokens != nil {
+		const prefix string = ",\"semanticTokens\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.SemanticTokens).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsTextDocumentClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsTextDocumentClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsTextDocumentClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsTextDocumentClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc16(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc17(in *jlexer.Lexer, out *LsSymbolKindClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "valueSet":
+			if in.IsNull() {
+				in.Skip()
+				out.ValueSet = nil
+			} else {
+				in.Delim('[')
+				if out.ValueSet == nil {
+					if !in.IsDelim(']') {
+						out.ValueSet = make([]LsSymbolKind, 0, 8)
+					} else {
+						out.ValueSet = []LsSymbolKind{}
+					}
+				} else {
+					out.ValueSet = (out.ValueSet)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v12 LsSymbolKind
+					v12 = LsSymbolKind(in.Int())
+					out.ValueSet = append(out.ValueSet, v12)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc17(out *jwriter.Writer, in LsSymbolKindClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.ValueSet) != 0 {
+		const prefix string = ",\"valueSet\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v13, v14 := range in.ValueSet {
+				if v13 > 0 {
+					out.RawByte(',')
+				}
+				out.Int(int(v14))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSymbolKindClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSymbolKindClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSymbolKindClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSymbolKindClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc17(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc18(in *jlexer.Lexer, out *LsSymbolInformation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "kind":
+			out.Kind = LsSymbolKind(in.Int())
+		case "location":
+			(out.Location).UnmarshalEasyJSON(in)
+		case "containerName":
+			out.ContainerName = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc18(out *jwriter.Writer, in LsSymbolInformation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Kind))
+	}
+	{
+		const prefix string = ",\"location\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Location).MarshalEasyJSON(out)
+	}
+	if in.ContainerName != "" {
+		const prefix string = ",\"containerName\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ContainerName))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSymbolInformation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSymbolInformation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSymbolInformation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSymbolInformation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc18(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc19(in *jlexer.Lexer, out *LsShowMessageParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Type = LsMessageType(in.Int())
+		case "message":
+			out.Message = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc19(out *jwriter.Writer, in LsShowMessageParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Type))
+	}
+	{
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsShowMessageParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsShowMessageParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsShowMessageParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsShowMessageParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc19(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc20(in *jlexer.Lexer, out *LsServerInfo) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "version":
+			out.Version = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc20(out *jwriter.Writer, in LsServerInfo) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Name))
+	}
+	if in.Version != "" {
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Version))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsServerInfo) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsServerInfo) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsServerInfo) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsServerInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc20(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc21(in *jlexer.Lexer, out *LsSemanticTokensRequests) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "range":
+			out.Range = bool(in.Bool())
+		case "full":
+			(out.Full).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc21(out *jwriter.Writer, in LsSemanticTokensRequests) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Range))
+	}
+	{
+		const prefix string = ",\"full\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Full).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensRequests) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc21(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensRequests) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc21(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensRequests) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc21(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensRequests) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc21(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc22(in *jlexer.Lexer, out *LsSemanticTokensRangeParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc22(out *jwriter.Writer, in LsSemanticTokensRangeParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensRangeParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc22(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensRangeParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc22(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensRangeParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc22(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensRangeParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc22(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc23(in *jlexer.Lexer, out *LsSemanticTokensParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc23(out *jwriter.Writer, in LsSemanticTokensParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc23(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc23(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc23(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc23(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc24(in *jlexer.Lexer, out *LsSemanticTokensLegend) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "tokenTypes":
+			if in.IsNull() {
+				in.Skip()
+				out.TokenTypes = nil
+			} else {
+				in.Delim('[')
+				if out.TokenTypes == nil {
+					if !in.IsDelim(']') {
+						out.TokenTypes = make([]string, 0, 4)
+					} else {
+						out.TokenTypes = []string{}
+					}
+				} else {
+					out.TokenTypes = (out.TokenTypes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v15 string
+					v15 = string(in.String())
+					out.TokenTypes = append(out.TokenTypes, v15)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "tokenModifiers":
+			if in.IsNull() {
+				in.Skip()
+				out.TokenModifiers = nil
+			} else {
+				in.Delim('[')
+				if out.TokenModifiers == nil {
+					if !in.IsDelim(']') {
+						out.TokenModifiers = make([]string, 0, 4)
+					} else {
+						out.TokenModifiers = []string{}
+					}
+				} else {
+					out.TokenModifiers = (out.TokenModifiers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v16 string
+					v16 = string(in.String())
+					out.TokenModifiers = append(out.TokenModifiers, v16)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc24(out *jwriter.Writer, in LsSemanticTokensLegend) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"tokenTypes\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.TokenTypes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v17, v18 := range in.TokenTypes {
+				if v17 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v18))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"tokenModifiers\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.TokenModifiers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v19, v20 := range in.TokenModifiers {
+				if v19 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v20))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensLegend) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc24(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensLegend) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc24(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensLegend) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc24(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensLegend) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc24(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc25(in *jlexer.Lexer, out *LsSemanticTokensFullRequests) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "delta":
+			out.Delta = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc25(out *jwriter.Writer, in LsSemanticTokensFullRequests) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"delta\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Delta))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensFullRequests) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc25(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensFullRequests) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc25(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensFullRequests) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc25(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensFullRequests) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc25(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc26(in *jlexer.Lexer, out *LsSemanticTokensEdit) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "start":
+			out.Start = int(in.Int())
+		case "deleteCount":
+			out.DeleteCount = int(in.Int())
+		case "data":
+			if in.IsNull() {
+				in.Skip()
+				out.Data = nil
+			} else {
+				in.Delim('[')
+				if out.Data == nil {
+					if !in.IsDelim(']') {
+						out.Data = make([]uint32, 0, 16)
+					} else {
+						out.Data = []uint32{}
+					}
+				} else {
+					out.Data = (out.Data)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v21 uint32
+					v21 = uint32(in.Uint32())
+					out.Data = append(out.Data, v21)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc26(out *jwriter.Writer, in LsSemanticTokensEdit) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"start\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Start))
+	}
+	{
+		const prefix string = ",\"deleteCount\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.DeleteCount))
+	}
+	if len(in.Data) != 0 {
+		const prefix string = ",\"data\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v22, v23 := range in.Data {
+				if v22 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint32(uint32(v23))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensEdit) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc26(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensEdit) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc26(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensEdit) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc26(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensEdit) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc26(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc27(in *jlexer.Lexer, out *LsSemanticTokensDeltaParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "previousResultId":
+			out.PreviousResultID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc27(out *jwriter.Writer, in LsSemanticTokensDeltaParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"previousResultId\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.PreviousResultID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensDeltaParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc27(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensDeltaParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc27(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensDeltaParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc27(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensDeltaParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc27(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc28(in *jlexer.Lexer, out *LsSemanticTokensDelta) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "resultId":
+			out.ResultID = string(in.String())
+		case "edits":
+			if in.IsNull() {
+				in.Skip()
+				out.Edits = nil
+			} else {
+				in.Delim('[')
+				if out.Edits == nil {
+					if !in.IsDelim(']') {
+						out.Edits = make([]LsSemanticTokensEdit, 0, 1)
+					} else {
+						out.Edits = []LsSemanticTokensEdit{}
+					}
+				} else {
+					out.Edits = (out.Edits)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v24 LsSemanticTokensEdit
+					(v24).UnmarshalEasyJSON(in)
+					out.Edits = append(out.Edits, v24)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc28(out *jwriter.Writer, in LsSemanticTokensDelta) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ResultID != "" {
+		const prefix string = ",\"resultId\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ResultID))
+	}
+	{
+		const prefix string = ",\"edits\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Edits == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v25, v26 := range in.Edits {
+				if v25 > 0 {
+					out.RawByte(',')
+				}
+				(v26).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensDelta) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc28(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensDelta) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc28(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensDelta) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc28(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensDelta) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc28(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc29(in *jlexer.Lexer, out *LsSemanticTokensClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "requests":
+			(out.Requests).UnmarshalEasyJSON(in)
+		case "tokenTypes":
+			if in.IsNull() {
+				in.Skip()
+				out.TokenTypes = nil
+			} else {
+				in.Delim('[')
+				if out.TokenTypes == nil {
+					if !in.IsDelim(']') {
+						out.TokenTypes = make([]string, 0, 4)
+					} else {
+						out.TokenTypes = []string{}
+					}
+				} else {
+					out.TokenTypes = (out.TokenTypes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v27 string
+					v27 = string(in.String())
+					out.TokenTypes = append(out.TokenTypes, v27)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "tokenModifiers":
+			if in.IsNull() {
+				in.Skip()
+				out.TokenModifiers = nil
+			} else {
+				in.Delim('[')
+				if out.TokenModifiers == nil {
+					if !in.IsDelim(']') {
+						out.TokenModifiers = make([]string, 0, 4)
+					} else {
+						out.TokenModifiers = []string{}
+					}
+				} else {
+					out.TokenModifiers = (out.TokenModifiers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v28 string
+					v28 = string(in.String())
+					out.TokenModifiers = append(out.TokenModifiers, v28)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "formats":
+			if in.IsNull() {
+				in.Skip()
+				out.Formats = nil
+			} else {
+				in.Delim('[')
+				if out.Formats == nil {
+					if !in.IsDelim(']') {
+						out.Formats = make([]string, 0, 4)
+					} else {
+						out.Formats = []string{}
+					}
+				} else {
+					out.Formats = (out.Formats)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v29 string
+					v29 = string(in.String())
+					out.Formats = append(out.Formats, v29)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc29(out *jwriter.Writer, in LsSemanticTokensClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"requests\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Requests).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"tokenTypes\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.TokenTypes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v30, v31 := range in.TokenTypes {
+				if v30 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v31))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"tokenModifiers\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.TokenModifiers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v32, v33 := range in.TokenModifiers {
+				if v32 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v33))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"formats\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Formats == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v34, v35 := range in.Formats {
+				if v34 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v35))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokensClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc29(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokensClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc29(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokensClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc29(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokensClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc29(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc30(in *jlexer.Lexer, out *LsSemanticTokens) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "resultId":
+			out.ResultID = string(in.String())
+		case "data":
+			if in.IsNull() {
+				in.Skip()
+				out.Data = nil
+			} else {
+				in.Delim('[')
+				if out.Data == nil {
+					if !in.IsDelim(']') {
+						out.Data = make([]uint32, 0, 16)
+					} else {
+						out.Data = []uint32{}
+					}
+				} else {
+					out.Data = (out.Data)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v36 uint32
+					v36 = uint32(in.Uint32())
+					out.Data = append(out.Data, v36)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc30(out *jwriter.Writer, in LsSemanticTokens) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ResultID != "" {
+		const prefix string = ",\"resultId\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ResultID))
+	}
+	{
+		const prefix string = ",\"data\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Data == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v37, v38 := range in.Data {
+				if v37 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint32(uint32(v38))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsSemanticTokens) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc30(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsSemanticTokens) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc30(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsSemanticTokens) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc30(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsSemanticTokens) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc30(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc31(in *jlexer.Lexer, out *LsResponseError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "code":
+			out.Code = LsErrorCode(in.Int())
+		case "message":
+			out.Message = string(in.String())
+		case "data":
+			(out.Data).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc31(out *jwriter.Writer, in LsResponseError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"code\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Code))
+	}
+	{
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	if (in.Data).IsDefined() {
+		const prefix string = ",\"data\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Data).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsResponseError) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc31(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsResponseError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc31(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsResponseError) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc31(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsResponseError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc31(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc32(in *jlexer.Lexer, out *LsRenameParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "position":
+			(out.Position).UnmarshalEasyJSON(in)
+		case "newName":
+			out.NewName = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc32(out *jwriter.Writer, in LsRenameParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"position\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Position).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"newName\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NewName))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsRenameParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc32(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsRenameParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc32(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsRenameParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc32(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsRenameParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc32(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc33(in *jlexer.Lexer, out *LsReferenceParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "position":
+			(out.Position).UnmarshalEasyJSON(in)
+		case "context":
+			(out.Context).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc33(out *jwriter.Writer, in LsReferenceParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"position\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Position).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"context\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Context).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsReferenceParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc33(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsReferenceParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc33(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsReferenceParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc33(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsReferenceParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc33(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc34(in *jlexer.Lexer, out *LsReferenceContext) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "includeDeclaration":
+			out.IncludeDeclaration = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc34(out *jwriter.Writer, in LsReferenceContext) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"includeDeclaration\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.IncludeDeclaration))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsReferenceContext) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc34(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsReferenceContext) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc34(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsReferenceContext) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc34(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsReferenceContext) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc34(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc35(in *jlexer.Lexer, out *LsRange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "start":
+			(out.Start).UnmarshalEasyJSON(in)
+		case "end":
+			(out.End).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc35(out *jwriter.Writer, in LsRange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"start\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Start).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"end\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.End).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsRange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc35(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsRange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc35(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsRange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc35(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsRange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc35(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc36(in *jlexer.Lexer, out *LsPublishDiagnosticsParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		case "diagnostics":
+			if in.IsNull() {
+				in.Skip()
+				out.Diagnostics = nil
+			} else {
+				in.Delim('[')
+				if out.Diagnostics == nil {
+					if !in.IsDelim(']') {
+						out.Diagnostics = make([]LsDiagnostic, 0, 1)
+					} else {
+						out.Diagnostics = []LsDiagnostic{}
+					}
+				} else {
+					out.Diagnostics = (out.Diagnostics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v39 LsDiagnostic
+					(v39).UnmarshalEasyJSON(in)
+					out.Diagnostics = append(out.Diagnostics, v39)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc36(out *jwriter.Writer, in LsPublishDiagnosticsParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"uri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URI))
+	}
+	{
+		const prefix string = ",\"diagnostics\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Diagnostics == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v40, v41 := range in.Diagnostics {
+				if v40 > 0 {
+					out.RawByte(',')
+				}
+				(v41).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsPublishDiagnosticsParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc36(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsPublishDiagnosticsParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc36(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsPublishDiagnosticsParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc36(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsPublishDiagnosticsParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc36(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc37(in *jlexer.Lexer, out *LsProgressParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "token":
+			(out.Token).UnmarshalEasyJSON(in)
+		case "value":
+			(out.Value).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc37(out *jwriter.Writer, in LsProgressParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"token\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Token).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"value\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Value).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsProgressParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc37(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsProgressParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc37(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsProgressParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc37(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsProgressParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc37(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc38(in *jlexer.Lexer, out *LsPosition) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "line":
+			out.Line = int(in.Int())
+		case "character":
+			out.Character = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc38(out *jwriter.Writer, in LsPosition) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"line\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Line))
+	}
+	{
+		const prefix string = ",\"character\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Character))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsPosition) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc38(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsPosition) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc38(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsPosition) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc38(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsPosition) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc38(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc39(in *jlexer.Lexer, out *LsMoniker) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scheme":
+			out.Scheme = string(in.String())
+		case "identifier":
+			out.Identifier = string(in.String())
+		case "unique":
+			out.Unique = string(in.String())
+		case "kind":
+			out.Kind = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc39(out *jwriter.Writer, in LsMoniker) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"scheme\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Scheme))
+	}
+	{
+		const prefix string = ",\"identifier\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Identifier))
+	}
+	{
+		const prefix string = ",\"unique\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Unique))
+	}
+	if in.Kind != "" {
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Kind))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsMoniker) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc39(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsMoniker) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc39(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsMoniker) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc39(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsMoniker) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc39(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc40(in *jlexer.Lexer, out *LsMarkupContent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "kind":
+			out.Kind = LsMarkupKind(in.String())
+		case "value":
+			out.Value = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc40(out *jwriter.Writer, in LsMarkupContent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"value\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Value))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsMarkupContent) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc40(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsMarkupContent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc40(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsMarkupContent) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc40(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsMarkupContent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc40(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc41(in *jlexer.Lexer, out *LsMarkedString) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "language":
+			out.Language = string(in.String())
+		case "value":
+			out.Value = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc41(out *jwriter.Writer, in LsMarkedString) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"language\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Language))
+	}
+	{
+		const prefix string = ",\"value\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Value))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsMarkedString) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc41(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsMarkedString) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc41(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsMarkedString) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc41(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsMarkedString) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc41(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc42(in *jlexer.Lexer, out *LsLocationLink) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "originSelectionRange":
+			if in.IsNull() {
+				in.Skip()
+				out.OriginSelectionRange = nil
+			} else {
+				if out.OriginSelectionRange == nil {
+					out.OriginSelectionRange = new(LsRange)
+				}
+				(*out.OriginSelectionRange).UnmarshalEasyJSON(in)
+			}
+		case "targetUri":
+			out.TargetURI = LsDocumentURI(in.String())
+		case "targetRange":
+			(out.TargetRange).UnmarshalEasyJSON(in)
+		case "targetSelectionRange":
+			(out.TargetSelectionRange).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc42(out *jwriter.Writer, in LsLocationLink) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.OriginSelectionRange != nil {
+		const prefix string = ",\"originSelectionRange\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.OriginSelectionRange).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"targetUri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.TargetURI))
+	}
+	{
+		const prefix string = ",\"targetRange\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TargetRange).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"targetSelectionRange\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TargetSelectionRange).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsLocationLink) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc42(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsLocationLink) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc42(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsLocationLink) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc42(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsLocationLink) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc42(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc43(in *jlexer.Lexer, out *LsLocation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc43(out *jwriter.Writer, in LsLocation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"uri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URI))
+	}
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsLocation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc43(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsLocation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc43(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsLocation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc43(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsLocation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc43(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc44(in *jlexer.Lexer, out *LsLinkedEditingRanges) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "ranges":
+			if in.IsNull() {
+				in.Skip()
+				out.Ranges = nil
+			} else {
+				in.Delim('[')
+				if out.Ranges == nil {
+					if !in.IsDelim(']') {
+						out.Ranges = make([]LsRange, 0, 2)
+					} else {
+						out.Ranges = []LsRange{}
+					}
+				} else {
+					out.Ranges = (out.Ranges)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v42 LsRange
+					(v42).UnmarshalEasyJSON(in)
+					out.Ranges = append(out.Ranges, v42)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "wordPattern":
+			out.WordPattern = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc44(out *jwriter.Writer, in LsLinkedEditingRanges) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"ranges\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Ranges == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v43, v44 := range in.Ranges {
+				if v43 > 0 {
+					out.RawByte(',')
+				}
+				(v44).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.WordPattern != "" {
+		const prefix string = ",\"wordPattern\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.WordPattern))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsLinkedEditingRanges) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc44(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsLinkedEditingRanges) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc44(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsLinkedEditingRanges) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc44(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsLinkedEditingRanges) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc44(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc45(in *jlexer.Lexer, out *LsInlineValueParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "context":
+			(out.Context).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc45(out *jwriter.Writer, in LsInlineValueParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"context\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Context).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsInlineValueParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc45(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsInlineValueParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc45(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsInlineValueParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc45(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsInlineValueParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc45(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc46(in *jlexer.Lexer, out *LsInlineValueContext) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "frameId":
+			out.FrameID = int(in.Int())
+		case "stoppedLocation":
+			(out.StoppedLocation).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc46(out *jwriter.Writer, in LsInlineValueContext) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"frameId\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.FrameID))
+	}
+	{
+		const prefix string = ",\"stoppedLocation\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.StoppedLocation).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsInlineValueContext) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc46(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsInlineValueContext) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc46(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsInlineValueContext) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc46(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsInlineValueContext) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc46(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc47(in *jlexer.Lexer, out *LsInlineValue) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "text":
+			if in.IsNull() {
+				in.Skip()
+				out.Text = nil
+			} else {
+				if out.Text == nil {
+					out.Text = new(string)
+				}
+				*out.Text = string(in.String())
+			}
+		case "variableName":
+			out.VariableName = string(in.String())
+		case "caseSensitiveLookup":
+			if in.IsNull() {
+				in.Skip()
+				out.CaseSensitiveLookup = nil
+			} else {
+				if out.CaseSensitiveLookup == nil {
+					out.CaseSensitiveLookup = new(bool)
+				}
+				*out.CaseSensitiveLookup = bool(in.Bool())
+			}
+		case "expression":
+			out.Expression = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc47(out *jwriter.Writer, in LsInlineValue) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	if in.Text != nil {
+		const prefix string = ",\"text\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.Text))
+	}
+	if in.VariableName != "" {
+		const prefix string = ",\"variableName\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.VariableName))
+	}
+	if in.CaseSensitiveLookup != nil {
+		const prefix string = ",\"caseSensitiveLookup\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(*in.CaseSensitiveLookup))
+	}
+	if in.Expression != "" {
+		const prefix string = ",\"expression\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Expression))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsInlineValue) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc47(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsInlineValue) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc47(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsInlineValue) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc47(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsInlineValue) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc47(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc48(in *jlexer.Lexer, out *LsInitializeResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "capabilities":
+			(out.Capabilities).UnmarshalEasyJSON(in)
+		case "serverInfo":
+			if in.IsNull() {
+				in.Skip()
+				out.ServerInfo = nil
+			} else {
+				if out.ServerInfo == nil {
+					out.ServerInfo = new(LsServerInfo)
+				}
+				(*out.ServerInfo).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc48(out *jwriter.Writer, in LsInitializeResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"capabilities\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Capabilities).MarshalEasyJSON(out)
+	}
+	if in.ServerInfo != nil {
+		const prefix string = ",\"serverInfo\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.ServerInfo).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsInitializeResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc48(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsInitializeResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc48(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsInitializeResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc48(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsInitializeResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc48(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc49(in *jlexer.Lexer, out *LsInitializeParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "processId":
+			if in.IsNull() {
+				in.Skip()
+				out.ProcessID = nil
+			} else {
+				if out.ProcessID == nil {
+					out.ProcessID = new(int)
+				}
+				*out.ProcessID = int(in.Int())
+			}
+		case "rootUri":
+			out.RootURI = LsDocumentURI(in.String())
+		case "initializationOptions":
+			(out.InitializationOptions).UnmarshalEasyJSON(in)
+		case "capabilities":
+			(out.Capabilities).UnmarshalEasyJSON(in)
+		case "workspaceFolders":
+			if in.IsNull() {
+				in.Skip()
+				out.WorkspaceFolders = nil
+			} else {
+				in.Delim('[')
+				if out.WorkspaceFolders == nil {
+					if !in.IsDelim(']') {
+						out.WorkspaceFolders = make([]LsWorkspaceFolder, 0, 2)
+					} else {
+						out.WorkspaceFolders = []LsWorkspaceFolder{}
+					}
+				} else {
+					out.WorkspaceFolders = (out.WorkspaceFolders)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v45 LsWorkspaceFolder
+					(v45).UnmarshalEasyJSON(in)
+					out.WorkspaceFolders = append(out.WorkspaceFolders, v45)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc49(out *jwriter.Writer, in LsInitializeParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"processId\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.ProcessID == nil {
+			out.RawString("null")
+		} else {
+			out.Int(int(*in.ProcessID))
+		}
+	}
+	{
+		const prefix string = ",\"rootUri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.RootURI))
+	}
+	{
+		const prefix string = ",\"initializationOptions\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.InitializationOptions).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"capabilities\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Capabilities).MarshalEasyJSON(out)
+	}
+	if len(in.WorkspaceFolders) != 0 {
+		const prefix string = ",\"workspaceFolders\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v46, v47 := range in.WorkspaceFolders {
+				if v46 > 0 {
+					out.RawByte(',')
+				}
+				(v47).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsInitializeParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc49(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsInitializeParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc49(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsInitializeParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc49(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsInitializeParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc49(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc50(in *jlexer.Lexer, out *LsHoverClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "contentFormat":
+			if in.IsNull() {
+				in.Skip()
+				out.ContentFormat = nil
+			} else {
+				in.Delim('[')
+				if out.ContentFormat == nil {
+					if !in.IsDelim(']') {
+						out.ContentFormat = make([]LsMarkupKind, 0, 4)
+					} else {
+						out.ContentFormat = []LsMarkupKind{}
+					}
+				} else {
+					out.ContentFormat = (out.ContentFormat)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v48 LsMarkupKind
+					v48 = LsMarkupKind(in.String())
+					out.ContentFormat = append(out.ContentFormat, v48)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc50(out *jwriter.Writer, in LsHoverClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.ContentFormat) != 0 {
+		const prefix string = ",\"contentFormat\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v49, v50 := range in.ContentFormat {
+				if v49 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v50))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsHoverClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc50(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsHoverClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc50(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsHoverClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc50(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsHoverClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc50(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc51(in *jlexer.Lexer, out *LsHover) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "contents":
+			(out.Contents).UnmarshalEasyJSON(in)
+		case "range":
+			if in.IsNull() {
+				in.Skip()
+				out.Range = nil
+			} else {
+				if out.Range == nil {
+					out.Range = new(LsRange)
+				}
+				(*out.Range).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc51(out *jwriter.Writer, in LsHover) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"contents\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Contents).MarshalEasyJSON(out)
+	}
+	if in.Range != nil {
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Range).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsHover) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc51(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsHover) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc51(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsHover) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc51(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsHover) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc51(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc52(in *jlexer.Lexer, out *LsFormattingOptions) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "tabSize":
+			out.TabSize = int(in.Int())
+		case "insertSpaces":
+			out.InsertSpaces = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc52(out *jwriter.Writer, in LsFormattingOptions) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"tabSize\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.TabSize))
+	}
+	{
+		const prefix string = ",\"insertSpaces\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.InsertSpaces))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsFormattingOptions) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc52(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsFormattingOptions) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc52(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsFormattingOptions) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc52(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsFormattingOptions) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc52(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc53(in *jlexer.Lexer, out *LsExecuteCommandParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "command":
+			out.Command = string(in.String())
+		case "arguments":
+			if in.IsNull() {
+				in.Skip()
+				out.Arguments = nil
+			} else {
+				in.Delim('[')
+				if out.Arguments == nil {
+					if !in.IsDelim(']') {
+						out.Arguments = make([]easyjson.RawMessage, 0, 2)
+					} else {
+						out.Arguments = []easyjson.RawMessage{}
+					}
+				} else {
+					out.Arguments = (out.Arguments)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v51 easyjson.RawMessage
+					(v51).UnmarshalEasyJSON(in)
+					out.Arguments = append(out.Arguments, v51)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc53(out *jwriter.Writer, in LsExecuteCommandParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"command\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Command))
+	}
+	if len(in.Arguments) != 0 {
+		const prefix string = ",\"arguments\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v52, v53 := range in.Arguments {
+				if v52 > 0 {
+					out.RawByte(',')
+				}
+				(v53).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsExecuteCommandParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc53(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsExecuteCommandParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc53(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsExecuteCommandParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc53(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsExecuteCommandParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc53(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc54(in *jlexer.Lexer, out *LsDocumentSymbolParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc54(out *jwriter.Writer, in LsDocumentSymbolParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDocumentSymbolParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc54(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDocumentSymbolParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc54(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDocumentSymbolParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc54(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDocumentSymbolParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc54(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc55(in *jlexer.Lexer, out *LsDocumentSymbolClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "symbolKind":
+			(out.SymbolKind).UnmarshalEasyJSON(in)
+		case "hierarchicalDocumentSymbolSupport":
+			out.HierarchicalDocumentSymbolSupport = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc55(out *jwriter.Writer, in LsDocumentSymbolClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"symbolKind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.SymbolKind).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"hierarchicalDocumentSymbolSupport\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.HierarchicalDocumentSymbolSupport))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDocumentSymbolClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc55(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDocumentSymbolClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc55(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDocumentSymbolClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc55(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDocumentSymbolClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc55(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc56(in *jlexer.Lexer, out *LsDocumentSymbol) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "detail":
+			out.Detail = string(in.String())
+		case "kind":
+			out.Kind = LsSymbolKind(in.Int())
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "selectionRange":
+			(out.SelectionRange).UnmarshalEasyJSON(in)
+		case "children":
+			if in.IsNull() {
+				in.Skip()
+				out.Children = nil
+			} else {
+				in.Delim('[')
+				if out.Children == nil {
+					if !in.IsDelim(']') {
+						out.Children = make([]LsDocumentSymbol, 0, 1)
+					} else {
+						out.Children = []LsDocumentSymbol{}
+					}
+				} else {
+					out.Children = (out.Children)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v54 LsDocumentSymbol
+					(v54).UnmarshalEasyJSON(in)
+					out.Children = append(out.Children, v54)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc56(out *jwriter.Writer, in LsDocumentSymbol) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Name))
+	}
+	if in.Detail != "" {
+		const prefix string = ",\"detail\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Detail))
+	}
+	{
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Kind))
+	}
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"selectionRange\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.SelectionRange).MarshalEasyJSON(out)
+	}
+	if len(in.Children) != 0 {
+		const prefix string = ",\"children\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v55, v56 := range in.Children {
+				if v55 > 0 {
+					out.RawByte(',')
+				}
+				(v56).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDocumentSymbol) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc56(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDocumentSymbol) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc56(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDocumentSymbol) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc56(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDocumentSymbol) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc56(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc57(in *jlexer.Lexer, out *LsDocumentRangeFormattingParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "options":
+			(out.Options).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc57(out *jwriter.Writer, in LsDocumentRangeFormattingParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"options\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Options).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDocumentRangeFormattingParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc57(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDocumentRangeFormattingParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc57(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDocumentRangeFormattingParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc57(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDocumentRangeFormattingParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc57(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc58(in *jlexer.Lexer, out *LsDocumentFormattingParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "options":
+			(out.Options).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc58(out *jwriter.Writer, in LsDocumentFormattingParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"options\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Options).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDocumentFormattingParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc58(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDocumentFormattingParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc58(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDocumentFormattingParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc58(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDocumentFormattingParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc58(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc59(in *jlexer.Lexer, out *LsDocumentChange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			if in.IsNull() {
+				in.Skip()
+				out.TextDocument = nil
+			} else {
+				if out.TextDocument == nil {
+					out.TextDocument = new(LsVersionedTextDocumentIdentifier)
+				}
+				(*out.TextDocument).UnmarshalEasyJSON(in)
+			}
+		case "edits":
+			if in.IsNull() {
+				in.Skip()
+				out.Edits = nil
+			} else {
+				in.Delim('[')
+				if out.Edits == nil {
+					if !in.IsDelim(']') {
+						out.Edits = make([]LsTextEdit, 0, 1)
+					} else {
+						out.Edits = []LsTextEdit{}
+					}
+				} else {
+					out.Edits = (out.Edits)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v57 LsTextEdit
+					(v57).UnmarshalEasyJSON(in)
+					out.Edits = append(out.Edits, v57)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "kind":
+			out.Kind = string(in.String())
+		case "uri":
+			out.URI = LsDocumentURI(in.String())
+		case "oldUri":
+			out.OldURI = LsDocumentURI(in.String())
+		case "newUri":
+			out.NewURI = LsDocumentURI(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc59(out *jwriter.Writer, in LsDocumentChange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.TextDocument != nil {
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.TextDocument).MarshalEasyJSON(out)
+	}
+	if len(in.Edits) != 0 {
+		const prefix string = ",\"edits\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v58, v59 := range in.Edits {
+				if v58 > 0 {
+					out.RawByte(',')
+				}
+				(v59).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Kind != "" {
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Kind))
+	}
+	if in.URI != "" {
+		const prefix string = ",\"uri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URI))
+	}
+	if in.OldURI != "" {
+		const prefix string = ",\"oldUri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.OldURI))
+	}
+	if in.NewURI != "" {
+		const prefix string = ",\"newUri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NewURI))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDocumentChange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc59(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDocumentChange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc59(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDocumentChange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc59(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDocumentChange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc59(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc60(in *jlexer.Lexer, out *LsDidOpenTextDocumentParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc60(out *jwriter.Writer, in LsDidOpenTextDocumentParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDidOpenTextDocumentParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc60(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDidOpenTextDocumentParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc60(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDidOpenTextDocumentParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc60(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDidOpenTextDocumentParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc60(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc61(in *jlexer.Lexer, out *LsDidCloseTextDocumentParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc61(out *jwriter.Writer, in LsDidCloseTextDocumentParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDidCloseTextDocumentParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc61(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDidCloseTextDocumentParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc61(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDidCloseTextDocumentParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc61(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDidCloseTextDocumentParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc61(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc62(in *jlexer.Lexer, out *LsDidChangeTextDocumentParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "contentChanges":
+			if in.IsNull() {
+				in.Skip()
+				out.ContentChanges = nil
+			} else {
+				in.Delim('[')
+				if out.ContentChanges == nil {
+					if !in.IsDelim(']') {
+						out.ContentChanges = make([]LsTextDocumentContentChangeEvent, 0, 4)
+					} else {
+						out.ContentChanges = []LsTextDocumentContentChangeEvent{}
+					}
+				} else {
+					out.ContentChanges = (out.ContentChanges)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v60 LsTextDocumentContentChangeEvent
+					(v60).UnmarshalEasyJSON(in)
+					out.ContentChanges = append(out.ContentChanges, v60)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc62(out *jwriter.Writer, in LsDidChangeTextDocumentParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"contentChanges\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.ContentChanges == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v61, v62 := range in.ContentChanges {
+				if v61 > 0 {
+					out.RawByte(',')
+				}
+				(v62).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDidChangeTextDocumentParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc62(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDidChangeTextDocumentParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc62(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDidChangeTextDocumentParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc62(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDidChangeTextDocumentParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc62(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc63(in *jlexer.Lexer, out *LsDidChangeConfigurationParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "settings":
+			(out.Settings).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc63(out *jwriter.Writer, in LsDidChangeConfigurationParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"settings\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Settings).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDidChangeConfigurationParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc63(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDidChangeConfigurationParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc63(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDidChangeConfigurationParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc63(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDidChangeConfigurationParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc63(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc64(in *jlexer.Lexer, out *LsDiagnosticRelatedInformation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "location":
+			(out.Location).UnmarshalEasyJSON(in)
+		case "message":
+			out.Message = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc64(out *jwriter.Writer, in LsDiagnosticRelatedInformation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"location\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Location).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDiagnosticRelatedInformation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc64(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDiagnosticRelatedInformation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc64(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDiagnosticRelatedInformation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc64(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDiagnosticRelatedInformation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc64(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc65(in *jlexer.Lexer, out *LsDiagnostic) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "severity":
+			out.Severity = LsDiagnosticSeverity(in.Int())
+		case "code":
+			(out.Code).UnmarshalEasyJSON(in)
+		case "source":
+			out.Source = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "codeDescription":
+			if in.IsNull() {
+				in.Skip()
+				out.CodeDescription = nil
+			} else {
+				if out.CodeDescription == nil {
+					out.CodeDescription = new(LsCodeDescription)
+				}
+				(*out.CodeDescription).UnmarshalEasyJSON(in)
+			}
+		case "relatedInformation":
+			if in.IsNull() {
+				in.Skip()
+				out.RelatedInformation = nil
+			} else {
+				in.Delim('[')
+				if out.RelatedInformation == nil {
+					if !in.IsDelim(']') {
+						out.RelatedInformation = make([]LsDiagnosticRelatedInformation, 0, 1)
+					} else {
+						out.RelatedInformation = []LsDiagnosticRelatedInformation{}
+					}
+				} else {
+					out.RelatedInformation = (out.RelatedInformation)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v63 LsDiagnosticRelatedInformation
+					(v63).UnmarshalEasyJSON(in)
+					out.RelatedInformation = append(out.RelatedInformation, v63)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc65(out *jwriter.Writer, in LsDiagnostic) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	if in.Severity != 0 {
+		const prefix string = ",\"severity\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Severity))
+	}
+	if (in.Code).IsDefined() {
+		const prefix string = ",\"code\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Code).MarshalEasyJSON(out)
+	}
+	if in.Source != "" {
+		const prefix string = ",\"source\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Source))
+	}
+	{
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	if in.CodeDescription != nil {
+		const prefix string = ",\"codeDescription\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.CodeDescription).MarshalEasyJSON(out)
+	}
+	if len(in.RelatedInformation) != 0 {
+		const prefix string = ",\"relatedInformation\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v64, v65 := range in.RelatedInformation {
+				if v64 > 0 {
+					out.RawByte(',')
+				}
+				(v65).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsDiagnostic) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc65(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsDiagnostic) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc65(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsDiagnostic) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc65(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsDiagnostic) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc65(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc66(in *jlexer.Lexer, out *LsConfigurationParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "items":
+			if in.IsNull() {
+				in.Skip()
+				out.Items = nil
+			} else {
+				in.Delim('[')
+				if out.Items == nil {
+					if !in.IsDelim(']') {
+						out.Items = make([]LsConfigurationItem, 0, 2)
+					} else {
+						out.Items = []LsConfigurationItem{}
+					}
+				} else {
+					out.Items = (out.Items)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v66 LsConfigurationItem
+					(v66).UnmarshalEasyJSON(in)
+					out.Items = append(out.Items, v66)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc66(out *jwriter.Writer, in LsConfigurationParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"items\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Items == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v67, v68 := range in.Items {
+				if v67 > 0 {
+					out.RawByte(',')
+				}
+				(v68).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsConfigurationParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc66(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsConfigurationParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc66(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsConfigurationParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc66(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsConfigurationParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc66(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc67(in *jlexer.Lexer, out *LsConfigurationItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scopeUri":
+			out.ScopeURI = LsDocumentURI(in.String())
+		case "section":
+			out.Section = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc67(out *jwriter.Writer, in LsConfigurationItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ScopeURI != "" {
+		const prefix string = ",\"scopeUri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ScopeURI))
+	}
+	if in.Section != "" {
+		const prefix string = ",\"section\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Section))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsConfigurationItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc67(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsConfigurationItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc67(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsConfigurationItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc67(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsConfigurationItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc67(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc68(in *jlexer.Lexer, out *LsCompletionList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "isIncomplete":
+			out.IsIncomplete = bool(in.Bool())
+		case "items":
+			if in.IsNull() {
+				in.Skip()
+				out.Items = nil
+			} else {
+				in.Delim('[')
+				if out.Items == nil {
+					if !in.IsDelim(']') {
+						out.Items = make([]LsCompletionItem, 0, 1)
+					} else {
+						out.Items = []LsCompletionItem{}
+					}
+				} else {
+					out.Items = (out.Items)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v69 LsCompletionItem
+					(v69).UnmarshalEasyJSON(in)
+					out.Items = append(out.Items, v69)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc68(out *jwriter.Writer, in LsCompletionList) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"isIncomplete\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.IsIncomplete))
+	}
+	{
+		const prefix string = ",\"items\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Items == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v70, v71 := range in.Items {
+				if v70 > 0 {
+					out.RawByte(',')
+				}
+				(v71).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCompletionList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc68(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCompletionList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc68(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCompletionList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc68(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCompletionList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc68(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc69(in *jlexer.Lexer, out *LsCompletionItemKindClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "valueSet":
+			if in.IsNull() {
+				in.Skip()
+				out.ValueSet = nil
+			} else {
+				in.Delim('[')
+				if out.ValueSet == nil {
+					if !in.IsDelim(']') {
+						out.ValueSet = make([]LsCompletionItemKind, 0, 8)
+					} else {
+						out.ValueSet = []LsCompletionItemKind{}
+					}
+				} else {
+					out.ValueSet = (out.ValueSet)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v72 LsCompletionItemKind
+					v72 = LsCompletionItemKind(in.Int())
+					out.ValueSet = append(out.ValueSet, v72)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc69(out *jwriter.Writer, in LsCompletionItemKindClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.ValueSet) != 0 {
+		const prefix string = ",\"valueSet\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v73, v74 := range in.ValueSet {
+				if v73 > 0 {
+					out.RawByte(',')
+				}
+				out.Int(int(v74))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCompletionItemKindClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc69(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCompletionItemKindClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc69(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCompletionItemKindClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc69(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCompletionItemKindClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc69(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc70(in *jlexer.Lexer, out *LsCompletionItemClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "snippetSupport":
+			out.SnippetSupport = bool(in.Bool())
+		case "documentationFormat":
+			if in.IsNull() {
+				in.Skip()
+				out.DocumentationFormat = nil
+			} else {
+				in.Delim('[')
+				if out.DocumentationFormat == nil {
+					if !in.IsDelim(']') {
+						out.DocumentationFormat = make([]LsMarkupKind, 0, 4)
+					} else {
+						out.DocumentationFormat = []LsMarkupKind{}
+					}
+				} else {
+					out.DocumentationFormat = (out.DocumentationFormat)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v75 LsMarkupKind
+					v75 = LsMarkupKind(in.String())
+					out.DocumentationFormat = append(out.DocumentationFormat, v75)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc70(out *jwriter.Writer, in LsCompletionItemClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"snippetSupport\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.SnippetSupport))
+	}
+	if len(in.DocumentationFormat) != 0 {
+		const prefix string = ",\"documentationFormat\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v76, v77 := range in.DocumentationFormat {
+				if v76 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v77))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCompletionItemClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc70(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCompletionItemClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc70(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCompletionItemClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc70(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCompletionItemClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc70(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc71(in *jlexer.Lexer, out *LsCompletionItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "label":
+			out.Label = string(in.String())
+		case "kind":
+			out.Kind = LsCompletionItemKind(in.Int())
+		case "detail":
+			out.Detail = string(in.String())
+		case "sortText":
+			out.SortText = string(in.String())
+		case "filterText":
+			out.FilterText = string(in.String())
+		case "insertText":
+			out.InsertText = string(in.String())
+		case "documentation":
+			(out.Documentation).UnmarshalEasyJSON(in)
+		case "data":
+			(out.Data).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc71(out *jwriter.Writer, in LsCompletionItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"label\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Label))
+	}
+	if in.Kind != 0 {
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Kind))
+	}
+	if in.Detail != "" {
+		const prefix string = ",\"detail\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Detail))
+	}
+	if in.SortText != "" {
+		const prefix string = ",\"sortText\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.SortText))
+	}
+	if in.FilterText != "" {
+		const prefix string = ",\"filterText\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.FilterText))
+	}
+	if in.InsertText != "" {
+		const prefix string = ",\"insertText\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.InsertText))
+	}
+	if (in.Documentation).IsDefined() {
+		const prefix string = ",\"documentation\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Documentation).MarshalEasyJSON(out)
+	}
+	if (in.Data).IsDefined() {
+		const prefix string = ",\"data\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Data).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCompletionItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc71(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCompletionItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc71(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCompletionItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc71(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCompletionItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc71(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc72(in *jlexer.Lexer, out *LsCompletionClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "completionItem":
+			(out.CompletionItem).UnmarshalEasyJSON(in)
+		case "completionItemKind":
+			(out.CompletionItemKind).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc72(out *jwriter.Writer, in LsCompletionClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"completionItem\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.CompletionItem).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"completionItemKind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.CompletionItemKind).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCompletionClientCapabilities) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc72(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCompletionClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc72(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCompletionClientCapabilities) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc72(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCompletionClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc72(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc73(in *jlexer.Lexer, out *LsCommand) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "title":
+			out.Title = string(in.String())
+		case "command":
+			out.Command = string(in.String())
+		case "arguments":
+			if in.IsNull() {
+				in.Skip()
+				out.Arguments = nil
+			} else {
+				in.Delim('[')
+				if out.Arguments == nil {
+					if !in.IsDelim(']') {
+						out.Arguments = make([]easyjson.RawMessage, 0, 2)
+					} else {
+						out.Arguments = []easyjson.RawMessage{}
+					}
+				} else {
+					out.Arguments = (out.Arguments)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v78 easyjson.RawMessage
+					(v78).UnmarshalEasyJSON(in)
+					out.Arguments = append(out.Arguments, v78)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc73(out *jwriter.Writer, in LsCommand) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"command\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Command))
+	}
+	if len(in.Arguments) != 0 {
+		const prefix string = ",\"arguments\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v79, v80 := range in.Arguments {
+				if v79 > 0 {
+					out.RawByte(',')
+				}
+				(v80).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCommand) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc73(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCommand) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc73(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCommand) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc73(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCommand) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc73(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc74(in *jlexer.Lexer, out *LsCodeDescription) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "href":
+			out.Href = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc74(out *jwriter.Writer, in LsCodeDescription) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"href\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Href))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCodeDescription) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc74(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCodeDescription) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc74(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCodeDescription) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc74(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCodeDescription) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc74(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc75(in *jlexer.Lexer, out *LsCodeActionParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		case "range":
+			(out.Range).UnmarshalEasyJSON(in)
+		case "context":
+			(out.Context).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc75(out *jwriter.Writer, in LsCodeActionParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"range\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Range).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"context\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Context).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCodeActionParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc75(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCodeActionParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc75(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCodeActionParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc75(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCodeActionParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc75(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc76(in *jlexer.Lexer, out *LsCodeActionDisabled) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "reason":
+			out.Reason = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc76(out *jwriter.Writer, in LsCodeActionDisabled) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"reason\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Reason))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCodeActionDisabled) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc76(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCodeActionDisabled) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc76(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCodeActionDisabled) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc76(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCodeActionDisabled) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc76(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc77(in *jlexer.Lexer, out *LsCodeActionContext) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "diagnostics":
+			if in.IsNull() {
+				in.Skip()
+				out.Diagnostics = nil
+			} else {
+				in.Delim('[')
+				if out.Diagnostics == nil {
+					if !in.IsDelim(']') {
+						out.Diagnostics = make([]LsDiagnostic, 0, 1)
+					} else {
+						out.Diagnostics = []LsDiagnostic{}
+					}
+				} else {
+					out.Diagnostics = (out.Diagnostics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v81 LsDiagnostic
+					(v81).UnmarshalEasyJSON(in)
+					out.Diagnostics = append(out.Diagnostics, v81)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "only":
+			if in.IsNull() {
+				in.Skip()
+				out.Only = nil
+			} else {
+				in.Delim('[')
+				if out.Only == nil {
+					if !in.IsDelim(']') {
+						out.Only = make([]string, 0, 4)
+					} else {
+						out.Only = []string{}
+					}
+				} else {
+					out.Only = (out.Only)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v82 string
+					v82 = string(in.String())
+					out.Only = append(out.Only, v82)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc77(out *jwriter.Writer, in LsCodeActionContext) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"diagnostics\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Diagnostics == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v83, v84 := range in.Diagnostics {
+				if v83 > 0 {
+					out.RawByte(',')
+				}
+				(v84).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Only) != 0 {
+		const prefix string = ",\"only\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v85, v86 := range in.Only {
+				if v85 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v86))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCodeActionContext) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc77(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCodeActionContext) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc77(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCodeActionContext) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc77(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCodeActionContext) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc77(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc78(in *jlexer.Lexer, out *LsCodeAction) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "title":
+			out.Title = string(in.String())
+		case "kind":
+			out.Kind = string(in.String())
+		case "diagnostics":
+			if in.IsNull() {
+				in.Skip()
+				out.Diagnostics = nil
+			} else {
+				in.Delim('[')
+				if out.Diagnostics == nil {
+					if !in.IsDelim(']') {
+						out.Diagnostics = make([]LsDiagnostic, 0, 1)
+					} else {
+						out.Diagnostics = []LsDiagnostic{}
+					}
+				} else {
+					out.Diagnostics = (out.Diagnostics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v87 LsDiagnostic
+					(v87).UnmarshalEasyJSON(in)
+					out.Diagnostics = append(out.Diagnostics, v87)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "isPreferred":
+			out.IsPreferred = bool(in.Bool())
+		case "disabled":
+			if in.IsNull() {
+				in.Skip()
+				out.Disabled = nil
+			} else {
+				if out.Disabled == nil {
+					out.Disabled = new(LsCodeActionDisabled)
+				}
+				(*out.Disabled).UnmarshalEasyJSON(in)
+			}
+		case "edit":
+			if in.IsNull() {
+				in.Skip()
+				out.Edit = nil
+			} else {
+				if out.Edit == nil {
+					out.Edit = new(LsWorkspaceEdit)
+				}
+				(*out.Edit).UnmarshalEasyJSON(in)
+			}
+		case "command":
+			(out.Command).UnmarshalEasyJSON(in)
+		case "arguments":
+			if in.IsNull() {
+				in.Skip()
+				out.Arguments = nil
+			} else {
+				in.Delim('[')
+				if out.Arguments == nil {
+					if !in.IsDelim(']') {
+						out.Arguments = make([]easyjson.RawMessage, 0, 2)
+					} else {
+						out.Arguments = []easyjson.RawMessage{}
+					}
+				} else {
+					out.Arguments = (out.Arguments)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v88 easyjson.RawMessage
+					(v88).UnmarshalEasyJSON(in)
+					out.Arguments = append(out.Arguments, v88)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "data":
+			(out.Data).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc78(out *jwriter.Writer, in LsCodeAction) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	if in.Kind != "" {
+		const prefix string = ",\"kind\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Kind))
+	}
+	if len(in.Diagnostics) != 0 {
+		const prefix string = ",\"diagnostics\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v89, v90 := range in.Diagnostics {
+				if v89 > 0 {
+					out.RawByte(',')
+				}
+				(v90).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.IsPreferred {
+		const prefix string = ",\"isPreferred\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.IsPreferred))
+	}
+	if in.Disabled != nil {
+		const prefix string = ",\"disabled\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Disabled).MarshalEasyJSON(out)
+	}
+	if in.Edit != nil {
+		const prefix string = ",\"edit\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Edit).MarshalEasyJSON(out)
+	}
+	if (in.Command).IsDefined() {
+		const prefix string = ",\"command\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Command).MarshalEasyJSON(out)
+	}
+	if len(in.Arguments) != 0 {
+		const prefix string = ",\"arguments\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v91, v92 := range in.Arguments {
+				if v91 > 0 {
+					out.RawByte(',')
+				}
+				(v92).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if (in.Data).IsDefined() {
+		const prefix string = ",\"data\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Data).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCodeAction) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc78(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCodeAction) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc78(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCodeAction) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc78(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCodeAction) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc78(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc79(in *jlexer.Lexer, out *LsClientCapabilities) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "workspace":
+			(out.Workspace).UnmarshalEasyJSON(in)
+		case "window":
+			(out.Window).UnmarshalEasyJSON(in)
+		case "textDocument":
+			(out.TextDocument).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc79(out *jwriter.Writer, in LsClientCapabilities) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"workspace\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Workspace).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"window\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Window).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"textDocument\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.TextDocument).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v LsInitializeParams) MarshalJSON() ([]byte, error) {
+func (v LsClientCapabilities) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC38a4abEncodeGithubComJacobdufaultLspc9(&w, v)
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc79(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v LsInitializeParams) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC38a4abEncodeGithubComJacobdufaultLspc9(w, v)
+func (v LsClientCapabilities) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc79(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *LsInitializeParams) UnmarshalJSON(data []byte) error {
+func (v *LsClientCapabilities) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(&r, v)
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc79(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *LsInitializeParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC38a4abDecodeGithubComJacobdufaultLspc9(l, v)
+func (v *LsClientCapabilities) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc79(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc80(in *jlexer.Lexer, out *LsCancelParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = RequestID(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc80(out *jwriter.Writer, in LsCancelParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.ID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsCancelParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc80(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsCancelParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc80(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsCancelParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc80(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsCancelParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc80(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc81(in *jlexer.Lexer, out *LsApplyWorkspaceEditResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "applied":
+			out.Applied = bool(in.Bool())
+		case "failureReason":
+			out.FailureReason = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc81(out *jwriter.Writer, in LsApplyWorkspaceEditResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"applied\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Applied))
+	}
+	if in.FailureReason != "" {
+		const prefix string = ",\"failureReason\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.FailureReason))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsApplyWorkspaceEditResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc81(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsApplyWorkspaceEditResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc81(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsApplyWorkspaceEditResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc81(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsApplyWorkspaceEditResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc81(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc82(in *jlexer.Lexer, out *LsApplyWorkspaceEditParams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "label":
+			out.Label = string(in.String())
+		case "edit":
+			(out.Edit).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc82(out *jwriter.Writer, in LsApplyWorkspaceEditParams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Label != "" {
+		const prefix string = ",\"label\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Label))
+	}
+	{
+		const prefix string = ",\"edit\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Edit).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LsApplyWorkspaceEditParams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc82(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LsApplyWorkspaceEditParams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc82(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LsApplyWorkspaceEditParams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc82(&r, v)
+	return r.Error()
 }
-func easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(in *jlexer.Lexer, out *JSONRPCHeader) {
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LsApplyWorkspaceEditParams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc82(l, v)
+}
+func easyjsonC38a4abDecodeGithubComJacobdufaultLspc83(in *jlexer.Lexer, out *JSONRPCHeader) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -874,6 +8469,18 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(in *jlexer.Lexer, out *JSO
 			out.ID = RequestID(in.Int())
 		case "params":
 			(out.Params).UnmarshalEasyJSON(in)
+		case "result":
+			(out.Result).UnmarshalEasyJSON(in)
+		case "error":
+			if in.IsNull() {
+				in.Skip()
+				out.Error = nil
+			} else {
+				if out.Error == nil {
+					out.Error = new(LsResponseError)
+				}
+				(*out.Error).UnmarshalEasyJSON(in)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -884,7 +8491,7 @@ func easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(in *jlexer.Lexer, out *JSO
 		in.Consumed()
 	}
 }
-func easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(out *jwriter.Writer, in JSONRPCHeader) {
+func easyjsonC38a4abEncodeGithubComJacobdufaultLspc83(out *jwriter.Writer, in JSONRPCHeader) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -898,7 +8505,7 @@ func easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(out *jwriter.Writer, in JS
 		}
 		out.String(string(in.JSONRPC))
 	}
-	{
+	if in.Method != "" {
 		const prefix string = ",\"method\":"
 		if first {
 			first = false
@@ -918,7 +8525,7 @@ func easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(out *jwriter.Writer, in JS
 		}
 		out.Int(int(in.ID))
 	}
-	{
+	if (in.Params).IsDefined() {
 		const prefix string = ",\"params\":"
 		if first {
 			first = false
@@ -928,29 +8535,49 @@ func easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(out *jwriter.Writer, in JS
 		}
 		(in.Params).MarshalEasyJSON(out)
 	}
+	if (in.Result).IsDefined() {
+		const prefix string = ",\"result\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Result).MarshalEasyJSON(out)
+	}
+	if in.Error != nil {
+		const prefix string = ",\"error\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Error).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v JSONRPCHeader) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(&w, v)
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc83(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v JSONRPCHeader) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC38a4abEncodeGithubComJacobdufaultLspc10(w, v)
+	easyjsonC38a4abEncodeGithubComJacobdufaultLspc83(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *JSONRPCHeader) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(&r, v)
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc83(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *JSONRPCHeader) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC38a4abDecodeGithubComJacobdufaultLspc10(l, v)
+	easyjsonC38a4abDecodeGithubComJacobdufaultLspc83(l, v)
 }
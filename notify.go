@@ -0,0 +1,69 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+func notifyShowMessage(l *languageServer, params LsShowMessageParams) {
+	switch params.Type {
+	case ErrorMessage:
+		sendDesktopNotification(fmt.Sprintf("%s error", l.name()), params.Message, true)
+	case WarningMessage:
+		sendDesktopNotification(fmt.Sprintf("%s warning", l.name()), params.Message, false)
+	}
+}
+
+func notifyProgressFinished(l *languageServer, progress *ProgressInfo) {
+	title := progress.Title
+	if title == "" {
+		title = "Background task"
+	}
+	message := fmt.Sprintf("%s finished in %s", title, l.directory)
+	if progress.Message != "" {
+		message += ": " + progress.Message
+	}
+	sendDesktopNotification(l.name(), message, false)
+}
+
+// sendDesktopNotification shows a notification using notify-send, or
+// osascript on macOS. It does not wait for the notification to be shown. A
+// variable so that tests can replace it.
+var sendDesktopNotification = func(title, message string, urgent bool) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// strconv.Quote output is a valid AppleScript string literal for
+		// typical messages.
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	} else {
+		urgency := "normal"
+		if urgent {
+			urgency = "critical"
+		}
+		cmd = exec.Command("notify-send", "--app-name=lspc", "--urgency="+urgency, title, message)
+	}
+
+	if e := cmd.Start(); e != nil {
+		log.Printf("Unable to show desktop notification: %s", e.Error())
+		return
+	}
+	go cmd.Wait()
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+// desktopNotification is a notification recorded instead of being shown.
+type desktopNotification struct {
+	title, message string
+	urgent         bool
+}
+
+// recordNotifications makes gNotify notifications append to the returned
+// slice until the returned function restores the previous behavior.
+func recordNotifications() (*[]desktopNotification, func()) {
+	notify, send := gNotify, sendDesktopNotification
+	var sent []desktopNotification
+	gNotify = true
+	sendDesktopNotification = func(title, message string, urgent bool) {
+		sent = append(sent, desktopNotification{title, message, urgent})
+	}
+	return &sent, func() {
+		gNotify, sendDesktopNotification = notify, send
+	}
+}
+
+func newNotifyingServer() *languageServer {
+	l := newTestLanguageServer(1, "/src/project")
+	l.args = []string{"/usr/bin/clangd"}
+	l.cmd = &exec.Cmd{Process: &os.Process{Pid: 1234}}
+	return l
+}
+
+func TestNotifyShowMessage(t *testing.T) {
+	sent, restore := recordNotifications()
+	defer restore()
+	l := newNotifyingServer()
+
+	for _, message := range []string{
+		`{"type": 1, "message": "crashed"}`,
+		`{"type": 2, "message": "no compile_commands.json"}`,
+		`{"type": 3, "message": "indexing"}`,
+		`{"type": 4, "message": "verbose"}`,
+	} {
+		l.handleNotification("window/showMessage", easyjson.RawMessage(message))
+	}
+	// Only errors and warnings are shown, and only errors are urgent.
+	assert.Equal(t, []desktopNotification{
+		{"clangd error", "crashed", true},
+		{"clangd warning", "no compile_commands.json", false},
+	}, *sent)
+
+	*sent = nil
+	gNotify = false
+	l.handleNotification("window/showMessage", easyjson.RawMessage(`{"type": 1, "message": "crashed"}`))
+	assert.Empty(t, *sent)
+}
+
+func TestNotifyProgressFinished(t *testing.T) {
+	sent, restore := recordNotifications()
+	defer restore()
+	l := newNotifyingServer()
+
+	progress := func(token, value string) {
+		l.handleNotification("$/progress", easyjson.RawMessage(`{"token": "`+token+`", "value": `+value+`}`))
+	}
+	progress("index", `{"kind": "begin", "title": "Indexing", "percentage": 0}`)
+	progress("index", `{"kind": "report", "percentage": 50}`)
+	assert.Empty(t, *sent)
+	progress("index", `{"kind": "end", "message": "42 files"}`)
+
+	// Untitled tasks are named generically.
+	progress("load", `{"kind": "begin"}`)
+	progress("load", `{"kind": "end"}`)
+
+	// The end of a task that never began is not shown.
+	progress("unknown", `{"kind": "end"}`)
+
+	assert.Equal(t, []desktopNotification{
+		{"clangd", "Indexing finished in /src/project: 42 files", false},
+		{"clangd", "Background task finished in /src/project", false},
+	}, *sent)
+
+	*sent = nil
+	gNotify = false
+	progress("index", `{"kind": "begin", "title": "Indexing"}`)
+	progress("index", `{"kind": "end"}`)
+	assert.Empty(t, *sent)
+}
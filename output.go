@@ -0,0 +1,161 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"text/tabwriter"
+)
+
+// Verbosity levels of the client, set with the global --quiet, -v and -vv
+// flags.
+const (
+	// Only results and errors.
+	verbosityQuiet = -1
+	// Also notices about results, ie, that a list is incomplete.
+	verbosityNormal = 0
+	// Also what the client does, ie, the daemon requests it sends and how
+	// long they take.
+	verbosityVerbose = 1
+	// Also the arguments and replies of daemon requests.
+	verbosityDebug = 2
+)
+
+var gPlain bool
+var gJSON bool
+var gQuiet bool
+var gVerbose bool
+var gDebug bool
+var gVerbosity = verbosityNormal
+
+// setVerbosity sets gVerbosity from the global flags.
+func setVerbosity(quiet, verbose, debug bool) error {
+	switch {
+	case quiet && (verbose || debug):
+		return fmt.Errorf("--quiet cannot be combined with -v or -vv")
+	case quiet:
+		gVerbosity = verbosityQuiet
+	case debug:
+		gVerbosity = verbosityDebug
+	case verbose:
+		gVerbosity = verbosityVerbose
+	default:
+		gVerbosity = verbosityNormal
+	}
+	return nil
+}
+
+// logf prints a message to stderr if the verbosity is at least level.
+func logf(level int, format string, args ...interface{}) {
+	if gVerbosity >= level {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// notef prints a notice about the output of a command to stderr unless
+// --quiet is set.
+func notef(format string, args ...interface{}) {
+	logf(verbosityNormal, format, args...)
+}
+
+// useColor returns true if output to f may use ANSI colors: f is a terminal,
+// and neither --plain nor $NO_COLOR is set.
+func useColor(f *os.File) bool {
+	return !gPlain && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
+
+// printJSON prints the result of a command as one line of JSON for --json. Nil
+// slices and maps are printed as empty ones instead of null, so that scripts
+// need not tell "no results" apart.
+func printJSON(v interface{}) error {
+	return writeJSON(os.Stdout, v)
+}
+
+// writeJSON is printJSON writing to out.
+func writeJSON(out io.Writer, v interface{}) error {
+	switch value := reflect.ValueOf(v); {
+	case value.Kind() == reflect.Slice && value.IsNil():
+		v = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	case value.Kind() == reflect.Map && value.IsNil():
+		v = reflect.MakeMap(value.Type()).Interface()
+	}
+	return json.NewEncoder(out).Encode(v)
+}
+
+// printJSONError prints err for --json as the HTTP gateway reports errors.
+func printJSONError(err error) {
+	printJSON(map[string]string{"error": err.Error()})
+}
+
+// outputFormat returns format, the value of the --format flag of a command
+// which can print JSON itself, or the command's json format if --json is set.
+func outputFormat(format, json string) string {
+	if gJSON {
+		return json
+	}
+	return format
+}
+
+// textOutput is where a command prints messages about its progress: stdout,
+// or stderr with --json so that only JSON is printed to stdout.
+func textOutput() io.Writer {
+	if gJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// debugValue formats the arguments or reply of a daemon request for -vv.
+func debugValue(v interface{}) string {
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// useIcons returns true if nerd font icons were requested and may be printed,
+// which --plain prevents.
+func useIcons(requested bool) bool {
+	return requested && !gPlain
+}
+
+// table lays out tab separated columns.
+type table interface {
+	io.Writer
+	Flush() error
+}
+
+// plainTable leaves columns tab separated.
+type plainTable struct {
+	io.Writer
+}
+
+func (plainTable) Flush() error {
+	return nil
+}
+
+// newTable returns a table writing to out. Columns are padded with spaces to
+// line up, except with --plain, where they stay separated by single tabs so
+// that their layout does not depend on the other rows.
+func newTable(out io.Writer) table {
+	if gPlain {
+		return plainTable{out}
+	}
+	return tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetVerbosity(t *testing.T) {
+	defer setVerbosity(false, false, false)
+
+	assert.NoError(t, setVerbosity(false, false, false))
+	assert.Equal(t, verbosityNormal, gVerbosity)
+	assert.NoError(t, setVerbosity(true, false, false))
+	assert.Equal(t, verbosityQuiet, gVerbosity)
+	assert.NoError(t, setVerbosity(false, true, false))
+	assert.Equal(t, verbosityVerbose, gVerbosity)
+	assert.NoError(t, setVerbosity(false, true, true))
+	assert.Equal(t, verbosityDebug, gVerbosity)
+	assert.Error(t, setVerbosity(true, false, true))
+}
+
+func TestPlainOutput(t *testing.T) {
+	defer func() { gPlain = false }()
+
+	render := func() string {
+		var out bytes.Buffer
+		w := newTable(&out)
+		fmt.Fprintf(w, "a\tb\n")
+		fmt.Fprintf(w, "longer\tc\n")
+		w.Flush()
+		return out.String()
+	}
+	assert.Equal(t, "a       b\nlonger  c\n", render())
+	assert.True(t, useIcons(true))
+
+	gPlain = true
+	assert.Equal(t, "a\tb\nlonger\tc\n", render())
+	assert.False(t, useIcons(true))
+	assert.Equal(t, "function", (&Config{}).kindLabel("function", useIcons(true)))
+}
+
+func TestJSONOutput(t *testing.T) {
+	defer func() { gJSON = false }()
+
+	write := func(v interface{}) string {
+		var out bytes.Buffer
+		assert.NoError(t, writeJSON(&out, v))
+		return out.String()
+	}
+	assert.Equal(t, "[]\n", write([]Location(nil)))
+	assert.Equal(t, "{}\n", write(map[string]string(nil)))
+	assert.Equal(t, `[{"file":"a.c","line":1,"column":2,"end_line":1,"end_column":3}]`+"\n", write([]Location{{"a.c", 1, 2, 1, 3}}))
+
+	assert.Equal(t, referencesGrep, outputFormat(referencesGrep, referencesJSON))
+	assert.Equal(t, os.Stdout, textOutput())
+	gJSON = true
+	assert.Equal(t, referencesJSON, outputFormat(referencesGrep, referencesJSON))
+	assert.Equal(t, os.Stderr, textOutput())
+}
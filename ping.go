@@ -0,0 +1,149 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// How long to wait for a language server to answer a ping.
+const pingTimeout = 5 * time.Second
+
+// Language servers do not implement this method, so they answer it right away
+// with a MethodNotFound error. That is all we need to measure round-trip time.
+const pingMethod = "$/lspc/ping"
+
+// PingArgs holds arguments for PingServers.
+type PingArgs struct {
+	// Ids of the language servers to ping. If empty every server is pinged.
+	IDs []int
+}
+
+// PingResult is the outcome of pinging a single language server.
+type PingResult struct {
+	ID        int
+	Directory string
+	RTT       time.Duration
+	// Empty if the server responded.
+	Err string
+}
+
+func (r PingResult) String() string {
+	if r.Err != "" {
+		return fmt.Sprintf("%d (%s): %s", r.ID, r.Directory, r.Err)
+	}
+	return fmt.Sprintf("%d (%s): %s", r.ID, r.Directory, r.RTT)
+}
+
+// PingReport is what ping prints with --json. Times are in nanoseconds.
+type PingReport struct {
+	Daemon  time.Duration
+	Servers []PingResult `json:",omitempty"`
+}
+
+// Ping does nothing; it lets the client measure round-trip time to the daemon.
+func (s *Server) Ping(_ bool, _ *bool) error {
+	log.Print("CMD ping")
+	return nil
+}
+
+// PingServers sends a cheap request to language servers and reports how long
+// each one took to respond.
+func (s *Server) PingServers(args PingArgs, results *[]PingResult) error {
+	log.Printf("CMD ping-servers %v", args.IDs)
+
+	servers := s.serverList()
+	if len(args.IDs) > 0 {
+		servers = nil
+		for _, id := range args.IDs {
+			server, err := s.findServer(id)
+			if err != nil {
+				return err
+			}
+			servers = append(servers, server)
+		}
+	}
+
+	for _, server := range servers {
+		result := PingResult{ID: server.id, Directory: server.directory}
+		start := time.Now()
+		_, err := server.call(pingMethod, nil, pingTimeout)
+		result.RTT = time.Since(start)
+		// A MethodNotFound error is the expected answer, but pending requests
+		// are also failed with a response error when the server exits.
+		select {
+		case <-server.done:
+			result.Err = fmt.Sprintf("exited (%s)", server.exitStatus)
+		default:
+			if _, isResponse := err.(*LsResponseError); err != nil && !isResponse {
+				result.Err = err.Error()
+			}
+		}
+		*results = append(*results, result)
+	}
+	return nil
+}
+
+// ping implements the ping command. servers contains language server ids or
+// "all".
+func ping(servers []string) error {
+	start := time.Now()
+	doRPC("Server.Ping", false, nil)
+	daemon := time.Since(start)
+	if !gJSON {
+		fmt.Printf("daemon: %s\n", daemon)
+	}
+
+	if len(servers) == 0 {
+		if gJSON {
+			return printJSON(PingReport{Daemon: daemon})
+		}
+		return nil
+	}
+
+	args, err := pingArgs(servers)
+	if err != nil {
+		return err
+	}
+	var results []PingResult
+	doRPC("Server.PingServers", args, &results)
+	if gJSON {
+		return printJSON(PingReport{Daemon: daemon, Servers: results})
+	}
+	for _, result := range results {
+		fmt.Println(result)
+	}
+	return nil
+}
+
+// pingArgs returns the PingArgs for the --server values of ping, which are
+// language server ids or "all".
+func pingArgs(servers []string) (PingArgs, error) {
+	args := PingArgs{}
+	for _, server := range servers {
+		if server == "all" {
+			return PingArgs{}, nil
+		}
+		id, err := strconv.Atoi(server)
+		if err != nil {
+			return PingArgs{}, fmt.Errorf("invalid server id %q", server)
+		}
+		args.IDs = append(args.IDs, id)
+	}
+	return args, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jacobdufault/lspc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPingedServer returns a language server that answers every request with
+// MethodNotFound, as language servers answer pings.
+func newPingedServer(t *testing.T, id int, directory string) *languageServer {
+	l := &languageServer{
+		id:          id,
+		directory:   directory,
+		onResponse:  make(map[RequestID]responseHandler),
+		stats:       newServerStats(),
+		initialized: true,
+	}
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	l.stdin, l.stdout = stdinWriter, stdoutReader
+	go func() {
+		scanner := bufio.NewScanner(stdinReader)
+		scanner.Split(jsonrpc.SplitFunc)
+		for scanner.Scan() {
+			var request JSONRPCHeader
+			assert.NoError(t, request.UnmarshalJSON(scanner.Bytes()))
+			response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"unknown method"}}`, request.ID)
+			fmt.Fprintf(stdoutWriter, "Content-Length: %d\r\n\r\n%s", len(response), response)
+		}
+		stdoutWriter.Close()
+	}()
+	l.readers.Add(1)
+	go l.stdoutReader()
+	return l
+}
+
+func TestPingServers(t *testing.T) {
+	defer func(size int) { gMaxMessageSize = size }(gMaxMessageSize)
+	gMaxMessageSize = 1
+
+	first, second := newPingedServer(t, 0, "/a"), newPingedServer(t, 1, "/b")
+	defer first.stdin.Close()
+	defer second.stdin.Close()
+	s := &Server{servers: []*languageServer{first, second}}
+
+	// MethodNotFound is the expected answer.
+	var results []PingResult
+	assert.NoError(t, s.PingServers(PingArgs{}, &results))
+	assert.Len(t, results, 2)
+	for i, result := range results {
+		assert.Equal(t, i, result.ID)
+		assert.Empty(t, result.Err)
+	}
+
+	results = nil
+	assert.NoError(t, s.PingServers(PingArgs{IDs: []int{1}}, &results))
+	assert.Len(t, results, 1)
+	assert.Equal(t, "/b", results[0].Directory)
+
+	assert.Error(t, s.PingServers(PingArgs{IDs: []int{1, 2}}, &results))
+}
+
+func TestPingArgs(t *testing.T) {
+	args, err := pingArgs([]string{"0", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, PingArgs{IDs: []int{0, 2}}, args)
+
+	// all pings every server, whatever else is given.
+	args, err = pingArgs([]string{"1", "all"})
+	assert.NoError(t, err)
+	assert.Empty(t, args.IDs)
+
+	_, err = pingArgs([]string{"clangd"})
+	assert.EqualError(t, err, `invalid server id "clangd"`)
+}
+
+func TestPingResultString(t *testing.T) {
+	assert.Equal(t, "0 (/src): 1.5ms", PingResult{ID: 0, Directory: "/src", RTT: 1500 * time.Microsecond}.String())
+	assert.Equal(t, "1 (/py): signal: killed", PingResult{ID: 1, Directory: "/py", RTT: time.Second, Err: "signal: killed"}.String())
+}
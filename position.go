@@ -0,0 +1,113 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ColumnUnit is what a column counts. LSP positions count UTF-16 code units,
+// which differs from bytes and runes as soon as a line contains non-ASCII
+// text.
+type ColumnUnit string
+
+const (
+	ByteColumns  ColumnUnit = "byte"
+	RuneColumns  ColumnUnit = "rune"
+	UTF16Columns ColumnUnit = "utf-16"
+)
+
+func parseColumnUnit(s string) (ColumnUnit, error) {
+	switch unit := ColumnUnit(s); unit {
+	case ByteColumns, RuneColumns, UTF16Columns:
+		return unit, nil
+	}
+	return "", fmt.Errorf("unknown column unit %q; expected byte, rune or utf-16", s)
+}
+
+// lineAt returns line (0-based) of text without the line terminator, or an
+// empty string if text has fewer lines.
+func lineAt(text string, line int) string {
+	for ; line > 0; line-- {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			return ""
+		}
+		text = text[i+1:]
+	}
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSuffix(text, "\r")
+}
+
+// toUTF16 converts pos, whose column is in unit, to an LSP position using
+// the document text. Columns past the end of the line are kept past the end.
+func toUTF16(text string, pos LsPosition, unit ColumnUnit) LsPosition {
+	if unit == UTF16Columns {
+		return pos
+	}
+	line := lineAt(text, pos.Line)
+	units, column := 0, 0
+	for i, r := range line {
+		if unit == ByteColumns && i >= pos.Character || unit == RuneColumns && column >= pos.Character {
+			return LsPosition{Line: pos.Line, Character: units}
+		}
+		units += utf16Len(r)
+		column++
+	}
+	// Past the end; every column there is a single unit.
+	if unit == ByteColumns {
+		column = len(line)
+	}
+	return LsPosition{Line: pos.Line, Character: units + pos.Character - column}
+}
+
+// fromUTF16 converts the LSP position pos to one whose column is in unit
+// using the document text.
+func fromUTF16(text string, pos LsPosition, unit ColumnUnit) LsPosition {
+	if unit == UTF16Columns {
+		return pos
+	}
+	line := lineAt(text, pos.Line)
+	units, column := 0, 0
+	for _, r := range line {
+		if units >= pos.Character {
+			return LsPosition{Line: pos.Line, Character: column}
+		}
+		units += utf16Len(r)
+		if unit == ByteColumns {
+			column += utf8.RuneLen(r)
+		} else {
+			column++
+		}
+	}
+	return LsPosition{Line: pos.Line, Character: column + pos.Character - units}
+}
+
+// fromUTF16Range converts both ends of r, see fromUTF16.
+func fromUTF16Range(text string, r LsRange, unit ColumnUnit) LsRange {
+	return LsRange{Start: fromUTF16(text, r.Start, unit), End: fromUTF16(text, r.End, unit)}
+}
+
+func utf16Len(r rune) int {
+	if r1, _ := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		return 2
+	}
+	return 1
+}
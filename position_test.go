@@ -0,0 +1,67 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineAt(t *testing.T) {
+	text := "a\r\nbc\n\nd"
+	assert.Equal(t, "a", lineAt(text, 0))
+	assert.Equal(t, "bc", lineAt(text, 1))
+	assert.Equal(t, "", lineAt(text, 2))
+	assert.Equal(t, "d", lineAt(text, 3))
+	assert.Equal(t, "", lineAt(text, 4))
+}
+
+func TestColumnConversion(t *testing.T) {
+	// 😀 is 4 bytes, 1 rune and 2 UTF-16 units; 中 is 3 bytes, 1 rune and 1
+	// UTF-16 unit.
+	text := "first\nx😀中y = 1;\n"
+	pos := func(line, character int) LsPosition {
+		return LsPosition{Line: line, Character: character}
+	}
+
+	// y
+	assert.Equal(t, pos(1, 4), toUTF16(text, pos(1, 8), ByteColumns))
+	assert.Equal(t, pos(1, 4), toUTF16(text, pos(1, 3), RuneColumns))
+	assert.Equal(t, pos(1, 4), toUTF16(text, pos(1, 4), UTF16Columns))
+	assert.Equal(t, pos(1, 8), fromUTF16(text, pos(1, 4), ByteColumns))
+	assert.Equal(t, pos(1, 3), fromUTF16(text, pos(1, 4), RuneColumns))
+
+	// Lines without multi-unit characters are unchanged.
+	assert.Equal(t, pos(0, 3), toUTF16(text, pos(0, 3), ByteColumns))
+	assert.Equal(t, pos(0, 3), fromUTF16(text, pos(0, 3), RuneColumns))
+
+	// End of line and past it.
+	assert.Equal(t, pos(1, 10), toUTF16(text, pos(1, 14), ByteColumns))
+	assert.Equal(t, pos(1, 13), toUTF16(text, pos(1, 17), ByteColumns))
+	assert.Equal(t, pos(1, 17), fromUTF16(text, pos(1, 13), ByteColumns))
+	assert.Equal(t, pos(3, 2), toUTF16(text, pos(3, 2), RuneColumns))
+
+	assert.Equal(t, LsRange{Start: pos(1, 1), End: pos(1, 5)},
+		fromUTF16Range(text, LsRange{Start: pos(1, 1), End: pos(1, 3)}, ByteColumns))
+}
+
+func TestParseColumnUnit(t *testing.T) {
+	unit, err := parseColumnUnit("rune")
+	assert.NoError(t, err)
+	assert.Equal(t, RuneColumns, unit)
+	_, err = parseColumnUnit("utf-8")
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+)
+
+// Exit status of lspc prepare-rename if the position cannot be renamed.
+const exitNotRenameable = 3
+
+// PrepareRename is the result of textDocument/prepareRename as shown to
+// users.
+type PrepareRename struct {
+	Renameable bool `json:"renameable"`
+	// Why the position cannot be renamed, if the server said.
+	Reason string `json:"reason,omitempty"`
+	// The range that would be renamed. Not set if the server renames the
+	// word at the position as a client would.
+	Range       *Location `json:"range,omitempty"`
+	Placeholder string    `json:"placeholder,omitempty"`
+}
+
+func (p PrepareRename) String() string {
+	if !p.Renameable {
+		return "not renameable"
+	}
+	if p.Range == nil {
+		return "renameable"
+	}
+	s := fmt.Sprintf("%s-%d:%d", p.Range, p.Range.EndLine, p.Range.EndColumn)
+	if p.Placeholder != "" {
+		s += " " + p.Placeholder
+	}
+	return s
+}
+
+// prepareRename asks the server whether the symbol at the 1-based line and
+// column of path can be renamed.
+func (l *languageServer) prepareRename(path string, line, column int, unit ColumnUnit) (PrepareRename, error) {
+	params, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return PrepareRename{}, err
+	}
+	result, err := l.call("textDocument/prepareRename", toJSON(params), queryTimeout())
+	if err != nil {
+		// Servers may explain why a position cannot be renamed with an
+		// error.
+		if e, isResponse := err.(*LsResponseError); isResponse && e.Code != MethodNotFound {
+			return PrepareRename{Reason: e.Message}, nil
+		}
+		return PrepareRename{}, err
+	}
+
+	// The result is a Range, {range, placeholder}, {defaultBehavior} or
+	// null. LsRange is not embedded since its UnmarshalJSON would be
+	// promoted.
+	var r struct {
+		Start           LsPosition `json:"start"`
+		End             LsPosition `json:"end"`
+		Range           *LsRange   `json:"range"`
+		Placeholder     string     `json:"placeholder"`
+		DefaultBehavior bool       `json:"defaultBehavior"`
+	}
+	if string(result) == "null" {
+		return PrepareRename{}, nil
+	}
+	if err := json.Unmarshal(result, &r); err != nil {
+		return PrepareRename{}, fmt.Errorf("cannot parse prepareRename result: %s", err.Error())
+	}
+	out := PrepareRename{Renameable: true, Placeholder: r.Placeholder}
+	switch {
+	case r.Range != nil:
+		location := l.userRange(path, *r.Range, unit)
+		out.Range = &location
+	case !r.DefaultBehavior:
+		location := l.userRange(path, LsRange{Start: r.Start, End: r.End}, unit)
+		out.Range = &location
+	}
+	return out, nil
+}
+
+// PrepareRename reports whether the symbol at a position can be renamed.
+func (s *Server) PrepareRename(args PositionArgs, result *PrepareRename) error {
+	log.Printf("CMD prepare-rename %s:%d:%d", args.File, args.Line, args.Column)
+	server, err := s.serverForPosition(args, "textDocument/prepareRename")
+	if err != nil {
+		return err
+	}
+	*result, err = server.prepareRename(args.File, args.Line, args.Column, args.Unit)
+	return err
+}
+
+func (s *Server) httpPrepareRename(query url.Values) (interface{}, error) {
+	position, server, err := s.queryPosition(query, "textDocument/prepareRename")
+	if err != nil {
+		return nil, err
+	}
+	return server.prepareRename(position.File, position.Line, position.Column, position.Unit)
+}
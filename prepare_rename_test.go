@@ -0,0 +1,29 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareRenameString(t *testing.T) {
+	assert.Equal(t, "not renameable", PrepareRename{Reason: "keyword"}.String())
+	assert.Equal(t, "renameable", PrepareRename{Renameable: true}.String())
+	location := &Location{File: "/a.c", Line: 2, Column: 1, EndLine: 2, EndColumn: 4}
+	assert.Equal(t, "/a.c:2:1-2:4 foo", PrepareRename{Renameable: true, Range: location, Placeholder: "foo"}.String())
+	assert.Equal(t, "/a.c:2:1-2:4", PrepareRename{Renameable: true, Range: location}.String())
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// closeFilesCommandName is the hidden command closeFilesCommand runs
+// language servers with.
+const closeFilesCommandName = "__exec-closing-files"
+
+// processFor returns the process settings for servers of language. Settings
+// of the language override the global ones.
+func (c *Config) processFor(language string) ProcessConfig {
+	process := c.Process
+	own := c.Languages[language].Process
+	if own.Umask != "" {
+		process.Umask = own.Umask
+	}
+	if own.User != "" {
+		process.User = own.User
+	}
+	if own.Group != "" {
+		process.Group = own.Group
+	}
+	process.CloseFds = process.CloseFds || own.CloseFds
+	return process
+}
+
+// validate returns the problems with p keyed by setting name. The user and
+// group must exist on this machine, and the umask must be supported by it.
+func (p ProcessConfig) validate() map[string]error {
+	problems := make(map[string]error)
+	if p.Umask != "" {
+		if _, err := umaskCommand(p.Umask, nil); err != nil {
+			problems["umask"] = err
+		}
+	}
+	if p.User != "" {
+		if _, err := lookupUser(p.User); err != nil {
+			problems["user"] = err
+		}
+	}
+	if p.Group != "" {
+		if _, err := lookupGroup(p.Group); err != nil {
+			problems["group"] = err
+		}
+	}
+	return problems
+}
+
+func parseUmask(umask string) (int, error) {
+	value, err := strconv.ParseUint(umask, 8, 32)
+	if err != nil || value > 0777 {
+		return 0, fmt.Errorf("%q is not an octal umask", umask)
+	}
+	return int(value), nil
+}
+
+// lookupUser finds a user by name or id.
+func lookupUser(name string) (*user.User, error) {
+	if _, err := strconv.Atoi(name); err == nil {
+		return user.LookupId(name)
+	}
+	return user.Lookup(name)
+}
+
+// lookupGroup finds a group by name or id.
+func lookupGroup(name string) (*user.Group, error) {
+	if _, err := strconv.Atoi(name); err == nil {
+		return user.LookupGroupId(name)
+	}
+	return user.LookupGroup(name)
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessFor(t *testing.T) {
+	config := &Config{
+		Process: ProcessConfig{Umask: "022", User: "lsp"},
+		Languages: map[string]LanguageConfig{
+			"cpp": {Process: ProcessConfig{Umask: "077", CloseFds: true}},
+		},
+	}
+	assert.Equal(t, ProcessConfig{Umask: "022", User: "lsp"}, config.processFor("go"))
+	assert.Equal(t, ProcessConfig{Umask: "077", User: "lsp", CloseFds: true}, config.processFor("cpp"))
+}
+
+func TestValidateProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		assert.Contains(t, ProcessConfig{Umask: "027"}.validate(), "umask")
+	} else {
+		assert.Empty(t, ProcessConfig{Umask: "027"}.validate())
+	}
+
+	problems := ProcessConfig{Umask: "999", User: "lspc-no-such-user", Group: "lspc-no-such-group"}.validate()
+	assert.EqualError(t, problems["umask"], `"999" is not an octal umask`)
+	assert.Error(t, problems["user"])
+	assert.Error(t, problems["group"])
+
+	umask, err := parseUmask("0027")
+	assert.NoError(t, err)
+	assert.Equal(t, 027, umask)
+	_, err = parseUmask("1777")
+	assert.Error(t, err)
+}
+
+func TestUmaskCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("umask is not supported on windows")
+	}
+	argv, err := umaskCommand("027", []string{"sh", "-c", "umask; echo $0", "arg"})
+	assert.NoError(t, err)
+	output, err := exec.Command(argv[0], argv[1:]...).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0027", "arg"}, strings.Fields(string(output)))
+}
+
+func TestCloseFilesCommand(t *testing.T) {
+	argv, err := closeFilesCommand([]string{"clangd", "--log=error"})
+	assert.NoError(t, err)
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, []string{"clangd", "--log=error"}, argv)
+		return
+	}
+	lspc, err := os.Executable()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{lspc, closeFilesCommandName, "clangd", "--log=error"}, argv)
+	assert.EqualError(t, execClosingFiles(nil), "no program")
+}
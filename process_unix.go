@@ -0,0 +1,147 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"syscall"
+)
+
+// umaskCommand returns the command line which runs argv with umask. The
+// process of a language server inherits the umask of the daemon otherwise,
+// and the umask cannot be set for a child alone, so a shell sets it.
+func umaskCommand(umask string, argv []string) ([]string, error) {
+	if _, err := parseUmask(umask); err != nil {
+		return nil, err
+	}
+	return append([]string{"/bin/sh", "-c", `umask "$0" && exec "$@"`, umask}, argv...), nil
+}
+
+// setCredentials makes cmd run as userName and groupName, which are names or
+// numeric ids. Either may be empty.
+func setCredentials(cmd *exec.Cmd, userName, groupName string) error {
+	if userName == "" && groupName == "" {
+		return nil
+	}
+	credential := &syscall.Credential{
+		Uid: uint32(syscall.Getuid()),
+		Gid: uint32(syscall.Getgid()),
+	}
+	if userName != "" {
+		u, err := lookupUser(userName)
+		if err != nil {
+			return err
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		credential.Uid, credential.Gid = uint32(uid), uint32(gid)
+		// Otherwise the server keeps the supplementary groups of the daemon.
+		groups, err := u.GroupIds()
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			if id, err := strconv.ParseUint(group, 10, 32); err == nil {
+				credential.Groups = append(credential.Groups, uint32(id))
+			}
+		}
+	}
+	if groupName != "" {
+		g, err := lookupGroup(groupName)
+		if err != nil {
+			return err
+		}
+		gid, _ := strconv.ParseUint(g.Gid, 10, 32)
+		credential.Gid = uint32(gid)
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = credential
+	return nil
+}
+
+// closeFilesCommand returns the command line which runs argv without the
+// file descriptors the daemon inherited from whatever started it. Descriptors
+// opened by Go are close-on-exec already. Marking the inherited ones would
+// affect every server started afterwards, so lspc itself is run in between
+// and marks them in its own process before executing argv.
+func closeFilesCommand(argv []string) ([]string, error) {
+	lspc, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{lspc, closeFilesCommandName}, argv...), nil
+}
+
+// execClosingFiles replaces this process with argv after marking every open
+// file descriptor above stderr close-on-exec.
+func execClosingFiles(argv []string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("no program")
+	}
+	entries, err := ioutil.ReadDir("/proc/self/fd")
+	if err != nil {
+		entries, err = ioutil.ReadDir("/dev/fd")
+		if err != nil {
+			return err
+		}
+	}
+	for _, entry := range entries {
+		if fd, err := strconv.Atoi(entry.Name()); err == nil && fd > 2 {
+			syscall.CloseOnExec(fd)
+		}
+	}
+	program, err := exec.LookPath(argv[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(program, argv, os.Environ())
+}
+
+// peakRSS returns the peak resident set size in bytes of the exited process
+// state belongs to, including children it waited for.
+func peakRSS(state *os.ProcessState) int64 {
+	if state == nil {
+		return 0
+	}
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// Linux and the BSDs report kilobytes, macOS bytes.
+	if runtime.GOOS == "darwin" {
+		return int64(usage.Maxrss)
+	}
+	return int64(usage.Maxrss) * 1024
+}
+
+// processAlive returns true if a process with the given pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
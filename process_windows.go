@@ -0,0 +1,59 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// umaskCommand fails; windows has no umask.
+func umaskCommand(umask string, argv []string) ([]string, error) {
+	return nil, fmt.Errorf("setting the umask of language servers is not supported on windows")
+}
+
+func setCredentials(cmd *exec.Cmd, userName, groupName string) error {
+	if userName == "" && groupName == "" {
+		return nil
+	}
+	return fmt.Errorf("running language servers as another user is not supported on windows")
+}
+
+// closeFilesCommand returns argv; windows handles are not inherited unless
+// they are marked inheritable.
+func closeFilesCommand(argv []string) ([]string, error) {
+	return argv, nil
+}
+
+func execClosingFiles(argv []string) error {
+	return fmt.Errorf("%s is not supported on windows", closeFilesCommandName)
+}
+
+// peakRSS returns 0; windows does not report the peak memory use of exited
+// processes through os.ProcessState.
+func peakRSS(state *os.ProcessState) int64 {
+	return 0
+}
+
+// processAlive returns true if a process with the given pid exists.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,503 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/urfave/cli"
+)
+
+// How long to wait for a language server to answer a query unless the config
+// sets request_timeout.
+const defaultQueryTimeout = 30 * time.Second
+
+// The request_timeout of the config in nanoseconds. Read with queryTimeout.
+var gQueryTimeout = int64(defaultQueryTimeout)
+
+// queryTimeout returns how long to wait for a language server to answer a
+// query before cancelling it.
+func queryTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&gQueryTimeout))
+}
+
+// Location is a range in a file as shown to users. Lines and columns are
+// 1-based and columns are counted in the unit the user asked for.
+type Location struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line"`
+	EndColumn int    `json:"end_column"`
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+}
+
+// Diagnostic is a diagnostic as shown to users.
+type Diagnostic struct {
+	Location
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Source   string `json:"source,omitempty"`
+	Code     string `json:"code,omitempty"`
+	// The language servers that reported the diagnostic, separated by ", ".
+	Server string `json:"server,omitempty"`
+	// Link to the documentation of the code.
+	Href string `json:"href,omitempty"`
+	// Other locations the message refers to.
+	Related []RelatedInformation `json:"related,omitempty"`
+}
+
+// RelatedInformation is a location a diagnostic refers to, ie, that of a
+// previous declaration.
+type RelatedInformation struct {
+	Location
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s: %s: %s", d.Location, d.Severity, d.Message)
+	switch {
+	case d.Source != "" && d.Code != "":
+		s += fmt.Sprintf(" [%s %s]", d.Source, d.Code)
+	case d.Source != "" || d.Code != "":
+		s += fmt.Sprintf(" [%s%s]", d.Source, d.Code)
+	}
+	if d.Server != "" && d.Server != d.Source {
+		s += fmt.Sprintf(" (%s)", d.Server)
+	}
+	return s
+}
+
+// serverForFile returns the running language server whose project directory
+// contains path. If several instances of the server run for the directory
+// they take turns. The server of a root mapped by the project config is
+// started first if needed, and if no server runs for path the one of the
+// auto-start rules is.
+func (s *Server) serverForFile(path string) (*languageServer, error) {
+	if err := s.startRootFor(path); err != nil {
+		return nil, err
+	}
+	infos := s.serverInfos()
+	info, found := serverForPath(infos, path)
+	if !found {
+		started, err := s.startAutoFor(path)
+		if err != nil {
+			return nil, err
+		}
+		if started {
+			infos = s.serverInfos()
+			info, found = serverForPath(infos, path)
+		}
+	}
+	if !found {
+		if len(infos) == 0 {
+			return nil, fmt.Errorf("no language server is running for %s", path)
+		}
+		return nil, fmt.Errorf("no language server is running for %s; servers are running for %s", path, strings.Join(projectDirectories(infos), ", "))
+	}
+	server, err := s.findServer(info.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.nextInstance(server), nil
+}
+
+// PositionArgs identifies a position for queries sent over rpc. Line and
+// Column are 1-based and the column counts Unit.
+type PositionArgs struct {
+	File   string
+	Line   int
+	Column int
+	Unit   ColumnUnit
+	// Server group to broadcast the request to, see GroupConfig.
+	Group string
+}
+
+// unitFlag selects what the columns of positions on the command line count.
+var unitFlag = cli.StringFlag{
+	Name:  "unit",
+	Usage: "what columns count: byte, rune or utf-16",
+	Value: string(ByteColumns),
+}
+
+// positionArgs parses the <file> <line> <col> arguments of a command. The file
+// is made absolute, since the daemon may run in a different directory.
+func positionArgs(c *cli.Context) (PositionArgs, error) {
+	if c.NArg() != 3 {
+		return PositionArgs{}, fmt.Errorf("expected <file> <line> <col>, got %d arguments", c.NArg())
+	}
+	return leadingPositionArgs(c)
+}
+
+// leadingPositionArgs parses the <file> <line> <col> arguments of a command
+// that takes further arguments after them.
+func leadingPositionArgs(c *cli.Context) (PositionArgs, error) {
+	file, err := filepath.Abs(c.Args().Get(0))
+	if err != nil {
+		return PositionArgs{}, err
+	}
+	line, err := strconv.Atoi(c.Args().Get(1))
+	if err != nil || line < 1 {
+		return PositionArgs{}, fmt.Errorf("line must be a positive integer, got %q", c.Args().Get(1))
+	}
+	column, err := strconv.Atoi(c.Args().Get(2))
+	if err != nil || column < 1 {
+		return PositionArgs{}, fmt.Errorf("col must be a positive integer, got %q", c.Args().Get(2))
+	}
+	unit, err := parseColumnUnit(c.String("unit"))
+	if err != nil {
+		return PositionArgs{}, err
+	}
+	return PositionArgs{File: file, Line: line, Column: column, Unit: unit, Group: c.String("group")}, nil
+}
+
+// serversForFile returns every language server running for the project
+// directory that contains path, ie, a compiler and a linter. The server that
+// queries about path are sent to comes first.
+func (s *Server) serversForFile(path string) ([]*languageServer, error) {
+	first, err := s.serverForFile(path)
+	if err != nil {
+		return nil, err
+	}
+	servers := []*languageServer{first}
+	seen := map[string]bool{first.startArgs.Bin: true}
+	for _, server := range s.serverList() {
+		if server.directory == first.directory && !seen[server.startArgs.Bin] {
+			seen[server.startArgs.Bin] = true
+			servers = append(servers, s.nextInstance(server))
+		}
+	}
+	return servers, nil
+}
+
+// serverForPosition returns the language server that method is routed to for
+// args.File, which must be absolute.
+func (s *Server) serverForPosition(args PositionArgs, method string) (*languageServer, error) {
+	if !filepath.IsAbs(args.File) {
+		return nil, fmt.Errorf("file must be an absolute path, got %q", args.File)
+	}
+	servers, err := s.serversForRequest(args.File, method, args.Group)
+	if err != nil {
+		return nil, err
+	}
+	return servers[0], nil
+}
+
+// serversForPosition is serverForPosition for methods whose results are
+// merged.
+func (s *Server) serversForPosition(args PositionArgs, method string) ([]*languageServer, error) {
+	if !filepath.IsAbs(args.File) {
+		return nil, fmt.Errorf("file must be an absolute path, got %q", args.File)
+	}
+	return s.serversForRequest(args.File, method, args.Group)
+}
+
+// nextInstance picks one of the running instances of l, which are servers
+// started with the same command in the same directory, in round-robin order.
+func (s *Server) nextInstance(l *languageServer) *languageServer {
+	var instances []*languageServer
+	for _, server := range s.serverList() {
+		if server.directory == l.directory && server.startArgs.Bin == l.startArgs.Bin {
+			instances = append(instances, server)
+		}
+	}
+	if len(instances) <= 1 {
+		return l
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.instanceTurns == nil {
+		s.instanceTurns = make(map[string]int)
+	}
+	key := l.startArgs.Bin + "\x00" + l.directory
+	turn := s.instanceTurns[key]
+	s.instanceTurns[key] = turn + 1
+	return instances[turn%len(instances)]
+}
+
+// parseLocations parses the result of textDocument/definition and similar
+// requests, which is a Location, a Location array, a LocationLink array or
+// null.
+func parseLocations(result easyjson.RawMessage) ([]LsLocation, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(result, &raw); err != nil {
+		raw = []json.RawMessage{json.RawMessage(result)}
+	}
+
+	var locations []LsLocation
+	for _, r := range raw {
+		if string(r) == "null" {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			return nil, fmt.Errorf("unexpected location %s", string(r))
+		}
+		if _, isLink := fields["targetUri"]; isLink {
+			link := LsLocationLink{}
+			if err := fromJSON(r, &link); err != nil {
+				return nil, err
+			}
+			locations = append(locations, LsLocation{URI: link.TargetURI, Range: link.TargetSelectionRange})
+			continue
+		}
+		location := LsLocation{}
+		if err := fromJSON(r, &location); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// userRange converts r in the document at path to a Location.
+func (l *languageServer) userRange(path string, r LsRange, unit ColumnUnit) Location {
+	// Without the text the columns are left in UTF-16 units.
+	text, _ := l.documentText(path)
+	r = fromUTF16Range(text, r, unit)
+	return Location{
+		File:      path,
+		Line:      r.Start.Line + 1,
+		Column:    r.Start.Character + 1,
+		EndLine:   r.End.Line + 1,
+		EndColumn: r.End.Character + 1,
+	}
+}
+
+// positionParams opens path and builds the parameters for a request at the
+// 1-based line and column.
+func (l *languageServer) positionParams(path string, line, column int, unit ColumnUnit) (LsTextDocumentPositionParams, error) {
+	text, err := l.openDocument(path)
+	if err != nil {
+		return LsTextDocumentPositionParams{}, err
+	}
+	return LsTextDocumentPositionParams{
+		TextDocument: LsTextDocumentIdentifier{URI: pathToURI(path)},
+		Position:     toUTF16(text, LsPosition{Line: line - 1, Character: column - 1}, unit),
+	}, nil
+}
+
+// definitionAt sends textDocument/definition.
+func (l *languageServer) definitionAt(params LsTextDocumentPositionParams) ([]LsLocation, error) {
+	result, err := l.call("textDocument/definition", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	return parseLocations(result)
+}
+
+// definition returns where the symbol at the 1-based line and column of path
+// is defined. Locations matching a rewrite rule of r are followed to another
+// server; r may be nil.
+func (l *languageServer) definition(path string, line, column int, unit ColumnUnit, r *rewriter) ([]Location, error) {
+	params, err := l.positionParams(path, line, column, unit)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := l.definitionAt(params)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		locations = r.follow(l, locations)
+	}
+
+	var out []Location
+	for _, location := range locations {
+		out = append(out, l.userRange(uriToPath(location.URI), location.Range, unit))
+	}
+	return out, nil
+}
+
+// definitions asks every server for the definition at args and merges the
+// locations they return.
+func definitions(servers []*languageServer, args PositionArgs, r *rewriter) ([]Location, error) {
+	lists := make([][]Location, len(servers))
+	err := callServers(servers, "textDocument/definition", func(i int, l *languageServer) (err error) {
+		lists[i], err = l.definition(args.File, args.Line, args.Column, args.Unit, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeLocations(lists), nil
+}
+
+// mergeLocations concatenates the locations several servers returned, leaving
+// out those an earlier server already returned.
+func mergeLocations(lists [][]Location) []Location {
+	var out []Location
+	seen := make(map[Location]bool)
+	for _, locations := range lists {
+		for _, location := range locations {
+			if !seen[location] {
+				seen[location] = true
+				out = append(out, location)
+			}
+		}
+	}
+	return out
+}
+
+// Definition returns where the symbol at a position is defined.
+func (s *Server) Definition(args PositionArgs, locations *[]Location) error {
+	log.Printf("CMD definition %s:%d:%d", args.File, args.Line, args.Column)
+	servers, err := s.serversForPosition(args, "textDocument/definition")
+	if err != nil {
+		return err
+	}
+	*locations, err = definitions(servers, args, s.rewriter(false))
+	return err
+}
+
+// fileDiagnostics returns the latest diagnostics published for path. The
+// document is opened so that the server starts analyzing it.
+func (l *languageServer) fileDiagnostics(path string, unit ColumnUnit) ([]Diagnostic, error) {
+	if _, err := l.openDocument(path); err != nil {
+		return nil, err
+	}
+	return l.publishedDiagnostics(path, unit), nil
+}
+
+// publishedDiagnostics returns the latest diagnostics published for path
+// without opening it.
+func (l *languageServer) publishedDiagnostics(path string, unit ColumnUnit) []Diagnostic {
+	l.mu.Lock()
+	diagnostics := l.diagnostics[pathToURI(path)]
+	l.mu.Unlock()
+	return l.userDiagnostics(path, diagnostics, unit)
+}
+
+// allDiagnostics returns the latest diagnostics of every document, keyed by
+// path. Documents without diagnostics are left out.
+func (l *languageServer) allDiagnostics(unit ColumnUnit) map[string][]Diagnostic {
+	l.mu.Lock()
+	published := make(map[LsDocumentURI][]LsDiagnostic)
+	for uri, diagnostics := range l.diagnostics {
+		if len(diagnostics) > 0 {
+			published[uri] = diagnostics
+		}
+	}
+	l.mu.Unlock()
+
+	out := make(map[string][]Diagnostic)
+	for uri, diagnostics := range published {
+		path := uriToPath(uri)
+		out[path] = l.userDiagnostics(path, diagnostics, unit)
+	}
+	return out
+}
+
+func (l *languageServer) userDiagnostics(path string, diagnostics []LsDiagnostic, unit ColumnUnit) []Diagnostic {
+	out := []Diagnostic{}
+	for _, d := range diagnostics {
+		diagnostic := Diagnostic{
+			Location: l.userRange(path, d.Range, unit),
+			Severity: d.Severity.String(),
+			Message:  d.Message,
+			Source:   d.Source,
+			Code:     diagnosticCode(d),
+			Server:   l.name(),
+		}
+		if d.CodeDescription != nil {
+			diagnostic.Href = d.CodeDescription.Href
+		}
+		for _, related := range d.RelatedInformation {
+			diagnostic.Related = append(diagnostic.Related, RelatedInformation{
+				Location: l.userRange(uriToPath(related.Location.URI), related.Location.Range, unit),
+				Message:  related.Message,
+			})
+		}
+		out = append(out, diagnostic)
+	}
+	sortDiagnostics(out)
+	return out
+}
+
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+}
+
+// mergeDiagnostics merges the diagnostics several servers reported for one
+// file. Diagnostics with the same range and message are reported once, with
+// the highest severity and the names of all servers that reported them.
+func mergeDiagnostics(lists ...[]Diagnostic) []Diagnostic {
+	type key struct {
+		Location
+		Message string
+	}
+	// Unknown severities lose against known ones.
+	rank := func(severity string) LsDiagnosticSeverity {
+		if s, err := parseSeverity(severity); err == nil {
+			return s
+		}
+		return Hint + 1
+	}
+	out := []Diagnostic{}
+	index := make(map[key]int)
+	for _, diagnostics := range lists {
+		for _, d := range diagnostics {
+			k := key{d.Location, d.Message}
+			i, seen := index[k]
+			if !seen {
+				index[k] = len(out)
+				out = append(out, d)
+				continue
+			}
+			merged := &out[i]
+			if rank(d.Severity) < rank(merged.Severity) {
+				merged.Severity = d.Severity
+			}
+			if merged.Source == "" {
+				merged.Source = d.Source
+			}
+			if merged.Code == "" {
+				merged.Code = d.Code
+			}
+			if merged.Href == "" {
+				merged.Href = d.Href
+			}
+			if len(merged.Related) == 0 {
+				merged.Related = d.Related
+			}
+			switch {
+			case merged.Server == "":
+				merged.Server = d.Server
+			case d.Server != "" && !strings.Contains(", "+merged.Server+", ", ", "+d.Server+", "):
+				merged.Server += ", " + d.Server
+			}
+		}
+	}
+	sortDiagnostics(out)
+	return out
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestParseLocations(t *testing.T) {
+	a := LsLocation{URI: "file:///a.c", Range: LsRange{Start: LsPosition{1, 2}, End: LsPosition{1, 5}}}
+	b := LsLocation{URI: "file:///b.c", Range: LsRange{Start: LsPosition{3, 0}, End: LsPosition{3, 4}}}
+	location := `{"uri":"file:///a.c","range":{"start":{"line":1,"character":2},"end":{"line":1,"character":5}}}`
+	link := `{"targetUri":"file:///b.c",
+		"targetRange":{"start":{"line":2,"character":0},"end":{"line":4,"character":1}},
+		"targetSelectionRange":{"start":{"line":3,"character":0},"end":{"line":3,"character":4}}}`
+
+	parse := func(result string) []LsLocation {
+		locations, err := parseLocations(easyjson.RawMessage(result))
+		assert.NoError(t, err)
+		return locations
+	}
+	assert.Equal(t, []LsLocation{a}, parse(location))
+	assert.Equal(t, []LsLocation{a, b}, parse("["+location+","+link+"]"))
+	assert.Equal(t, []LsLocation{b}, parse(link))
+	assert.Empty(t, parse("null"))
+	assert.Empty(t, parse("[]"))
+
+	_, err := parseLocations(easyjson.RawMessage(`[1]`))
+	assert.Error(t, err)
+}
+
+func TestLanguageID(t *testing.T) {
+	assert.Equal(t, "c", languageID("/src/a.c"))
+	assert.Equal(t, "go", languageID("main.go"))
+	assert.Equal(t, "plaintext", languageID("README"))
+}
+
+func TestNextInstance(t *testing.T) {
+	newServer := func(id int, bin, directory string) *languageServer {
+		return &languageServer{id: id, directory: directory, startArgs: StartArgs{Bin: bin, Directory: directory}}
+	}
+	a := newServer(0, "clangd", "/src")
+	b := newServer(1, "clangd", "/src")
+	other := newServer(2, "pyright", "/src")
+	c := newServer(3, "clangd", "/src")
+	alone := newServer(4, "clangd", "/other")
+	s := &Server{servers: []*languageServer{a, b, other, c, alone}}
+
+	var picked []int
+	for i := 0; i < 4; i++ {
+		picked = append(picked, s.nextInstance(b).id)
+	}
+	assert.Equal(t, []int{0, 1, 3, 0}, picked)
+	assert.Equal(t, other, s.nextInstance(other))
+	assert.Equal(t, alone, s.nextInstance(alone))
+}
+
+func TestPositionArgs(t *testing.T) {
+	parse := func(args ...string) (PositionArgs, error) {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		unitFlag.Apply(set)
+		assert.NoError(t, set.Parse(args))
+		return positionArgs(cli.NewContext(nil, set, nil))
+	}
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	args, err := parse("--unit", "utf-16", "a.c", "3", "7")
+	assert.NoError(t, err)
+	assert.Equal(t, PositionArgs{File: filepath.Join(wd, "a.c"), Line: 3, Column: 7, Unit: UTF16Columns}, args)
+
+	args, err = parse("/src/a.c", "1", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, ByteColumns, args.Unit)
+
+	_, err = parse("/src/a.c", "0", "1")
+	assert.EqualError(t, err, `line must be a positive integer, got "0"`)
+	_, err = parse("/src/a.c", "1")
+	assert.Error(t, err)
+	_, err = parse("--unit", "words", "/src/a.c", "1", "1")
+	assert.Error(t, err)
+}
+
+func TestMergeDiagnostics(t *testing.T) {
+	at := func(line int) Location {
+		return Location{File: "/a.c", Line: line, Column: 1, EndLine: line, EndColumn: 4}
+	}
+	compiler := []Diagnostic{
+		{Location: at(3), Severity: "warning", Message: "unused x", Source: "clang", Server: "clangd"},
+		{Location: at(1), Severity: "error", Message: "expected ;", Server: "clangd"},
+	}
+	linter := []Diagnostic{
+		{Location: at(3), Severity: "error", Message: "unused x", Source: "tidy", Code: "W1", Server: "tidy-lsp"},
+		{Location: at(2), Severity: "hint", Message: "use auto", Server: "tidy-lsp"},
+		{Location: at(2), Severity: "hint", Message: "use auto", Server: "tidy-lsp"},
+	}
+
+	assert.Equal(t, []Diagnostic{
+		{Location: at(1), Severity: "error", Message: "expected ;", Server: "clangd"},
+		{Location: at(2), Severity: "hint", Message: "use auto", Server: "tidy-lsp"},
+		{Location: at(3), Severity: "error", Message: "unused x", Source: "clang", Code: "W1", Server: "clangd, tidy-lsp"},
+	}, mergeDiagnostics(compiler, linter))
+	assert.Equal(t, []Diagnostic{}, mergeDiagnostics())
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// Capacity of the queue of closed language servers.
+const closedQueueSize = 1000
+
+// Maximum number of messages queued for a language server that has not
+// finished initializing. Further messages are dropped.
+const maxPendingMessages = 1000
+
+// QueueStats describes the load on a bounded internal queue.
+type QueueStats struct {
+	Name     string
+	Depth    int
+	Capacity int
+	// Number of times a sender had to wait because the queue was full.
+	Blocked int
+	// Number of items discarded because the queue was full.
+	Dropped int
+}
+
+func (q QueueStats) String() string {
+	return fmt.Sprintf("%s %d/%d, %d blocked, %d dropped", q.Name, q.Depth, q.Capacity, q.Blocked, q.Dropped)
+}
+
+// serverQueue is a bounded queue of language servers. Items cannot be
+// dropped, so senders block when it is full; how often that happens is
+// counted so that overload shows up in stats instead of as a silent hang.
+type serverQueue struct {
+	name    string
+	c       chan *languageServer
+	blocked int64
+}
+
+func newServerQueue(name string, capacity int) *serverQueue {
+	return &serverQueue{name: name, c: make(chan *languageServer, capacity)}
+}
+
+func (q *serverQueue) push(l *languageServer) {
+	select {
+	case q.c <- l:
+	default:
+		atomic.AddInt64(&q.blocked, 1)
+		log.Printf("The %s queue is full; waiting for the daemon", q.name)
+		q.c <- l
+	}
+}
+
+func (q *serverQueue) stats() QueueStats {
+	return QueueStats{
+		Name:     q.name,
+		Depth:    len(q.c),
+		Capacity: cap(q.c),
+		Blocked:  int(atomic.LoadInt64(&q.blocked)),
+	}
+}
+
+// Queues returns the state of the daemon's internal queues. Queues of
+// individual language servers are part of Stats.
+func (s *Server) Queues(_ bool, queues *[]QueueStats) error {
+	*queues = append(*queues, languageServerClosed.stats())
+	*queues = append(*queues, daemonEvents.stats()...)
+	return nil
+}
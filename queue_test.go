@@ -0,0 +1,44 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerQueueCountsBlockedSenders(t *testing.T) {
+	q := newServerQueue("test", 1)
+	a, b := &languageServer{id: 1}, &languageServer{id: 2}
+
+	q.push(a)
+	assert.Equal(t, QueueStats{Name: "test", Depth: 1, Capacity: 1}, q.stats())
+
+	pushed := make(chan bool)
+	go func() {
+		q.push(b)
+		close(pushed)
+	}()
+	for q.stats().Blocked == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, a, <-q.c)
+	<-pushed
+	assert.Equal(t, b, <-q.c)
+	assert.Equal(t, QueueStats{Name: "test", Capacity: 1, Blocked: 1}, q.stats())
+}
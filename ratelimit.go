@@ -0,0 +1,278 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig limits the requests each client of the daemon may send, so
+// that a runaway script or editor plugin cannot starve the other clients of
+// the same language servers:
+//
+//	[rate_limit]
+//	requests_per_second = 20
+//	burst = 40
+//	max_in_flight = 4
+//
+// Clients of the socket are told apart by user and parent process, so every
+// lspc command that one script or editor runs counts towards the same limits.
+// Clients of the HTTP gateway and the gRPC API are told apart by address.
+// Requests over the limits fail right away.
+type RateLimitConfig struct {
+	// Sustained requests per second of each client. 0 means no limit.
+	RequestsPerSecond float64 `toml:"requests_per_second,omitempty"`
+
+	// Requests a client may send at once above the sustained rate. Defaults
+	// to requests_per_second, rounded up.
+	Burst int `toml:"burst,omitempty"`
+
+	// Requests of each client that are answered at the same time. 0 means no
+	// limit.
+	MaxInFlight int `toml:"max_in_flight,omitempty"`
+}
+
+func (c RateLimitConfig) validate() map[string]error {
+	problems := make(map[string]error)
+	if c.RequestsPerSecond < 0 {
+		problems["requests_per_second"] = fmt.Errorf("must not be negative")
+	}
+	if c.Burst < 0 {
+		problems["burst"] = fmt.Errorf("must not be negative")
+	}
+	if c.MaxInFlight < 0 {
+		problems["max_in_flight"] = fmt.Errorf("must not be negative")
+	}
+	return problems
+}
+
+func (c RateLimitConfig) burst() float64 {
+	if c.Burst > 0 {
+		return float64(c.Burst)
+	}
+	return math.Max(1, math.Ceil(c.RequestsPerSecond))
+}
+
+func (c RateLimitConfig) String() string {
+	if c.RequestsPerSecond == 0 && c.MaxInFlight == 0 {
+		return "none"
+	}
+	s := "requests_per_second = unlimited"
+	if c.RequestsPerSecond > 0 {
+		s = fmt.Sprintf("requests_per_second = %g, burst = %g", c.RequestsPerSecond, c.burst())
+	}
+	if c.MaxInFlight > 0 {
+		s += fmt.Sprintf(", max_in_flight = %d", c.MaxInFlight)
+	}
+	return s
+}
+
+// Methods that are never limited: top polls the stats, KeepAlive must get
+// through for the daemon to stay up and Kill for it to go down.
+var rateLimitExemptMethods = map[string]bool{
+	"Server.Stats":     true,
+	"Server.Queues":    true,
+	"Server.KeepAlive": true,
+	"Server.Kill":      true,
+}
+
+// Clients that have not sent a request for this long and have none in flight
+// are forgotten.
+const rateLimitIdle = time.Minute
+
+// clientLimit is the state of one client: a token bucket refilled at the
+// configured rate and the number of its requests being answered.
+type clientLimit struct {
+	tokens   float64
+	last     time.Time
+	inFlight int
+}
+
+// rateLimiter applies a RateLimitConfig to every client of the daemon.
+type rateLimiter struct {
+	mu        sync.Mutex
+	config    RateLimitConfig
+	clients   map[string]*clientLimit
+	lastPrune time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{clients: make(map[string]*clientLimit)}
+}
+
+// The limits of the current daemon config.
+var gRateLimits = newRateLimiter()
+
+// configure replaces the limits. Clients keep their in-flight requests but
+// start over with a full bucket.
+func (r *rateLimiter) configure(config RateLimitConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = config
+	for _, c := range r.clients {
+		c.tokens = config.burst()
+	}
+}
+
+// admit reserves a request of client. release must be called once the request
+// has been answered.
+func (r *rateLimiter) admit(client string) (release func(), err error) {
+	return r.admitAt(client, time.Now())
+}
+
+func (r *rateLimiter) admitAt(client string, now time.Time) (release func(), err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.config.RequestsPerSecond == 0 && r.config.MaxInFlight == 0 {
+		return func() {}, nil
+	}
+	r.prune(now)
+
+	c, has := r.clients[client]
+	if !has {
+		c = &clientLimit{tokens: r.config.burst(), last: now}
+		r.clients[client] = c
+	}
+	if r.config.RequestsPerSecond > 0 {
+		c.tokens = math.Min(r.config.burst(), c.tokens+now.Sub(c.last).Seconds()*r.config.RequestsPerSecond)
+		c.last = now
+		if c.tokens < 1 {
+			return nil, fmt.Errorf("rate limit exceeded: %s sent over %g requests per second", client, r.config.RequestsPerSecond)
+		}
+	}
+	if r.config.MaxInFlight > 0 && c.inFlight >= r.config.MaxInFlight {
+		return nil, fmt.Errorf("rate limit exceeded: %s already has %d requests in flight", client, c.inFlight)
+	}
+	if r.config.RequestsPerSecond > 0 {
+		c.tokens--
+	}
+	c.last = now
+	c.inFlight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			c.inFlight--
+			r.mu.Unlock()
+		})
+	}, nil
+}
+
+// prune forgets idle clients, at most once per rateLimitIdle.
+func (r *rateLimiter) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < rateLimitIdle {
+		return
+	}
+	r.lastPrune = now
+	for client, c := range r.clients {
+		if c.inFlight == 0 && now.Sub(c.last) >= rateLimitIdle {
+			delete(r.clients, client)
+		}
+	}
+}
+
+// socketClient names the client on the other end of a control connection for
+// rate limiting. Without the credentials of the peer every connection is its
+// own client.
+func socketClient(c net.Conn, peer auditPeer) string {
+	if peer.Transport == "tcp" {
+		return addressClient(peer.Transport, peer.Addr)
+	}
+	if peer.Uid == nil || peer.Pid == 0 {
+		return fmt.Sprintf("connection %p", c)
+	}
+	if parent := parentPid(peer.Pid); parent > 0 {
+		return fmt.Sprintf("uid %d, parent pid %d", *peer.Uid, parent)
+	}
+	return fmt.Sprintf("uid %d, pid %d", *peer.Uid, peer.Pid)
+}
+
+// addressClient names a client of the HTTP gateway or the gRPC API by the host
+// of its address, since its port changes with every connection.
+func addressClient(transport, addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return transport + " " + addr
+}
+
+// rateLimitCodec wraps the codec of a control connection and fails requests
+// of client over the limits of gRateLimits. Like auditCodec, pending is
+// guarded by mu since responses are written concurrently.
+type rateLimitCodec struct {
+	rpc.ServerCodec
+	client string
+
+	// The request whose body is read next.
+	current rpc.Request
+
+	mu      sync.Mutex
+	pending map[uint64]func()
+}
+
+func (c *rateLimitCodec) ReadRequestHeader(r *rpc.Request) error {
+	err := c.ServerCodec.ReadRequestHeader(r)
+	c.current = *r
+	return err
+}
+
+func (c *rateLimitCodec) ReadRequestBody(body interface{}) error {
+	if err := c.ServerCodec.ReadRequestBody(body); err != nil {
+		return err
+	}
+	if rateLimitExemptMethods[c.current.ServiceMethod] {
+		return nil
+	}
+	release, err := gRateLimits.admit(c.client)
+	if err != nil {
+		// net/rpc answers the request with the error.
+		log.Printf("Rejecting %s: %s", c.current.ServiceMethod, err.Error())
+		return err
+	}
+	c.mu.Lock()
+	c.pending[c.current.Seq] = release
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *rateLimitCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.mu.Lock()
+	release, has := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mu.Unlock()
+
+	if has {
+		release()
+	}
+	return c.ServerCodec.WriteResponse(r, body)
+}
+
+func (c *rateLimitCodec) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for seq, release := range c.pending {
+		release()
+		delete(c.pending, seq)
+	}
+	return c.ServerCodec.Close()
+}
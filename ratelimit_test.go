@@ -0,0 +1,105 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	assert.Empty(t, RateLimitConfig{RequestsPerSecond: 2.5, MaxInFlight: 4}.validate())
+
+	problems := RateLimitConfig{RequestsPerSecond: -1, Burst: -1, MaxInFlight: -1}.validate()
+	assert.Contains(t, problems, "requests_per_second")
+	assert.Contains(t, problems, "burst")
+	assert.Contains(t, problems, "max_in_flight")
+}
+
+func TestRateLimitRequestsPerSecond(t *testing.T) {
+	r := newRateLimiter()
+	r.configure(RateLimitConfig{RequestsPerSecond: 2, Burst: 3})
+	now := time.Now()
+
+	// The burst is available right away, then requests come in at the rate.
+	for i := 0; i < 3; i++ {
+		release, err := r.admitAt("script", now)
+		assert.NoError(t, err)
+		release()
+	}
+	_, err := r.admitAt("script", now)
+	assert.Error(t, err)
+	_, err = r.admitAt("script", now.Add(400*time.Millisecond))
+	assert.Error(t, err)
+	_, err = r.admitAt("script", now.Add(500*time.Millisecond))
+	assert.NoError(t, err)
+
+	// Other clients are not affected.
+	_, err = r.admitAt("editor", now)
+	assert.NoError(t, err)
+
+	// Without limits everything is admitted.
+	r.configure(RateLimitConfig{})
+	for i := 0; i < 10; i++ {
+		_, err = r.admitAt("script", now)
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimitInFlight(t *testing.T) {
+	r := newRateLimiter()
+	r.configure(RateLimitConfig{MaxInFlight: 2})
+	now := time.Now()
+
+	first, err := r.admitAt("script", now)
+	assert.NoError(t, err)
+	_, err = r.admitAt("script", now)
+	assert.NoError(t, err)
+	_, err = r.admitAt("script", now)
+	assert.EqualError(t, err, "rate limit exceeded: script already has 2 requests in flight")
+
+	// Releasing twice frees one slot only.
+	first()
+	first()
+	_, err = r.admitAt("script", now)
+	assert.NoError(t, err)
+	_, err = r.admitAt("script", now)
+	assert.Error(t, err)
+}
+
+func TestRateLimitPrune(t *testing.T) {
+	r := newRateLimiter()
+	r.configure(RateLimitConfig{RequestsPerSecond: 1, MaxInFlight: 1})
+	now := time.Now()
+
+	release, err := r.admitAt("idle", now)
+	assert.NoError(t, err)
+	release()
+	_, err = r.admitAt("busy", now)
+	assert.NoError(t, err)
+
+	// Clients with requests in flight are kept.
+	_, err = r.admitAt("other", now.Add(rateLimitIdle))
+	assert.NoError(t, err)
+	assert.NotContains(t, r.clients, "idle")
+	assert.Contains(t, r.clients, "busy")
+}
+
+func TestAddressClient(t *testing.T) {
+	assert.Equal(t, "grpc 127.0.0.1", addressClient("grpc", "127.0.0.1:52341"))
+	assert.Equal(t, "http [::1]", addressClient("http", "[::1]"))
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+)
+
+// How long the sockets opened by RawIO and Events wait for the client to
+// connect.
+const rawIOAcceptTimeout = 10 * time.Second
+
+// Messages from the server queued for a raw-io client before new ones are
+// dropped.
+const rawIOTapSize = 256
+
+// Methods raw-io clients may not send, since they would break the session
+// the daemon has with the server.
+var rawIOForbidden = map[string]bool{
+	"initialize": true,
+	"shutdown":   true,
+	"exit":       true,
+}
+
+// rawIOMessage is a message sent by a raw-io client.
+type rawIOMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// rawIOResponse is written back to a raw-io client. ID is the id the client
+// used, not the one the daemon sent to the server.
+type rawIOResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *LsResponseError `json:"error,omitempty"`
+}
+
+func rawIOError(id json.RawMessage, code LsErrorCode, format string, args ...interface{}) rawIOResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return rawIOResponse{JSONRPC: "2.0", ID: id, Error: &LsResponseError{Code: code, Message: fmt.Sprintf(format, args...)}}
+}
+
+// RawIO opens a socket which bridges one client to the protocol stream of
+// the language server with the given id and returns its path, see
+// `lspc raw-io`.
+func (s *Server) RawIO(id int, socket *string) error {
+	log.Printf("CMD raw-io %d", id)
+	server, err := s.findServer(id)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s.raw-%d-%d", gSocket, id, time.Now().UnixNano())
+	if err := serveOnce(path, "raw-io client for "+server.name(), server.bridgeRawIO); err != nil {
+		return err
+	}
+	*socket = path
+	return nil
+}
+
+// serveOnce opens a unix socket at path and passes the first connection to
+// serve on a new goroutine. The socket is removed once a client connects or
+// after rawIOAcceptTimeout. client names the client for the log.
+func serveOnce(path, client string, serve func(net.Conn)) error {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer os.Remove(path)
+		// Give up if the client never connects.
+		timer := time.AfterFunc(rawIOAcceptTimeout, func() { listener.Close() })
+		conn, err := listener.Accept()
+		timer.Stop()
+		listener.Close()
+		if err != nil {
+			log.Printf("%s did not connect: %s", client, err.Error())
+			return
+		}
+		serve(conn)
+	}()
+	return nil
+}
+
+// addTap returns a channel that receives the requests and notifications the
+// server sends until removeTap is called.
+func (l *languageServer) addTap() chan []byte {
+	tap := make(chan []byte, rawIOTapSize)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.taps == nil {
+		l.taps = make(map[chan []byte]struct{})
+	}
+	l.taps[tap] = struct{}{}
+	return tap
+}
+
+func (l *languageServer) removeTap(tap chan []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.taps, tap)
+}
+
+// tapMessage passes a message from the server on to every tap. Taps which
+// are full miss it; the server must never wait for a client.
+func (l *languageServer) tapMessage(message []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for tap := range l.taps {
+		select {
+		case tap <- message:
+		default:
+			log.Printf("raw-io client of %s is not keeping up; dropped a message", l.name())
+		}
+	}
+}
+
+// bridgeRawIO forwards requests and notifications read from conn, one JSON
+// message per line, to the server and writes the responses, along with every
+// request and notification from the server, back to conn. Requests are
+// renumbered so they do not clash with those of the daemon.
+func (l *languageServer) bridgeRawIO(conn net.Conn) {
+	defer conn.Close()
+	log.Printf("raw-io session with %s started", l.name())
+	defer log.Printf("raw-io session with %s ended", l.name())
+
+	var writeMu sync.Mutex
+	writeLine := func(line []byte) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.Write(append(line, '\n'))
+	}
+	respond := func(response rawIOResponse) {
+		line, err := json.Marshal(response)
+		if err == nil {
+			writeLine(line)
+		}
+	}
+
+	tap := l.addTap()
+	defer l.removeTap(tap)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case message := <-tap:
+				writeLine(message)
+			}
+		}
+	}()
+
+	var pending sync.WaitGroup
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, gMaxMessageSize*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var m rawIOMessage
+		if err := json.Unmarshal(line, &m); err != nil {
+			respond(rawIOError(nil, ParseError, "invalid JSON: %s", err.Error()))
+			continue
+		}
+		isRequest := len(m.ID) > 0 && string(m.ID) != "null"
+		switch {
+		case m.Method == "":
+			respond(rawIOError(m.ID, InvalidRequest, "only requests and notifications can be sent; lspc answers requests from the server"))
+			continue
+		case rawIOForbidden[m.Method]:
+			respond(rawIOError(m.ID, InvalidRequest, "%s is managed by lspc", m.Method))
+			continue
+		}
+		if err := l.checkMethod(m.Method); err != nil {
+			respond(rawIOError(m.ID, RequestFailed, "%s", err.Error()))
+			continue
+		}
+
+		params := easyjson.RawMessage(m.Params)
+		if !isRequest {
+			l.writeNotification(m.Method, params)
+			continue
+		}
+		pending.Add(1)
+		id := append(json.RawMessage(nil), m.ID...)
+		l.writeRequest(m.Method, params, func(result easyjson.RawMessage, err *LsResponseError) {
+			defer pending.Done()
+			response := rawIOResponse{JSONRPC: "2.0", ID: id, Error: err}
+			if err == nil {
+				response.Result = json.RawMessage(result)
+				if len(result) == 0 {
+					response.Result = json.RawMessage("null")
+				}
+			}
+			respond(response)
+		})
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		log.Printf("raw-io connection to %s failed: %s", l.name(), err.Error())
+		return
+	}
+
+	// The client closed its input; answer what it already asked.
+	answered := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(answered)
+	}()
+	select {
+	case <-answered:
+	case <-time.After(queryTimeout()):
+		log.Printf("raw-io client of %s closed before every request was answered", l.name())
+	}
+}
+
+// rawIO connects to the socket returned by RawIO and copies in to it and
+// what it sends to out until the daemon closes the connection.
+func rawIO(socket string, in io.Reader, out io.Writer) error {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		io.Copy(conn, in)
+		// Lets the daemon answer outstanding requests before closing.
+		if unix, isUnix := conn.(*net.UnixConn); isUnix {
+			unix.CloseWrite()
+		}
+	}()
+	_, err = io.Copy(out, conn)
+	return err
+}
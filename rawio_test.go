@@ -0,0 +1,48 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTapMessage(t *testing.T) {
+	l := &languageServer{args: []string{"clangd"}}
+	tap := l.addTap()
+	l.tapMessage([]byte(`{"method":"a"}`))
+	assert.Equal(t, `{"method":"a"}`, string(<-tap))
+
+	// A full tap misses messages instead of blocking the server.
+	for i := 0; i < rawIOTapSize+1; i++ {
+		l.tapMessage([]byte(`{}`))
+	}
+	assert.Len(t, tap, rawIOTapSize)
+
+	l.removeTap(tap)
+	assert.Empty(t, l.taps)
+}
+
+func TestRawIOError(t *testing.T) {
+	line, err := json.Marshal(rawIOError(nil, ParseError, "bad %s", "input"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": null, "error": {"code": -32700, "message": "bad input"}}`, string(line))
+
+	line, err = json.Marshal(rawIOError(json.RawMessage(`"x"`), InvalidRequest, "no"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": "x", "error": {"code": -32600, "message": "no"}}`, string(line))
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Methods servers may handle by modifying files themselves instead of asking
+// with workspace/applyEdit. They are not sent to servers of a read-only
+// project, and call does not hold editMu for reading while they run, since
+// the server may send workspace/applyEdit before answering.
+var writingMethods = map[string]bool{
+	"workspace/executeCommand": true,
+}
+
+// readOnlyFor returns true if files in directory must not be modified, either
+// because of --read-only, the config or the project config. A root of a
+// read-only checkout is read-only too.
+func (c *Config) readOnlyFor(directory string) bool {
+	if gReadOnly || c.ReadOnly {
+		return true
+	}
+	project, err := loadProjectConfig(directory)
+	if err == nil && project.ReadOnly {
+		return true
+	}
+	checkout, project, found := findCheckout(filepath.Dir(directory))
+	if !found {
+		return false
+	}
+	_, _, isRoot := project.rootFor(checkout, directory)
+	return isRoot && project.ReadOnly
+}
+
+// readOnlyError describes why op, ie, "rename --apply", was rejected.
+func readOnlyError(op, directory string) error {
+	return fmt.Errorf("%s is not allowed: %s is read-only", op, directory)
+}
+
+// checkWritable returns an error if op would modify files in the project of
+// l. Every operation which writes to disk must call it first.
+func (l *languageServer) checkWritable(op string) error {
+	if l.readOnly {
+		return readOnlyError(op, l.directory)
+	}
+	return nil
+}
+
+// readOnlyProject returns the directory of the read-only project containing
+// path, ie, that of a project config with read_only in a parent of path.
+func readOnlyProject(path string) (string, bool) {
+	for directory := filepath.Dir(filepath.Clean(path)); ; {
+		project, err := loadProjectConfig(directory)
+		if err == nil && project.ReadOnly {
+			return directory, true
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", false
+		}
+		directory = parent
+	}
+}
+
+// checkWritablePaths is checkWritable for an edit of paths. Servers may edit
+// files outside of their project, so each path is checked for being in a
+// read-only project too.
+func (l *languageServer) checkWritablePaths(op string, paths []string) error {
+	if err := l.checkWritable(op); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if directory, readOnly := readOnlyProject(path); readOnly {
+			return readOnlyError(op, directory)
+		}
+	}
+	return nil
+}
+
+// checkMethod returns an error if sending method to l could modify files.
+func (l *languageServer) checkMethod(method string) error {
+	if writingMethods[method] {
+		return l.checkWritable(method)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyFor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	pristine := filepath.Join(dir, "pristine")
+	assert.NoError(t, os.Mkdir(pristine, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pristine, projectConfigName), []byte("read_only = true\n"), 0644))
+
+	config := &Config{}
+	assert.False(t, config.readOnlyFor(dir))
+	assert.True(t, config.readOnlyFor(pristine))
+
+	config.ReadOnly = true
+	assert.True(t, config.readOnlyFor(dir))
+}
+
+func TestCheckWritable(t *testing.T) {
+	l := &languageServer{directory: "/src"}
+	assert.NoError(t, l.checkWritable("rename --apply"))
+	assert.NoError(t, l.checkMethod("workspace/executeCommand"))
+
+	l.readOnly = true
+	assert.EqualError(t, l.checkWritable("rename --apply"), "rename --apply is not allowed: /src is read-only")
+	assert.Error(t, l.checkMethod("workspace/executeCommand"))
+	assert.NoError(t, l.checkMethod("textDocument/definition"))
+}
+
+func TestCheckWritablePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	pristine := filepath.Join(dir, "pristine")
+	assert.NoError(t, os.MkdirAll(filepath.Join(pristine, "src"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pristine, projectConfigName), []byte("read_only = true\n"), 0644))
+
+	l := &languageServer{directory: filepath.Join(dir, "project")}
+	inProject := filepath.Join(dir, "project", "a.c")
+	assert.NoError(t, l.checkWritablePaths("rename --apply", []string{inProject}))
+
+	// Edits of files in another, read-only, project are rejected.
+	assert.EqualError(t, l.checkWritablePaths("rename --apply", []string{inProject, filepath.Join(pristine, "src", "b.c")}),
+		"rename --apply is not allowed: "+pristine+" is read-only")
+
+	l.readOnly = true
+	assert.EqualError(t, l.checkWritablePaths("rename --apply", []string{inProject}),
+		"rename --apply is not allowed: "+l.directory+" is read-only")
+}
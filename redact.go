@@ -0,0 +1,149 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sync"
+)
+
+// Keys whose values are always redacted.
+const defaultRedactPattern = `token|secret|passw(or)?d|api_?key|credential`
+
+// Replaces redacted values.
+const redactedValue = "<redacted>"
+
+// redactor hides the values of secret keys in JSON before it is logged or
+// printed, so that audit logs and configs can be shared in bug reports.
+type redactor struct {
+	keys []*regexp.Regexp
+}
+
+// redactor returns the redactor for the default patterns and those in the
+// redact setting. Invalid patterns, which config validate reports, are
+// skipped.
+func (c *Config) redactor() *redactor {
+	r := &redactor{keys: []*regexp.Regexp{regexp.MustCompile("(?i)" + defaultRedactPattern)}}
+	for _, pattern := range c.Redact {
+		if key, err := regexp.Compile("(?i)" + pattern); err == nil {
+			r.keys = append(r.keys, key)
+		}
+	}
+	return r
+}
+
+func (r *redactor) secret(key string) bool {
+	for _, pattern := range r.keys {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = r.value(v[i])
+		}
+	case map[string]interface{}:
+		for key, value := range v {
+			if r.secret(key) {
+				v[key] = redactAll(value)
+			} else {
+				v[key] = r.value(value)
+			}
+		}
+	}
+	return v
+}
+
+// redactAll replaces every value inside of v except null.
+func redactAll(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for i := range v {
+			v[i] = redactAll(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = redactAll(v[key])
+		}
+		return v
+	}
+	return redactedValue
+}
+
+// json returns data with the value of every secret key replaced. Objects
+// and arrays under secret keys keep their structure so that it remains
+// visible what was set. data is returned unchanged if it is not JSON.
+func (r *redactor) json(data []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.value(v)); err != nil {
+		return data
+	}
+	return bytes.TrimSuffix(redacted.Bytes(), []byte("\n"))
+}
+
+// jsonString is json for JSON stored in a string, ie, init_options. Empty
+// strings stay empty.
+func (r *redactor) jsonString(s string) string {
+	if s == "" {
+		return s
+	}
+	return string(r.json([]byte(s)))
+}
+
+// redactSecrets redacts the init options and settings of c.
+func (c *Config) redactSecrets(r *redactor) {
+	c.InitOptions = r.jsonString(c.InitOptions)
+	for name, language := range c.Languages {
+		language.InitOptions = r.jsonString(language.InitOptions)
+		language.Settings = r.jsonString(language.Settings)
+		c.Languages[name] = language
+	}
+}
+
+var (
+	gRedactorMu sync.Mutex
+	// The redactor of the current daemon config.
+	gRedactor = (&Config{}).redactor()
+)
+
+func setRedactor(r *redactor) {
+	gRedactorMu.Lock()
+	gRedactor = r
+	gRedactorMu.Unlock()
+}
+
+// redactJSON redacts data with the redactor of the current config.
+func redactJSON(data []byte) []byte {
+	gRedactorMu.Lock()
+	r := gRedactor
+	gRedactorMu.Unlock()
+	return r.json(data)
+}
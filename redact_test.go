@@ -0,0 +1,67 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactJSON(t *testing.T) {
+	r := (&Config{Redact: []string{"^cacheDir"}}).redactor()
+	assert.JSONEq(t, `{
+		"apiKey": "<redacted>",
+		"github_token": "<redacted>",
+		"Password": "<redacted>",
+		"credentials": {"user": "<redacted>", "ids": ["<redacted>", "<redacted>"], "unset": null},
+		"cacheDirectory": "<redacted>",
+		"servers": [{"name": "a", "secret": "<redacted>"}],
+		"tokenize": "<redacted>",
+		"verbose": true
+	}`, string(r.json([]byte(`{
+		"apiKey": "k",
+		"github_token": 1,
+		"Password": "p",
+		"credentials": {"user": "u", "ids": [1, 2], "unset": null},
+		"cacheDirectory": "/c",
+		"servers": [{"name": "a", "secret": "s"}],
+		"tokenize": false,
+		"verbose": true
+	}`))))
+
+	assert.Equal(t, "not json", string(r.json([]byte("not json"))))
+	assert.Equal(t, "", r.jsonString(""))
+}
+
+func TestRedactSecrets(t *testing.T) {
+	config := &Config{
+		InitOptions: `{"token": "t"}`,
+		Languages: map[string]LanguageConfig{
+			"go": {Command: "gopls", Settings: `{"gopls": {"apiKey": "k", "staticcheck": true}}`},
+		},
+	}
+	config.redactSecrets(config.redactor())
+	assert.JSONEq(t, `{"token": "<redacted>"}`, config.InitOptions)
+	assert.JSONEq(t, `{"gopls": {"apiKey": "<redacted>", "staticcheck": true}}`, config.Languages["go"].Settings)
+	assert.Equal(t, "", config.Languages["go"].InitOptions)
+	assert.Equal(t, "gopls", config.Languages["go"].Command)
+}
+
+func TestAuditArgsRedacted(t *testing.T) {
+	args := auditArgs(StartArgs{Bin: "clangd", InitOpts: []byte(`{"remote": {"token": "t"}}`)})
+	assert.Contains(t, string(args), `"token":"<redacted>"`)
+	assert.Contains(t, string(args), `"Bin":"clangd"`)
+}
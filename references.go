@@ -0,0 +1,137 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Output formats of lspc references.
+const (
+	// <file>:<line>:<col>
+	referencesPlain = "plain"
+	// <file>:<line>:<col>:<text of the line>, as printed by grep -n and
+	// read by the errorformat of vim.
+	referencesGrep = "grep"
+	// One Location object per line.
+	referencesJSON = "json"
+)
+
+func checkReferencesFormat(format string) error {
+	switch format {
+	case referencesPlain, referencesGrep, referencesJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q; expected plain, grep or json", format)
+}
+
+// ReferencesArgs are the arguments of Server.References.
+type ReferencesArgs struct {
+	PositionArgs
+	// Also return the declaration of the symbol.
+	IncludeDeclaration bool
+}
+
+// references returns the references to the symbol at args.
+func (l *languageServer) references(args ReferencesArgs) ([]Location, error) {
+	position, err := l.positionParams(args.File, args.Line, args.Column, args.Unit)
+	if err != nil {
+		return nil, err
+	}
+	params := LsReferenceParams{
+		TextDocument: position.TextDocument,
+		Position:     position.Position,
+		Context:      LsReferenceContext{IncludeDeclaration: args.IncludeDeclaration},
+	}
+	result, err := l.call("textDocument/references", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	locations, err := parseLocations(result)
+	if err != nil {
+		return nil, err
+	}
+	var out []Location
+	for _, location := range locations {
+		out = append(out, l.userRange(uriToPath(location.URI), location.Range, args.Unit))
+	}
+	return out, nil
+}
+
+// References returns the references to the symbol at a position, sorted by
+// file and position.
+func (s *Server) References(args ReferencesArgs, locations *[]Location) error {
+	log.Printf("CMD references %s:%d:%d", args.File, args.Line, args.Column)
+	servers, err := s.serversForPosition(args.PositionArgs, "textDocument/references")
+	if err != nil {
+		return err
+	}
+	lists := make([][]Location, len(servers))
+	err = callServers(servers, "textDocument/references", func(i int, l *languageServer) (err error) {
+		lists[i], err = l.references(args)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	*locations = mergeLocations(lists)
+	sort.SliceStable(*locations, func(i, j int) bool {
+		a, b := (*locations)[i], (*locations)[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return nil
+}
+
+// writeReferences prints locations in format.
+func writeReferences(out io.Writer, locations []Location, format string) error {
+	// Lines of the files read for grep, keyed by path.
+	lines := make(map[string][]string)
+	for _, location := range locations {
+		switch format {
+		case referencesJSON:
+			if err := json.NewEncoder(out).Encode(location); err != nil {
+				return err
+			}
+		case referencesGrep:
+			fileLines, has := lines[location.File]
+			if !has {
+				// Files that cannot be read print empty lines.
+				content, _ := ioutil.ReadFile(location.File)
+				fileLines = strings.Split(string(content), "\n")
+				lines[location.File] = fileLines
+			}
+			text := ""
+			if location.Line <= len(fileLines) {
+				text = strings.TrimSuffix(fileLines[location.Line-1], "\r")
+			}
+			fmt.Fprintf(out, "%s:%s\n", location, text)
+		default:
+			fmt.Fprintln(out, location)
+		}
+	}
+	return nil
+}
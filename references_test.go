@@ -0,0 +1,59 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteReferences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-references")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path, missing := filepath.Join(dir, "a.c"), filepath.Join(dir, "missing.c")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("int foo;\r\nint bar = foo;\n"), 0644))
+	locations := []Location{
+		{File: path, Line: 1, Column: 5, EndLine: 1, EndColumn: 8},
+		{File: path, Line: 2, Column: 11, EndLine: 2, EndColumn: 14},
+		{File: missing, Line: 3, Column: 1, EndLine: 3, EndColumn: 4},
+	}
+	write := func(format string) string {
+		var out bytes.Buffer
+		assert.NoError(t, writeReferences(&out, locations, format))
+		return out.String()
+	}
+
+	assert.Equal(t, path+":1:5\n"+path+":2:11\n"+missing+":3:1\n", write(referencesPlain))
+	assert.Equal(t, path+":1:5:int foo;\n"+path+":2:11:int bar = foo;\n"+missing+":3:1:\n", write(referencesGrep))
+	assert.Equal(t, `{"file":"`+path+`","line":1,"column":5,"end_line":1,"end_column":8}`+"\n",
+		string(bytes.SplitAfter([]byte(write(referencesJSON)), []byte("\n"))[0]))
+
+	assert.NoError(t, checkReferencesFormat("grep"))
+	assert.Error(t, checkReferencesFormat("quickfix"))
+}
+
+func TestMergeLocations(t *testing.T) {
+	a := Location{File: "/a.c", Line: 1, Column: 1}
+	b := Location{File: "/b.c", Line: 2, Column: 1}
+	assert.Equal(t, []Location{a, b}, mergeLocations([][]Location{{a}, {b, a}}))
+	assert.Nil(t, mergeLocations(nil))
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// The value of --max-servers, used when the config does not set max_servers.
+var flagMaxServers = -1
+
+// The file the daemon currently logs to, if any.
+var logFile *os.File
+
+// reloadConfig re-reads the config file and applies it.
+func (s *Server) reloadConfig() ([]string, error) {
+	config, err := loadConfig(gConfig)
+	if err != nil {
+		log.Printf("Keeping previous config: %s", err.Error())
+		return nil, err
+	}
+	return s.applyConfig(config), nil
+}
+
+// applyConfig makes config the active config and returns a description of
+// what changed.
+func (s *Server) applyConfig(config *Config) []string {
+	var changes []string
+	old := s.currentConfig()
+
+	if config.LogFile != old.LogFile {
+		if err := setLogFile(config.LogFile); err != nil {
+			changes = append(changes, fmt.Sprintf("log_file: %s", err.Error()))
+		} else {
+			changes = append(changes, fmt.Sprintf("log_file: %q", config.LogFile))
+		}
+	}
+
+	s.mu.Lock()
+	if flagMaxServers < 0 {
+		flagMaxServers = gMaxServers
+	}
+	maxServers := flagMaxServers
+	if config.MaxServers != nil {
+		maxServers = *config.MaxServers
+	}
+	if maxServers != gMaxServers {
+		changes = append(changes, fmt.Sprintf("max_servers: %d", maxServers))
+		gMaxServers = maxServers
+	}
+	s.config = config
+	s.mu.Unlock()
+	setRedactor(config.redactor())
+	if config.RateLimit != old.RateLimit {
+		changes = append(changes, fmt.Sprintf("rate_limit: %s", config.RateLimit))
+		gRateLimits.configure(config.RateLimit)
+	}
+	if timeout := config.requestTimeout(); timeout != queryTimeout() {
+		changes = append(changes, fmt.Sprintf("request_timeout: %s", timeout))
+		atomic.StoreInt64(&gQueryTimeout, int64(timeout))
+	}
+	s.enforceMaxServers()
+
+	for _, ls := range s.serverList() {
+		ls.setDiagnosticRules(config.diagnosticRulesFor(ls.language))
+		ls.setEncodingRules(config.encodingRules())
+		ls.setDocumentLimit(config.documentLimit())
+		if ls.language == "" {
+			continue
+		}
+		settings := config.settingsFor(ls.language)
+		if !bytes.Equal(settings, old.settingsFor(ls.language)) {
+			changes = append(changes, fmt.Sprintf("language.%s.settings: sent to %d", ls.language, ls.id))
+			ls.setSettings(settings)
+		}
+	}
+
+	for _, change := range changes {
+		log.Printf("Config changed %s", change)
+	}
+	return changes
+}
+
+// setLogFile redirects the daemon log to path, or to stderr if path is empty.
+func setLogFile(path string) error {
+	var out io.Writer = os.Stderr
+	var file *os.File
+	if path != "" {
+		var err error
+		file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		out = file
+	}
+
+	log.SetOutput(out)
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = file
+	return nil
+}
+
+// ReloadConfig re-reads the config file.
+func (s *Server) ReloadConfig(_ bool, changes *[]string) error {
+	log.Print("CMD reload-config")
+	c, err := s.reloadConfig()
+	*changes = c
+	return err
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+// A daemon started with --listen tcp://<host>:<port> also accepts clients over
+// TCP, ie, on a build machine which keeps the indexes of a large project,
+// which connect to it with --remote. Every connection starts with the
+// handshake
+//
+//	lspc-auth <token>
+//
+// on a line of its own, which the daemon answers with ok, or with an error
+// before closing the connection. The connection then carries the same
+// requests as the socket. The token is not encrypted; use a tunnel on
+// untrusted networks.
+
+var gListen string
+var gRemote string
+var gAuthToken string
+
+const (
+	tcpScheme       = "tcp://"
+	handshakePrefix = "lspc-auth "
+	handshakeOK     = "ok"
+)
+
+// How long each side of the handshake gets to answer.
+const handshakeTimeout = 5 * time.Second
+
+// Longest handshake line that is read.
+const maxHandshakeSize = 4096
+
+// parseTCPAddress returns the <host>:<port> of a tcp://<host>:<port> address.
+func parseTCPAddress(address string) (string, error) {
+	hostPort := strings.TrimPrefix(address, tcpScheme)
+	if hostPort == address {
+		return "", fmt.Errorf("address must be tcp://<host>:<port>, got %q", address)
+	}
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		return "", fmt.Errorf("address must be tcp://<host>:<port>, got %q", address)
+	}
+	return hostPort, nil
+}
+
+// listenTCP accepts clients on address and passes those that authenticate
+// with token to conns.
+func listenTCP(address, token string, conns chan<- net.Conn) (net.Listener, error) {
+	if token == "" {
+		return nil, fmt.Errorf("--listen requires --auth-token")
+	}
+	addr, err := parseTCPAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Listening for clients on %s", listener.Addr())
+
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				if !shutdownRequested() {
+					log.Printf("%s", err.Error())
+				}
+				return
+			}
+			// A slow client must not hold up the others.
+			go func() {
+				if err := acceptHandshake(c, token); err != nil {
+					log.Printf("Rejecting client %s: %s", c.RemoteAddr(), err.Error())
+					c.Close()
+					return
+				}
+				conns <- c
+			}()
+		}
+	}()
+	return listener, nil
+}
+
+// acceptHandshake checks the handshake of a client against token.
+func acceptHandshake(c net.Conn, token string) error {
+	c.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	line, err := readHandshakeLine(c)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, handshakePrefix) {
+		fmt.Fprintf(c, "expected %s<token>\n", handshakePrefix)
+		return fmt.Errorf("no handshake")
+	}
+	given := strings.TrimPrefix(line, handshakePrefix)
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		fmt.Fprintln(c, "invalid auth token")
+		return fmt.Errorf("invalid auth token")
+	}
+	_, err = fmt.Fprintln(c, handshakeOK)
+	return err
+}
+
+// readHandshakeLine reads a line without reading past it, since the requests
+// that follow are read by the RPC codec.
+func readHandshakeLine(c net.Conn) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for len(line) < maxHandshakeSize {
+		if _, err := c.Read(b); err != nil {
+			return "", err
+		}
+		if b[0] == '\n' {
+			return string(line), nil
+		}
+		line = append(line, b[0])
+	}
+	return "", fmt.Errorf("handshake is longer than %d bytes", maxHandshakeSize)
+}
+
+// dialDaemon connects to the daemon given by --remote, or otherwise to the
+// one listening on the socket.
+func dialDaemon() (net.Conn, error) {
+	if gRemote == "" {
+		return net.Dial("unix", gSocket)
+	}
+	addr, err := parseTCPAddress(gRemote)
+	if err != nil {
+		return nil, err
+	}
+	c, err := net.DialTimeout("tcp", addr, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := dialHandshake(c, gAuthToken); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("%s rejected the connection: %s", gRemote, err.Error())
+	}
+	return c, nil
+}
+
+// dialHandshake authenticates with token.
+func dialHandshake(c net.Conn, token string) error {
+	c.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	if _, err := fmt.Fprintf(c, "%s%s\n", handshakePrefix, token); err != nil {
+		return err
+	}
+	answer, err := readHandshakeLine(c)
+	if err != nil {
+		return err
+	}
+	if answer != handshakeOK {
+		return fmt.Errorf("%s", answer)
+	}
+	return nil
+}
+
+// daemonAddress describes where the client connects to, for messages.
+func daemonAddress() string {
+	if gRemote != "" {
+		return gRemote
+	}
+	return fmt.Sprintf("%s (%s)", gSocket, gSocketSource)
+}
+
+// isLoopbackAddress returns if the <host>:<port> addr only accepts
+// connections from this machine. An empty host listens on every interface.
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkLocalDaemon fails commands which exchange data with the daemon over a
+// socket of their own, which a remote daemon cannot share.
+func checkLocalDaemon(command string) error {
+	if gRemote != "" {
+		return fmt.Errorf("%s is not supported with --remote", command)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTCPAddress(t *testing.T) {
+	addr, err := parseTCPAddress("tcp://build:7660")
+	assert.NoError(t, err)
+	assert.Equal(t, "build:7660", addr)
+	addr, err = parseTCPAddress("tcp://[::1]:7660")
+	assert.NoError(t, err)
+	assert.Equal(t, "[::1]:7660", addr)
+
+	for _, address := range []string{"build:7660", "unix:///tmp/lspc", "tcp://build"} {
+		_, err = parseTCPAddress(address)
+		assert.Error(t, err, address)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	handshake := func(sent, expected string) (error, error) {
+		client, daemon := net.Pipe()
+		defer client.Close()
+		defer daemon.Close()
+		accepted := make(chan error)
+		go func() {
+			accepted <- acceptHandshake(daemon, expected)
+		}()
+		dialed := dialHandshake(client, sent)
+		return dialed, <-accepted
+	}
+
+	dialed, accepted := handshake("secret", "secret")
+	assert.NoError(t, dialed)
+	assert.NoError(t, accepted)
+	dialed, accepted = handshake("guess", "secret")
+	assert.EqualError(t, dialed, "invalid auth token")
+	assert.EqualError(t, accepted, "invalid auth token")
+
+	// Requests may follow the handshake right away.
+	client, daemon := net.Pipe()
+	defer client.Close()
+	go func() {
+		dialHandshake(client, "secret")
+		client.Write([]byte("request"))
+	}()
+	assert.NoError(t, acceptHandshake(daemon, "secret"))
+	rest := make([]byte, 7)
+	_, err := daemon.Read(rest)
+	assert.NoError(t, err)
+	assert.Equal(t, "request", string(rest))
+}
+
+func TestRemoteDaemon(t *testing.T) {
+	defer func() { gRemote, gAuthToken = "", "" }()
+
+	_, err := listenTCP("tcp://127.0.0.1:0", "", nil)
+	assert.EqualError(t, err, "--listen requires --auth-token")
+
+	conns := make(chan net.Conn)
+	listener, err := listenTCP("tcp://127.0.0.1:0", "secret", conns)
+	assert.NoError(t, err)
+	defer listener.Close()
+	daemon := rpc.NewServer()
+	assert.NoError(t, daemon.Register(&Server{config: &Config{}}))
+	go func() {
+		for c := range conns {
+			go daemon.ServeConn(c)
+		}
+	}()
+
+	gRemote = "tcp://" + listener.Addr().String()
+	gAuthToken = "secret"
+	var pid int
+	assert.NoError(t, tryRPC("Server.ServerPid", false, &pid))
+	assert.Equal(t, os.Getpid(), pid)
+
+	gAuthToken = "guess"
+	err = tryRPC("Server.ServerPid", false, &pid)
+	assert.EqualError(t, err, gRemote+" rejected the connection: invalid auth token")
+
+	assert.EqualError(t, checkLocalDaemon("events"), "events is not supported with --remote")
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	assert.True(t, isLoopbackAddress("localhost:7659"))
+	assert.True(t, isLoopbackAddress("127.0.0.1:7659"))
+	assert.True(t, isLoopbackAddress("[::1]:7659"))
+	assert.False(t, isLoopbackAddress(":7659"))
+	assert.False(t, isLoopbackAddress("0.0.0.0:7659"))
+	assert.False(t, isLoopbackAddress("build-host:7659"))
+	assert.False(t, isLoopbackAddress("192.168.1.2:7659"))
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// RenameArgs are the arguments of Server.Rename.
+type RenameArgs struct {
+	PositionArgs
+	NewName string
+	// Write the edit to disk instead of only previewing it.
+	Apply bool
+}
+
+// rename asks the server for the edit that renames the symbol at args to
+// args.NewName.
+func (l *languageServer) rename(args RenameArgs) (LsWorkspaceEdit, error) {
+	position, err := l.positionParams(args.File, args.Line, args.Column, args.Unit)
+	if err != nil {
+		return LsWorkspaceEdit{}, err
+	}
+	params := LsRenameParams{
+		TextDocument: position.TextDocument,
+		Position:     position.Position,
+		NewName:      args.NewName,
+	}
+	result, err := l.call("textDocument/rename", toJSON(params), queryTimeout())
+	if err != nil {
+		return LsWorkspaceEdit{}, err
+	}
+	if string(result) == "null" {
+		return LsWorkspaceEdit{}, fmt.Errorf("cannot rename at %s:%d:%d", args.File, args.Line, args.Column)
+	}
+	var edit LsWorkspaceEdit
+	if err := json.Unmarshal(result, &edit); err != nil {
+		return LsWorkspaceEdit{}, fmt.Errorf("cannot parse rename result: %s", err.Error())
+	}
+	return edit, nil
+}
+
+// Rename renames the symbol at a position. preview describes the changes of
+// the edit, which are only written to disk if args.Apply is set.
+func (s *Server) Rename(args RenameArgs, preview *EditPreview) error {
+	log.Printf("CMD rename %s:%d:%d %s apply=%t", args.File, args.Line, args.Column, args.NewName, args.Apply)
+	if args.NewName == "" {
+		return errors.New("new name must not be empty")
+	}
+	server, err := s.serverForPosition(args.PositionArgs, "textDocument/rename")
+	if err != nil {
+		return err
+	}
+	// Fail before the server computes an edit that cannot be applied.
+	if args.Apply {
+		if err := server.checkWritable("rename --apply"); err != nil {
+			return err
+		}
+	}
+	edit, err := server.rename(args)
+	if err != nil {
+		return err
+	}
+	plan, err := server.planEdit("Rename to "+args.NewName, edit)
+	if err != nil {
+		return err
+	}
+	*preview = plan.preview()
+	if !args.Apply {
+		return nil
+	}
+	return server.applyPlan("rename --apply", plan)
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameArgs(t *testing.T) {
+	s := &Server{config: &Config{}}
+	var preview EditPreview
+	err := s.Rename(RenameArgs{PositionArgs: PositionArgs{File: "/p/a.c", Line: 1, Column: 1}}, &preview)
+	assert.EqualError(t, err, "new name must not be empty")
+	err = s.Rename(RenameArgs{PositionArgs: PositionArgs{File: "a.c", Line: 1, Column: 1}, NewName: "b"}, &preview)
+	assert.Error(t, err)
+}
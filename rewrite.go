@@ -0,0 +1,224 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RewriteRule sends definition queries that end in locations one server
+// cannot resolve on to another server, ie, from generated protobuf code to
+// the .proto file it was generated from:
+//
+//	[[rewrite]]
+//	match = '^file://(.*)/bazel-bin/(.*)\.pb\.(h|cc)$'
+//	replace = 'file://$1/$2.proto'
+//	by = "symbol"
+//
+// Rules also apply to locations in foreign schemes, ie, jdt:// URIs.
+type RewriteRule struct {
+	// Regular expression matched against the URI of every location a
+	// definition request returns. The first matching rule applies.
+	Match string `toml:"match"`
+
+	// The URI to query instead, where $1 and ${name} are replaced by the
+	// groups of Match.
+	Replace string `toml:"replace"`
+
+	// How to find the symbol in the rewritten file. "position", the default,
+	// asks the server for the rewritten file for the definition at the same
+	// position. "symbol" searches its workspace for the name found at the
+	// original location, for files whose lines do not correspond.
+	By string `toml:"by,omitempty"`
+}
+
+func (r RewriteRule) validate() map[string]error {
+	problems := make(map[string]error)
+	if _, err := regexp.Compile(r.Match); err != nil {
+		problems["match"] = err
+	}
+	if r.Replace == "" {
+		problems["replace"] = fmt.Errorf("must not be empty")
+	}
+	if r.By != "" && r.By != "position" && r.By != "symbol" {
+		problems["by"] = fmt.Errorf("must be position or symbol, got %q", r.By)
+	}
+	return problems
+}
+
+// rewriteRule is a compiled RewriteRule.
+type rewriteRule struct {
+	match    *regexp.Regexp
+	replace  string
+	bySymbol bool
+}
+
+// rewriteRules compiles the rewrite rules of c. Invalid rules, which config
+// validate reports, are skipped.
+func (c *Config) rewriteRules() []rewriteRule {
+	var rules []rewriteRule
+	for _, rule := range c.Rewrites {
+		if len(rule.validate()) > 0 {
+			continue
+		}
+		rules = append(rules, rewriteRule{
+			match:    regexp.MustCompile(rule.Match),
+			replace:  rule.Replace,
+			bySymbol: rule.By == "symbol",
+		})
+	}
+	return rules
+}
+
+// rewriter follows the rewrite rules of the config.
+type rewriter struct {
+	rules []rewriteRule
+	// Returns the server for a file. From outside of the main loop this must
+	// go through runOnMainLoop.
+	serverFor func(path string) (*languageServer, error)
+}
+
+// rewriter returns a rewriter for the current config. It must be called on
+// the main loop; viaMainLoop is set if the rewriter is used elsewhere.
+func (s *Server) rewriter(viaMainLoop bool) *rewriter {
+	serverFor := func(path string) (*languageServer, error) {
+		return s.serverForMethod(path, "textDocument/definition")
+	}
+	r := &rewriter{rules: s.currentConfig().rewriteRules(), serverFor: serverFor}
+	if viaMainLoop {
+		r.serverFor = func(path string) (server *languageServer, err error) {
+			runOnMainLoop(func() {
+				server, err = serverFor(path)
+			})
+			return server, err
+		}
+	}
+	return r
+}
+
+// rewrite returns the rule matching uri and the URI it rewrites uri to.
+func (r *rewriter) rewrite(uri LsDocumentURI) (rewriteRule, LsDocumentURI, bool) {
+	for _, rule := range r.rules {
+		match := rule.match.FindStringSubmatchIndex(string(uri))
+		if match == nil {
+			continue
+		}
+		rewritten := rule.match.ExpandString(nil, rule.replace, string(uri), match)
+		return rule, LsDocumentURI(rewritten), true
+	}
+	return rewriteRule{}, "", false
+}
+
+// follow replaces the locations that origin returned and that match a rule
+// with the definitions found by the server for the rewritten file. Locations
+// that cannot be followed are kept as they are.
+func (r *rewriter) follow(origin *languageServer, locations []LsLocation) []LsLocation {
+	var out []LsLocation
+	for _, location := range locations {
+		rule, uri, matched := r.rewrite(location.URI)
+		if !matched {
+			out = append(out, location)
+			continue
+		}
+		followed, err := r.followRule(origin, location, rule, uri)
+		if err != nil {
+			log.Printf("Unable to follow %s to %s: %s", location.URI, uri, err.Error())
+			out = append(out, location)
+			continue
+		}
+		out = append(out, followed...)
+	}
+	return out
+}
+
+func (r *rewriter) followRule(origin *languageServer, location LsLocation, rule rewriteRule, uri LsDocumentURI) ([]LsLocation, error) {
+	path := uriToPath(uri)
+	server, err := r.serverFor(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := server.openDocument(path); err != nil {
+		return nil, err
+	}
+
+	if !rule.bySymbol {
+		params := LsTextDocumentPositionParams{
+			TextDocument: LsTextDocumentIdentifier{URI: uri},
+			Position:     location.Range.Start,
+		}
+		definitions, err := server.definitionAt(params)
+		if err != nil {
+			return nil, err
+		}
+		if len(definitions) == 0 {
+			return []LsLocation{{URI: uri, Range: location.Range}}, nil
+		}
+		return definitions, nil
+	}
+
+	text, err := origin.documentText(uriToPath(location.URI))
+	if err != nil {
+		return nil, err
+	}
+	start := fromUTF16(text, location.Range.Start, ByteColumns)
+	name := identifierAt(lineAt(text, start.Line), start.Character)
+	if name == "" {
+		return nil, fmt.Errorf("no symbol name at %d:%d", start.Line+1, start.Character+1)
+	}
+	symbols, err := server.workspaceSymbolInformation(name)
+	if err != nil {
+		return nil, err
+	}
+	var found []LsLocation
+	for _, symbol := range symbols {
+		if symbol.Name == name && symbol.Location.URI == uri {
+			found = append(found, symbol.Location)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no symbol named %s", name)
+	}
+	return found, nil
+}
+
+// identifierAt returns the identifier in line around the byte column.
+func identifierAt(line string, column int) string {
+	isIdentifier := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	if column > len(line) {
+		return ""
+	}
+	start := column
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if !isIdentifier(r) {
+			break
+		}
+		start -= size
+	}
+	end := strings.IndexFunc(line[column:], func(r rune) bool { return !isIdentifier(r) })
+	if end < 0 {
+		end = len(line)
+	} else {
+		end += column
+	}
+	return line[start:end]
+}
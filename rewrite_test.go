@@ -0,0 +1,65 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteRuleValidate(t *testing.T) {
+	assert.Empty(t, RewriteRule{Match: `^jdt://(.*)$`, Replace: "file:///src/$1"}.validate())
+	assert.Empty(t, RewriteRule{Match: `x`, Replace: "y", By: "symbol"}.validate())
+
+	problems := RewriteRule{Match: `((`, By: "name"}.validate()
+	assert.Contains(t, problems, "match")
+	assert.Contains(t, problems, "replace")
+	assert.Contains(t, problems, "by")
+}
+
+func TestRewriterRewrite(t *testing.T) {
+	config := &Config{Rewrites: []RewriteRule{
+		{Match: `((`, Replace: "invalid"},
+		{Match: `^file://(.*)/bazel-bin/(?P<file>.*)\.pb\.(h|cc)$`, Replace: "file://$1/${file}.proto", By: "symbol"},
+		{Match: `^jdt://contents/(.*)\.class$`, Replace: "file:///src/$1.java"},
+	}}
+	r := &rewriter{rules: config.rewriteRules()}
+	assert.Len(t, r.rules, 2)
+
+	rule, uri, matched := r.rewrite("file:///p/bazel-bin/api/foo.pb.h")
+	assert.True(t, matched)
+	assert.True(t, rule.bySymbol)
+	assert.Equal(t, LsDocumentURI("file:///p/api/foo.proto"), uri)
+
+	rule, uri, matched = r.rewrite("jdt://contents/java/util/List.class")
+	assert.True(t, matched)
+	assert.False(t, rule.bySymbol)
+	assert.Equal(t, LsDocumentURI("file:///src/java/util/List.java"), uri)
+
+	_, _, matched = r.rewrite("file:///p/api/foo.h")
+	assert.False(t, matched)
+}
+
+func TestIdentifierAt(t *testing.T) {
+	line := "  Foo::bar_baz(x→y1);"
+	assert.Equal(t, "Foo", identifierAt(line, 2))
+	assert.Equal(t, "Foo", identifierAt(line, 4))
+	assert.Equal(t, "bar_baz", identifierAt(line, 7))
+	assert.Equal(t, "bar_baz", identifierAt(line, 14))
+	assert.Equal(t, "y1", identifierAt(line, 19))
+	assert.Equal(t, "", identifierAt(line, 0))
+	assert.Equal(t, "", identifierAt(line, 100))
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	shellwords "github.com/mattn/go-shellwords"
+)
+
+// RootConfig maps a subdirectory of a checkout to the language server that
+// handles it, so one checkout of a polyglot monorepo can be served by several
+// servers, ie,
+//
+//	[[root]]
+//	path = "frontend"
+//	language = "typescript"
+//
+//	[[root]]
+//	path = "native"
+//	command = "clangd --background-index"
+//
+// A query about a file inside a root starts the server for the root if it is
+// not already running; the file is then routed to it since its directory is
+// the closest.
+type RootConfig struct {
+	// Relative to the directory of .lspc.toml.
+	Path string `toml:"path"`
+
+	// Configured language whose command serves the root.
+	Language string `toml:"language,omitempty"`
+
+	// Runs instead of the command of the language.
+	Command string `toml:"command,omitempty"`
+}
+
+// validate returns problems keyed by the name of the setting.
+func (r RootConfig) validate() map[string]error {
+	problems := make(map[string]error)
+	if r.Path == "" {
+		problems["path"] = fmt.Errorf("missing")
+	} else if filepath.IsAbs(r.Path) {
+		problems["path"] = fmt.Errorf("must be relative to the project directory, got %q", r.Path)
+	} else if clean := filepath.Clean(r.Path); clean == ".." || clean == "." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		problems["path"] = fmt.Errorf("must be a subdirectory of the project directory, got %q", r.Path)
+	}
+	validateCommand(r.Command, r.Language, problems)
+	return problems
+}
+
+// validateCommand checks the command and language of a root or server in a
+// project config and adds problems to problems.
+func validateCommand(command, language string, problems map[string]error) {
+	if command == "" && language == "" {
+		problems["command"] = fmt.Errorf("either command or language must be set")
+	} else if command != "" {
+		if words, err := shellwords.Parse(command); err != nil {
+			problems["command"] = err
+		} else if len(words) == 0 {
+			problems["command"] = fmt.Errorf("no program")
+		}
+	}
+}
+
+// findCheckout returns the closest directory containing path, or path itself,
+// whose project config maps roots.
+func findCheckout(path string) (string, *ProjectConfig, bool) {
+	for directory := filepath.Clean(path); ; {
+		project, err := loadProjectConfig(directory)
+		if err != nil {
+			log.Printf("%s", err.Error())
+		} else if len(project.Roots) > 0 {
+			return directory, project, true
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", nil, false
+		}
+		directory = parent
+	}
+}
+
+// rootFor returns the root of project, which is the config of checkout, that
+// contains path. The innermost root wins.
+func (p *ProjectConfig) rootFor(checkout, path string) (RootConfig, string, bool) {
+	var best RootConfig
+	bestDir := ""
+	for _, root := range p.Roots {
+		dir := filepath.Join(checkout, root.Path)
+		if pathInDirectory(path, dir) && len(dir) > len(bestDir) {
+			best, bestDir = root, dir
+		}
+	}
+	return best, bestDir, bestDir != ""
+}
+
+// rootStartArgs returns how to start the server for root, which is in
+// directory.
+func (c *Config) rootStartArgs(root RootConfig, directory string) (StartArgs, error) {
+	args := StartArgs{Bin: root.Command, Directory: directory, Language: root.Language}
+	if args.Bin == "" {
+		language, has := c.Languages[root.Language]
+		if !has || language.Command == "" {
+			return StartArgs{}, fmt.Errorf("root %s: language %q has no command", directory, root.Language)
+		}
+		args.Bin = language.Command
+	}
+	return args, nil
+}
+
+// startRootFor starts the language server of the root containing path if the
+// checkout of path maps roots and none runs for it yet.
+func (s *Server) startRootFor(path string) error {
+	checkout, project, found := findCheckout(filepath.Dir(path))
+	if !found {
+		return nil
+	}
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+	root, directory, found := project.rootFor(checkout, path)
+	if !found {
+		return nil
+	}
+	for _, server := range s.serverList() {
+		if filepath.Clean(server.directory) == directory {
+			return nil
+		}
+	}
+
+	args, err := s.currentConfig().rootStartArgs(root, directory)
+	if err != nil {
+		return err
+	}
+	log.Printf("Starting %s for root %s", args.Bin, directory)
+	_, err = s.start(args)
+	return err
+}
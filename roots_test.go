@@ -0,0 +1,96 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootConfigValidate(t *testing.T) {
+	assert.Empty(t, RootConfig{Path: "frontend", Language: "typescript"}.validate())
+	assert.Empty(t, RootConfig{Path: "services/go", Command: "gopls"}.validate())
+
+	problems := RootConfig{}.validate()
+	assert.Contains(t, problems, "path")
+	assert.Contains(t, problems, "command")
+	assert.Contains(t, RootConfig{Path: "/src", Command: "gopls"}.validate(), "path")
+	assert.Contains(t, RootConfig{Path: "../other", Command: "gopls"}.validate(), "path")
+	assert.Contains(t, RootConfig{Path: ".", Command: "gopls"}.validate(), "path")
+	assert.Contains(t, RootConfig{Path: "native", Command: "clangd 'x"}.validate(), "command")
+}
+
+func TestRootFor(t *testing.T) {
+	project := &ProjectConfig{Roots: []RootConfig{
+		{Path: "services", Language: "java"},
+		{Path: "services/go", Language: "go"},
+		{Path: "frontend", Language: "typescript"},
+	}}
+
+	root, dir, found := project.rootFor("/src", "/src/services/go/main.go")
+	assert.True(t, found)
+	assert.Equal(t, "go", root.Language)
+	assert.Equal(t, "/src/services/go", dir)
+
+	root, _, found = project.rootFor("/src", "/src/services/Main.java")
+	assert.True(t, found)
+	assert.Equal(t, "java", root.Language)
+
+	_, _, found = project.rootFor("/src", "/src/frontend-old/index.ts")
+	assert.False(t, found)
+	_, _, found = project.rootFor("/src", "/src/README.md")
+	assert.False(t, found)
+}
+
+func TestRootStartArgs(t *testing.T) {
+	config := &Config{Languages: map[string]LanguageConfig{"go": {Command: "gopls serve"}}}
+
+	args, err := config.rootStartArgs(RootConfig{Path: "services/go", Language: "go"}, "/src/services/go")
+	assert.NoError(t, err)
+	assert.Equal(t, StartArgs{Bin: "gopls serve", Directory: "/src/services/go", Language: "go"}, args)
+
+	args, err = config.rootStartArgs(RootConfig{Path: "native", Command: "clangd"}, "/src/native")
+	assert.NoError(t, err)
+	assert.Equal(t, "clangd", args.Bin)
+
+	_, err = config.rootStartArgs(RootConfig{Path: "frontend", Language: "typescript"}, "/src/frontend")
+	assert.Error(t, err)
+}
+
+func TestFindCheckout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	native := filepath.Join(dir, "native", "src")
+	assert.NoError(t, os.MkdirAll(native, 0755))
+	config := "read_only = true\n[[root]]\npath = \"native\"\ncommand = \"clangd\"\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, projectConfigName), []byte(config), 0644))
+
+	checkout, project, found := findCheckout(native)
+	assert.True(t, found)
+	assert.Equal(t, dir, checkout)
+	assert.Len(t, project.Roots, 1)
+
+	_, _, found = findCheckout(os.TempDir())
+	assert.False(t, found)
+
+	// Roots of a read-only checkout are read-only.
+	assert.True(t, (&Config{}).readOnlyFor(filepath.Join(dir, "native")))
+	assert.False(t, (&Config{}).readOnlyFor(os.TempDir()))
+}
@@ -0,0 +1,255 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+
+	easyjson "github.com/mailru/easyjson"
+)
+
+// The method routed to pick the servers whose diagnostics are reported.
+const diagnosticsMethod = "textDocument/publishDiagnostics"
+
+// RouteRule sends requests for some methods to specific servers among those
+// running for a project, ie, formatting to a formatter server while
+// everything else goes to clangd:
+//
+//	[[route]]
+//	methods = ["textDocument/formatting", "textDocument/rangeFormatting"]
+//	path = '\.(c|h)$'
+//	servers = ["efm-langserver"]
+//
+//	[[route]]
+//	methods = ["textDocument/codeAction", "textDocument/publishDiagnostics"]
+//	servers = ["clangd", "efm-langserver"]
+//
+// Results of several servers are merged. Without a rule requests go to the
+// server for the file and the diagnostics of every server are reported. The
+// first matching rule applies.
+type RouteRule struct {
+	// LSP methods the rule applies to. A method also matches the methods
+	// below it, ie, textDocument/semanticTokens matches
+	// textDocument/semanticTokens/full.
+	Methods []string `toml:"methods"`
+
+	// Regular expression matched against the path of the file relative to
+	// the project directory, with / as separator. Empty matches everything.
+	Path string `toml:"path,omitempty"`
+
+	// Servers to send the requests to, by configured language or program
+	// name. Servers that are not running are skipped; if none is, the rule
+	// does not apply.
+	Servers []string `toml:"servers"`
+}
+
+func (r RouteRule) validate() map[string]error {
+	problems := make(map[string]error)
+	if len(r.Methods) == 0 {
+		problems["methods"] = fmt.Errorf("must not be empty")
+	}
+	if _, err := regexp.Compile(r.Path); err != nil {
+		problems["path"] = err
+	}
+	if len(r.Servers) == 0 {
+		problems["servers"] = fmt.Errorf("must not be empty")
+	}
+	return problems
+}
+
+// routeRule is a compiled RouteRule.
+type routeRule struct {
+	methods []string
+	path    *regexp.Regexp
+	servers []string
+}
+
+// routeRules compiles the route rules of c. Invalid rules, which config
+// validate reports, are skipped.
+func (c *Config) routeRules() []routeRule {
+	var rules []routeRule
+	for _, rule := range c.Routes {
+		if len(rule.validate()) > 0 {
+			continue
+		}
+		rules = append(rules, routeRule{
+			methods: rule.Methods,
+			path:    regexp.MustCompile(rule.Path),
+			servers: rule.Servers,
+		})
+	}
+	return rules
+}
+
+func (r routeRule) matches(method, path string) bool {
+	if !r.path.MatchString(path) {
+		return false
+	}
+	for _, m := range r.methods {
+		if method == m || strings.HasPrefix(method, m+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// route returns the servers among running that the first rule matching
+// method and path, which is relative to the project directory, sends to, in
+// the order of the rule.
+func route(rules []routeRule, method, path string, running []*languageServer) ([]*languageServer, bool) {
+	for _, rule := range rules {
+		if !rule.matches(method, path) {
+			continue
+		}
+		routed := serversNamed(rule.servers, running)
+		return routed, len(routed) > 0
+	}
+	return nil, false
+}
+
+// serversNamed returns the servers among running whose configured language
+// or program name is one of names, in the order of names.
+func serversNamed(names []string, running []*languageServer) []*languageServer {
+	var named []*languageServer
+	seen := make(map[*languageServer]bool)
+	for _, name := range names {
+		for _, server := range running {
+			if !seen[server] && (server.language == name || server.name() == name) {
+				seen[server] = true
+				named = append(named, server)
+			}
+		}
+	}
+	return named
+}
+
+// serversForMethod returns the language servers that requests for method
+// about path are sent to according to the route rules. The first server is
+// the one whose result is used when results cannot be merged.
+func (s *Server) serversForMethod(path, method string) ([]*languageServer, error) {
+	return s.serversForRequest(path, method, "")
+}
+
+// serversForRequest is serversForMethod, except that if group is set the
+// request is broadcast to the running servers of that group instead.
+func (s *Server) serversForRequest(path, method, group string) ([]*languageServer, error) {
+	if group != "" {
+		return s.serversForGroup(path, group)
+	}
+	running, err := s.serversForFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules := s.currentConfig().routeRules()
+	if routed, found := route(rules, method, running[0].relativePath(path), running); found {
+		return routed, nil
+	}
+	if method == diagnosticsMethod {
+		return running, nil
+	}
+	return running[:1], nil
+}
+
+// serverForMethod returns the language server for requests which only one
+// server can answer, ie, hover.
+func (s *Server) serverForMethod(path, method string) (*languageServer, error) {
+	servers, err := s.serversForMethod(path, method)
+	if err != nil {
+		return nil, err
+	}
+	return servers[0], nil
+}
+
+// callServers calls query for every server in parallel with the index of the
+// server. The request only fails if the first server fails; failures of the
+// others are logged, so that a broken secondary server does not hide the
+// answer of the primary one.
+func callServers(servers []*languageServer, method string, query func(i int, l *languageServer) error) error {
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server *languageServer) {
+			defer wg.Done()
+			errs[i] = query(i, server)
+		}(i, server)
+	}
+	wg.Wait()
+	for i, err := range errs[1:] {
+		if err != nil {
+			log.Printf("Ignoring %s of %s: %s", method, servers[i+1].name(), err.Error())
+		}
+	}
+	return errs[0]
+}
+
+// mergeResults merges the results several servers returned for one request.
+// Arrays, ie, of locations or code actions, are concatenated, as are the
+// items of completion lists, and single objects are added to them. If no
+// server returned an array or a list the first result that is not null wins,
+// since results such as hovers cannot be merged.
+func mergeResults(results []easyjson.RawMessage) easyjson.RawMessage {
+	var first easyjson.RawMessage
+	var elements []json.RawMessage
+	isArray, isList, incomplete := false, false, false
+	for _, result := range results {
+		trimmed := bytes.TrimSpace(result)
+		if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+		if first == nil {
+			first = easyjson.RawMessage(trimmed)
+		}
+		var array []json.RawMessage
+		if json.Unmarshal(trimmed, &array) == nil {
+			isArray = true
+			elements = append(elements, array...)
+			continue
+		}
+		var list struct {
+			IsIncomplete bool              `json:"isIncomplete"`
+			Items        []json.RawMessage `json:"items"`
+		}
+		if json.Unmarshal(trimmed, &list) == nil && list.Items != nil {
+			isList = true
+			incomplete = incomplete || list.IsIncomplete
+			elements = append(elements, list.Items...)
+			continue
+		}
+		elements = append(elements, json.RawMessage(trimmed))
+	}
+
+	if elements == nil {
+		elements = []json.RawMessage{}
+	}
+	var merged []byte
+	switch {
+	case first == nil:
+		return easyjson.RawMessage("null")
+	case isList:
+		merged, _ = json.Marshal(map[string]interface{}{"isIncomplete": incomplete, "items": elements})
+	case isArray:
+		merged, _ = json.Marshal(elements)
+	default:
+		return first
+	}
+	return easyjson.RawMessage(merged)
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteRuleValidate(t *testing.T) {
+	assert.Empty(t, RouteRule{Methods: []string{"textDocument/formatting"}, Path: `\.c$`, Servers: []string{"efm-langserver"}}.validate())
+
+	problems := RouteRule{Path: `((`}.validate()
+	assert.Contains(t, problems, "methods")
+	assert.Contains(t, problems, "path")
+	assert.Contains(t, problems, "servers")
+}
+
+func TestRoute(t *testing.T) {
+	clangd := &languageServer{args: []string{"/usr/bin/clangd"}, language: "cpp"}
+	efm := &languageServer{args: []string{"efm-langserver"}}
+	running := []*languageServer{clangd, efm}
+
+	config := &Config{Routes: []RouteRule{
+		{Methods: []string{"textDocument/formatting"}, Path: `\.c$`, Servers: []string{"efm-langserver"}},
+		{Methods: []string{"textDocument/codeAction", "textDocument/semanticTokens"}, Servers: []string{"efm-langserver", "cpp", "clangd"}},
+		{Methods: []string{"textDocument/hover"}, Servers: []string{"pyright"}},
+		{Methods: []string{"textDocument/hover"}, Servers: []string{"clangd"}},
+	}}
+	rules := config.routeRules()
+
+	routed, found := route(rules, "textDocument/formatting", "src/a.c", running)
+	assert.True(t, found)
+	assert.Equal(t, []*languageServer{efm}, routed)
+	_, found = route(rules, "textDocument/formatting", "src/a.cc", running)
+	assert.False(t, found)
+
+	// Servers are listed once, in the order of the rule.
+	routed, found = route(rules, "textDocument/codeAction", "a.cc", running)
+	assert.True(t, found)
+	assert.Equal(t, []*languageServer{efm, clangd}, routed)
+	_, found = route(rules, "textDocument/semanticTokens/full/delta", "a.cc", running)
+	assert.True(t, found)
+	_, found = route(rules, "textDocument/codeActionX", "a.cc", running)
+	assert.False(t, found)
+
+	// The first matching rule applies even if none of its servers runs.
+	_, found = route(rules, "textDocument/hover", "a.cc", running)
+	assert.False(t, found)
+}
+
+func TestMergeResults(t *testing.T) {
+	merge := func(results ...string) string {
+		var raw []easyjson.RawMessage
+		for _, result := range results {
+			raw = append(raw, easyjson.RawMessage(result))
+		}
+		return string(mergeResults(raw))
+	}
+
+	assert.Equal(t, "null", merge("null", ""))
+	assert.Equal(t, `{"contents":"a"}`, merge("null", `{"contents":"a"}`, `{"contents":"b"}`))
+	assert.Equal(t, `[]`, merge("[]", "null"))
+	assert.Equal(t, `[{"uri":"a"},{"uri":"b"},{"uri":"c"}]`, merge(`[{"uri":"a"}]`, "null", `{"uri":"b"}`, `[{"uri":"c"}]`))
+	assert.Equal(t, `{"isIncomplete":true,"items":[{"label":"a"},{"label":"b"},{"label":"c"}]}`,
+		merge(`{"isIncomplete":false,"items":[{"label":"a"}]}`, `[{"label":"b"}]`, `{"isIncomplete":true,"items":[{"label":"c"}]}`))
+}
+
+func TestCallServers(t *testing.T) {
+	servers := []*languageServer{{args: []string{"a"}}, {args: []string{"b"}}}
+	names := make([]string, len(servers))
+	err := callServers(servers, "m", func(i int, l *languageServer) error {
+		names[i] = l.name()
+		if i == 1 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	err = callServers(servers, "m", func(i int, l *languageServer) error {
+		if i == 0 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+	assert.Error(t, err)
+}
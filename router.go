@@ -0,0 +1,71 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pathInDirectory returns true if path is directory or is inside of it. Both
+// paths must be absolute.
+func pathInDirectory(path, directory string) bool {
+	path = filepath.Clean(path)
+	directory = filepath.Clean(directory)
+	if path == directory {
+		return true
+	}
+	if !strings.HasSuffix(directory, string(filepath.Separator)) {
+		directory += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, directory)
+}
+
+// serverForPath returns the server whose directory is the longest prefix of
+// path. Of several servers for that directory the first one wins.
+func serverForPath(servers []ServerInfo, path string) (ServerInfo, bool) {
+	best, bestLen := -1, 0
+	for i, server := range servers {
+		if !pathInDirectory(path, server.Directory) {
+			continue
+		}
+		// Compared cleaned so that a trailing separator does not make a
+		// directory longer.
+		if n := len(filepath.Clean(server.Directory)); best < 0 || n > bestLen {
+			best, bestLen = i, n
+		}
+	}
+	if best < 0 {
+		return ServerInfo{}, false
+	}
+	return servers[best], true
+}
+
+// projectDirectories returns the distinct directories of servers, sorted, to
+// list the candidates when no server covers a path.
+func projectDirectories(servers []ServerInfo) []string {
+	var directories []string
+	seen := make(map[string]bool)
+	for _, server := range servers {
+		directory := filepath.Clean(server.Directory)
+		if !seen[directory] {
+			seen[directory] = true
+			directories = append(directories, directory)
+		}
+	}
+	sort.Strings(directories)
+	return directories
+}
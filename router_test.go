@@ -0,0 +1,60 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathInDirectory(t *testing.T) {
+	assert.True(t, pathInDirectory("/a/b", "/a/b"))
+	assert.True(t, pathInDirectory("/a/b/c.cc", "/a/b"))
+	assert.True(t, pathInDirectory("/a/b/c.cc", "/a/b/"))
+	assert.True(t, pathInDirectory("/a/b/c.cc", "/"))
+	assert.False(t, pathInDirectory("/a/bc/d.cc", "/a/b"))
+	assert.False(t, pathInDirectory("/a", "/a/b"))
+}
+
+func TestServerForPath(t *testing.T) {
+	servers := []ServerInfo{
+		{ID: 0, Directory: "/work"},
+		{ID: 1, Directory: "/work/chrome"},
+		{ID: 2, Directory: "/other"},
+	}
+
+	server, found := serverForPath(servers, "/work/chrome/base/a.cc")
+	assert.True(t, found)
+	assert.Equal(t, 1, server.ID)
+
+	server, found = serverForPath(servers, "/work/v8/a.cc")
+	assert.True(t, found)
+	assert.Equal(t, 0, server.ID)
+
+	_, found = serverForPath(servers, "/home/a.cc")
+	assert.False(t, found)
+
+	// A trailing separator does not make a directory longer.
+	server, found = serverForPath([]ServerInfo{{ID: 0, Directory: "/work/"}, {ID: 1, Directory: "/work"}}, "/work/a.cc")
+	assert.True(t, found)
+	assert.Equal(t, 0, server.ID)
+}
+
+func TestProjectDirectories(t *testing.T) {
+	servers := []ServerInfo{{Directory: "/work/"}, {Directory: "/other"}, {Directory: "/work"}}
+	assert.Equal(t, []string{"/other", "/work"}, projectDirectories(servers))
+	assert.Empty(t, projectDirectories(nil))
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox tools.
+const (
+	sandboxNone     = "none"
+	sandboxBwrap    = "bwrap"
+	sandboxFirejail = "firejail"
+)
+
+// Directories with the system's programs and libraries, which sandboxed
+// servers may read.
+var sandboxSystemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt", "/nix"}
+
+// sandboxFor returns the sandbox for servers of language. The language's
+// sandbox replaces the global one if it sets a tool; otherwise its paths are
+// added to the global sandbox.
+func (c *Config) sandboxFor(language string) SandboxConfig {
+	global := c.Sandbox
+	own := c.Languages[language].Sandbox
+	if own.Tool != "" {
+		return own
+	}
+	return SandboxConfig{
+		Tool:          global.Tool,
+		Paths:         append(append([]string(nil), global.Paths...), own.Paths...),
+		ReadOnlyPaths: append(append([]string(nil), global.ReadOnlyPaths...), own.ReadOnlyPaths...),
+	}
+}
+
+func (s SandboxConfig) validate() error {
+	switch s.Tool {
+	case "", sandboxNone, sandboxBwrap, sandboxFirejail:
+		return nil
+	}
+	return fmt.Errorf("unknown tool %q; use %s, %s or %s", s.Tool, sandboxBwrap, sandboxFirejail, sandboxNone)
+}
+
+// sandboxPath makes a configured path absolute. ~ is the home directory and
+// relative paths are inside of the project directory.
+func sandboxPath(path, directory string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(directory, path)
+	}
+	return filepath.Clean(path)
+}
+
+// sandboxCommand returns the command line which runs argv, the command line
+// of a language server started in directory, inside of the sandbox. If
+// readOnlyProject is set the server cannot write to directory, only to the
+// configured paths.
+func sandboxCommand(sandbox SandboxConfig, argv []string, directory string, readOnlyProject bool) ([]string, error) {
+	if sandbox.Tool == "" || sandbox.Tool == sandboxNone {
+		return argv, nil
+	}
+	if err := sandbox.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath(sandbox.Tool); err != nil {
+		return nil, fmt.Errorf("cannot sandbox %s: %s", argv[0], err.Error())
+	}
+
+	var readOnly, writable []string
+	// The server itself may be installed outside of the system directories,
+	// ie, by lspc install.
+	if program, err := exec.LookPath(argv[0]); err == nil {
+		if program, err = filepath.Abs(program); err == nil {
+			readOnly = append(readOnly, filepath.Dir(program))
+		}
+	}
+	if gInstallDir != "" && fileExists(gInstallDir) {
+		readOnly = append(readOnly, gInstallDir)
+	}
+	for _, path := range sandbox.ReadOnlyPaths {
+		readOnly = append(readOnly, sandboxPath(path, directory))
+	}
+	if readOnlyProject {
+		readOnly = append(readOnly, directory)
+	} else {
+		writable = append(writable, directory)
+	}
+	for _, path := range sandbox.Paths {
+		path = sandboxPath(path, directory)
+		// Caches may not exist yet, and cannot be created inside of the
+		// sandbox.
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+		writable = append(writable, path)
+	}
+
+	var args []string
+	switch sandbox.Tool {
+	case sandboxBwrap:
+		args = []string{sandboxBwrap, "--die-with-parent", "--new-session", "--unshare-all", "--share-net"}
+		for _, dir := range sandboxSystemDirs {
+			// Usually /bin and friends link into /usr.
+			if target, err := os.Readlink(dir); err == nil {
+				args = append(args, "--symlink", target, dir)
+			} else {
+				args = append(args, "--ro-bind-try", dir, dir)
+			}
+		}
+		args = append(args, "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp")
+		for _, path := range readOnly {
+			args = append(args, "--ro-bind-try", path, path)
+		}
+		for _, path := range writable {
+			args = append(args, "--bind", path, path)
+		}
+		args = append(args, "--chdir", directory, "--")
+
+	case sandboxFirejail:
+		args = []string{sandboxFirejail, "--quiet", "--noprofile", "--private-tmp", "--caps.drop=all", "--nonewprivs"}
+		for _, path := range readOnly {
+			args = append(args, "--whitelist="+path, "--read-only="+path)
+		}
+		for _, path := range writable {
+			args = append(args, "--whitelist="+path)
+		}
+		args = append(args, "--")
+	}
+	return append(args, argv...), nil
+}
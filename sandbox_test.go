@@ -0,0 +1,100 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandboxFor(t *testing.T) {
+	config := &Config{
+		Sandbox: SandboxConfig{Tool: "bwrap", Paths: []string{"~/.cache"}},
+		Languages: map[string]LanguageConfig{
+			"rust":   {Sandbox: SandboxConfig{Paths: []string{"~/.cargo"}}},
+			"python": {Sandbox: SandboxConfig{Tool: "none"}},
+			"go":     {Sandbox: SandboxConfig{Tool: "firejail", ReadOnlyPaths: []string{"/go"}}},
+		},
+	}
+	assert.Equal(t, SandboxConfig{Tool: "bwrap", Paths: []string{"~/.cache"}}, config.sandboxFor("cpp"))
+	assert.Equal(t, SandboxConfig{Tool: "bwrap", Paths: []string{"~/.cache", "~/.cargo"}}, config.sandboxFor("rust"))
+	assert.Equal(t, SandboxConfig{Tool: "none"}, config.sandboxFor("python"))
+	assert.Equal(t, SandboxConfig{Tool: "firejail", ReadOnlyPaths: []string{"/go"}}, config.sandboxFor("go"))
+	// The global config is not modified.
+	assert.Equal(t, []string{"~/.cache"}, config.Sandbox.Paths)
+
+	assert.NoError(t, SandboxConfig{}.validate())
+	assert.Error(t, SandboxConfig{Tool: "docker"}.validate())
+}
+
+func TestSandboxPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cache"), sandboxPath("~/.cache", "/src"))
+	assert.Equal(t, "/src/build", sandboxPath("build", "/src"))
+	assert.Equal(t, "/opt/sdk", sandboxPath("/opt/sdk/", "/src"))
+}
+
+func TestSandboxCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+
+	argv := []string{"clangd", "--log=error"}
+	unchanged, err := sandboxCommand(SandboxConfig{Tool: "none"}, argv, "/src", false)
+	assert.NoError(t, err)
+	assert.Equal(t, argv, unchanged)
+	_, err = sandboxCommand(SandboxConfig{Tool: "bwrap"}, argv, "/src", false)
+	assert.Error(t, err, "bwrap is not installed")
+
+	for _, name := range []string{"bwrap", "firejail", "clangd"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), nil, 0755))
+	}
+	cache := filepath.Join(dir, "cache")
+	sandbox := SandboxConfig{Tool: "bwrap", Paths: []string{cache}, ReadOnlyPaths: []string{"/sdk"}}
+
+	wrapped, err := sandboxCommand(sandbox, argv, "/src", false)
+	assert.NoError(t, err)
+	command := strings.Join(wrapped, " ")
+	assert.True(t, strings.HasPrefix(command, "bwrap --die-with-parent"))
+	assert.Contains(t, command, "--ro-bind-try "+dir+" "+dir)
+	assert.Contains(t, command, "--ro-bind-try /sdk /sdk")
+	assert.Contains(t, command, "--bind /src /src --bind "+cache+" "+cache)
+	assert.True(t, strings.HasSuffix(command, "--chdir /src -- clangd --log=error"))
+	assert.True(t, fileExists(cache))
+
+	sandbox.Tool = "firejail"
+	wrapped, err = sandboxCommand(sandbox, argv, "/src", false)
+	assert.NoError(t, err)
+	command = strings.Join(wrapped, " ")
+	assert.True(t, strings.HasPrefix(command, "firejail --quiet --noprofile"))
+	assert.Contains(t, command, "--whitelist=/sdk --read-only=/sdk")
+	assert.Contains(t, command, "--whitelist=/src --whitelist="+cache)
+	assert.True(t, strings.HasSuffix(command, "-- clangd --log=error"))
+
+	sandbox.Tool = "bwrap"
+	wrapped, err = sandboxCommand(sandbox, argv, "/src", true)
+	assert.NoError(t, err)
+	command = strings.Join(wrapped, " ")
+	assert.Contains(t, command, "--ro-bind-try /src /src")
+	assert.NotContains(t, command, "--bind /src /src")
+}
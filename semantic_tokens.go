@@ -0,0 +1,300 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	easyjson "github.com/mailru/easyjson"
+)
+
+// The token types and modifiers predefined by the protocol, which lspc
+// accepts from servers.
+var (
+	semanticTokenTypes = []string{
+		"namespace", "type", "class", "enum", "interface", "struct",
+		"typeParameter", "parameter", "variable", "property", "enumMember",
+		"event", "function", "method", "macro", "keyword", "modifier",
+		"comment", "string", "number", "regexp", "operator", "decorator",
+	}
+	semanticTokenModifiers = []string{
+		"declaration", "definition", "readonly", "static", "deprecated",
+		"abstract", "async", "modification", "documentation", "defaultLibrary",
+	}
+)
+
+// SemanticToken is a decoded semantic token as shown to users.
+type SemanticToken struct {
+	Location
+	Type      string   `json:"type"`
+	Modifiers []string `json:"modifiers,omitempty"`
+}
+
+// semanticTokensState is the latest tokens of a document, which are needed
+// to apply the edits of a full/delta response.
+type semanticTokensState struct {
+	// Given to clients, which can pass it back to only receive the changes.
+	id string
+	// Given by the server; sent as previousResultId of full/delta requests.
+	serverResultID string
+	data           []uint32
+}
+
+// semanticTokensProvider is the semanticTokensProvider server capability.
+type semanticTokensProvider struct {
+	legend LsSemanticTokensLegend
+	delta  bool
+}
+
+// parseSemanticTokensProvider returns the semantic tokens support announced
+// in capabilities, or nil if there is none.
+func parseSemanticTokensProvider(capabilities easyjson.RawMessage) *semanticTokensProvider {
+	var caps struct {
+		Provider *struct {
+			Legend LsSemanticTokensLegend `json:"legend"`
+			// Either a boolean or {"delta": boolean}.
+			Full json.RawMessage `json:"full"`
+		} `json:"semanticTokensProvider"`
+	}
+	if err := json.Unmarshal(capabilities, &caps); err != nil || caps.Provider == nil {
+		return nil
+	}
+	provider := &semanticTokensProvider{legend: caps.Provider.Legend}
+	var full struct {
+		Delta bool `json:"delta"`
+	}
+	if json.Unmarshal(caps.Provider.Full, &full) == nil {
+		provider.delta = full.Delta
+	}
+	return provider
+}
+
+// semanticTokensProvider returns the semantic tokens support of l, or nil if
+// it has none or is not initialized yet.
+func (l *languageServer) semanticTokensProvider() *semanticTokensProvider {
+	l.mu.Lock()
+	result := l.initializeResult
+	l.mu.Unlock()
+	if result == nil {
+		return nil
+	}
+	return parseSemanticTokensProvider(result.Capabilities)
+}
+
+// semanticTokens returns the tokens of the whole document at path, along
+// with the tokens returned by the previous call. Once the server has returned
+// tokens with a result id, only the changes since are requested if the
+// server supports full/delta.
+func (l *languageServer) semanticTokens(path string) (previous, current semanticTokensState, err error) {
+	if _, err := l.openDocument(path); err != nil {
+		return previous, current, err
+	}
+	uri := pathToURI(path)
+	document := LsTextDocumentIdentifier{URI: uri}
+
+	l.docMu.Lock()
+	previous = l.tokens[uri]
+	l.docMu.Unlock()
+
+	var data []uint32
+	var resultID string
+	if provider := l.semanticTokensProvider(); provider != nil && provider.delta && previous.serverResultID != "" {
+		var result easyjson.RawMessage
+		result, err = l.call("textDocument/semanticTokens/full/delta", toJSON(LsSemanticTokensDeltaParams{
+			TextDocument:     document,
+			PreviousResultID: previous.serverResultID,
+		}), queryTimeout())
+		if err == nil {
+			data, resultID, err = parseSemanticTokensResult(result, previous.data)
+		}
+	}
+	// Servers may have forgotten the previous result; start over.
+	if data == nil {
+		var result easyjson.RawMessage
+		result, err = l.call("textDocument/semanticTokens/full", toJSON(LsSemanticTokensParams{TextDocument: document}), queryTimeout())
+		if err != nil {
+			return previous, current, err
+		}
+		if data, resultID, err = parseSemanticTokensResult(result, nil); err != nil {
+			return previous, current, err
+		}
+	}
+
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+	current = semanticTokensState{id: previous.id, serverResultID: resultID, data: data}
+	if previous.id == "" || !equalTokens(previous.data, data) {
+		l.tokensVersion++
+		current.id = strconv.Itoa(l.tokensVersion)
+	}
+	l.tokens[uri] = current
+	return previous, current, nil
+}
+
+// semanticTokensRange returns the tokens of path between the 1-based lines
+// start and end, inclusive.
+func (l *languageServer) semanticTokensRange(path string, start, end int) ([]uint32, error) {
+	if _, err := l.openDocument(path); err != nil {
+		return nil, err
+	}
+	result, err := l.call("textDocument/semanticTokens/range", toJSON(LsSemanticTokensRangeParams{
+		TextDocument: LsTextDocumentIdentifier{URI: pathToURI(path)},
+		Range: LsRange{
+			Start: LsPosition{Line: start - 1},
+			End:   LsPosition{Line: end},
+		},
+	}), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := parseSemanticTokensResult(result, nil)
+	return data, err
+}
+
+// parseSemanticTokensResult returns the data of a SemanticTokens or
+// SemanticTokensDelta result. Deltas are applied to previous. The returned
+// data is never nil.
+func parseSemanticTokensResult(result easyjson.RawMessage, previous []uint32) ([]uint32, string, error) {
+	var r struct {
+		ResultID string                  `json:"resultId"`
+		Data     []uint32                `json:"data"`
+		Edits    *[]LsSemanticTokensEdit `json:"edits"`
+	}
+	if err := json.Unmarshal(result, &r); err != nil {
+		return nil, "", fmt.Errorf("cannot parse semantic tokens: %s", err.Error())
+	}
+	if r.Edits != nil {
+		data, err := applySemanticTokensEdits(previous, *r.Edits)
+		return data, r.ResultID, err
+	}
+	if r.Data == nil {
+		r.Data = []uint32{}
+	}
+	return r.Data, r.ResultID, nil
+}
+
+// applySemanticTokensEdits returns data with edits applied. Edit positions
+// refer to data, not to the result of earlier edits.
+func applySemanticTokensEdits(data []uint32, edits []LsSemanticTokensEdit) ([]uint32, error) {
+	edits = append([]LsSemanticTokensEdit(nil), edits...)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+
+	out := []uint32{}
+	position := 0
+	for _, edit := range edits {
+		end := edit.Start + edit.DeleteCount
+		if edit.Start < position || edit.DeleteCount < 0 || end > len(data) {
+			return nil, fmt.Errorf("semantic tokens edit at %d deleting %d does not fit %d integers", edit.Start, edit.DeleteCount, len(data))
+		}
+		out = append(out, data[position:edit.Start]...)
+		out = append(out, edit.Data...)
+		position = end
+	}
+	return append(out, data[position:]...), nil
+}
+
+// diffSemanticTokens returns the edits which turn previous into current: a
+// single edit replacing everything between the tokens both start and end
+// with, or none if they are equal.
+func diffSemanticTokens(previous, current []uint32) []LsSemanticTokensEdit {
+	if equalTokens(previous, current) {
+		return []LsSemanticTokensEdit{}
+	}
+	shorter := len(previous)
+	if len(current) < shorter {
+		shorter = len(current)
+	}
+	prefix := 0
+	for prefix < shorter && previous[prefix] == current[prefix] {
+		prefix++
+	}
+	prefix -= prefix % 5
+	suffix := 0
+	for suffix < shorter-prefix && previous[len(previous)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+	suffix -= suffix % 5
+	return []LsSemanticTokensEdit{{
+		Start:       prefix,
+		DeleteCount: len(previous) - prefix - suffix,
+		Data:        current[prefix : len(current)-suffix],
+	}}
+}
+
+func equalTokens(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeSemanticTokens converts data, tokens of the document at path, to
+// absolute positions with columns in unit.
+func (l *languageServer) decodeSemanticTokens(path string, data []uint32, legend LsSemanticTokensLegend, unit ColumnUnit) []SemanticToken {
+	// Without the text the columns are left in UTF-16 units.
+	text, _ := l.documentText(path)
+	lines := strings.Split(text, "\n")
+	lineText := func(line int) string {
+		if line < len(lines) {
+			return strings.TrimSuffix(lines[line], "\r")
+		}
+		return ""
+	}
+
+	tokens := []SemanticToken{}
+	line, character := 0, 0
+	for i := 0; i+5 <= len(data); i += 5 {
+		if data[i] > 0 {
+			line += int(data[i])
+			character = 0
+		}
+		character += int(data[i+1])
+		length := int(data[i+2])
+
+		token := SemanticToken{Type: fmt.Sprintf("unknown(%d)", data[i+3])}
+		if t := int(data[i+3]); t < len(legend.TokenTypes) {
+			token.Type = legend.TokenTypes[t]
+		}
+		for bit, modifier := range legend.TokenModifiers {
+			if data[i+4]&(1<<uint(bit)) != 0 {
+				token.Modifiers = append(token.Modifiers, modifier)
+			}
+		}
+
+		// Tokens never span lines since lspc does not claim
+		// multilineTokenSupport.
+		current := lineText(line)
+		start := fromUTF16(current, LsPosition{Character: character}, unit)
+		end := fromUTF16(current, LsPosition{Character: character + length}, unit)
+		token.Location = Location{
+			File:      path,
+			Line:      line + 1,
+			Column:    start.Character + 1,
+			EndLine:   line + 1,
+			EndColumn: end.Character + 1,
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
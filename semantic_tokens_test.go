@@ -0,0 +1,100 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSemanticTokensProvider(t *testing.T) {
+	assert.Nil(t, parseSemanticTokensProvider(easyjson.RawMessage(`{"hoverProvider": true}`)))
+
+	provider := parseSemanticTokensProvider(easyjson.RawMessage(`{"semanticTokensProvider": {
+		"legend": {"tokenTypes": ["function"], "tokenModifiers": ["static"]}, "full": {"delta": true}}}`))
+	if assert.NotNil(t, provider) {
+		assert.True(t, provider.delta)
+		assert.Equal(t, []string{"function"}, provider.legend.TokenTypes)
+	}
+	provider = parseSemanticTokensProvider(easyjson.RawMessage(`{"semanticTokensProvider": {"legend": {}, "full": true}}`))
+	if assert.NotNil(t, provider) {
+		assert.False(t, provider.delta)
+	}
+}
+
+func TestSemanticTokensEdits(t *testing.T) {
+	previous := []uint32{0, 0, 3, 1, 0, 1, 2, 4, 2, 1, 0, 5, 1, 0, 0}
+	current := []uint32{0, 0, 3, 1, 0, 1, 2, 6, 2, 1, 0, 5, 1, 0, 0}
+	edits := diffSemanticTokens(previous, current)
+	assert.Equal(t, []LsSemanticTokensEdit{{Start: 5, DeleteCount: 5, Data: []uint32{1, 2, 6, 2, 1}}}, edits)
+	applied, err := applySemanticTokensEdits(previous, edits)
+	assert.NoError(t, err)
+	assert.Equal(t, current, applied)
+
+	assert.Empty(t, diffSemanticTokens(current, current))
+	applied, err = applySemanticTokensEdits(previous, diffSemanticTokens(previous, nil))
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// Edits refer to the original data regardless of their order.
+	applied, err = applySemanticTokensEdits([]uint32{1, 2, 3, 4}, []LsSemanticTokensEdit{
+		{Start: 3, DeleteCount: 1, Data: []uint32{9}},
+		{Start: 0, DeleteCount: 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{3, 9}, applied)
+
+	_, err = applySemanticTokensEdits([]uint32{1}, []LsSemanticTokensEdit{{Start: 0, DeleteCount: 2}})
+	assert.Error(t, err)
+}
+
+func TestParseSemanticTokensResult(t *testing.T) {
+	data, id, err := parseSemanticTokensResult(easyjson.RawMessage(`{"resultId": "2", "edits": [{"start": 0, "deleteCount": 5}]}`), []uint32{0, 0, 1, 0, 0, 1, 0, 1, 0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", id)
+	assert.Equal(t, []uint32{1, 0, 1, 0, 0}, data)
+
+	data, id, err = parseSemanticTokensResult(easyjson.RawMessage(`null`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", id)
+	assert.Equal(t, []uint32{}, data)
+}
+
+func TestDecodeSemanticTokens(t *testing.T) {
+	path := "/src/a.c"
+	l := &languageServer{documents: map[LsDocumentURI]string{
+		pathToURI(path): "int main() {\n  char* s = \"é\"; return f(s);\n}\n",
+	}}
+	legend := LsSemanticTokensLegend{TokenTypes: []string{"function", "variable"}, TokenModifiers: []string{"declaration", "readonly"}}
+	data := []uint32{
+		0, 4, 4, 0, 1, // main
+		1, 8, 1, 1, 3, // s
+		0, 16, 1, 0, 0, // f, after a two byte character
+		0, 2, 1, 7, 0, // s with an unknown type
+	}
+	tokens := l.decodeSemanticTokens(path, data, legend, ByteColumns)
+	if assert.Len(t, tokens, 4) {
+		assert.Equal(t, SemanticToken{
+			Location:  Location{File: path, Line: 1, Column: 5, EndLine: 1, EndColumn: 9},
+			Type:      "function",
+			Modifiers: []string{"declaration"},
+		}, tokens[0])
+		assert.Equal(t, []string{"declaration", "readonly"}, tokens[1].Modifiers)
+		assert.Equal(t, Location{File: path, Line: 2, Column: 26, EndLine: 2, EndColumn: 27}, tokens[2].Location)
+		assert.Equal(t, "unknown(7)", tokens[3].Type)
+	}
+}
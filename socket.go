@@ -0,0 +1,104 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The value of --socket. The socket actually used is gSocket.
+var gSocketFlag string
+var gProfile string
+var gPerWorkspace bool
+
+// The socket of the daemon and why it was chosen, set by initSocket.
+var gSocket string
+var gSocketSource string
+
+// getSocketFilename returns the socket used when nothing selects another.
+func getSocketFilename() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "all"
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("lspc.%s", user))
+}
+
+// socketResolution is a socket path and what selected it.
+type socketResolution struct {
+	Path string
+	// ie, "--socket" or "workspace /src/project".
+	Source string
+}
+
+// resolveSocket picks the socket of the daemon. In order of precedence it is
+// given by flag, env (LSPC_SOCKET), the profile, which names a separate
+// daemon, or, if perWorkspace is set, a hash of the workspace containing cwd.
+// Otherwise every command talks to the same daemon.
+func resolveSocket(flag, env, profile string, perWorkspace bool, cwd string) (socketResolution, error) {
+	switch {
+	case flag != "":
+		return socketResolution{flag, "--socket"}, nil
+	case env != "":
+		return socketResolution{env, "LSPC_SOCKET"}, nil
+	case profile != "":
+		if strings.ContainsAny(profile, `/\`) || profile == "." || profile == ".." {
+			return socketResolution{}, fmt.Errorf("invalid profile %q; it must be a plain name", profile)
+		}
+		return socketResolution{getSocketFilename() + "." + profile, "--profile " + profile}, nil
+	case perWorkspace:
+		workspace := workspaceRoot(cwd)
+		sum := sha1.Sum([]byte(workspace))
+		return socketResolution{getSocketFilename() + ".ws-" + hex.EncodeToString(sum[:6]), "workspace " + workspace}, nil
+	}
+	return socketResolution{getSocketFilename(), "default"}, nil
+}
+
+// workspaceRoot returns the closest directory containing directory, or
+// directory itself, that has a project config or is a git checkout, or
+// directory if there is none.
+func workspaceRoot(directory string) string {
+	directory = filepath.Clean(directory)
+	for dir := directory; ; {
+		if fileExists(filepath.Join(dir, projectConfigName)) || fileExists(filepath.Join(dir, ".git")) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return directory
+		}
+		dir = parent
+	}
+}
+
+// initSocket sets gSocket from the flags and environment. Both the client and
+// the daemon use it, so they agree on the socket.
+func initSocket() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	resolution, err := resolveSocket(gSocketFlag, os.Getenv("LSPC_SOCKET"), gProfile, gPerWorkspace, cwd)
+	if err != nil {
+		return err
+	}
+	gSocket, gSocketSource = resolution.Path, resolution.Source
+	return nil
+}
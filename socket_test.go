@@ -0,0 +1,57 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSocket(t *testing.T) {
+	resolve := func(flag, env, profile string, perWorkspace bool) socketResolution {
+		r, err := resolveSocket(flag, env, profile, perWorkspace, "/nonexistent/src")
+		assert.NoError(t, err)
+		return r
+	}
+	assert.Equal(t, socketResolution{"/tmp/a", "--socket"}, resolve("/tmp/a", "/tmp/b", "dev", true))
+	assert.Equal(t, socketResolution{"/tmp/b", "LSPC_SOCKET"}, resolve("", "/tmp/b", "dev", true))
+	assert.Equal(t, socketResolution{getSocketFilename() + ".dev", "--profile dev"}, resolve("", "", "dev", true))
+	assert.Equal(t, socketResolution{getSocketFilename(), "default"}, resolve("", "", "", false))
+
+	workspace := resolve("", "", "", true)
+	assert.Equal(t, "workspace /nonexistent/src", workspace.Source)
+	assert.NotEqual(t, getSocketFilename(), workspace.Path)
+
+	_, err := resolveSocket("", "", "../other", false, "/")
+	assert.Error(t, err)
+}
+
+func TestWorkspaceRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	nested := filepath.Join(dir, "a", "b")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+
+	assert.Equal(t, nested, workspaceRoot(nested))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0755))
+	assert.Equal(t, dir, workspaceRoot(nested))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", projectConfigName), nil, 0644))
+	assert.Equal(t, filepath.Join(dir, "a"), workspaceRoot(nested))
+}
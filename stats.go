@@ -0,0 +1,284 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Number of publishDiagnostics notifications remembered per server.
+const maxRecentDiagnostics = 10
+
+// MethodStats holds request counts and latencies for one method.
+type MethodStats struct {
+	Method string
+	Count  int
+	Errors int
+	Total  time.Duration
+	Max    time.Duration
+}
+
+// ProgressInfo is the latest state of an active $/progress token.
+type ProgressInfo struct {
+	Token   string
+	Title   string
+	Message string
+	// -1 if the server did not report a percentage.
+	Percentage int
+	// When the first report for the token arrived.
+	Started time.Time
+	// Estimated time until the percentage reaches 100, or -1 if the server
+	// has not reported enough percentages yet.
+	ETA time.Duration
+
+	// The first reported percentage and when it was reported, and when the
+	// latest one was, from which the rate of progress is estimated.
+	firstPercentage int
+	firstTime       time.Time
+	lastTime        time.Time
+}
+
+// estimate updates p.ETA assuming that progress continues at the average rate
+// since the first reported percentage.
+func (p *ProgressInfo) estimate(now time.Time) {
+	p.ETA = -1
+	progressed := p.Percentage - p.firstPercentage
+	if p.Percentage < 0 || progressed <= 0 {
+		return
+	}
+	elapsed := p.lastTime.Sub(p.firstTime)
+	remaining := time.Duration(float64(elapsed) * float64(100-p.Percentage) / float64(progressed))
+	// Time since the last report already counts towards the remaining time.
+	remaining -= now.Sub(p.lastTime)
+	if remaining < 0 {
+		remaining = 0
+	}
+	p.ETA = remaining
+}
+
+func (p ProgressInfo) String() string {
+	s := p.Title
+	if s == "" {
+		s = p.Token
+	}
+	if p.Percentage >= 0 {
+		s += fmt.Sprintf(" %d%%", p.Percentage)
+	}
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+	if !p.Started.IsZero() {
+		s += fmt.Sprintf(", running for %s", time.Since(p.Started).Round(time.Second))
+	}
+	if p.ETA >= 0 {
+		s += fmt.Sprintf(", about %s left", p.ETA.Round(time.Second))
+	}
+	return s
+}
+
+// DiagnosticsEvent summarizes a publishDiagnostics notification.
+type DiagnosticsEvent struct {
+	URI      LsDocumentURI
+	Errors   int
+	Warnings int
+	Other    int
+	Time     time.Time
+}
+
+// ServerStats is a snapshot of the activity of one language server. Progress
+// is part of ServerInfo.
+type ServerStats struct {
+	ServerInfo
+	Methods     []MethodStats
+	Diagnostics []DiagnosticsEvent
+	// Messages waiting for the server to finish initializing.
+	Pending QueueStats
+}
+
+// serverStats collects activity for a language server. It is updated from the
+// stdout reader and read by rpc handlers, so all access goes through mu.
+type serverStats struct {
+	mu          sync.Mutex
+	methods     map[string]*MethodStats
+	progress    map[string]*ProgressInfo
+	diagnostics []DiagnosticsEvent
+}
+
+func newServerStats() *serverStats {
+	return &serverStats{
+		methods:  make(map[string]*MethodStats),
+		progress: make(map[string]*ProgressInfo),
+	}
+}
+
+func (s *serverStats) recordResponse(method string, latency time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, has := s.methods[method]
+	if !has {
+		m = &MethodStats{Method: method}
+		s.methods[method] = m
+	}
+	m.Count++
+	if failed {
+		m.Errors++
+	}
+	m.Total += latency
+	if latency > m.Max {
+		m.Max = latency
+	}
+}
+
+// recordProgress updates the progress for params.Token and returns a copy of
+// its state, or nil if the report was not understood. finished is set if
+// this was the final report.
+func (s *serverStats) recordProgress(params LsProgressParams) (progress *ProgressInfo, finished bool) {
+	return s.recordProgressAt(params, time.Now())
+}
+
+func (s *serverStats) recordProgressAt(params LsProgressParams, now time.Time) (progress *ProgressInfo, finished bool) {
+	value := LsWorkDoneProgress{}
+	if e := fromJSON(params.Value, &value); e != nil {
+		log.Printf("Unable to parse $/progress value %s", string(params.Value))
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := string(params.Token)
+	if value.Kind == "end" {
+		p, has := s.progress[token]
+		if !has {
+			return nil, false
+		}
+		delete(s.progress, token)
+		if value.Message != "" {
+			p.Message = value.Message
+		}
+		p.ETA = 0
+		return p, true
+	}
+
+	p, has := s.progress[token]
+	if !has {
+		p = &ProgressInfo{Token: token, Percentage: -1, Started: now}
+		s.progress[token] = p
+	}
+	if value.Title != "" {
+		p.Title = value.Title
+	}
+	if value.Message != "" {
+		p.Message = value.Message
+	}
+	if value.Percentage != nil {
+		p.Percentage = *value.Percentage
+		if p.firstTime.IsZero() {
+			p.firstPercentage = p.Percentage
+			p.firstTime = now
+		}
+		p.lastTime = now
+	}
+	p.estimate(now)
+	current := *p
+	return &current, false
+}
+
+// progressSnapshot returns the active progress tokens sorted by token, with
+// estimates as of now.
+func (s *serverStats) progressSnapshot(now time.Time) []ProgressInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var progress []ProgressInfo
+	for _, p := range s.progress {
+		p.estimate(now)
+		progress = append(progress, *p)
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].Token < progress[j].Token
+	})
+	return progress
+}
+
+func (s *serverStats) recordDiagnostics(params LsPublishDiagnosticsParams) DiagnosticsEvent {
+	event := DiagnosticsEvent{URI: params.URI, Time: time.Now()}
+	for _, d := range params.Diagnostics {
+		switch d.Severity {
+		case Error:
+			event.Errors++
+		case Warning:
+			event.Warnings++
+		default:
+			event.Other++
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.diagnostics = append(s.diagnostics, event)
+	if len(s.diagnostics) > maxRecentDiagnostics {
+		s.diagnostics = s.diagnostics[len(s.diagnostics)-maxRecentDiagnostics:]
+	}
+	return event
+}
+
+// snapshot copies the collected stats into out.
+func (s *serverStats) snapshot(out *ServerStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.methods {
+		out.Methods = append(out.Methods, *m)
+	}
+	sort.Slice(out.Methods, func(i, j int) bool {
+		return out.Methods[i].Method < out.Methods[j].Method
+	})
+	out.Diagnostics = append(out.Diagnostics, s.diagnostics...)
+}
+
+// Stats returns activity statistics for every running language server. This
+// is not logged since top calls it every refresh.
+func (s *Server) Stats(_ bool, stats *[]ServerStats) error {
+	for _, server := range s.serverList() {
+		out := ServerStats{ServerInfo: server.info(), Pending: server.pendingStats()}
+		server.stats.snapshot(&out)
+		*stats = append(*stats, out)
+	}
+	return nil
+}
+
+// serverStatus describes whether server is busy for the status command.
+func serverStatus(server ServerInfo) string {
+	header := fmt.Sprintf("%d: %s in %s", server.ID, strings.Join(server.Args, " "), server.Directory)
+	if len(server.Progress) == 0 {
+		return header + ": ready\n"
+	}
+	if len(server.Progress) == 1 {
+		return header + ": " + server.Progress[0].String() + "\n"
+	}
+	s := header + ":\n"
+	for _, p := range server.Progress {
+		s += "    " + p.String() + "\n"
+	}
+	return s
+}
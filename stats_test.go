@@ -0,0 +1,62 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func progressParams(value string) LsProgressParams {
+	return LsProgressParams{Token: []byte(`"idx"`), Value: []byte(value)}
+}
+
+func TestRecordProgressETA(t *testing.T) {
+	stats := newServerStats()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p, finished := stats.recordProgressAt(progressParams(`{"kind":"begin","title":"Indexing","percentage":10}`), start)
+	assert.False(t, finished)
+	assert.Equal(t, start, p.Started)
+	assert.Equal(t, time.Duration(-1), p.ETA)
+
+	// 20% in 10 seconds leaves 70% for 35 seconds.
+	p, _ = stats.recordProgressAt(progressParams(`{"kind":"report","percentage":30}`), start.Add(10*time.Second))
+	assert.Equal(t, 35*time.Second, p.ETA)
+
+	// Time since the last report is subtracted.
+	progress := stats.progressSnapshot(start.Add(15 * time.Second))
+	assert.Len(t, progress, 1)
+	assert.Equal(t, 30*time.Second, progress[0].ETA)
+	assert.Equal(t, time.Duration(0), stats.progressSnapshot(start.Add(time.Hour))[0].ETA)
+
+	p, finished = stats.recordProgressAt(progressParams(`{"kind":"end"}`), start.Add(20*time.Second))
+	assert.True(t, finished)
+	assert.Equal(t, time.Duration(0), p.ETA)
+	assert.Empty(t, stats.progressSnapshot(start))
+}
+
+func TestServerStatus(t *testing.T) {
+	server := ServerInfo{ID: 1, Args: []string{"clangd", "-j=4"}, Directory: "/src"}
+	assert.Equal(t, "1: clangd -j=4 in /src: ready\n", serverStatus(server))
+
+	server.Progress = []ProgressInfo{{Token: "idx", Title: "Indexing", Percentage: 40, Message: "3/8", ETA: 90 * time.Second}}
+	assert.Equal(t, "1: clangd -j=4 in /src: Indexing 40%: 3/8, about 1m30s left\n", serverStatus(server))
+
+	server.Progress = append(server.Progress, ProgressInfo{Token: "build", Percentage: -1, ETA: -1})
+	assert.Equal(t, "1: clangd -j=4 in /src:\n    Indexing 40%: 3/8, about 1m30s left\n    build\n", serverStatus(server))
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// findServerByTarget returns the running language server target refers to:
+// its id as listed by lspc ls, its pid, its project directory, which must be
+// absolute, or its configured language or program name. Ids take precedence
+// over pids.
+func (s *Server) findServerByTarget(target string) (*languageServer, error) {
+	servers := s.serverList()
+	if n, err := strconv.Atoi(target); err == nil {
+		for _, server := range servers {
+			if server.id == n {
+				return server, nil
+			}
+		}
+		for _, server := range servers {
+			if server.info().Pid == n {
+				return server, nil
+			}
+		}
+		return nil, fmt.Errorf("no language server with id or pid %d", n)
+	}
+
+	var matches []*languageServer
+	where := "in " + target
+	if filepath.IsAbs(target) {
+		for _, server := range servers {
+			if filepath.Clean(server.directory) == filepath.Clean(target) {
+				matches = append(matches, server)
+			}
+		}
+	} else {
+		where = "named " + target
+		matches = serversNamed([]string{target}, servers)
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no language server is running %s", where)
+	case 1:
+		return matches[0], nil
+	}
+	var candidates []string
+	for _, server := range matches {
+		candidates = append(candidates, fmt.Sprintf("%d (%s in %s)", server.id, server.name(), server.directory))
+	}
+	return nil, fmt.Errorf("%d language servers are running %s: %s; pick one by id", len(matches), where, strings.Join(candidates, ", "))
+}
+
+// serverTarget turns the argument of a command taking findServerByTarget
+// targets into one the daemon understands: directories are made absolute,
+// since the daemon may be running in a different directory.
+func serverTarget(arg string) (string, error) {
+	if _, err := strconv.Atoi(arg); err == nil {
+		return arg, nil
+	}
+	if info, err := os.Stat(arg); err == nil && info.IsDir() || strings.ContainsRune(arg, filepath.Separator) {
+		return filepath.Abs(arg)
+	}
+	return arg, nil
+}
+
+// Stop shuts down one language server, given by id, pid, directory or name, and
+// removes it from the running servers. Unlike Down it waits for the server
+// to answer the shutdown request before telling it to exit.
+func (s *Server) Stop(target string, stopped *DownServer) error {
+	log.Printf("CMD stop %s", target)
+	server, err := s.findServerByTarget(target)
+	if err != nil {
+		return err
+	}
+	// Another client may have stopped it in the meantime.
+	if _, err := s.stopServer(server.id); err != nil {
+		return err
+	}
+	server.shutdown(closeTimeout)
+	*stopped = DownServer{Info: server.info(), ExitStatus: server.exitStatus}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindServerByTarget(t *testing.T) {
+	var servers []*languageServer
+	for i, directory := range []string{"/a", "/b", "/b/"} {
+		l := newTestLanguageServer(i, directory)
+		l.cmd = &exec.Cmd{Process: &os.Process{Pid: 100 + i}}
+		servers = append(servers, l)
+	}
+	s := &Server{config: &Config{}, servers: servers}
+
+	found, err := s.findServerByTarget("1")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[1], found)
+	found, err = s.findServerByTarget("102")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[2], found)
+	_, err = s.findServerByTarget("7")
+	assert.EqualError(t, err, "no language server with id or pid 7")
+
+	found, err = s.findServerByTarget("/a/")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[0], found)
+	_, err = s.findServerByTarget("/b")
+	assert.EqualError(t, err, "2 language servers are running in /b: 1 (fake in /b), 2 (fake in /b/); pick one by id")
+	_, err = s.findServerByTarget("/c")
+	assert.Error(t, err)
+
+	servers[0].args = []string{"/usr/bin/clangd"}
+	servers[1].language = "python"
+	found, err = s.findServerByTarget("clangd")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[0], found)
+	found, err = s.findServerByTarget("python")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[1], found)
+	_, err = s.findServerByTarget("fake")
+	assert.EqualError(t, err, "2 language servers are running named fake: 1 (fake in /b), 2 (fake in /b/); pick one by id")
+	_, err = s.findServerByTarget("a")
+	assert.EqualError(t, err, "no language server is running named a")
+}
+
+func TestServerTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-stop")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "clangd"), 0755))
+	assert.NoError(t, os.Chdir(dir))
+
+	for arg, expected := range map[string]string{
+		"12":      "12",
+		"gopls":   "gopls",
+		"clangd":  filepath.Join(dir, "clangd"),
+		"./gopls": filepath.Join(dir, "gopls"),
+		"/p/":     "/p",
+	} {
+		target, err := serverTarget(arg)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, target, arg)
+	}
+}
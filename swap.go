@@ -0,0 +1,209 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+)
+
+// SwapArgs describes a server to replace with a different binary for
+// `lspc swap`.
+type SwapArgs struct {
+	ID  int
+	Bin string
+	// How long the new server gets to initialize before the swap is
+	// abandoned.
+	Timeout time.Duration
+}
+
+// SwapResult describes the servers involved in a swap.
+type SwapResult struct {
+	Old ServerInfo
+	New ServerInfo
+	// Number of open documents replayed into the new server.
+	Documents int
+}
+
+// documentSnapshot is the state of a document as a language server has it.
+type documentSnapshot struct {
+	uri     LsDocumentURI
+	text    string
+	version int
+}
+
+// serverSnapshot is the state a language server was given by the daemon,
+// enough to bring another server to the same state.
+type serverSnapshot struct {
+	initOpts  easyjson.RawMessage
+	documents []documentSnapshot
+}
+
+// snapshot copies the init options and open documents of the server. The
+// documents are sorted by uri.
+func (l *languageServer) snapshot() serverSnapshot {
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+
+	snapshot := serverSnapshot{initOpts: l.initOpts}
+	for uri, text := range l.documents {
+		snapshot.documents = append(snapshot.documents, documentSnapshot{uri, text, l.versions[uri]})
+	}
+	sort.Slice(snapshot.documents, func(i, j int) bool {
+		return snapshot.documents[i].uri < snapshot.documents[j].uri
+	})
+	return snapshot
+}
+
+// replay opens the documents of snapshot in the server with the versions
+// they had, so that later changes continue the same version sequence.
+func (l *languageServer) replay(snapshot serverSnapshot) {
+	l.docMu.Lock()
+	defer l.docMu.Unlock()
+
+	for _, d := range snapshot.documents {
+		// Documents that do not fit in the queue are opened by the next
+		// request about them instead.
+		if !l.writeNotification("textDocument/didOpen", toJSON(LsDidOpenTextDocumentParams{
+			TextDocument: LsTextDocumentItem{
+				URI:        d.uri,
+				LanguageID: languageID(uriToPath(d.uri)),
+				Version:    d.version,
+				Text:       d.text,
+			},
+		})) {
+			continue
+		}
+		l.documents[d.uri] = d.text
+		l.versions[d.uri] = d.version
+	}
+}
+
+// waitInitialized waits until the server has answered initialize.
+func (l *languageServer) waitInitialized(timeout time.Duration) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		l.mu.Lock()
+		initialized := l.initialized
+		l.mu.Unlock()
+		if initialized {
+			return nil
+		}
+
+		select {
+		case <-l.done:
+			return fmt.Errorf("%s exited before initializing (%s)", l.name(), l.exitStatus)
+		case <-deadline:
+			return fmt.Errorf("%s did not initialize within %s", l.name(), timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// replaceServer moves replacement into the place of old in s.servers, so that
+// it is routed everything old was, and removes old. Both must be running.
+func (s *Server) replaceServer(old, replacement *languageServer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, server := range s.servers {
+		if server == replacement {
+			s.servers = append(s.servers[:i], s.servers[i+1:]...)
+			break
+		}
+	}
+	for i, server := range s.servers {
+		if server == old {
+			s.servers[i] = replacement
+			return
+		}
+	}
+	s.servers = append(s.servers, replacement)
+}
+
+// Swap starts Bin for the project of the server with the given id, using the
+// same init options, replays the documents open in the old server into it and
+// then routes requests to it instead of the old server, which is shut down.
+// The replacement happens under s.mu, so every request is routed to exactly
+// one of the two servers.
+func (s *Server) Swap(args SwapArgs, result *SwapResult) error {
+	log.Printf("CMD swap %d to %s", args.ID, args.Bin)
+	old, err := s.findServer(args.ID)
+	if err != nil {
+		return err
+	}
+
+	startArgs := old.startArgs
+	startArgs.Bin = args.Bin
+	startArgs.InitOpts = old.initOpts
+	startArgs.Language = old.language
+	return s.replace(old, startArgs, args.Timeout, result)
+}
+
+// RestartArgs describes a server to restart for `lspc restart`.
+type RestartArgs struct {
+	// Id, pid, project directory or name of the server, see
+	// findServerByTarget.
+	Target string
+	// How long the new server gets to initialize before the restart is
+	// abandoned.
+	Timeout time.Duration
+}
+
+// Restart starts the server given by target again with the arguments it was
+// originally started with. Like Swap the new server takes over the open
+// documents of the old one, which keeps answering requests until then.
+// Config changes, ie, to init options, apply to the new server.
+func (s *Server) Restart(args RestartArgs, result *SwapResult) error {
+	log.Printf("CMD restart %s", args.Target)
+	old, err := s.findServerByTarget(args.Target)
+	if err != nil {
+		return err
+	}
+	return s.replace(old, old.startArgs, args.Timeout, result)
+}
+
+// replace starts a server with startArgs, waiting up to timeout for it to
+// initialize, replays the documents open in old into it and then routes
+// requests to it instead of old, which is shut down.
+func (s *Server) replace(old *languageServer, startArgs StartArgs, timeout time.Duration, result *SwapResult) error {
+	ls, err := s.start(startArgs)
+	if err != nil {
+		return err
+	}
+	if err := ls.waitInitialized(timeout); err != nil {
+		if _, e := s.stopServer(ls.id); e == nil {
+			go ls.close(closeTimeout)
+		}
+		return err
+	}
+
+	snapshot := old.snapshot()
+	ls.replay(snapshot)
+	s.replaceServer(old, ls)
+	log.Printf("Replaced %s (%d) with %s (%d) with %d open documents", old.name(), old.id, ls.name(), ls.id, len(snapshot.documents))
+
+	result.Old = old.info()
+	result.New = ls.info()
+	result.Documents = len(snapshot.documents)
+	// Shutting down can take a while; do not hold up the request.
+	go old.shutdown(closeTimeout)
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerSnapshot(t *testing.T) {
+	l := &languageServer{
+		initOpts:  easyjson.RawMessage(`{"cache":"/tmp"}`),
+		documents: map[LsDocumentURI]string{"file:///b.c": "b", "file:///a.c": "a"},
+		versions:  map[LsDocumentURI]int{"file:///b.c": 3},
+	}
+	snapshot := l.snapshot()
+	assert.Equal(t, `{"cache":"/tmp"}`, string(snapshot.initOpts))
+	assert.Equal(t, []documentSnapshot{
+		{"file:///a.c", "a", 0},
+		{"file:///b.c", "b", 3},
+	}, snapshot.documents)
+}
+
+func TestReplaceServer(t *testing.T) {
+	a, b, c := &languageServer{id: 0}, &languageServer{id: 1}, &languageServer{id: 2}
+	replacement := &languageServer{id: 3}
+	s := &Server{servers: []*languageServer{a, b, c, replacement}}
+
+	// The replacement takes the place of the old server so that it wins the
+	// same routing ties.
+	s.replaceServer(b, replacement)
+	assert.Equal(t, []*languageServer{a, replacement, c}, s.servers)
+
+	// If the old server is gone the replacement is kept.
+	s.replaceServer(b, replacement)
+	assert.Equal(t, []*languageServer{a, c, replacement}, s.servers)
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// Symbol is a workspace symbol as shown to users.
+type Symbol struct {
+	Location
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// The name of the symbol containing this one, if any.
+	Container string `json:"container,omitempty"`
+	// The server that found the symbol, if several were searched.
+	Server string `json:"server,omitempty"`
+}
+
+// WorkspaceSymbolsArgs selects the server running for Directory and what to
+// search its workspace for.
+type WorkspaceSymbolsArgs struct {
+	// Searches every running server if empty.
+	Directory string
+	Query     string
+	Unit      ColumnUnit
+	// Server group to search the workspaces of, see GroupConfig.
+	Group string
+}
+
+// workspaceSymbolInformation sends workspace/symbol.
+func (l *languageServer) workspaceSymbolInformation(query string) ([]LsSymbolInformation, error) {
+	params := LsWorkspaceSymbolParams{Query: query}
+	result, err := l.call("workspace/symbol", toJSON(params), queryTimeout())
+	if err != nil {
+		return nil, err
+	}
+	var information []LsSymbolInformation
+	if err := json.Unmarshal(result, &information); err != nil {
+		return nil, fmt.Errorf("cannot parse workspace symbols: %s", err.Error())
+	}
+	return information, nil
+}
+
+// workspaceSymbols searches the workspace of l for symbols matching query.
+func (l *languageServer) workspaceSymbols(query string, unit ColumnUnit) ([]Symbol, error) {
+	information, err := l.workspaceSymbolInformation(query)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := []Symbol{}
+	for _, i := range information {
+		path := uriToPath(i.Location.URI)
+		symbols = append(symbols, Symbol{
+			Location:  l.userRange(path, i.Location.Range, unit),
+			Name:      i.Name,
+			Kind:      i.Kind.String(),
+			Container: i.ContainerName,
+		})
+	}
+	return symbols, nil
+}
+
+// runningServers returns one instance of every running language server, or
+// of those of group if it is set.
+func (s *Server) runningServers(group string) ([]*languageServer, error) {
+	var servers []*languageServer
+	seen := make(map[string]bool)
+	for _, server := range s.serverList() {
+		key := server.startArgs.Bin + "\x00" + server.directory
+		if !seen[key] {
+			seen[key] = true
+			servers = append(servers, s.nextInstance(server))
+		}
+	}
+	if group != "" {
+		g, has := s.currentConfig().Groups[group]
+		if !has {
+			return nil, fmt.Errorf("no server group named %q in the config", group)
+		}
+		if servers = serversNamed(g.Servers, servers); len(servers) == 0 {
+			return nil, fmt.Errorf("no server of group %s is running", group)
+		}
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no language server is running")
+	}
+	return servers, nil
+}
+
+// WorkspaceSymbols searches the workspace of the server running for a
+// directory, or of every running server. Without a directory servers that
+// fail are skipped unless all of them do.
+func (s *Server) WorkspaceSymbols(args WorkspaceSymbolsArgs, symbols *[]Symbol) error {
+	log.Printf("CMD workspace-symbols %s %q", args.Directory, args.Query)
+	var servers []*languageServer
+	var err error
+	if args.Directory == "" {
+		servers, err = s.runningServers(args.Group)
+	} else if !filepath.IsAbs(args.Directory) {
+		return fmt.Errorf("directory must be an absolute path, got %q", args.Directory)
+	} else {
+		servers, err = s.serversForRequest(args.Directory, "workspace/symbol", args.Group)
+	}
+	if err != nil {
+		return err
+	}
+	lists := make([][]Symbol, len(servers))
+	errs := make([]error, len(servers))
+	err = callServers(servers, "workspace/symbol", func(i int, l *languageServer) error {
+		lists[i], errs[i] = l.workspaceSymbols(args.Query, args.Unit)
+		if args.Directory == "" {
+			return nil
+		}
+		return errs[i]
+	})
+	if err != nil {
+		return err
+	}
+	if args.Directory == "" && allFailed(errs) {
+		return errs[0]
+	}
+	*symbols = []Symbol{}
+	for i, list := range lists {
+		for _, symbol := range list {
+			if len(servers) > 1 {
+				symbol.Server = servers[i].name()
+			}
+			*symbols = append(*symbols, symbol)
+		}
+	}
+	return nil
+}
+
+// allFailed returns true if every error of errs is set.
+func allFailed(errs []error) bool {
+	for _, err := range errs {
+		if err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSymbolKinds parses symbol kind names, ie, "function" or
+// "enum-member". Each name may hold several separated by commas.
+func parseSymbolKinds(names []string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, list := range names {
+		for _, name := range strings.Split(list, ",") {
+			name = normalizeKindName(name)
+			found := false
+			for kind, kindName := range symbolKindNames {
+				if kind > 0 && kindName == name {
+					kinds[name] = true
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown symbol kind %q", name)
+			}
+		}
+	}
+	return kinds, nil
+}
+
+// matchingSymbols returns the symbols whose kind is in kinds, or every symbol
+// if kinds is empty.
+func matchingSymbols(symbols []Symbol, kinds map[string]bool) []Symbol {
+	if len(kinds) == 0 {
+		return symbols
+	}
+	var matching []Symbol
+	for _, symbol := range symbols {
+		if kinds[symbol.Kind] {
+			matching = append(matching, symbol)
+		}
+	}
+	return matching
+}
+
+// writeSymbols prints the location, kind and name, with its container, of
+// each symbol whose kind is in kinds, or of every symbol if kinds is empty, followed by
+// the server that found it if several were searched.
+func writeSymbols(out io.Writer, symbols []Symbol, kinds map[string]bool, config *Config, icons bool) {
+	w := newTable(out)
+	for _, symbol := range matchingSymbols(symbols, kinds) {
+		name := symbol.Name
+		if symbol.Container != "" {
+			name += " (in " + symbol.Container + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s", symbol.Location, config.kindLabel(symbol.Kind, icons), name)
+		if symbol.Server != "" {
+			fmt.Fprintf(w, "\t%s", symbol.Server)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
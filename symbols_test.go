@@ -0,0 +1,53 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSymbolKinds(t *testing.T) {
+	kinds, err := parseSymbolKinds([]string{"class,enum-member", "Type_Parameter"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"class": true, "enum member": true, "type parameter": true}, kinds)
+
+	_, err = parseSymbolKinds([]string{"snippet"})
+	assert.Error(t, err)
+}
+
+func TestWriteSymbols(t *testing.T) {
+	symbols := []Symbol{
+		{Location: Location{File: "/p/a.c", Line: 1, Column: 5}, Name: "Foo", Kind: "class"},
+		{Location: Location{File: "/p/a.c", Line: 2, Column: 7}, Name: "bar", Kind: "method", Container: "Foo", Server: "clangd"},
+	}
+	config := &Config{Kinds: map[string]KindConfig{"method": {Name: "fn"}}}
+
+	var out bytes.Buffer
+	writeSymbols(&out, symbols, nil, config, false)
+	assert.Equal(t, "/p/a.c:1:5  class  Foo\n/p/a.c:2:7  fn     bar (in Foo)  clangd\n", out.String())
+
+	out.Reset()
+	writeSymbols(&out, symbols, map[string]bool{"class": true}, config, false)
+	assert.Equal(t, "/p/a.c:1:5  class  Foo\n", out.String())
+}
+
+func TestAllFailed(t *testing.T) {
+	assert.True(t, allFailed([]error{fmt.Errorf("a"), fmt.Errorf("b")}))
+	assert.False(t, allFailed([]error{fmt.Errorf("a"), nil}))
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	ansiClearScreen = "\x1b[H\x1b[2J"
+	ansiHideCursor  = "\x1b[?25l"
+	ansiShowCursor  = "\x1b[?25h"
+)
+
+// methodKey identifies a method of a specific language server.
+type methodKey struct {
+	server int
+	method string
+}
+
+// TopSnapshot is one refresh of top, printed as a line of JSON with --json.
+type TopSnapshot struct {
+	Time    time.Time
+	Servers []ServerStats
+	Queues  []QueueStats
+	// Set if the daemon could not be reached.
+	Error string `json:",omitempty"`
+}
+
+// top implements the top command. It redraws the dashboard whenever the
+// event stream of the daemon reports a change, and every interval so that
+// request rates stay current, until interrupted. With --plain or without a
+// terminal every refresh is printed after the previous one instead, and with
+// --json every refresh is printed as a TopSnapshot.
+func top(interval time.Duration) error {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	changed, stream := topEvents()
+	if stream != nil {
+		defer stream.Close()
+	}
+
+	redraw := !gPlain && !gJSON && isTerminal(os.Stdout)
+	if redraw {
+		fmt.Print(ansiHideCursor)
+		defer fmt.Print(ansiShowCursor)
+	}
+
+	// Previous request counts, used to compute request rates.
+	prevCounts := make(map[methodKey]int)
+	prevTime := time.Now()
+	first := true
+
+	for {
+		var stats []ServerStats
+		var queues []QueueStats
+		err := tryRPC("Server.Stats", false, &stats)
+		if err == nil {
+			err = tryRPC("Server.Queues", false, &queues)
+		}
+
+		now := time.Now()
+		if gJSON {
+			snapshot := TopSnapshot{Time: now, Servers: stats, Queues: queues}
+			if err != nil {
+				snapshot.Error = err.Error()
+			}
+			if err := printJSON(snapshot); err != nil {
+				return err
+			}
+			select {
+			case <-interrupt:
+				return nil
+			case <-changed:
+			case <-time.After(interval):
+			}
+			continue
+		}
+		rates := make(map[methodKey]float64)
+		counts := make(map[methodKey]int)
+		for _, server := range stats {
+			for _, m := range server.Methods {
+				key := methodKey{server.ID, m.Method}
+				counts[key] = m.Count
+				if prev, has := prevCounts[key]; has {
+					rates[key] = float64(m.Count-prev) / now.Sub(prevTime).Seconds()
+				}
+			}
+		}
+		prevCounts = counts
+		prevTime = now
+
+		var buffer bytes.Buffer
+		if err != nil {
+			fmt.Fprintf(&buffer, "lspc top - %s\n\nUnable to reach daemon: %s\n", now.Format("15:04:05"), err.Error())
+		} else {
+			renderTop(&buffer, now, stats, queues, rates)
+		}
+		if redraw {
+			fmt.Print(ansiClearScreen)
+		} else if !first {
+			fmt.Println()
+		}
+		first = false
+		buffer.WriteTo(os.Stdout)
+
+		select {
+		case <-interrupt:
+			return nil
+		case <-changed:
+		case <-time.After(interval):
+		}
+	}
+}
+
+// topEvents subscribes to the event stream of the daemon. The returned
+// channel receives a value after events arrive; events that arrive while the
+// previous value has not been received yet are coalesced into it. Without a
+// stream, ie, with --remote, the channel is nil and top only polls.
+func topEvents() (<-chan struct{}, io.Closer) {
+	if checkLocalDaemon("top") != nil {
+		return nil, nil
+	}
+	var socket string
+	if err := tryRPC("Server.Events", []int32(nil), &socket); err != nil {
+		return nil, nil
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil
+	}
+	changed := make(chan struct{}, 1)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(nil, gMaxMessageSize*1024*1024)
+		for scanner.Scan() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changed, conn
+}
+
+func renderTop(out io.Writer, now time.Time, stats []ServerStats, queues []QueueStats, rates map[methodKey]float64) {
+	fmt.Fprintf(out, "lspc top - %s - %d language server(s)\n", now.Format("15:04:05"), len(stats))
+	for _, q := range queues {
+		fmt.Fprintf(out, "queue %s\n", q)
+	}
+	fmt.Fprintln(out)
+
+	for _, server := range stats {
+		fmt.Fprintf(out, "[%d] pid %d in %s: %s", server.ID, server.Pid, server.Directory, strings.Join(server.Args, " "))
+		if server.Version != "" {
+			fmt.Fprintf(out, " (%s)", server.Version)
+		}
+		fmt.Fprintln(out)
+
+		w := newTable(out)
+		if len(server.Methods) > 0 {
+			fmt.Fprintf(w, "  METHOD\tCOUNT\tRATE/S\tAVG\tMAX\tERRORS\n")
+		}
+		for _, m := range server.Methods {
+			avg := time.Duration(0)
+			if m.Count > 0 {
+				avg = m.Total / time.Duration(m.Count)
+			}
+			fmt.Fprintf(w, "  %s\t%d\t%.1f\t%s\t%s\t%d\n", m.Method, m.Count,
+				rates[methodKey{server.ID, m.Method}], roundDuration(avg), roundDuration(m.Max), m.Errors)
+		}
+
+		for _, p := range server.Progress {
+			percentage := ""
+			if p.Percentage >= 0 {
+				percentage = fmt.Sprintf("%d%%", p.Percentage)
+			}
+			eta := ""
+			if p.ETA >= 0 {
+				eta = fmt.Sprintf("about %s left", p.ETA.Round(time.Second))
+			}
+			fmt.Fprintf(w, "  progress\t%s\t%s\t%s\t%s\n", p.Title, percentage, eta, p.Message)
+		}
+
+		if p := server.Pending; p.Depth > 0 || p.Dropped > 0 {
+			fmt.Fprintf(w, "  queue\t%s\n", p)
+		}
+
+		for _, d := range server.Diagnostics {
+			fmt.Fprintf(w, "  diagnostics\t%s\t%d errors, %d warnings, %d other\t%s ago\n",
+				d.URI, d.Errors, d.Warnings, d.Other, now.Sub(d.Time).Round(time.Second))
+		}
+		w.Flush()
+		fmt.Fprintln(out)
+	}
+}
+
+// roundDuration rounds d to a precision that is readable in a table.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d > time.Second:
+		return d.Round(time.Millisecond)
+	case d > time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
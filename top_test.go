@@ -0,0 +1,66 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTop(t *testing.T) {
+	defer func(plain bool) { gPlain = plain }(gPlain)
+	gPlain = false
+
+	now := time.Date(2018, 3, 4, 12, 30, 0, 0, time.UTC)
+	stats := []ServerStats{
+		{
+			ServerInfo: ServerInfo{
+				ID: 0, Pid: 100, Args: []string{"clangd", "--log=error"}, Directory: "/src", Version: "clangd 17.0.3",
+				Progress: []ProgressInfo{{Title: "indexing", Message: "3/4 files", Percentage: 75, ETA: 10 * time.Second}},
+			},
+			Methods: []MethodStats{
+				{Method: "textDocument/definition", Count: 4, Errors: 1, Total: 10 * time.Millisecond, Max: 4 * time.Millisecond},
+				{Method: "textDocument/hover", Count: 0},
+			},
+			Diagnostics: []DiagnosticsEvent{{URI: "file:///src/a.cc", Errors: 2, Warnings: 1, Time: now.Add(-5 * time.Second)}},
+		},
+		{
+			ServerInfo: ServerInfo{ID: 1, Pid: 101, Args: []string{"pyls"}, Directory: "/py"},
+			Pending:    QueueStats{Name: "pending", Depth: 3, Capacity: maxPendingMessages},
+		},
+	}
+	queues := []QueueStats{{Name: "closed servers", Depth: 1, Capacity: 16, Blocked: 2}}
+	rates := map[methodKey]float64{{0, "textDocument/definition"}: 0.5}
+
+	var out bytes.Buffer
+	renderTop(&out, now, stats, queues, rates)
+	assert.Equal(t, `lspc top - 12:30:00 - 2 language server(s)
+queue closed servers 1/16, 2 blocked, 0 dropped
+
+[0] pid 100 in /src: clangd --log=error (clangd 17.0.3)
+  METHOD                   COUNT             RATE/S                         AVG             MAX  ERRORS
+  textDocument/definition  4                 0.5                            2.5ms           4ms  1
+  textDocument/hover       0                 0.0                            0s              0s   0
+  progress                 indexing          75%                            about 10s left  3/4 files
+  diagnostics              file:///src/a.cc  2 errors, 1 warnings, 0 other  5s ago
+
+[1] pid 101 in /py: pyls
+  queue  pending 3/1000, 0 blocked, 0 dropped
+
+`, out.String())
+}
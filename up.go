@@ -0,0 +1,278 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ProjectServerConfig is a language server that lspc up starts for a
+// project, ie, for a mixed C++ and Python checkout,
+//
+//	[[server]]
+//	language = "cpp"
+//
+//	[[server]]
+//	command = "pyright-langserver --stdio"
+//	path = "tools"
+type ProjectServerConfig struct {
+	// Relative to the directory of .lspc.toml, which is the default.
+	Path string `toml:"path,omitempty"`
+
+	// Configured language whose command runs.
+	Language string `toml:"language,omitempty"`
+
+	// Runs instead of the command of the language.
+	Command string `toml:"command,omitempty"`
+}
+
+// validate returns problems keyed by the name of the setting.
+func (p ProjectServerConfig) validate() map[string]error {
+	problems := make(map[string]error)
+	if filepath.IsAbs(p.Path) {
+		problems["path"] = fmt.Errorf("must be relative to the project directory, got %q", p.Path)
+	} else if clean := filepath.Clean(p.Path); clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		problems["path"] = fmt.Errorf("must be inside the project directory, got %q", p.Path)
+	}
+	validateCommand(p.Command, p.Language, problems)
+	return problems
+}
+
+// upStartArgs returns how to start every server and root of the project in
+// directory, whose config is project.
+func (c *Config) upStartArgs(project *ProjectConfig, directory string) ([]StartArgs, error) {
+	var all []StartArgs
+	for _, server := range project.Servers {
+		args, err := c.rootStartArgs(RootConfig{Language: server.Language, Command: server.Command}, filepath.Join(directory, server.Path))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, args)
+	}
+	for _, root := range project.Roots {
+		args, err := c.rootStartArgs(root, filepath.Join(directory, root.Path))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, args)
+	}
+	return all, nil
+}
+
+// UpServer describes a server lspc up asked for.
+type UpServer struct {
+	// -1 if the server could not be started.
+	ID        int
+	Bin       string
+	Directory string
+	// The server was already running, so no new one was started.
+	AlreadyRunning bool
+	// Why the server could not be started.
+	Error string
+}
+
+// Up starts every language server listed in the project config of directory
+// which is not already running there. Servers are started without waiting for
+// each other, so they initialize in parallel; the client waits for them to
+// become ready.
+func (s *Server) Up(directory string, servers *[]UpServer) error {
+	log.Printf("CMD up %s", directory)
+	project, err := loadProjectConfig(directory)
+	if err != nil {
+		return err
+	}
+	all, err := s.currentConfig().upStartArgs(project, directory)
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("%s lists no servers or roots", filepath.Join(directory, projectConfigName))
+	}
+	*servers = s.startMissing(all)
+	return nil
+}
+
+// startMissing starts the servers of all which are not already running in
+// their directory.
+func (s *Server) startMissing(all []StartArgs) []UpServer {
+	var servers []UpServer
+next:
+	for _, args := range all {
+		bin := s.currentConfig().resolveBin(args.Bin)
+		for _, server := range s.serverList() {
+			if server.directory == args.Directory && server.startArgs.Bin == bin {
+				servers = append(servers, UpServer{ID: server.id, Bin: bin, Directory: args.Directory, AlreadyRunning: true})
+				continue next
+			}
+		}
+		up := UpServer{ID: -1, Bin: bin, Directory: args.Directory}
+		if ls, err := s.start(args); err != nil {
+			log.Printf("Unable to start %s in %s: %s", bin, args.Directory, err.Error())
+			up.Error = err.Error()
+		} else {
+			up.ID = ls.id
+		}
+		servers = append(servers, up)
+	}
+	return servers
+}
+
+// UpStatus is how far a server got, as printed by up with --json.
+type UpStatus struct {
+	UpServer
+	Ready bool
+	// As printed without --json, ie, "ready after 1.2s".
+	Status string
+}
+
+// upState is how far a server started by lspc up got.
+type upState struct {
+	UpServer
+	// When the server was last seen busy.
+	busySince time.Time
+	ready     bool
+	// From starting the server until it was last busy.
+	readyAfter time.Duration
+	exited     bool
+}
+
+func (u *upState) String() string {
+	switch {
+	case u.Error != "":
+		return "failed: " + u.Error
+	case u.exited:
+		return "exited"
+	case u.AlreadyRunning:
+		return "already running"
+	case u.ready:
+		return fmt.Sprintf("ready after %s", u.readyAfter.Round(100*time.Millisecond))
+	}
+	return "not ready"
+}
+
+// up implements the up and start-auto commands. It starts the servers of
+// directory with method, Server.Up or Server.StartAuto, and polls the daemon
+// until every one of them has initialized and reported no progress for
+// settle, or timeout passes. Returns false if a server is not ready.
+func up(out io.Writer, method, directory string, settle, timeout time.Duration) bool {
+	var servers []UpServer
+	doRPC(method, directory, &servers)
+
+	start := time.Now()
+	states := make([]*upState, len(servers))
+	for i, server := range servers {
+		states[i] = &upState{UpServer: server, busySince: start}
+	}
+
+	for {
+		var running []ServerInfo
+		if err := tryRPC("Server.Ls", false, &running); err != nil {
+			fmt.Fprintf(textOutput(), "Unable to reach daemon: %s\n", err.Error())
+			return false
+		}
+		byID := make(map[int]ServerInfo)
+		for _, info := range running {
+			byID[info.ID] = info
+		}
+
+		now := time.Now()
+		waiting := 0
+		for _, state := range states {
+			if state.Error != "" || state.exited || state.AlreadyRunning || state.ready {
+				continue
+			}
+			info, has := byID[state.ID]
+			switch {
+			case !has:
+				state.exited = true
+			case !info.Initialized || len(info.Progress) > 0:
+				state.busySince = now
+				waiting++
+			case now.Sub(state.busySince) >= settle:
+				state.ready = true
+				state.readyAfter = state.busySince.Sub(start)
+			default:
+				waiting++
+			}
+		}
+		if waiting == 0 || now.Sub(start) >= timeout {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	ok := true
+	var statuses []UpStatus
+	for _, state := range states {
+		ready := state.AlreadyRunning || state.ready
+		statuses = append(statuses, UpStatus{UpServer: state.UpServer, Ready: ready, Status: state.String()})
+		if !ready {
+			ok = false
+		}
+	}
+	if gJSON {
+		json.NewEncoder(out).Encode(statuses)
+		return ok
+	}
+
+	w := newTable(out)
+	fmt.Fprintf(w, "ID\tSERVER\tDIRECTORY\tSTATUS\n")
+	for _, state := range states {
+		id := "-"
+		if state.ID >= 0 {
+			id = fmt.Sprint(state.ID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, state.Bin, state.Directory, state)
+	}
+	w.Flush()
+	return ok
+}
+
+// DownServer describes a server stopped by lspc down.
+type DownServer struct {
+	Info       ServerInfo
+	ExitStatus string
+}
+
+// Down gracefully stops every language server running in directory or in a
+// subdirectory of it, ie, the servers lspc up started for the project, and
+// waits for them to exit. Other servers keep running.
+func (s *Server) Down(directory string, stopped *[]DownServer) error {
+	log.Printf("CMD down %s", directory)
+	var closing []*languageServer
+	s.mu.Lock()
+	i := 0
+	for i < len(s.servers) {
+		if pathInDirectory(s.servers[i].directory, directory) {
+			closing = append(closing, s.servers[i])
+			s.servers = append(s.servers[:i], s.servers[i+1:]...)
+		} else {
+			i++
+		}
+	}
+	s.mu.Unlock()
+
+	closeServers(closing)
+	for _, ls := range closing {
+		*stopped = append(*stopped, DownServer{Info: ls.info(), ExitStatus: ls.exitStatus})
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectServerConfigValidate(t *testing.T) {
+	assert.Empty(t, ProjectServerConfig{Language: "go"}.validate())
+	assert.Empty(t, ProjectServerConfig{Path: "tools", Command: "pyright-langserver --stdio"}.validate())
+
+	problems := ProjectServerConfig{Path: "../other"}.validate()
+	assert.Contains(t, problems, "path")
+	assert.Contains(t, problems, "command")
+	assert.Contains(t, ProjectServerConfig{Path: "/src", Language: "go"}.validate(), "path")
+}
+
+func TestUpStartArgs(t *testing.T) {
+	config := &Config{Languages: map[string]LanguageConfig{"cpp": {Command: "clangd"}}}
+	project := &ProjectConfig{
+		Servers: []ProjectServerConfig{{Language: "cpp"}, {Path: "tools", Command: "pyright"}},
+		Roots:   []RootConfig{{Path: "web", Command: "tsserver"}},
+	}
+	all, err := config.upStartArgs(project, "/src")
+	assert.NoError(t, err)
+	assert.Equal(t, []StartArgs{
+		{Bin: "clangd", Directory: "/src", Language: "cpp"},
+		{Bin: "pyright", Directory: "/src/tools"},
+		{Bin: "tsserver", Directory: "/src/web"},
+	}, all)
+
+	project.Servers = append(project.Servers, ProjectServerConfig{Language: "rust"})
+	_, err = config.upStartArgs(project, "/src")
+	assert.Error(t, err)
+}
+
+func TestDown(t *testing.T) {
+	var servers []*languageServer
+	for i, directory := range []string{"/proj", "/other", "/proj/sub", "/project"} {
+		_, stdin := io.Pipe()
+		l := &languageServer{
+			cmd:        &exec.Cmd{Process: &os.Process{Pid: 100 + i}},
+			id:         i,
+			directory:  directory,
+			args:       []string{"fake"},
+			stats:      newServerStats(),
+			stdin:      stdin,
+			exitStatus: "exit status 0",
+			// The server has exited, so closing it returns at once.
+			done: make(chan struct{}),
+		}
+		close(l.done)
+		servers = append(servers, l)
+	}
+	s := &Server{config: &Config{}, servers: servers}
+
+	// Only servers in the directory or below it are stopped.
+	var stopped []DownServer
+	assert.NoError(t, s.Down("/proj", &stopped))
+	var ids []int
+	for _, server := range stopped {
+		ids = append(ids, server.Info.ID)
+		assert.Equal(t, "exit status 0", server.ExitStatus)
+	}
+	assert.Equal(t, []int{0, 2}, ids)
+	ids = nil
+	for _, server := range s.servers {
+		ids = append(ids, server.id)
+	}
+	assert.Equal(t, []int{1, 3}, ids)
+
+	stopped = nil
+	assert.NoError(t, s.Down("/none", &stopped))
+	assert.Empty(t, stopped)
+	assert.Len(t, s.servers, 2)
+}
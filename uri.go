@@ -14,32 +14,31 @@
 
 package main
 
-import "strings"
+import "github.com/jacobdufault/lspc/uri"
 
+// pathToURI returns the file:// uri sent to language servers for the
+// absolute path.
 func pathToURI(absolutePath string) LsDocumentURI {
-	m := map[rune](string){
-		' ': "%20",
-		'#': "%23",
-		'$': "%24",
-		'&': "%26",
-		'(': "%28",
-		')': "%29",
-		'+': "%2B",
-		',': "%2C",
-		':': "%3A",
-		';': "%3B",
-		'?': "%3F",
-		'@': "%40",
-	}
+	return LsDocumentURI(uri.FromPath(absolutePath))
+}
 
-	result := ""
-	for _, c := range absolutePath {
-		if _, has := m[c]; has {
-			result += m[c]
-		} else {
-			result += string(c)
-		}
+// uriToPath converts a file:// uri returned by a language server back to a
+// path. Other uris are returned unchanged.
+func uriToPath(u LsDocumentURI) string {
+	path, err := uri.ToPath(string(u))
+	if err != nil {
+		return string(u)
 	}
+	return path
+}
 
-	return LsDocumentURI("file://" + strings.Replace(result, "\\", "/", -1))
+// canonicalURI re-encodes a file:// uri of a language server as pathToURI
+// does, so that it can be looked up among the uris lspc sent. Servers may
+// encode fewer characters or use another case for escapes.
+func canonicalURI(u LsDocumentURI) LsDocumentURI {
+	path, err := uri.ToPath(string(u))
+	if err != nil {
+		return u
+	}
+	return pathToURI(path)
 }
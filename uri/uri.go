@@ -0,0 +1,125 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package uri converts between file paths and the file URIs of the language
+// server protocol, ie, file:///home/a%20b/c.cc or file:///C:/src/main.cc.
+package uri
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// FromPath returns the file URI of the absolute path. \ separates directories
+// only on Windows; elsewhere it is part of a file name. Drive letters become
+// file:///C:/ and UNC paths \\host\share become file://host/share.
+// Everything but unreserved characters and separators is percent-encoded.
+func FromPath(path string) string {
+	path = filepath.ToSlash(path)
+	host := ""
+	switch {
+	case strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "///"):
+		host = strings.TrimPrefix(path, "//")
+		path = ""
+		if i := strings.IndexByte(host, '/'); i >= 0 {
+			host, path = host[:i], host[i:]
+		}
+	case hasDriveLetter(path):
+		path = "/" + path
+	case !strings.HasPrefix(path, "/"):
+		path = "/" + path
+	}
+
+	encoded := escape(path)
+	// Drive letters keep their colon, as most servers expect.
+	if hasDriveLetter(strings.TrimPrefix(path, "/")) {
+		encoded = path[:3] + encoded[5:]
+	}
+	return "file://" + escape(host) + encoded
+}
+
+// ToPath returns the path of a file URI, with the separators of the current
+// platform. The host of the URI, unless it is empty or localhost, makes it a
+// UNC path.
+func ToPath(uri string) (string, error) {
+	const scheme = "file:"
+	if len(uri) < len(scheme) || !strings.EqualFold(uri[:len(scheme)], scheme) {
+		return "", fmt.Errorf("%q is not a file URI", uri)
+	}
+	rest := uri[len(scheme):]
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	host := ""
+	if strings.HasPrefix(rest, "//") {
+		host = strings.TrimPrefix(rest, "//")
+		rest = ""
+		if i := strings.IndexByte(host, '/'); i >= 0 {
+			host, rest = host[:i], host[i:]
+		}
+	}
+	path, err := url.PathUnescape(rest)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URI %q: %s", uri, err.Error())
+	}
+	host, err = url.PathUnescape(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URI %q: %s", uri, err.Error())
+	}
+
+	switch {
+	case host != "" && !strings.EqualFold(host, "localhost"):
+		path = "//" + host + path
+	case hasDriveLetter(strings.TrimPrefix(path, "/")):
+		path = strings.TrimPrefix(path, "/")
+	case path == "":
+		path = "/"
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// hasDriveLetter returns if path starts with a Windows drive, ie, C: or c:/.
+func hasDriveLetter(path string) bool {
+	if len(path) < 2 || path[1] != ':' || (len(path) > 2 && path[2] != '/') {
+		return false
+	}
+	c := path[0]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// escape percent-encodes every byte of path that is not an unreserved
+// character of RFC 3986 or /.
+func escape(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if isUnreserved(c) || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uri
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromPath(t *testing.T) {
+	assert.Equal(t, "file:///a/b/c", FromPath("/a/b/c"))
+	assert.Equal(t, "file:///", FromPath("/"))
+	assert.Equal(t, "file:///a%20b/c%23d/%40e%2Bf%3Bg%3Dh", FromPath("/a b/c#d/@e+f;g=h"))
+	assert.Equal(t, "file:///a-b/c_d/e.f~g", FromPath("/a-b/c_d/e.f~g"))
+	assert.Equal(t, "file:///%C3%A9t%C3%A9/%E6%97%A5.go", FromPath("/été/日.go"))
+	assert.Equal(t, "file:///a%25b", FromPath("/a%b"))
+}
+
+func TestFromWindowsPath(t *testing.T) {
+	assert.Equal(t, "file:///C:/src/main.cc", FromPath("C:/src/main.cc"))
+	assert.Equal(t, "file:///c:/a%20b", FromPath("c:/a b"))
+	assert.Equal(t, "file:///D:", FromPath("D:"))
+	assert.Equal(t, "file://server/share/a%20b.txt", FromPath("//server/share/a b.txt"))
+	assert.Equal(t, "file://server", FromPath("//server"))
+	// Not a drive.
+	assert.Equal(t, "file:///ab%3A/c", FromPath("/ab:/c"))
+
+	// \ is a separator only on Windows.
+	if filepath.Separator == '\\' {
+		assert.Equal(t, "file:///C:/src/main.cc", FromPath(`C:\src\main.cc`))
+		assert.Equal(t, "file://server/share/a%20b.txt", FromPath(`\\server\share\a b.txt`))
+	} else {
+		assert.Equal(t, "file:///tmp/a%5Cb.c", FromPath(`/tmp/a\b.c`))
+		assert.Equal(t, "file:///%5C%5Cserver%5Cshare", FromPath(`\\server\share`))
+	}
+}
+
+func TestToPath(t *testing.T) {
+	for uri, path := range map[string]string{
+		"file:///a/b/c":               "/a/b/c",
+		"file:///a%20b/c%23d":         "/a b/c#d",
+		"file:///%C3%A9t%C3%A9":       "/été",
+		"file://localhost/a/b":        "/a/b",
+		"FILE:///a":                   "/a",
+		"file:/a/b":                   "/a/b",
+		"file://":                     "/",
+		"file:///C:/src/main.cc":      "C:/src/main.cc",
+		"file:///c%3A/src":            "c:/src",
+		"file://server/share/a%20b":   "//server/share/a b",
+		"file:///a/b?query#fragment":  "/a/b",
+		"file:///a/%E6%97%A5.go#L10":  "/a/日.go",
+		"file:///a/with+plus%2Bcoded": "/a/with+plus+coded",
+	} {
+		actual, err := ToPath(uri)
+		assert.NoError(t, err, uri)
+		assert.Equal(t, filepath.FromSlash(path), actual, uri)
+	}
+
+	_, err := ToPath("untitled:Untitled-1")
+	assert.Error(t, err)
+	_, err = ToPath("https://example.com/a")
+	assert.Error(t, err)
+	_, err = ToPath("file:///a%zz")
+	assert.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, path := range []string{
+		"/",
+		"/a/b/c.cc",
+		"/a b/c#d?e/f%20g",
+		"/!$&'()*+,;=:@/[]",
+		"/été/日本語.go",
+		"C:/src/main.cc",
+		"//server/share/dir/file.txt",
+		`/tmp/a\b.c`,
+	} {
+		actual, err := ToPath(FromPath(path))
+		assert.NoError(t, err, path)
+		assert.Equal(t, filepath.FromSlash(path), actual, path)
+	}
+}
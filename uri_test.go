@@ -15,6 +15,7 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,12 +24,32 @@ import (
 func TestURINix(t *testing.T) {
 	assert.Equal(t, LsDocumentURI("file:///a/b/c"), pathToURI("/a/b/c"))
 	assert.Equal(t, LsDocumentURI("file:///a/b/c/"), pathToURI("/a/b/c/"))
-	assert.Equal(t, LsDocumentURI("file:///a/b/c/"), pathToURI("\\a/b\\c\\"))
 	assert.Equal(t, LsDocumentURI("file:///"), pathToURI("/"))
+	// \ is part of a file name unless it is the separator.
+	if filepath.Separator == '\\' {
+		assert.Equal(t, LsDocumentURI("file:///a/b/c/"), pathToURI("\\a/b\\c\\"))
+	} else {
+		assert.Equal(t, LsDocumentURI("file:///a/b%5Cc"), pathToURI("/a/b\\c"))
+	}
 }
 
 func TestURISlashConversion(t *testing.T) {
 	assert.Equal(t, LsDocumentURI("file:///a%20b"), pathToURI("/a b"))
 }
 
-// TODO: add windows-style path tests
+func TestURIToPath(t *testing.T) {
+	assert.Equal(t, "/a b/c#d", uriToPath(pathToURI("/a b/c#d")))
+	assert.Equal(t, "/a/b", uriToPath("file:///a/b"))
+	assert.Equal(t, "untitled:1", uriToPath("untitled:1"))
+}
+
+func TestURIWindows(t *testing.T) {
+	assert.Equal(t, LsDocumentURI("file:///C:/a/b%20c"), pathToURI(filepath.FromSlash("C:/a/b c")))
+	assert.Equal(t, LsDocumentURI("file://server/share/a"), pathToURI(filepath.FromSlash("//server/share/a")))
+}
+
+func TestCanonicalURI(t *testing.T) {
+	assert.Equal(t, LsDocumentURI("file:///a%2Bb/%C3%A9"), canonicalURI("file:///a+b/%c3%a9"))
+	assert.Equal(t, LsDocumentURI("file:///a%2Bb/%C3%A9"), canonicalURI("file:///a+b/é"))
+	assert.Equal(t, LsDocumentURI("untitled:1"), canonicalURI("untitled:1"))
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// How long a language server gets to answer --version.
+const versionProbeTimeout = 5 * time.Second
+
+// Longest version kept from --version output.
+const maxVersionLength = 80
+
+// serverVersion formats the serverInfo of an initialize result, ie, "clangd
+// 17.0.3". Returns an empty string if the server did not report a version.
+func serverVersion(info *LsServerInfo) string {
+	if info == nil || info.Version == "" {
+		return ""
+	}
+	if info.Name == "" {
+		return info.Version
+	}
+	return info.Name + " " + info.Version
+}
+
+// parseVersionOutput returns the first non-empty line printed by --version.
+func parseVersionOutput(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > maxVersionLength {
+			line = line[:maxVersionLength] + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// probeVersion runs the program of the language server with --version, for
+// servers that do not send serverInfo when initializing.
+func (l *languageServer) probeVersion() {
+	ctx, cancel := context.WithTimeout(context.Background(), versionProbeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, l.args[0], "--version")
+	cmd.Dir = l.directory
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("Unable to detect the version of %s: %s", l.name(), err.Error())
+		return
+	}
+	if version := parseVersionOutput(string(output)); version != "" {
+		l.mu.Lock()
+		l.version = version
+		l.mu.Unlock()
+	}
+}
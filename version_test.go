@@ -0,0 +1,43 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerVersion(t *testing.T) {
+	assert.Equal(t, "", serverVersion(nil))
+	assert.Equal(t, "", serverVersion(&LsServerInfo{Name: "clangd"}))
+	assert.Equal(t, "clangd 17.0.3", serverVersion(&LsServerInfo{Name: "clangd", Version: "17.0.3"}))
+	assert.Equal(t, "0.1", serverVersion(&LsServerInfo{Version: "0.1"}))
+}
+
+func TestParseVersionOutput(t *testing.T) {
+	assert.Equal(t, "clangd version 17.0.3", parseVersionOutput("\n  clangd version 17.0.3  \nFeatures: linux\n"))
+	assert.Equal(t, "", parseVersionOutput("\n\n"))
+	long := parseVersionOutput(strings.Repeat("x", 200))
+	assert.Equal(t, strings.Repeat("x", maxVersionLength)+"...", long)
+}
+
+func TestServerInfoString(t *testing.T) {
+	info := ServerInfo{ID: 1, Args: []string{"clangd"}, Directory: "/src"}
+	assert.Equal(t, "1: [clangd] in /src", info.String())
+	info.Version = "clangd 17.0.3"
+	assert.Equal(t, "1: [clangd] in /src (clangd 17.0.3)", info.String())
+}
@@ -0,0 +1,329 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	easyjson "github.com/mailru/easyjson"
+)
+
+// wsRequest is sent by WebSocket clients. id is echoed back in the response
+// and may be any JSON value.
+type wsRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// wsMessage is sent to WebSocket clients. It is either the response to a
+// request, with result or error set, or an event.
+type wsMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Event  *DaemonEvent    `json:"event,omitempty"`
+}
+
+var wsUpgrader = websocket.Upgrader{CheckOrigin: checkWebSocketOrigin}
+
+// checkWebSocketOrigin allows connections from non-browser clients, pages
+// served from the gateway's own address and the origins in --http-origins.
+// Without this any website open in a browser could use the language servers.
+// A page whose name was rebound to the gateway has a matching Host, so the
+// host must also be one of the gateway's, as hostChecked requires of every
+// request.
+func checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == r.Host && allowedHost(gatewayHosts(gHTTP), u.Host) {
+		return true
+	}
+	for _, allowed := range strings.Split(gHTTPOrigins, ",") {
+		if strings.TrimSpace(allowed) == origin {
+			return true
+		}
+	}
+	log.Printf("Rejecting WebSocket connection from %s; allow it with --http-origins", origin)
+	return false
+}
+
+// serveWebSocket bridges a WebSocket connection to the daemon. Each text
+// message is a request
+//
+//	{"id": 1, "method": "definition", "params": {"file": "/src/a.c", "line": 3, "col": 5}}
+//
+// answered with {"id": 1, "result": ...} or {"id": 1, "error": "..."}.
+// Requests are handled concurrently, so responses may arrive out of order.
+// Methods:
+//
+//	servers, definition, diagnostics,  params as for the HTTP endpoints
+//	hover, moniker
+//	inlineValues                       params as for /inline-values
+//	linkedEditingRanges                params as for /linked-editing-ranges
+//	prepareRename                      params as for /prepare-rename
+//	semanticTokens                     params as for /semantic-tokens
+//	lsp                                {"file", "method", "params"}: send an
+//	                                   LSP request to the server for file;
+//	                                   only methods in wsLSPMethods
+//	subscribe                          {"ids": [...]}: receive {"event": ...}
+//	                                   for the servers, or all if empty
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already responded with an error.
+		return
+	}
+	defer conn.Close()
+	log.Printf("WebSocket connection from %s", r.RemoteAddr)
+
+	var writeMu sync.Mutex
+	send := func(message wsMessage) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.WriteJSON(message)
+	}
+	write := func(message wsMessage) {
+		switch fault, delay := gChaos.pick(message.Event == nil); fault {
+		case chaosDelay:
+			// Delayed messages may overtake others, as with a real server.
+			go func() {
+				time.Sleep(delay)
+				send(message)
+			}()
+			return
+		case chaosDrop:
+			log.Printf("chaos: dropping WebSocket message to %s", r.RemoteAddr)
+			return
+		case chaosError:
+			message = wsMessage{ID: message.ID, Error: chaosErr.Error()}
+		}
+		send(message)
+	}
+
+	methods := map[string]gatewayHandler{
+		"servers":     s.httpServers,
+		"definition":  s.httpDefinition,
+		"diagnostics": s.httpDiagnostics,
+		"hover":       s.httpHover,
+		"moniker":     s.httpMoniker,
+
+		"inlineValues":        s.httpInlineValues,
+		"linkedEditingRanges": s.httpLinkedEditingRanges,
+		"prepareRename":       s.httpPrepareRename,
+		"semanticTokens":      s.httpSemanticTokens,
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	var sub *eventSubscriber
+
+	for {
+		request := wsRequest{}
+		if err := conn.ReadJSON(&request); err != nil {
+			if _, isClose := err.(*websocket.CloseError); !isClose {
+				log.Printf("WebSocket connection from %s failed: %s", r.RemoteAddr, err.Error())
+			}
+			break
+		}
+		log.Printf("WS %s", request.Method)
+
+		release, err := gRateLimits.admit(addressClient("http", r.RemoteAddr))
+		if err != nil {
+			log.Printf("Rejecting WS %s: %s", request.Method, err.Error())
+			write(wsMessage{ID: request.ID, Error: err.Error()})
+			continue
+		}
+
+		switch request.Method {
+		case "subscribe":
+			release()
+			var params struct {
+				IDs idSet `json:"ids"`
+			}
+			if err := decodeParams(request.Params, &params); err != nil {
+				write(wsMessage{ID: request.ID, Error: err.Error()})
+				continue
+			}
+			if sub != nil {
+				write(wsMessage{ID: request.ID, Error: "already subscribed"})
+				continue
+			}
+			sub = daemonEvents.subscribe()
+			defer daemonEvents.unsubscribe(sub)
+			go func(sub *eventSubscriber, ids idSet) {
+				for {
+					select {
+					case <-done:
+						return
+					case e := <-sub.c:
+						if ids.has(e.Server.ID) {
+							write(wsMessage{Event: &e})
+						}
+					}
+				}
+			}(sub, params.IDs)
+			write(wsMessage{ID: request.ID, Result: true})
+
+		case "lsp":
+			go func(request wsRequest) {
+				defer release()
+				result, err := s.wsLSP(request.Params)
+				write(wsResponse(request.ID, result, err))
+			}(request)
+
+		default:
+			handler, has := methods[request.Method]
+			if !has {
+				release()
+				write(wsMessage{ID: request.ID, Error: fmt.Sprintf("unknown method %q", request.Method)})
+				continue
+			}
+			go func(request wsRequest) {
+				defer release()
+				query, err := wsQuery(request.Params)
+				var result interface{}
+				if err == nil {
+					result, err = handler(query)
+				}
+				write(wsResponse(request.ID, result, err))
+			}(request)
+		}
+	}
+}
+
+func wsResponse(id json.RawMessage, result interface{}, err error) wsMessage {
+	if err != nil {
+		return wsMessage{ID: id, Error: err.Error()}
+	}
+	return wsMessage{ID: id, Result: result}
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("invalid params: %s", err.Error())
+	}
+	return nil
+}
+
+// wsQuery converts the params of a request, a JSON object with string or
+// number values, to the query of the HTTP endpoint with the same name.
+func wsQuery(params json.RawMessage) (url.Values, error) {
+	var fields map[string]interface{}
+	if err := decodeParams(params, &fields); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	for name, value := range fields {
+		switch value.(type) {
+		case string, float64:
+			query.Set(name, fmt.Sprint(value))
+		default:
+			return nil, fmt.Errorf("param %s must be a string or number", name)
+		}
+	}
+	return query, nil
+}
+
+// wsLSPMethods are the LSP requests the lsp method of the WebSocket endpoint
+// forwards. They only read the project; requests that may change files or run
+// commands, ie, workspace/executeCommand, are not forwarded.
+var wsLSPMethods = map[string]bool{
+	"textDocument/hover":                     true,
+	"textDocument/completion":                true,
+	"completionItem/resolve":                 true,
+	"textDocument/signatureHelp":             true,
+	"textDocument/definition":                true,
+	"textDocument/declaration":               true,
+	"textDocument/typeDefinition":            true,
+	"textDocument/implementation":            true,
+	"textDocument/references":                true,
+	"textDocument/documentHighlight":         true,
+	"textDocument/documentSymbol":            true,
+	"textDocument/documentLink":              true,
+	"textDocument/codeLens":                  true,
+	"textDocument/foldingRange":              true,
+	"textDocument/selectionRange":            true,
+	"textDocument/inlayHint":                 true,
+	"textDocument/inlineValue":               true,
+	"textDocument/linkedEditingRange":        true,
+	"textDocument/moniker":                   true,
+	"textDocument/prepareRename":             true,
+	"textDocument/semanticTokens/full":       true,
+	"textDocument/semanticTokens/full/delta": true,
+	"textDocument/semanticTokens/range":      true,
+	"textDocument/prepareCallHierarchy":      true,
+	"callHierarchy/incomingCalls":            true,
+	"callHierarchy/outgoingCalls":            true,
+	"textDocument/prepareTypeHierarchy":      true,
+	"typeHierarchy/supertypes":               true,
+	"typeHierarchy/subtypes":                 true,
+	"workspace/symbol":                       true,
+	"textDocument/diagnostic":                true,
+}
+
+// wsLSP sends an LSP request in wsLSPMethods to the language servers the
+// method is routed to for a file, which is opened first, and merges their
+// results. Positions in params are sent to the servers as-is.
+func (s *Server) wsLSP(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		File   string          `json:"file"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Method == "" {
+		return nil, fmt.Errorf("method is required")
+	}
+	if !wsLSPMethods[p.Method] {
+		return nil, fmt.Errorf("%s is not allowed; only requests that read the project are", p.Method)
+	}
+	file, servers, err := s.queryServers(url.Values{"file": {p.File}}, p.Method)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		if err := server.checkMethod(p.Method); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]easyjson.RawMessage, len(servers))
+	err = callServers(servers, p.Method, func(i int, l *languageServer) (err error) {
+		if _, err := l.openDocument(file); err != nil {
+			return err
+		}
+		results[i], err = l.call(p.Method, easyjson.RawMessage(p.Params), queryTimeout())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(mergeResults(results)), nil
+}
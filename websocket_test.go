@@ -0,0 +1,71 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWSQuery(t *testing.T) {
+	query, err := wsQuery(json.RawMessage(`{"file": "/src/a.c", "line": 3, "col": 12}`))
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"file": {"/src/a.c"}, "line": {"3"}, "col": {"12"}}, query)
+
+	query, err = wsQuery(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, query)
+
+	_, err = wsQuery(json.RawMessage(`{"file": ["/src/a.c"]}`))
+	assert.Error(t, err)
+	_, err = wsQuery(json.RawMessage(`[]`))
+	assert.Error(t, err)
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	defer func(origins string) { gHTTPOrigins = origins }(gHTTPOrigins)
+	gHTTPOrigins = "http://localhost:5173, http://127.0.0.1:8080"
+
+	request := func(origin string) *http.Request {
+		r := &http.Request{Host: "localhost:7658", Header: http.Header{}}
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+	assert.True(t, checkWebSocketOrigin(request("")))
+	assert.True(t, checkWebSocketOrigin(request("http://localhost:7658")))
+	assert.True(t, checkWebSocketOrigin(request("http://localhost:5173")))
+	assert.True(t, checkWebSocketOrigin(request("http://127.0.0.1:8080")))
+	assert.False(t, checkWebSocketOrigin(request("https://example.com")))
+	assert.False(t, checkWebSocketOrigin(request("http://localhost:5174")))
+
+	// A page of evil.com rebound to the gateway sends its own name as Host.
+	rebound := &http.Request{Host: "evil.com:7658", Header: http.Header{"Origin": {"http://evil.com:7658"}}}
+	assert.False(t, checkWebSocketOrigin(rebound))
+}
+
+func TestWSLSPMethods(t *testing.T) {
+	s := &Server{config: &Config{}}
+	_, err := s.wsLSP(json.RawMessage(`{"file": "/src/a.c", "method": "workspace/executeCommand", "params": {"command": "rm"}}`))
+	assert.EqualError(t, err, "workspace/executeCommand is not allowed; only requests that read the project are")
+	_, err = s.wsLSP(json.RawMessage(`{"file": "/src/a.c", "method": "textDocument/rename"}`))
+	assert.Error(t, err)
+	assert.True(t, wsLSPMethods["textDocument/hover"])
+}
@@ -0,0 +1,309 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Directories not searched for nested workspaces.
+var skipWorkspaceDirs = map[string]bool{
+	"node_modules": true,
+	"testdata":     true,
+	"vendor":       true,
+}
+
+// How many directories deep nested workspaces are searched for.
+const maxWorkspaceDepth = 3
+
+// findUp returns the closest directory at or above dir that contains name.
+func findUp(dir, name string) (string, bool) {
+	for {
+		if fileExists(filepath.Join(dir, name)) {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// findNested returns the directories below dir, up to maxWorkspaceDepth
+// deep, that contain name. Hidden directories and those in
+// skipWorkspaceDirs are left out.
+func findNested(dir, name string) []string {
+	var found []string
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || skipWorkspaceDirs[entry.Name()] {
+				continue
+			}
+			sub := filepath.Join(dir, entry.Name())
+			if fileExists(filepath.Join(sub, name)) {
+				found = append(found, sub)
+			} else if depth < maxWorkspaceDepth {
+				walk(sub, depth+1)
+			}
+		}
+	}
+	walk(dir, 1)
+	return found
+}
+
+// goWorkspace returns where gopls should run for the project in dir and
+// its workspace folders. If dir is inside of a go.work workspace gopls runs
+// at its root, so that it sees every module the workspace uses. If dir
+// contains go.work workspaces they become the workspace folders. found is
+// false if there is no go.work.
+func goWorkspace(dir string) (root string, folders []string, found bool) {
+	if root, found := findUp(dir, "go.work"); found {
+		return root, []string{root}, true
+	}
+	if nested := findNested(dir, "go.work"); len(nested) > 0 {
+		return dir, nested, true
+	}
+	return dir, nil, false
+}
+
+// Language servers for JavaScript and TypeScript, which need to see every
+// package of a monorepo.
+var jsServers = map[string]bool{
+	"typescript-language-server": true,
+	"vtsls":                      true,
+	"tsserver":                   true,
+}
+
+var jsLanguages = map[string]bool{
+	"javascript":      true,
+	"javascriptreact": true,
+	"typescript":      true,
+	"typescriptreact": true,
+}
+
+// How many directories deep packages of a monorepo are searched for.
+const maxPackageDepth = 5
+
+// packageJSON holds the fields of package.json that describe a monorepo.
+// Workspaces is either a list of globs or an object with a packages list.
+type packageJSON struct {
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// npmWorkspaces returns the package globs of the package.json in dir, and
+// false if it does not declare workspaces.
+func npmWorkspaces(dir string) ([]string, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, false
+	}
+	var p packageJSON
+	if err := json.Unmarshal(data, &p); err != nil || len(p.Workspaces) == 0 {
+		return nil, false
+	}
+	var globs []string
+	if err := json.Unmarshal(p.Workspaces, &globs); err == nil {
+		return globs, true
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(p.Workspaces, &object); err == nil {
+		return object.Packages, true
+	}
+	return nil, false
+}
+
+// pnpmWorkspaces returns the package globs of pnpm-workspace.yaml in dir,
+// and false if there is none. Only the packages list is read, ie,
+//
+//	packages:
+//	  - 'packages/*'
+//	  - '!**/test/**'
+func pnpmWorkspaces(dir string) ([]string, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml"))
+	if err != nil {
+		return nil, false
+	}
+	var globs []string
+	inPackages := false
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-"):
+			inPackages = trimmed == "packages:"
+		case inPackages && strings.HasPrefix(trimmed, "-"):
+			glob := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			globs = append(globs, strings.Trim(glob, `'"`))
+		}
+	}
+	return globs, true
+}
+
+// jsWorkspaceGlobs returns the package globs of the monorepo whose root is
+// dir, and false if dir is not the root of one. turbo.json relies on the
+// workspaces of the package manager, so it may have no globs.
+func jsWorkspaceGlobs(dir string) ([]string, bool) {
+	if globs, found := pnpmWorkspaces(dir); found {
+		return globs, true
+	}
+	if globs, found := npmWorkspaces(dir); found {
+		return globs, true
+	}
+	return nil, fileExists(filepath.Join(dir, "turbo.json"))
+}
+
+// matchWorkspaceGlob matches the slash separated relative path rel against
+// a package glob, in which ** matches any number of directories.
+func matchWorkspaceGlob(glob, rel string) bool {
+	glob = strings.Trim(strings.TrimPrefix(glob, "./"), "/")
+	var match func(glob, rel []string) bool
+	match = func(glob, rel []string) bool {
+		if len(glob) == 0 {
+			return len(rel) == 0
+		}
+		if glob[0] == "**" {
+			for i := 0; i <= len(rel); i++ {
+				if match(glob[1:], rel[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(rel) == 0 {
+			return false
+		}
+		matched, _ := path.Match(glob[0], rel[0])
+		return matched && match(glob[1:], rel[1:])
+	}
+	return match(strings.Split(glob, "/"), strings.Split(rel, "/"))
+}
+
+// jsPackages returns the directories below root that contain package.json
+// and match globs. Globs starting with ! exclude packages.
+func jsPackages(root string, globs []string) []string {
+	var packages []string
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || skipWorkspaceDirs[entry.Name()] {
+				continue
+			}
+			sub := filepath.Join(dir, entry.Name())
+			if fileExists(filepath.Join(sub, "package.json")) {
+				rel, _ := filepath.Rel(root, sub)
+				included := false
+				for _, glob := range globs {
+					if strings.HasPrefix(glob, "!") {
+						if matchWorkspaceGlob(glob[1:], filepath.ToSlash(rel)) {
+							included = false
+							break
+						}
+					} else if matchWorkspaceGlob(glob, filepath.ToSlash(rel)) {
+						included = true
+					}
+				}
+				if included {
+					packages = append(packages, sub)
+				}
+			}
+			if depth < maxPackageDepth {
+				walk(sub, depth+1)
+			}
+		}
+	}
+	walk(root, 1)
+	return packages
+}
+
+// jsWorkspace returns where a JavaScript or TypeScript server should run for
+// the project in dir and its workspace folders. If dir is inside of a
+// monorepo, which is declared by pnpm-workspace.yaml, the workspaces of
+// package.json or turbo.json, the server runs at its root with every package
+// as a workspace folder, so that one server covers the monorepo. found is
+// false if dir is not part of a monorepo.
+func jsWorkspace(dir string) (root string, folders []string, found bool) {
+	for root = dir; ; {
+		if globs, found := jsWorkspaceGlobs(root); found {
+			folders = jsPackages(root, globs)
+			if len(folders) == 0 {
+				folders = []string{root}
+			}
+			return root, folders, true
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return dir, nil, false
+		}
+		root = parent
+	}
+}
+
+// detectWorkspace returns args with the directory and workspace folders the
+// server should be started with when the project is part of a workspace it
+// needs to see as a whole. Unless args sets workspace folders, only the
+// directory of args is sent as the root.
+func (c *Config) detectWorkspace(args StartArgs) StartArgs {
+	if len(args.WorkspaceFolders) > 0 || args.Directory == "" {
+		return args
+	}
+	bin := c.resolveBin(args.Bin)
+	language := c.languageFor(bin, args.Language)
+	var root, kind string
+	var folders []string
+	var found bool
+	switch {
+	case language == "go" || executableName(bin) == "gopls":
+		root, folders, found = goWorkspace(args.Directory)
+		kind = "go.work workspace"
+	case jsLanguages[language] || jsServers[executableName(bin)]:
+		root, folders, found = jsWorkspace(args.Directory)
+		kind = "monorepo"
+	}
+	if !found {
+		return args
+	}
+	if root != args.Directory {
+		log.Printf("Starting %s at %s; %s is part of its %s", args.Bin, root, args.Directory, kind)
+	}
+	args.Directory = root
+	args.WorkspaceFolders = folders
+	return args
+}
+
+// workspaceFolders returns the workspace folders sent in initialize.
+func workspaceFolders(paths []string) []LsWorkspaceFolder {
+	var folders []LsWorkspaceFolder
+	for _, path := range paths {
+		folders = append(folders, LsWorkspaceFolder{URI: pathToURI(path), Name: filepath.Base(path)})
+	}
+	return folders
+}
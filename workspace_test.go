@@ -0,0 +1,123 @@
+// Copyright 2018 Jacob Dufault
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeTree creates files, given relative to dir, with empty contents.
+func makeTree(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+}
+
+func TestGoWorkspace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	makeTree(t, dir,
+		"repo/go.work", "repo/a/go.mod", "repo/b/go.mod",
+		"mono/services/go.work", "mono/tools/x/go.work", "mono/vendor/y/go.work", "mono/.cache/go.work",
+		"plain/go.mod")
+
+	root, folders, found := goWorkspace(filepath.Join(dir, "repo", "a"))
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "repo"), root)
+	assert.Equal(t, []string{filepath.Join(dir, "repo")}, folders)
+
+	root, folders, found = goWorkspace(filepath.Join(dir, "mono"))
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "mono"), root)
+	assert.Equal(t, []string{filepath.Join(dir, "mono", "services"), filepath.Join(dir, "mono", "tools", "x")}, folders)
+
+	_, _, found = goWorkspace(filepath.Join(dir, "plain"))
+	assert.False(t, found)
+}
+
+func TestDetectWorkspace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	makeTree(t, dir, "go.work", "a/go.mod")
+	sub := filepath.Join(dir, "a")
+
+	config := &Config{Languages: map[string]LanguageConfig{"go": {Command: "/opt/go/gopls-wrapper"}}}
+	for _, bin := range []string{"gopls", "/usr/bin/gopls serve", "/opt/go/gopls-wrapper"} {
+		args := config.detectWorkspace(StartArgs{Bin: bin, Directory: sub})
+		assert.Equal(t, dir, args.Directory, bin)
+		assert.Equal(t, []string{dir}, args.WorkspaceFolders, bin)
+	}
+
+	args := config.detectWorkspace(StartArgs{Bin: "clangd", Directory: sub})
+	assert.Equal(t, sub, args.Directory)
+	assert.Empty(t, args.WorkspaceFolders)
+
+	args = config.detectWorkspace(StartArgs{Bin: "gopls", Directory: sub, WorkspaceFolders: []string{sub}})
+	assert.Equal(t, sub, args.Directory)
+}
+
+func TestJSWorkspace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lspc-workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	makeTree(t, dir,
+		"pnpm/packages/a/package.json", "pnpm/packages/b/test/package.json", "pnpm/apps/web/src/index.ts",
+		"pnpm/apps/web/package.json", "pnpm/node_modules/dep/package.json",
+		"npm/libs/x/package.json", "npm/libs/y/package.json",
+		"turbo/turbo.json", "turbo/web/package.json",
+		"single/package.json")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pnpm", "pnpm-workspace.yaml"),
+		[]byte("# comment\npackages:\n  - 'packages/**'\n  - \"apps/*\"\n  - '!**/test/**'\ncatalog:\n  - ignored\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "npm", "package.json"),
+		[]byte(`{"name": "npm", "workspaces": {"packages": ["libs/x"]}}`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "single", "package.json"), []byte(`{"name": "single"}`), 0644))
+
+	root, folders, found := jsWorkspace(filepath.Join(dir, "pnpm", "apps", "web", "src"))
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "pnpm"), root)
+	assert.Equal(t, []string{filepath.Join(dir, "pnpm", "apps", "web"), filepath.Join(dir, "pnpm", "packages", "a")}, folders)
+
+	root, folders, found = jsWorkspace(filepath.Join(dir, "npm", "libs", "y"))
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "npm"), root)
+	assert.Equal(t, []string{filepath.Join(dir, "npm", "libs", "x")}, folders)
+
+	root, folders, found = jsWorkspace(filepath.Join(dir, "turbo"))
+	assert.True(t, found)
+	assert.Equal(t, []string{filepath.Join(dir, "turbo")}, folders)
+
+	_, _, found = jsWorkspace(filepath.Join(dir, "single"))
+	assert.False(t, found)
+
+	args := (&Config{}).detectWorkspace(StartArgs{Bin: "typescript-language-server --stdio", Directory: filepath.Join(dir, "npm", "libs", "x")})
+	assert.Equal(t, filepath.Join(dir, "npm"), args.Directory)
+}
+
+func TestMatchWorkspaceGlob(t *testing.T) {
+	assert.True(t, matchWorkspaceGlob("packages/*", "packages/a"))
+	assert.False(t, matchWorkspaceGlob("packages/*", "packages/a/b"))
+	assert.True(t, matchWorkspaceGlob("./packages/**", "packages/a/b"))
+	assert.True(t, matchWorkspaceGlob("**/test/**", "packages/b/test"))
+	assert.False(t, matchWorkspaceGlob("apps/*", "packages/a"))
+}